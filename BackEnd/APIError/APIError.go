@@ -0,0 +1,125 @@
+// Package APIError writes every error answer of the API in one JSON shape, DataBase.ErrorResponse,
+// with a stable machine-readable code, so clients can branch on the code instead of the message.
+// It also tags each request with an ID that is echoed in the X-Request-ID header, in error
+// bodies and in the server log.
+package APIError
+
+import (
+	"BackEnd/DataBase"
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// Code identifies the kind of error. Codes never change once published; add a new one instead.
+type Code string
+
+const (
+	// Malformed requests and invalid input
+	MalformedRequest Code = "MALFORMED_REQUEST"
+	ValidationFailed Code = "VALIDATION_FAILED"
+	RouteNotFound    Code = "ROUTE_NOT_FOUND"
+	MethodNotAllowed Code = "METHOD_NOT_ALLOWED"
+	InvalidImport    Code = "INVALID_IMPORT"
+
+	// Authentication and authorization
+	Unauthorized         Code = "UNAUTHORIZED"
+	Forbidden            Code = "FORBIDDEN"
+	InvalidCredentials   Code = "INVALID_CREDENTIALS"
+	TooManyAttempts      Code = "TOO_MANY_ATTEMPTS"
+	SecondFactorRequired Code = "SECOND_FACTOR_REQUIRED"
+	InvalidSecondFactor  Code = "INVALID_SECOND_FACTOR"
+	InvalidToken         Code = "INVALID_TOKEN"
+	InvalidBootstrap     Code = "INVALID_BOOTSTRAP_TOKEN"
+	NoPendingEnrolment   Code = "NO_PENDING_ENROLMENT"
+
+	// Missing records
+	SportNotFound    Code = "SPORT_NOT_FOUND"
+	CourtNotFound    Code = "COURT_NOT_FOUND"
+	BookingNotFound  Code = "BOOKING_NOT_FOUND"
+	CustomerNotFound Code = "CUSTOMER_NOT_FOUND"
+	AdminNotFound    Code = "ADMIN_NOT_FOUND"
+	BlackoutNotFound Code = "BLACKOUT_NOT_FOUND"
+
+	// Conflicts with the current state
+	SportAlreadyExists      Code = "SPORT_ALREADY_EXISTS"
+	CourtAlreadyExists      Code = "COURT_ALREADY_EXISTS"
+	CustomerAlreadyExists   Code = "CUSTOMER_ALREADY_EXISTS"
+	AdminAlreadyExists      Code = "ADMIN_ALREADY_EXISTS"
+	BlackoutAlreadyExists   Code = "BLACKOUT_ALREADY_EXISTS"
+	AlreadyBootstrapped     Code = "ALREADY_BOOTSTRAPPED"
+	SlotUnavailable         Code = "SLOT_UNAVAILABLE"
+	CourtUnavailable        Code = "COURT_UNAVAILABLE"
+	InvalidStatusTransition Code = "INVALID_STATUS_TRANSITION"
+	NotBookingOwner         Code = "NOT_BOOKING_OWNER"
+	ParentDeleted           Code = "PARENT_DELETED"
+	LastSuperAdmin          Code = "LAST_SUPER_ADMIN"
+	AdminDeactivated        Code = "ADMIN_DEACTIVATED"
+	SecondFactorEnrolled    Code = "SECOND_FACTOR_ALREADY_ENROLLED"
+	IdempotencyKeyReused    Code = "IDEMPOTENCY_KEY_REUSED"
+	IdempotencyKeyInFlight  Code = "IDEMPOTENCY_KEY_IN_FLIGHT"
+
+	InternalError Code = "INTERNAL_ERROR"
+)
+
+// Field returns the validation detail for one field.
+func Field(field, message string) DataBase.FieldError {
+	return DataBase.FieldError{Field: field, Message: message}
+}
+
+// Write answers status with an error of the given code and message.
+func Write(w http.ResponseWriter, r *http.Request, status int, code Code, message string) {
+	write(w, r, status, DataBase.ErrorResponse{Code: string(code), Message: message})
+}
+
+// Validation answers 400 VALIDATION_FAILED, listing each rejected field.
+func Validation(w http.ResponseWriter, r *http.Request, message string, details ...DataBase.FieldError) {
+	write(w, r, http.StatusBadRequest, DataBase.ErrorResponse{Code: string(ValidationFailed), Message: message, Details: details})
+}
+
+// Malformed answers 400 MALFORMED_REQUEST, for a body or parameter that cannot be parsed at all.
+func Malformed(w http.ResponseWriter, r *http.Request, message string) {
+	Write(w, r, http.StatusBadRequest, MalformedRequest, message)
+}
+
+// ImportRejected answers 400 INVALID_IMPORT for an uploaded file that was not imported, with
+// the import's report listing what is wrong with it.
+func ImportRejected(w http.ResponseWriter, r *http.Request, message string, report interface{}) {
+	write(w, r, http.StatusBadRequest, DataBase.ErrorResponse{Code: string(InvalidImport), Message: message, Report: report})
+}
+
+// SlotTaken answers 409 SLOT_UNAVAILABLE, offering the free slots in alternatives instead.
+func SlotTaken(w http.ResponseWriter, r *http.Request, alternatives []DataBase.SlotAlternative) {
+	write(w, r, http.StatusConflict, DataBase.ErrorResponse{Code: string(SlotUnavailable),
+		Message: "Slot is already booked or unavailable", Alternatives: alternatives})
+}
+
+// Internal answers 500 INTERNAL_ERROR with message and logs err under the request ID.
+// The cause is only logged, since it may reveal details of the database.
+func Internal(w http.ResponseWriter, r *http.Request, message string, err error) {
+	if err != nil {
+		log.Printf("request %s: %s: %v", RequestIDFrom(r.Context()), message, err)
+	}
+	Write(w, r, http.StatusInternalServerError, InternalError, message)
+}
+
+// NotFoundHandler answers requests that match no route.
+func NotFoundHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Write(w, r, http.StatusNotFound, RouteNotFound, "No such endpoint")
+	})
+}
+
+// MethodNotAllowedHandler answers requests whose path matches a route but not its method.
+func MethodNotAllowedHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Write(w, r, http.StatusMethodNotAllowed, MethodNotAllowed, "Method not allowed")
+	})
+}
+
+func write(w http.ResponseWriter, r *http.Request, status int, body DataBase.ErrorResponse) {
+	body.RequestID = RequestIDFrom(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,49 @@
+package APIError
+
+import (
+	"BackEnd/DataBase"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidationCarriesRequestID(t *testing.T) {
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Validation(w, r, "Invalid court", Field("name", "is required"))
+	}))
+
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/courts", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	handler.ServeHTTP(rr, req)
+
+	var body DataBase.ErrorResponse
+	json.Unmarshal(rr.Body.Bytes(), &body)
+	if rr.Code != http.StatusBadRequest || body.Code != "VALIDATION_FAILED" || body.Message != "Invalid court" {
+		t.Fatalf("unexpected error %d %s", rr.Code, rr.Body.String())
+	}
+	if len(body.Details) != 1 || body.Details[0] != Field("name", "is required") {
+		t.Errorf("expected the field detail, got %+v", body.Details)
+	}
+	if body.RequestID != "abc-123" || rr.Header().Get(RequestIDHeader) != "abc-123" {
+		t.Errorf("expected the client's request ID to be kept, got %q and %q", body.RequestID, rr.Header().Get(RequestIDHeader))
+	}
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	var seen string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFrom(r.Context())
+	}))
+
+	for _, sent := range []string{"", "has spaces", "line\nbreak"} {
+		rr := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set(RequestIDHeader, sent)
+		handler.ServeHTTP(rr, req)
+		if len(seen) != 32 || seen == sent || rr.Header().Get(RequestIDHeader) != seen {
+			t.Errorf("%q: expected a generated ID in the context and header, got %q", sent, seen)
+		}
+	}
+}
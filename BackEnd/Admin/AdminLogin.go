@@ -1,45 +1,139 @@
 package Admin
 
 import (
+	"BackEnd/APIError"
 	"BackEnd/DataBase"
 	"encoding/json"
+	"fmt"
+	"math"
 	"net/http"
+	"time"
 )
 
 type LoginRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username     string `json:"username"`
+	Password     string `json:"password"`
+	TOTPCode     string `json:"totp_code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+// LoginResponse carries the session to send as "Authorization: Bearer <token>" to the admin routes.
+type LoginResponse struct {
+	Message   string    `json:"message"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 // LoginAdmin handles admin login requests.
 // @Summary Admin login
-// @Description Validates admin credentials (plain text match)
+// @Description Validates admin credentials. Repeated failures lock the username and client IP with a growing delay.
+// @Description When the admin has enrolled TOTP, a valid totp_code or unused recovery_code is also required.
+// @Description The returned token is the admin session for the routes that need one.
 // @Tags admins
 // @Accept json
 // @Produce json
 // @Param credentials body LoginRequest true "Admin credentials"
-// @Success 200 {object} map[string]string "Login successful"
-// @Failure 401 {object} map[string]string "Unauthorized"
-// @Failure 400 {object} map[string]string "Invalid request body"
+// @Success 200 {object} LoginResponse "Login successful"
+// @Failure 401 {object} DataBase.ErrorResponse "INVALID_CREDENTIALS, SECOND_FACTOR_REQUIRED or INVALID_SECOND_FACTOR"
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid request body"
+// @Failure 429 {object} DataBase.ErrorResponse "Too many failed attempts"
 // @Router /AdminLogin [post]
-func AdminLogin(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) AdminLogin(w http.ResponseWriter, r *http.Request) {
 	var loginReq LoginRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&loginReq); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		APIError.Malformed(w, r, "Invalid request body")
 		return
 	}
 
-	var admin DataBase.Admin
-	// GORM raw query or quoted Where clause is needed for Case Sensitive columns in Postgres
-	result := DataBase.DB.Where("\"Username\" = ? AND \"Password\" = ?", loginReq.Username, loginReq.Password).First(&admin)
-	if result.Error != nil || result.RowsAffected == 0 {
-		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+	admin, keys, ok := h.authenticateAdmin(w, r, loginReq.Username, loginReq.Password)
+	if !ok {
+		return
+	}
+
+	if admin.TOTP_Enabled {
+		if loginReq.TOTPCode == "" && loginReq.RecoveryCode == "" {
+			APIError.Write(w, r, http.StatusUnauthorized, APIError.SecondFactorRequired, "Second factor required")
+			return
+		}
+
+		if !h.verifySecondFactor(admin, loginReq.TOTPCode, loginReq.RecoveryCode) {
+			APIError.Write(w, r, http.StatusUnauthorized, APIError.InvalidSecondFactor, "Invalid second factor")
+			return
+		}
+	}
+
+	h.clearLoginFailures(keys)
+	now := time.Now()
+	admin.Last_Login = &now
+	h.Store.Admins().Save(&admin)
+
+	token, expires, err := h.issueSession(admin, now)
+	if err != nil {
+		APIError.Internal(w, r, "Failed to issue admin session", err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Login successful",
+	json.NewEncoder(w).Encode(LoginResponse{
+		Message:   "Login successful",
+		Token:     token,
+		ExpiresAt: expires,
 	})
 }
+
+// authenticateAdmin counts the attempt against the lockout keys, then checks the password for an admin.
+// On failure it writes the response itself and returns ok == false.
+func (h *Handler) authenticateAdmin(w http.ResponseWriter, r *http.Request, username, password string) (admin DataBase.Admin, keys []string, ok bool) {
+	now := time.Now()
+	keys = loginAttemptKeys(username, r)
+
+	until, locked, err := h.reserveLoginAttempt(keys, now)
+	if err != nil {
+		APIError.Internal(w, r, "Failed to record login attempt", err)
+		return admin, keys, false
+	}
+	if locked {
+		retryAfter := int(math.Ceil(until.Sub(now).Seconds()))
+		w.Header().Set("Retry-After", fmt.Sprint(retryAfter))
+		APIError.Write(w, r, http.StatusTooManyRequests, APIError.TooManyAttempts, "Too many failed login attempts. Try again later.")
+		return admin, keys, false
+	}
+
+	admin, err = h.Store.Admins().FindByUsername(username)
+	if err != nil || admin.Status != DataBase.AdminStatusActive || !checkPassword(admin.Password, password) {
+		APIError.Write(w, r, http.StatusUnauthorized, APIError.InvalidCredentials, "Invalid username or password")
+		return admin, keys, false
+	}
+
+	// Upgrade passwords stored before hashing was introduced
+	if !isPasswordHash(admin.Password) {
+		if hash, err := hashPassword(password); err == nil {
+			admin.Password = hash
+			h.Store.Admins().Save(&admin)
+		}
+	}
+
+	return admin, keys, true
+}
+
+// verifySecondFactor accepts either a current TOTP code or an unused recovery code. Both are burned,
+// a TOTP code by moving the admin's last accepted step up to its own.
+func (h *Handler) verifySecondFactor(admin DataBase.Admin, totpCode, recoveryCode string) bool {
+	if totpCode != "" {
+		return h.useTOTP(admin, totpCode)
+	}
+
+	used, err := h.Store.Admins().UseRecoveryCode(admin.Admin_ID, hashRecoveryCode(recoveryCode), time.Now())
+	return err == nil && used
+}
+
+// useTOTP checks a TOTP code and records its step, refusing a code whose step was already used.
+func (h *Handler) useTOTP(admin DataBase.Admin, code string) bool {
+	step, ok := validateTOTP(admin.TOTP_Secret, code, time.Now())
+	if !ok {
+		return false
+	}
+	used, err := h.Store.Admins().UseTOTPStep(admin.Admin_ID, step)
+	return err == nil && used
+}
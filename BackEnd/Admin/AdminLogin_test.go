@@ -2,35 +2,26 @@ package Admin
 
 import (
 	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
-
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 )
 
-func setupTestDB() *gorm.DB {
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
-	if err != nil {
-		panic("failed to connect to test database")
-	}
-	db.AutoMigrate(&DataBase.Admin{})
-	return db
-}
-
 func TestAdminLogin_Success(t *testing.T) {
-	db := setupTestDB()
-	DataBase.DB = db
+	store := TestStore.Open(t)
+	h := NewHandler(store)
 
 	// Add test admin
 	admin := DataBase.Admin{
 		Username: "adminuser",
 		Password: "adminpass",
 	}
-	db.Create(&admin)
+	store.Admins().Create(&admin)
 
 	// Create request body
 	loginReq := LoginRequest{
@@ -46,7 +37,7 @@ func TestAdminLogin_Success(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	rr := httptest.NewRecorder()
-	handler := http.HandlerFunc(AdminLogin)
+	handler := http.HandlerFunc(h.AdminLogin)
 	handler.ServeHTTP(rr, req)
 
 	if status := rr.Code; status != http.StatusOK {
@@ -61,15 +52,15 @@ func TestAdminLogin_Success(t *testing.T) {
 }
 
 func TestAdminLogin_InvalidCredentials(t *testing.T) {
-	db := setupTestDB()
-	DataBase.DB = db
+	store := TestStore.Open(t)
+	h := NewHandler(store)
 
 	// Add test admin
 	admin := DataBase.Admin{
 		Username: "adminuser",
 		Password: "adminpass",
 	}
-	db.Create(&admin)
+	store.Admins().Create(&admin)
 
 	// Wrong password
 	loginReq := LoginRequest{
@@ -82,7 +73,7 @@ func TestAdminLogin_InvalidCredentials(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	rr := httptest.NewRecorder()
-	handler := http.HandlerFunc(AdminLogin)
+	handler := http.HandlerFunc(h.AdminLogin)
 	handler.ServeHTTP(rr, req)
 
 	if rr.Code != http.StatusUnauthorized {
@@ -91,15 +82,15 @@ func TestAdminLogin_InvalidCredentials(t *testing.T) {
 }
 
 func TestAdminLogin_InvalidUsername(t *testing.T) {
-	db := setupTestDB()
-	DataBase.DB = db
+	store := TestStore.Open(t)
+	h := NewHandler(store)
 
 	// Valid admin in DB
 	admin := DataBase.Admin{
 		Username: "adminuser",
 		Password: "adminpass",
 	}
-	db.Create(&admin)
+	store.Admins().Create(&admin)
 
 	// Wrong username
 	loginReq := LoginRequest{
@@ -112,7 +103,7 @@ func TestAdminLogin_InvalidUsername(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	rr := httptest.NewRecorder()
-	handler := http.HandlerFunc(AdminLogin)
+	handler := http.HandlerFunc(h.AdminLogin)
 	handler.ServeHTTP(rr, req)
 
 	if rr.Code != http.StatusUnauthorized {
@@ -121,14 +112,101 @@ func TestAdminLogin_InvalidUsername(t *testing.T) {
 }
 
 func TestAdminLogin_InvalidJSON(t *testing.T) {
+	h := NewHandler(TestStore.Open(t))
+
 	req, _ := http.NewRequest("POST", "/AdminLogin", bytes.NewBuffer([]byte(`invalid-json`)))
 	req.Header.Set("Content-Type", "application/json")
 
 	rr := httptest.NewRecorder()
-	handler := http.HandlerFunc(AdminLogin)
+	handler := http.HandlerFunc(h.AdminLogin)
 	handler.ServeHTTP(rr, req)
 
 	if rr.Code != http.StatusBadRequest {
 		t.Errorf("expected status 400, got %d", rr.Code)
 	}
 }
+
+func TestAdminLogin_LockoutAfterRepeatedFailures(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	store.Admins().Create(&DataBase.Admin{Username: "lockeduser", Password: "rightpass"})
+
+	login := func(password, remoteAddr string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(LoginRequest{Username: "lockeduser", Password: password})
+		req, _ := http.NewRequest("POST", "/AdminLogin", bytes.NewBuffer(body))
+		req.RemoteAddr = remoteAddr
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(h.AdminLogin).ServeHTTP(rr, req)
+		return rr
+	}
+
+	for i := 0; i < maxLoginFailures; i++ {
+		if rr := login("wrongpass", "10.0.0.1:5000"); rr.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected status 401, got %d", i+1, rr.Code)
+		}
+	}
+
+	// The right password is refused while the username is locked, even from another IP
+	rr := login("rightpass", "10.0.0.2:5000")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", rr.Code)
+	}
+	if rr.Header().Get("Retry-After") == "" {
+		t.Errorf("expected Retry-After header on locked response")
+	}
+}
+
+func TestAdminLogin_ParallelGuessesStopAtTheLockout(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	store.Admins().Create(&DataBase.Admin{Username: "raceduser", Password: "rightpass"})
+
+	const guesses = 4 * maxLoginFailures
+	codes := make(chan int, guesses)
+	var wg sync.WaitGroup
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body, _ := json.Marshal(LoginRequest{Username: "raceduser", Password: fmt.Sprintf("guess%d", i)})
+			req, _ := http.NewRequest("POST", "/AdminLogin", bytes.NewBuffer(body))
+			req.RemoteAddr = fmt.Sprintf("10.0.1.%d:5000", i)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(h.AdminLogin).ServeHTTP(rr, req)
+			codes <- rr.Code
+		}(i)
+	}
+	wg.Wait()
+	close(codes)
+
+	checked := 0
+	for code := range codes {
+		switch code {
+		case http.StatusUnauthorized:
+			checked++
+		case http.StatusTooManyRequests:
+		default:
+			t.Errorf("unexpected status %d", code)
+		}
+	}
+	if checked != maxLoginFailures {
+		t.Errorf("expected exactly %d passwords to be checked before the lockout, got %d", maxLoginFailures, checked)
+	}
+}
+
+func TestLockoutForIsProgressive(t *testing.T) {
+	if d := lockoutFor(maxLoginFailures - 1); d != 0 {
+		t.Errorf("expected no lockout below the threshold, got %v", d)
+	}
+	if d := lockoutFor(maxLoginFailures); d != baseLockout {
+		t.Errorf("expected %v at the threshold, got %v", baseLockout, d)
+	}
+	if d := lockoutFor(maxLoginFailures + 2); d != 4*baseLockout {
+		t.Errorf("expected %v two failures past the threshold, got %v", 4*baseLockout, d)
+	}
+	if d := lockoutFor(maxLoginFailures + 100); d != maxLockout {
+		t.Errorf("expected lockout to be capped at %v, got %v", maxLockout, d)
+	}
+}
@@ -0,0 +1,101 @@
+package Admin
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/Backup"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// maxBundleSize caps the size of an uploaded import bundle.
+const maxBundleSize = 32 << 20
+
+// ExportData downloads every record as a backup bundle.
+// @Summary Export all data (Admin)
+// @Description Downloads every sport, court, blackout, customer and booking as a versioned bundle: one JSON document, or with format=zip a ZIP holding manifest.json and one CSV per table. Deleted and archived records are left out.
+// @Tags admin
+// @Produce json
+// @Produce application/zip
+// @Param  format query string false "json (default) or zip"
+// @Success 200 {object} Backup.Bundle
+// @Failure 400 {object} DataBase.ErrorResponse "Unsupported format"
+// @Failure 500 {object} DataBase.ErrorResponse "Failed to export data"
+// @Router /admin/export [get]
+func (h *Handler) ExportData(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = Backup.FormatJSON
+	}
+	if format != Backup.FormatJSON && format != Backup.FormatZIP {
+		APIError.Validation(w, r, "format must be json or zip", APIError.Field("format", "must be json or zip"))
+		return
+	}
+
+	// Encode in full first so a failure is still reported as an error response
+	var body bytes.Buffer
+	bundle, err := Backup.Export(h.Store)
+	if err == nil {
+		err = Backup.Encode(&body, bundle, format)
+	}
+	if err != nil {
+		APIError.Internal(w, r, "Failed to export data", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/"+format)
+	w.Header().Set("Content-Disposition",
+		"attachment; filename=\"courtlink-export-"+bundle.Exported_At.Format("20060102-150405")+"."+format+"\"")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes())
+}
+
+// ImportData adds the records of a backup bundle.
+// @Summary Import a data bundle (Admin)
+// @Description Validates an exported bundle (JSON or ZIP, sent as the request body) and adds its records in one transaction. Sports and courts are matched by name, customers by email and bookings by customer, court, date and slot; everything else is created with new IDs and references are remapped. With dry_run=true nothing is written and the report shows what would happen.
+// @Tags admin
+// @Accept json
+// @Accept application/zip
+// @Produce json
+// @Param  dry_run query bool false "Only report what the import would do"
+// @Success 200 {object} Backup.Report
+// @Failure 400 {object} DataBase.ErrorResponse{report=Backup.Report} "INVALID_IMPORT, with the problems found in report"
+// @Failure 500 {object} DataBase.ErrorResponse "Failed to import data"
+// @Router /admin/import [post]
+func (h *Handler) ImportData(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	dryRun := false
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			APIError.Validation(w, r, "dry_run must be true or false", APIError.Field("dry_run", "must be true or false"))
+			return
+		}
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBundleSize))
+	if err != nil {
+		APIError.Malformed(w, r, "Failed to read bundle")
+		return
+	}
+	bundle, err := Backup.Decode(data)
+	if err != nil {
+		APIError.Malformed(w, r, err.Error())
+		return
+	}
+
+	report, err := Backup.Import(h.Store, bundle, dryRun)
+	switch {
+	case errors.Is(err, Backup.ErrInvalidBundle):
+		APIError.ImportRejected(w, r, "The bundle has problems, so nothing was imported", report)
+	case err != nil:
+		APIError.Internal(w, r, "Failed to import data", err)
+	default:
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(report)
+	}
+}
@@ -0,0 +1,88 @@
+package Admin
+
+import (
+	"BackEnd/Backup"
+	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExportAndImportData(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+	if err := store.Sports().Create(&DataBase.Sport{Sport_name: "Tennis"}); err != nil {
+		t.Fatalf("failed to create sport: %v", err)
+	}
+
+	if rr := callWithID(h.ExportData, "GET", 0, nil); rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+
+	req, _ := http.NewRequest("GET", "/admin/export?format=xml", nil)
+	rr := httptest.NewRecorder()
+	h.ExportData(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an unknown format, got %d", rr.Code)
+	}
+
+	req, _ = http.NewRequest("GET", "/admin/export?format=zip", nil)
+	rr = httptest.NewRecorder()
+	h.ExportData(rr, req)
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("expected a ZIP download, got %d %q", rr.Code, rr.Header().Get("Content-Type"))
+	}
+	bundle := rr.Body.Bytes()
+
+	invalid, _ := json.Marshal(Backup.Bundle{Format_Version: Backup.FormatVersion + 1})
+
+	cases := []struct {
+		name    string
+		query   string
+		body    []byte
+		status  int
+		created int
+	}{
+		{"Not a bundle", "", []byte("hello"), http.StatusBadRequest, 0},
+		{"Invalid bundle", "", invalid, http.StatusBadRequest, 0},
+		{"Bad dry_run", "?dry_run=maybe", bundle, http.StatusBadRequest, 0},
+		{"Dry run", "?dry_run=true", bundle, http.StatusOK, 1},
+		{"Import", "", bundle, http.StatusOK, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			target := TestStore.Open(t)
+			req, _ := http.NewRequest("POST", "/admin/import"+tc.query, bytes.NewReader(tc.body))
+			rr := httptest.NewRecorder()
+			NewHandler(target).ImportData(rr, req)
+			if rr.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, rr.Code, rr.Body.String())
+			}
+			if tc.name == "Invalid bundle" {
+				var rejected struct {
+					DataBase.ErrorResponse
+					Report Backup.Report `json:"report"`
+				}
+				json.NewDecoder(rr.Body).Decode(&rejected)
+				if rejected.Code != "INVALID_IMPORT" || len(rejected.Report.Problems) == 0 {
+					t.Errorf("expected INVALID_IMPORT with the problems in its report, got %s", rr.Body.String())
+				}
+			}
+			if tc.status != http.StatusOK {
+				return
+			}
+			var report Backup.Report
+			json.NewDecoder(rr.Body).Decode(&report)
+			if report.Sports.Created != tc.created {
+				t.Errorf("expected %d sport created, got %+v", tc.created, report.Sports)
+			}
+			sports, _ := target.Sports().List()
+			if report.Dry_Run == (len(sports) != 0) {
+				t.Errorf("expected a dry run to write nothing and an import to write, got %d sports", len(sports))
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package Admin
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/DataBase"
+	"BackEnd/Events"
+	"BackEnd/Repository"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type SetBookingStatusRequest struct {
+	Status DataBase.BookingStatus `json:"status"`
+	Note   string                 `json:"note"`
+}
+
+// bookingIDFromPath parses the {id} route variable, writing the error response itself.
+func bookingIDFromPath(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		APIError.Malformed(w, r, "Invalid booking ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// SetBookingStatus moves a booking to a new status.
+// @Summary Change a booking's status (Admin)
+// @Description Moves a booking along its lifecycle, e.g. confirmed to checked_in or no_show, and records the change and the admin who made it in its history. Only defined transitions are allowed.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param  id path int true "Booking ID"
+// @Param  request body SetBookingStatusRequest true "New status"
+// @Success 200 {object} map[string]string "Booking status updated"
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid booking ID or status"
+// @Failure 404 {object} DataBase.ErrorResponse "Booking not found"
+// @Failure 409 {object} DataBase.ErrorResponse "Transition not allowed"
+// @Router /admin/bookings/{id}/status [post]
+func (h *Handler) SetBookingStatus(w http.ResponseWriter, r *http.Request) {
+	id, ok := bookingIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req SetBookingStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !req.Status.Valid() {
+		APIError.Validation(w, r, "Invalid booking status", APIError.Field("status", "must be a known booking status"))
+		return
+	}
+
+	admin, _ := CurrentAdmin(r)
+	err := h.Store.Bookings().AdminTransition(id, req.Status, req.Note, admin.Admin_ID)
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case errors.Is(err, Repository.ErrNotFound):
+		APIError.Write(w, r, http.StatusNotFound, APIError.BookingNotFound, "Booking not found")
+	case errors.Is(err, Repository.ErrInvalidTransition):
+		APIError.Write(w, r, http.StatusConflict, APIError.InvalidStatusTransition, "Booking cannot move to "+string(req.Status)+" from its current status")
+	case err != nil:
+		APIError.Internal(w, r, "Failed to update booking status", err)
+	default:
+		// Only a cancellation frees the slot
+		if req.Status.Cancelled() {
+			if booking, err := h.Store.Bookings().FindByID(id); err == nil {
+				h.Events.Publish(Events.ForBooking(Events.Cancelled, booking))
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Booking status updated"})
+	}
+}
+
+// GetBookingHistory lists the status changes of a booking.
+// @Summary Booking status history (Admin)
+// @Description Lists every status a booking has had, oldest first, with when and why it changed and which admin changed it.
+// @Tags admin
+// @Produce json
+// @Param  id path int true "Booking ID"
+// @Success 200 {array} DataBase.Booking_StatusHistory
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid booking ID"
+// @Failure 404 {object} DataBase.ErrorResponse "Booking not found"
+// @Failure 500 {object} DataBase.ErrorResponse "Failed to load booking history"
+// @Router /admin/bookings/{id}/history [get]
+func (h *Handler) GetBookingHistory(w http.ResponseWriter, r *http.Request) {
+	id, ok := bookingIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := h.Store.Bookings().FindByID(id); errors.Is(err, Repository.ErrNotFound) {
+		APIError.Write(w, r, http.StatusNotFound, APIError.BookingNotFound, "Booking not found")
+		return
+	}
+
+	history, err := h.Store.Bookings().History(id)
+	if err != nil {
+		APIError.Internal(w, r, "Failed to load booking history", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(history)
+}
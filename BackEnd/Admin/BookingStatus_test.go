@@ -0,0 +1,79 @@
+package Admin
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSetBookingStatus(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	desk := DataBase.Admin{Username: "desk", Password: "deskpass"}
+	store.Admins().Create(&desk)
+	token := loginToken(t, h, "desk", "deskpass")
+	// Runs a handler behind RequireAdmin with desk's session, as main registers it
+	asDesk := func(handler http.HandlerFunc, method string, id uint, payload interface{}) *httptest.ResponseRecorder {
+		return callWithID(func(w http.ResponseWriter, r *http.Request) {
+			r.Header.Set("Authorization", "Bearer "+token)
+			h.RequireAdmin(handler).ServeHTTP(w, r)
+		}, method, id, payload)
+	}
+
+	booking := DataBase.Bookings{Customer_ID: 1, Sport_ID: 1, Court_ID: 1, Booking_Status: DataBase.BookingConfirmed,
+		Booking_Date: DataBase.BookingDate(time.Now())}
+	if err := store.Bookings().Create(&booking); err != nil {
+		t.Fatalf("failed to create booking: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		id     uint
+		body   SetBookingStatusRequest
+		status int
+	}{
+		{"Unknown status", booking.Booking_ID, SetBookingStatusRequest{Status: "booked"}, http.StatusBadRequest},
+		{"Missing booking", 999, SetBookingStatusRequest{Status: DataBase.BookingCheckedIn}, http.StatusNotFound},
+		{"Skipping check in", booking.Booking_ID, SetBookingStatusRequest{Status: DataBase.BookingCompleted}, http.StatusConflict},
+		{"Check in", booking.Booking_ID, SetBookingStatusRequest{Status: DataBase.BookingCheckedIn, Note: "Arrived early"}, http.StatusOK},
+		{"Cancel after check in", booking.Booking_ID, SetBookingStatusRequest{Status: DataBase.BookingCancelledByAdmin}, http.StatusConflict},
+		{"Complete", booking.Booking_ID, SetBookingStatusRequest{Status: DataBase.BookingCompleted}, http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := asDesk(h.SetBookingStatus, "POST", tc.id, tc.body)
+			if rr.Code != tc.status {
+				t.Errorf("expected status %d, got %d: %s", tc.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	rr := asDesk(h.GetBookingHistory, "GET", booking.Booking_ID, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var history []DataBase.Booking_StatusHistory
+	json.NewDecoder(rr.Body).Decode(&history)
+	if len(history) != 3 || history[1].To_Status != DataBase.BookingCheckedIn || history[1].Note != "Arrived early" ||
+		history[2].To_Status != DataBase.BookingCompleted {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+	// The booking was made by its customer and every later change by desk
+	if history[0].Changed_By != nil {
+		t.Errorf("expected no admin on the booking itself, got %d", *history[0].Changed_By)
+	}
+	for _, entry := range history[1:] {
+		if entry.Changed_By == nil || *entry.Changed_By != desk.Admin_ID {
+			t.Errorf("expected desk to have moved the booking to %s, got %v", entry.To_Status, entry.Changed_By)
+		}
+	}
+
+	if rr := callWithID(h.GetBookingHistory, "GET", 999, nil); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for a missing booking, got %d", rr.Code)
+	}
+}
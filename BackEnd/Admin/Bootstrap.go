@@ -0,0 +1,118 @@
+package Admin
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// errAlreadyBootstrapped is returned once any admin exists.
+var errAlreadyBootstrapped = errors.New("admins already exist")
+
+// validateCredentials lists what is wrong with a new admin's username and password.
+func validateCredentials(username, password string) []DataBase.FieldError {
+	var details []DataBase.FieldError
+	if username == "" {
+		details = append(details, APIError.Field("username", "is required"))
+	}
+	if len(password) < minPasswordLength {
+		details = append(details, passwordDetail())
+	}
+	return details
+}
+
+func passwordDetail() DataBase.FieldError {
+	return APIError.Field("password", "must be at least "+strconv.Itoa(minPasswordLength)+" characters")
+}
+
+type BootstrapRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// BootstrapAdmin creates the first super admin. It only works while the Admin table is empty.
+// When ADMIN_BOOTSTRAP_TOKEN is set, the request must carry the same value in the X-Bootstrap-Token header.
+// @Summary Bootstrap the first super admin
+// @Description One-time setup: creates a super admin when no admin exists yet. Further calls are rejected.
+// @Tags admins
+// @Accept json
+// @Produce json
+// @Param X-Bootstrap-Token header string false "Must match ADMIN_BOOTSTRAP_TOKEN when that is configured"
+// @Param admin body BootstrapRequest true "Username and password"
+// @Success 201 {object} AdminSummary "Super admin created"
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid request or weak password"
+// @Failure 403 {object} DataBase.ErrorResponse "Bootstrap token mismatch"
+// @Failure 409 {object} DataBase.ErrorResponse "Already bootstrapped"
+// @Router /admin/bootstrap [post]
+func (h *Handler) BootstrapAdmin(w http.ResponseWriter, r *http.Request) {
+	if h.BootstrapToken != "" &&
+		subtle.ConstantTimeCompare([]byte(h.BootstrapToken), []byte(r.Header.Get("X-Bootstrap-Token"))) != 1 {
+		APIError.Write(w, r, http.StatusForbidden, APIError.InvalidBootstrap, "Invalid bootstrap token")
+		return
+	}
+
+	var req BootstrapRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		APIError.Malformed(w, r, "Invalid request body")
+		return
+	}
+
+	req.Username = strings.TrimSpace(req.Username)
+	if details := validateCredentials(req.Username, req.Password); len(details) > 0 {
+		APIError.Validation(w, r, "username is required and password must be at least "+strconv.Itoa(minPasswordLength)+" characters", details...)
+		return
+	}
+
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		APIError.Internal(w, r, "Failed to hash password", err)
+		return
+	}
+
+	admin := DataBase.Admin{
+		Username: req.Username,
+		Password: hash,
+		Role:     DataBase.AdminRoleSuperAdmin,
+		Status:   DataBase.AdminStatusActive,
+	}
+	err = h.Store.Transaction(func(tx Repository.Store) error {
+		count, err := tx.Admins().Count()
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errAlreadyBootstrapped
+		}
+		return tx.Admins().Create(&admin)
+	})
+	if errors.Is(err, errAlreadyBootstrapped) {
+		APIError.Write(w, r, http.StatusConflict, APIError.AlreadyBootstrapped, "Admins already exist, bootstrap is disabled")
+		return
+	}
+	if err != nil {
+		APIError.Internal(w, r, "Failed to create admin", err)
+		return
+	}
+	if h.BootstrapToken == "" {
+		log.Printf("request %s: WARNING: %s bootstrapped super admin %q without a bootstrap token",
+			APIError.RequestIDFrom(r.Context()), r.RemoteAddr, admin.Username)
+	} else {
+		log.Printf("request %s: bootstrapped super admin %q", APIError.RequestIDFrom(r.Context()), admin.Username)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(AdminSummary{
+		Admin_ID: admin.Admin_ID,
+		Username: admin.Username,
+		Role:     admin.Role,
+		Status:   admin.Status,
+	})
+}
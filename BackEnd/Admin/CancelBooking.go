@@ -1,9 +1,12 @@
 package Admin
 
 import (
+	"BackEnd/APIError"
 	"BackEnd/DataBase"
+	"BackEnd/Events"
+	"BackEnd/Repository"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
 )
 
@@ -19,64 +22,36 @@ type AdminCancelRequest struct {
 // @Produce json
 // @Param  booking body AdminCancelRequest true "Cancel Request"
 // @Success 200 {object} map[string]interface{} "Cancellation successful"
-// @Failure 400 {object} map[string]string "Invalid request"
-// @Failure 404 {object} map[string]string "Booking not found"
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid request"
+// @Failure 404 {object} DataBase.ErrorResponse "Booking not found"
+// @Failure 409 {object} DataBase.ErrorResponse "Booking can no longer be cancelled"
+// @Deprecated
 // @Router /admin/cancelBooking [post]
-func AdminCancelBooking(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) AdminCancelBooking(w http.ResponseWriter, r *http.Request) {
 	var req AdminCancelRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid request body"})
+		APIError.Malformed(w, r, "Invalid request body")
 		return
 	}
 
 	// 1. Get Booking
-	var booking DataBase.Bookings
-	if err := DataBase.DB.First(&booking, req.BookingID).Error; err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Booking not found"})
+	booking, err := h.Store.Bookings().FindByID(req.BookingID)
+	if err != nil {
+		APIError.Write(w, r, http.StatusNotFound, APIError.BookingNotFound, "Booking not found")
 		return
 	}
 
-	// 2. Identify Slot Column
-	slotColumns := []string{
-		"slot_08_09", "slot_09_10", "slot_10_11", "slot_11_12", "slot_12_13",
-		"slot_13_14", "slot_14_15", "slot_15_16", "slot_16_17", "slot_17_18",
-	}
-	if booking.Booking_Time < 0 || booking.Booking_Time >= len(slotColumns) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid booking time index in record"})
-		return
-	}
-	columnName := slotColumns[booking.Booking_Time]
-
-	// 3. Start Transaction
-	tx := DataBase.DB.Begin()
-
-	// 3a. Delete Booking
-	if err := tx.Delete(&booking).Error; err != nil {
-		tx.Rollback()
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to delete booking"})
+	// 2. Cancel rather than delete, so the customer still sees what happened to the booking
+	admin, _ := CurrentAdmin(r)
+	err = h.Store.Bookings().AdminTransition(booking.Booking_ID, DataBase.BookingCancelledByAdmin, "", admin.Admin_ID)
+	if errors.Is(err, Repository.ErrInvalidTransition) {
+		APIError.Write(w, r, http.StatusConflict, APIError.InvalidStatusTransition, "Booking can no longer be cancelled")
 		return
-	}
-
-	// 3b. Update Court_TimeSlots (Set to 1 - Available)
-	// Note: We need to use "Court_ID" (quoted) for case sensitive postgres
-	updateQuery := fmt.Sprintf("UPDATE \"Court_TimeSlots\" SET \"%s\" = 1 WHERE \"Court_ID\" = ?", columnName)
-	if err := tx.Exec(updateQuery, booking.Court_ID).Error; err != nil {
-		tx.Rollback()
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to update court availability"})
+	} else if err != nil {
+		APIError.Internal(w, r, "Failed to cancel booking", err)
 		return
 	}
-
-	tx.Commit()
+	h.Events.Publish(Events.ForBooking(Events.Cancelled, booking))
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
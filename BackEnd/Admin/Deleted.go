@@ -0,0 +1,124 @@
+package Admin
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// DeletedRecords lists what can still be restored.
+type DeletedRecords struct {
+	Sports   []DataBase.Sport    `json:"sports"`
+	Courts   []DataBase.Court    `json:"courts"`
+	Bookings []DataBase.Bookings `json:"bookings"`
+}
+
+// ListDeleted lists the soft deleted sports, courts and bookings.
+// @Summary List deleted records (Admin)
+// @Description Lists the sports, courts and bookings that were deleted but not yet purged, most recently deleted first.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} DeletedRecords
+// @Failure 401 {object} DataBase.ErrorResponse "Missing or invalid admin session"
+// @Failure 500 {object} DataBase.ErrorResponse "Failed to list deleted records"
+// @Router /admin/deleted [get]
+func (h *Handler) ListDeleted(w http.ResponseWriter, r *http.Request) {
+	var records DeletedRecords
+	var err error
+	if records.Sports, err = h.Store.Sports().ListDeleted(); err == nil {
+		if records.Courts, err = h.Store.Courts().ListDeleted(); err == nil {
+			records.Bookings, err = h.Store.Bookings().ListDeleted()
+		}
+	}
+	if err != nil {
+		APIError.Internal(w, r, "Failed to list deleted records", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(records)
+}
+
+// RestoreSport undeletes a sport with the courts and bookings deleted along with it.
+// @Summary Restore a deleted sport (Admin)
+// @Description Restores a soft deleted sport together with the courts and bookings that were deleted with it. Courts deleted on their own beforehand stay deleted.
+// @Tags admin
+// @Produce json
+// @Param  id path int true "Sport ID"
+// @Success 200 {object} map[string]string "Sport restored"
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid sport ID"
+// @Failure 401 {object} DataBase.ErrorResponse "Missing or invalid admin session"
+// @Failure 404 {object} DataBase.ErrorResponse "No deleted sport with this ID"
+// @Failure 409 {object} DataBase.ErrorResponse "An active sport or court has the same name"
+// @Router /admin/sports/{id}/restore [post]
+func (h *Handler) RestoreSport(w http.ResponseWriter, r *http.Request) {
+	restoreRecord(w, r, "sport", APIError.SportNotFound, APIError.SportAlreadyExists, h.Store.Sports().Restore)
+}
+
+// RestoreCourt undeletes a court with the bookings deleted along with it.
+// @Summary Restore a deleted court (Admin)
+// @Description Restores a soft deleted court together with the bookings that were deleted with it. A court whose sport is deleted can only come back by restoring the sport.
+// @Tags admin
+// @Produce json
+// @Param  id path int true "Court ID"
+// @Success 200 {object} map[string]string "Court restored"
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid court ID"
+// @Failure 401 {object} DataBase.ErrorResponse "Missing or invalid admin session"
+// @Failure 404 {object} DataBase.ErrorResponse "No deleted court with this ID"
+// @Failure 409 {object} DataBase.ErrorResponse "The court's sport is deleted, or an active court has the same name"
+// @Router /admin/courts/{id}/restore [post]
+func (h *Handler) RestoreCourt(w http.ResponseWriter, r *http.Request) {
+	restoreRecord(w, r, "court", APIError.CourtNotFound, APIError.CourtAlreadyExists, h.Store.Courts().Restore)
+}
+
+// RestoreBooking undeletes a booking.
+// @Summary Restore a deleted booking (Admin)
+// @Description Restores a soft deleted booking with the status it had. A booking whose court is deleted can only come back by restoring the court.
+// @Tags admin
+// @Produce json
+// @Param  id path int true "Booking ID"
+// @Success 200 {object} map[string]string "Booking restored"
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid booking ID"
+// @Failure 401 {object} DataBase.ErrorResponse "Missing or invalid admin session"
+// @Failure 404 {object} DataBase.ErrorResponse "No deleted booking with this ID"
+// @Failure 409 {object} DataBase.ErrorResponse "The booking's court is deleted, or its slot is booked again"
+// @Router /admin/bookings/{id}/restore [post]
+func (h *Handler) RestoreBooking(w http.ResponseWriter, r *http.Request) {
+	restoreRecord(w, r, "booking", APIError.BookingNotFound, APIError.SlotUnavailable, h.Store.Bookings().Restore)
+}
+
+// restoreRecord parses the {id} route variable, calls restore and writes the response,
+// answering notFound when there is no deleted record of that kind and taken when an active
+// record has since claimed its name or slot.
+func restoreRecord(w http.ResponseWriter, r *http.Request, kind string, notFound, taken APIError.Code, restore func(id uint) error) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		APIError.Malformed(w, r, "Invalid "+kind+" ID")
+		return
+	}
+
+	err = restore(uint(id))
+	switch {
+	case errors.Is(err, Repository.ErrNotFound):
+		APIError.Write(w, r, http.StatusNotFound, notFound, "No deleted "+kind+" with this ID")
+	case errors.Is(err, Repository.ErrParentDeleted):
+		APIError.Write(w, r, http.StatusConflict, APIError.ParentDeleted, "The "+kind+" belongs to a deleted record; restore that first")
+	case errors.Is(err, Repository.ErrDuplicate):
+		APIError.Write(w, r, http.StatusConflict, taken, "An active record has taken the "+kind+"'s name or slot since it was deleted; rename or delete that first")
+	case err != nil:
+		APIError.Internal(w, r, "Failed to restore "+kind, err)
+	default:
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": strings.ToUpper(kind[:1]) + kind[1:] + " restored"})
+	}
+}
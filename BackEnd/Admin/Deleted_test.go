@@ -0,0 +1,90 @@
+package Admin
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestListAndRestoreDeleted(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	sport := DataBase.Sport{Sport_name: "Tennis"}
+	if err := store.Sports().Create(&sport); err != nil {
+		t.Fatalf("failed to create sport: %v", err)
+	}
+	court := DataBase.Court{Court_Name: "Court A", Court_Location: "Downtown", Court_Status: 1, Sport_id: sport.Sport_ID}
+	if err := store.Courts().Create(&court); err != nil {
+		t.Fatalf("failed to create court: %v", err)
+	}
+	booking := DataBase.Bookings{Customer_ID: 1, Sport_ID: sport.Sport_ID, Court_ID: court.Court_ID,
+		Booking_Status: DataBase.BookingConfirmed, Booking_Date: DataBase.BookingDate(time.Now())}
+	if err := store.Bookings().Create(&booking); err != nil {
+		t.Fatalf("failed to create booking: %v", err)
+	}
+	if err := store.Sports().Delete(sport.Sport_ID); err != nil {
+		t.Fatalf("failed to delete sport: %v", err)
+	}
+
+	rr := callWithID(h.ListDeleted, "GET", 0, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var deleted DeletedRecords
+	json.NewDecoder(rr.Body).Decode(&deleted)
+	if len(deleted.Sports) != 1 || len(deleted.Courts) != 1 || len(deleted.Bookings) != 1 {
+		t.Fatalf("expected one deleted record of each kind, got %+v", deleted)
+	}
+
+	// A new sport under the deleted one's name keeps it from coming back until it goes again
+	replacement := DataBase.Sport{Sport_name: "Tennis"}
+	if err := store.Sports().Create(&replacement); err != nil {
+		t.Fatalf("failed to create the replacement sport: %v", err)
+	}
+	rr = callWithID(h.RestoreSport, "POST", sport.Sport_ID, nil)
+	var conflict DataBase.ErrorResponse
+	json.NewDecoder(rr.Body).Decode(&conflict)
+	if rr.Code != http.StatusConflict || conflict.Code != "SPORT_ALREADY_EXISTS" {
+		t.Errorf("expected 409 SPORT_ALREADY_EXISTS while the name is taken, got %d %+v", rr.Code, conflict)
+	}
+	store.Sports().Delete(replacement.Sport_ID)
+
+	cases := []struct {
+		name    string
+		handler http.HandlerFunc
+		id      uint
+		status  int
+	}{
+		{"Court of a deleted sport", h.RestoreCourt, court.Court_ID, http.StatusConflict},
+		{"Booking on a deleted court", h.RestoreBooking, booking.Booking_ID, http.StatusConflict},
+		{"Unknown sport", h.RestoreSport, 999, http.StatusNotFound},
+		{"Sport", h.RestoreSport, sport.Sport_ID, http.StatusOK},
+		{"Sport again", h.RestoreSport, sport.Sport_ID, http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if rr := callWithID(tc.handler, "POST", tc.id, nil); rr.Code != tc.status {
+				t.Errorf("expected status %d, got %d: %s", tc.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	if _, err := store.Bookings().FindByID(booking.Booking_ID); err != nil {
+		t.Errorf("expected the booking to be restored with its sport, got %v", err)
+	}
+
+	req, _ := http.NewRequest("POST", "/admin/courts/abc/restore", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "abc"})
+	rr = httptest.NewRecorder()
+	h.RestoreCourt(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a malformed ID, got %d", rr.Code)
+	}
+}
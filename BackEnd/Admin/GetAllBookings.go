@@ -3,38 +3,48 @@ package Admin
 import (
 	"BackEnd/Bookings"
 	"BackEnd/DataBase"
+	"BackEnd/Pagination"
+	"BackEnd/Repository"
 	"encoding/json"
 	"net/http"
 )
 
 // GetAllBookings retrieves all bookings in the system with full details
 // @Summary List all bookings (Admin)
-// @Description Retrieves a list of all bookings including customer, court, and sport details.
+// @Description Retrieves the bookings including customer, court, and sport details, a page at a time. Cancelled bookings are left out unless **status** asks for them. X-Next-Cursor and the Link header give the next page.
 // @Tags admin
 // @Accept json
 // @Produce json
+// @Param from query string false "Earliest booking date, inclusive" example(2026-01-01)
+// @Param to query string false "Latest booking date, inclusive" example(2026-01-31)
+// @Param sport_id query int false "Only bookings for this sport"
+// @Param court_id query int false "Only bookings on this court"
+// @Param customer_id query int false "Only bookings of this customer"
+// @Param ufid query string false "Only bookings of the customer with this UFID"
+// @Param status query string false "Only bookings in these statuses, comma separated" example(confirmed,checked_in)
+// @Param limit query int false "Bookings per page, at most 500" default(100)
+// @Param cursor query string false "Cursor of the page, from X-Next-Cursor"
+// @Param sort query string false "id, date, slot, status, sport_id, court_id or customer_id, prefixed with - for descending order" default(id)
 // @Success 200 {array} Bookings.BookingResponse "List of all bookings"
-// @Failure 500 {string} string "Database error"
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid list parameters"
+// @Failure 500 {object} DataBase.ErrorResponse "Database error"
 // @Router /admin/allBookings [get]
-func GetAllBookings(w http.ResponseWriter, r *http.Request) {
-
-	var bookings []DataBase.Bookings
-	// Use Preload to fetch relationships safely
-	// Filtering: Exclude any booking that is "Cancelled" or "Cancelled by UF CourtLink"
-	if err := DataBase.DB.
-		Where("\"Booking_Status\" NOT LIKE ?", "Cancelled%").
-		Preload("Customer").
-		Preload("Court").
-		Preload("Sport").
-		Find(&bookings).Error; err != nil {
-		http.Error(w, "Database error while fetching bookings", http.StatusInternalServerError)
+func (h *Handler) GetAllBookings(w http.ResponseWriter, r *http.Request) {
+	params := Pagination.New(r)
+	filter := Bookings.Filter(params)
+	filter.CustomerID = params.ID("customer_id")
+	filter.UFID = params.String("ufid")
+	q := params.List(Repository.BookingSorts)
+	if !params.Check(w, r) {
 		return
 	}
 
-	slots := []string{
-		"08:00 - 09:00", "09:00 - 10:00", "10:00 - 11:00", "11:00 - 12:00",
-		"12:00 - 13:00", "13:00 - 14:00", "14:00 - 15:00", "15:00 - 16:00",
-		"16:00 - 17:00", "17:00 - 18:00",
+	// Filtering: Exclude cancelled bookings unless they are asked for
+	filter.Active = len(filter.Statuses) == 0
+	bookings, next, err := h.Store.Bookings().Page(filter, q)
+	if err != nil {
+		Pagination.Failed(w, r, err, "Database error while fetching bookings")
+		return
 	}
 
 	type AdminBookingResponse struct {
@@ -46,17 +56,15 @@ func GetAllBookings(w http.ResponseWriter, r *http.Request) {
 
 	var responseBookings []AdminBookingResponse
 	for _, b := range bookings {
-		slotTime := ""
-		if b.Booking_Time >= 0 && b.Booking_Time < len(slots) {
-			slotTime = slots[b.Booking_Time]
-		}
-
 		baseResponse := Bookings.BookingResponse{
 			BookingID:     b.Booking_ID,
 			CourtName:     b.Court.Court_Name,
 			SportName:     b.Sport.Sport_name,
-			SlotTime:      slotTime,
-			BookingStatus: b.Booking_Status,
+			SlotTime:      DataBase.SlotLabel(b.Booking_Time),
+			BookingStatus: string(b.Booking_Status),
+		}
+		if b.Booking_Date != nil {
+			baseResponse.BookingDate = *b.Booking_Date
 		}
 
 		responseBookings = append(responseBookings, AdminBookingResponse{
@@ -67,6 +75,7 @@ func GetAllBookings(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	Pagination.SetNext(w, r, q, next)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(responseBookings)
 }
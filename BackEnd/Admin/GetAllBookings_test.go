@@ -0,0 +1,81 @@
+package Admin
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetAllBookingsFilters(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	store.Sports().Create(&DataBase.Sport{Sport_name: "Tennis"})
+	store.Sports().Create(&DataBase.Sport{Sport_name: "Squash"})
+	store.Courts().Create(&DataBase.Court{Court_Name: "Tennis 1", Court_Location: "North", Court_Status: 1, Sport_id: 1})
+	store.Courts().Create(&DataBase.Court{Court_Name: "Squash 1", Court_Location: "South", Court_Status: 1, Sport_id: 2})
+	store.Customers().Create(&DataBase.Customer{Name: "Ada", UFID: "11111111", Email: "ada@example.com"})
+	store.Customers().Create(&DataBase.Customer{Name: "Bob", UFID: "22222222", Email: "bob@example.com"})
+
+	date := func(d string) *string { return &d }
+	bookings := []DataBase.Bookings{
+		{Customer_ID: 1, Sport_ID: 1, Court_ID: 1, Booking_Time: 0, Booking_Date: date("2026-03-01"), Booking_Status: DataBase.BookingConfirmed},
+		{Customer_ID: 2, Sport_ID: 1, Court_ID: 1, Booking_Time: 1, Booking_Date: date("2026-03-02"), Booking_Status: DataBase.BookingConfirmed},
+		{Customer_ID: 1, Sport_ID: 2, Court_ID: 2, Booking_Time: 0, Booking_Date: date("2026-03-03"), Booking_Status: DataBase.BookingCheckedIn},
+		{Customer_ID: 2, Sport_ID: 2, Court_ID: 2, Booking_Time: 1, Booking_Date: date("2026-03-03"), Booking_Status: DataBase.BookingCancelledByUser},
+	}
+	for i := range bookings {
+		if err := store.Bookings().Create(&bookings[i]); err != nil {
+			t.Fatalf("failed to create booking: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name  string
+		query string
+		ids   []uint
+	}{
+		{"Active bookings by default", "", []uint{1, 2, 3}},
+		{"Date range", "?from=2026-03-02&to=2026-03-03", []uint{2, 3}},
+		{"Sport", "?sport_id=2", []uint{3}},
+		{"Court and customer", "?court_id=1&customer_id=2", []uint{2}},
+		{"UFID", "?ufid=11111111", []uint{1, 3}},
+		{"Cancelled on request", "?status=cancelled_by_user,checked_in", []uint{3, 4}},
+		{"Sorted by date, newest first", "?sort=-date", []uint{3, 2, 1}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.GetAllBookings(rr, httptest.NewRequest("GET", "/admin/allBookings"+tc.query, nil))
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+			}
+			var got []struct {
+				BookingID    uint   `json:"booking_id"`
+				BookingDate  string `json:"booking_date"`
+				CustomerUFID string `json:"customer_ufid"`
+			}
+			json.NewDecoder(rr.Body).Decode(&got)
+			if len(got) != len(tc.ids) {
+				t.Fatalf("expected bookings %v, got %+v", tc.ids, got)
+			}
+			for i, b := range got {
+				if b.BookingID != tc.ids[i] || b.BookingDate == "" || b.CustomerUFID == "" {
+					t.Errorf("expected bookings %v, got %+v", tc.ids, got)
+					break
+				}
+			}
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	h.GetAllBookings(rr, httptest.NewRequest("GET", "/admin/allBookings?status=booked&from=March", nil))
+	var body DataBase.ErrorResponse
+	json.NewDecoder(rr.Body).Decode(&body)
+	if rr.Code != http.StatusBadRequest || len(body.Details) != 2 {
+		t.Errorf("expected both invalid filters to be reported, got %d %+v", rr.Code, body)
+	}
+}
@@ -0,0 +1,25 @@
+package Admin
+
+import (
+	"BackEnd/Events"
+	"BackEnd/Repository"
+	"time"
+)
+
+// Handler serves the admin endpoints using the injected repositories.
+type Handler struct {
+	Store Repository.Store
+	// BootstrapToken, when set, must be sent in X-Bootstrap-Token to create the first admin.
+	BootstrapToken string
+	// Events receives every change to availability; nil ignores them.
+	Events *Events.Broker
+	// SessionSecret signs the admin sessions issued at login. NewHandler picks a random one,
+	// which ends every session when the server restarts.
+	SessionSecret []byte
+	SessionTTL    time.Duration
+}
+
+// NewHandler returns a Handler backed by the given store.
+func NewHandler(store Repository.Store) *Handler {
+	return &Handler{Store: store, SessionSecret: newSessionSecret(), SessionTTL: DefaultSessionTTL}
+}
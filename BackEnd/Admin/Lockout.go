@@ -0,0 +1,68 @@
+package Admin
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	// maxLoginFailures is the number of consecutive failures allowed before a key is locked.
+	maxLoginFailures = 5
+	// baseLockout is the first lockout period; it doubles with every further failure.
+	baseLockout = time.Minute
+	// maxLockout caps the progressive lockout period.
+	maxLockout = time.Hour
+)
+
+// loginAttemptKeys returns the lockout keys for a login attempt: one per username and one per client IP.
+func loginAttemptKeys(username string, r *http.Request) []string {
+	keys := []string{"user:" + username}
+
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+	if ip != "" {
+		keys = append(keys, "ip:"+ip)
+	}
+	return keys
+}
+
+// reserveLoginAttempt counts an attempt against every key before the credentials are checked, so
+// parallel guesses cannot all pass the lockout check before any of them is recorded. It returns the
+// latest lockout expiry if any key is already locked; locked keys do not count the attempt.
+func (h *Handler) reserveLoginAttempt(keys []string, now time.Time) (time.Time, bool, error) {
+	var until time.Time
+	for _, key := range keys {
+		attempt, ok, err := h.Store.Admins().ReserveLoginAttempt(key, now, lockoutFor)
+		if err != nil {
+			return until, false, err
+		}
+		if !ok && attempt.Locked_Until.After(until) {
+			until = *attempt.Locked_Until
+		}
+	}
+	return until, !until.IsZero(), nil
+}
+
+// lockoutFor returns how long a key stays locked after the given number of consecutive failures.
+func lockoutFor(failures int) time.Duration {
+	if failures < maxLoginFailures {
+		return 0
+	}
+	d := baseLockout
+	for i := maxLoginFailures; i < failures; i++ {
+		d *= 2
+		if d >= maxLockout {
+			return maxLockout
+		}
+	}
+	return d
+}
+
+// clearLoginFailures resets the counters for the given keys after a successful login. Until then
+// every attempt counts as a failure, including one that is only missing its second factor.
+func (h *Handler) clearLoginFailures(keys []string) {
+	h.Store.Admins().ClearLoginAttempts(keys)
+}
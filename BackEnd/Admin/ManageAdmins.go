@@ -0,0 +1,391 @@
+package Admin
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// inviteTTL is how long invite and password reset tokens stay valid.
+const inviteTTL = 72 * time.Hour
+
+// errLastSuperAdmin is returned when a change would leave no active super admin.
+var errLastSuperAdmin = errors.New("at least one active super admin must remain")
+
+type AdminSummary struct {
+	Admin_ID     uint       `json:"Admin_ID"`
+	Username     string     `json:"Username"`
+	Role         string     `json:"Role"`
+	Status       string     `json:"Status"`
+	TOTP_Enabled bool       `json:"TOTP_Enabled"`
+	Last_Login   *time.Time `json:"Last_Login"`
+}
+
+type InviteAdminRequest struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+type AdminTokenResponse struct {
+	Message   string    `json:"message"`
+	Admin_ID  uint      `json:"Admin_ID"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type SetRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type SetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func validRole(role string) bool {
+	return role == DataBase.AdminRoleSuperAdmin || role == DataBase.AdminRoleAdmin
+}
+
+// ensureOtherSuperAdmin fails with errLastSuperAdmin unless an active super admin other than adminID exists.
+func ensureOtherSuperAdmin(admins Repository.AdminRepository, adminID uint) error {
+	count, err := admins.CountActiveSuperAdmins(adminID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errLastSuperAdmin
+	}
+	return nil
+}
+
+// adminIDFromPath parses the {id} route variable, writing the error response itself.
+func adminIDFromPath(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		APIError.Malformed(w, r, "Invalid admin ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// ListAdmins returns every admin account with its role, status and last login.
+// @Summary List admins
+// @Description Lists all admin accounts, including invited and deactivated ones, with their last login time.
+// @Tags admins
+// @Produce json
+// @Success 200 {array} AdminSummary "Admin accounts"
+// @Failure 500 {object} DataBase.ErrorResponse "Database error"
+// @Failure 401 {object} DataBase.ErrorResponse "Missing or invalid admin session"
+// @Failure 403 {object} DataBase.ErrorResponse "Not a super admin"
+// @Router /admin/admins [get]
+func (h *Handler) ListAdmins(w http.ResponseWriter, r *http.Request) {
+	admins, err := h.Store.Admins().List()
+	if err != nil {
+		APIError.Internal(w, r, "Database error while fetching admins", err)
+		return
+	}
+
+	response := make([]AdminSummary, 0, len(admins))
+	for _, a := range admins {
+		response = append(response, AdminSummary{
+			Admin_ID:     a.Admin_ID,
+			Username:     a.Username,
+			Role:         a.Role,
+			Status:       a.Status,
+			TOTP_Enabled: a.TOTP_Enabled,
+			Last_Login:   a.Last_Login,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// InviteAdmin creates an invited admin account and returns a one-time token for choosing a password.
+// @Summary Invite an admin
+// @Description Creates an admin in the "invited" state. The returned token must be passed to /admin/admins/password to activate the account.
+// @Tags admins
+// @Accept json
+// @Produce json
+// @Param invite body InviteAdminRequest true "Username and role (admin or super_admin)"
+// @Success 201 {object} AdminTokenResponse "Invite created"
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid request"
+// @Failure 409 {object} DataBase.ErrorResponse "Username already taken"
+// @Failure 401 {object} DataBase.ErrorResponse "Missing or invalid admin session"
+// @Failure 403 {object} DataBase.ErrorResponse "Not a super admin"
+// @Router /admin/admins [post]
+func (h *Handler) InviteAdmin(w http.ResponseWriter, r *http.Request) {
+	var req InviteAdminRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		APIError.Malformed(w, r, "Invalid request body")
+		return
+	}
+
+	req.Username = strings.TrimSpace(req.Username)
+	if req.Role == "" {
+		req.Role = DataBase.AdminRoleAdmin
+	}
+	var details []DataBase.FieldError
+	if req.Username == "" {
+		details = append(details, APIError.Field("username", "is required"))
+	}
+	if !validRole(req.Role) {
+		details = append(details, APIError.Field("role", "must be admin or super_admin"))
+	}
+	if len(details) > 0 {
+		APIError.Validation(w, r, "username is required and role must be admin or super_admin", details...)
+		return
+	}
+
+	token, tokenHash, err := newToken()
+	if err != nil {
+		APIError.Internal(w, r, "Failed to generate invite token", err)
+		return
+	}
+	expires := time.Now().Add(inviteTTL)
+
+	admin := DataBase.Admin{
+		Username:       req.Username,
+		Role:           req.Role,
+		Status:         DataBase.AdminStatusInvited,
+		Invite_Token:   tokenHash,
+		Invite_Expires: &expires,
+	}
+	if err := h.Store.Admins().Create(&admin); errors.Is(err, Repository.ErrDuplicate) {
+		APIError.Write(w, r, http.StatusConflict, APIError.AdminAlreadyExists, "An admin with this username already exists")
+		return
+	} else if err != nil {
+		APIError.Internal(w, r, "Failed to create admin", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(AdminTokenResponse{
+		Message:   "Admin invited",
+		Admin_ID:  admin.Admin_ID,
+		Token:     token,
+		ExpiresAt: expires,
+	})
+}
+
+// SetAdminPassword redeems an invite or password reset token and sets the admin's password.
+// @Summary Set password from invite or reset token
+// @Description Sets a new password using a token from an invite or a password reset, and activates invited accounts.
+// @Tags admins
+// @Accept json
+// @Produce json
+// @Param request body SetPasswordRequest true "Token and new password"
+// @Success 200 {object} map[string]string "Password set"
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid request or weak password"
+// @Failure 401 {object} DataBase.ErrorResponse "Invalid or expired token"
+// @Router /admin/admins/password [post]
+func (h *Handler) SetAdminPassword(w http.ResponseWriter, r *http.Request) {
+	var req SetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		APIError.Malformed(w, r, "Invalid request body")
+		return
+	}
+
+	if len(req.Password) < minPasswordLength {
+		APIError.Validation(w, r, "Password must be at least "+strconv.Itoa(minPasswordLength)+" characters", passwordDetail())
+		return
+	}
+
+	admin, err := h.Store.Admins().FindByInviteToken(hashToken(req.Token))
+	if req.Token == "" || err != nil || admin.Invite_Expires == nil || admin.Invite_Expires.Before(time.Now()) || admin.Status == DataBase.AdminStatusDeactivated {
+		APIError.Write(w, r, http.StatusUnauthorized, APIError.InvalidToken, "Invalid or expired token")
+		return
+	}
+
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		APIError.Internal(w, r, "Failed to hash password", err)
+		return
+	}
+
+	admin.Password = hash
+	admin.Status = DataBase.AdminStatusActive
+	admin.Invite_Token = ""
+	admin.Invite_Expires = nil
+	if err := h.Store.Admins().Save(&admin); err != nil {
+		APIError.Internal(w, r, "Failed to set password", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Password set for admin " + admin.Username})
+}
+
+// SetAdminRole changes an admin's role.
+// @Summary Change admin role
+// @Description Sets the role of an admin. Demoting the last active super admin is rejected.
+// @Tags admins
+// @Accept json
+// @Produce json
+// @Param id path int true "Admin ID"
+// @Param role body SetRoleRequest true "New role (admin or super_admin)"
+// @Success 200 {object} AdminSummary "Updated admin"
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid role"
+// @Failure 404 {object} DataBase.ErrorResponse "Admin not found"
+// @Failure 409 {object} DataBase.ErrorResponse "Would remove the last super admin"
+// @Failure 401 {object} DataBase.ErrorResponse "Missing or invalid admin session"
+// @Failure 403 {object} DataBase.ErrorResponse "Not a super admin"
+// @Router /admin/admins/{id}/role [put]
+func (h *Handler) SetAdminRole(w http.ResponseWriter, r *http.Request) {
+	id, ok := adminIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req SetRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !validRole(req.Role) {
+		APIError.Validation(w, r, "role must be admin or super_admin", APIError.Field("role", "must be admin or super_admin"))
+		return
+	}
+
+	var admin DataBase.Admin
+	err := h.Store.Transaction(func(tx Repository.Store) error {
+		var err error
+		if admin, err = tx.Admins().FindByID(id); err != nil {
+			return err
+		}
+		if admin.Role == DataBase.AdminRoleSuperAdmin && req.Role != DataBase.AdminRoleSuperAdmin {
+			if err := ensureOtherSuperAdmin(tx.Admins(), admin.Admin_ID); err != nil {
+				return err
+			}
+		}
+		admin.Role = req.Role
+		return tx.Admins().Save(&admin)
+	})
+	if err != nil {
+		writeAdminError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AdminSummary{
+		Admin_ID:     admin.Admin_ID,
+		Username:     admin.Username,
+		Role:         admin.Role,
+		Status:       admin.Status,
+		TOTP_Enabled: admin.TOTP_Enabled,
+		Last_Login:   admin.Last_Login,
+	})
+}
+
+// DeactivateAdmin disables an admin account.
+// @Summary Deactivate an admin
+// @Description Deactivates an admin so they can no longer log in. Deactivating the last active super admin is rejected.
+// @Tags admins
+// @Produce json
+// @Param id path int true "Admin ID"
+// @Success 200 {object} map[string]string "Admin deactivated"
+// @Failure 404 {object} DataBase.ErrorResponse "Admin not found"
+// @Failure 409 {object} DataBase.ErrorResponse "Would remove the last super admin"
+// @Failure 401 {object} DataBase.ErrorResponse "Missing or invalid admin session"
+// @Failure 403 {object} DataBase.ErrorResponse "Not a super admin"
+// @Router /admin/admins/{id}/deactivate [post]
+func (h *Handler) DeactivateAdmin(w http.ResponseWriter, r *http.Request) {
+	id, ok := adminIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var admin DataBase.Admin
+	err := h.Store.Transaction(func(tx Repository.Store) error {
+		var err error
+		if admin, err = tx.Admins().FindByID(id); err != nil {
+			return err
+		}
+		if admin.Role == DataBase.AdminRoleSuperAdmin {
+			if err := ensureOtherSuperAdmin(tx.Admins(), admin.Admin_ID); err != nil {
+				return err
+			}
+		}
+		admin.Status = DataBase.AdminStatusDeactivated
+		admin.Invite_Token = ""
+		admin.Invite_Expires = nil
+		return tx.Admins().Save(&admin)
+	})
+	if err != nil {
+		writeAdminError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Admin " + admin.Username + " deactivated"})
+}
+
+// ResetAdminPassword issues a password reset token for an admin.
+// @Summary Reset an admin's password
+// @Description Invalidates the current password and returns a one-time token to be redeemed at /admin/admins/password.
+// @Tags admins
+// @Produce json
+// @Param id path int true "Admin ID"
+// @Success 200 {object} AdminTokenResponse "Reset token issued"
+// @Failure 404 {object} DataBase.ErrorResponse "Admin not found"
+// @Failure 409 {object} DataBase.ErrorResponse "Admin is deactivated"
+// @Failure 401 {object} DataBase.ErrorResponse "Missing or invalid admin session"
+// @Failure 403 {object} DataBase.ErrorResponse "Not a super admin"
+// @Router /admin/admins/{id}/reset-password [post]
+func (h *Handler) ResetAdminPassword(w http.ResponseWriter, r *http.Request) {
+	id, ok := adminIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	admin, err := h.Store.Admins().FindByID(id)
+	if err != nil {
+		writeAdminError(w, r, err)
+		return
+	}
+
+	if admin.Status == DataBase.AdminStatusDeactivated {
+		APIError.Write(w, r, http.StatusConflict, APIError.AdminDeactivated, "Admin is deactivated")
+		return
+	}
+
+	token, tokenHash, err := newToken()
+	if err != nil {
+		APIError.Internal(w, r, "Failed to generate reset token", err)
+		return
+	}
+	expires := time.Now().Add(inviteTTL)
+
+	// Clearing the password means the old one stops working immediately
+	admin.Password = ""
+	admin.Invite_Token = tokenHash
+	admin.Invite_Expires = &expires
+	if err := h.Store.Admins().Save(&admin); err != nil {
+		APIError.Internal(w, r, "Failed to reset password", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AdminTokenResponse{
+		Message:   "Password reset for admin " + admin.Username,
+		Admin_ID:  admin.Admin_ID,
+		Token:     token,
+		ExpiresAt: expires,
+	})
+}
+
+func writeAdminError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, Repository.ErrNotFound):
+		APIError.Write(w, r, http.StatusNotFound, APIError.AdminNotFound, "Admin not found")
+	case errors.Is(err, errLastSuperAdmin):
+		APIError.Write(w, r, http.StatusConflict, APIError.LastSuperAdmin, "At least one active super admin must remain")
+	default:
+		APIError.Internal(w, r, "Database error", err)
+	}
+}
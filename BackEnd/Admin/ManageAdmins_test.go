@@ -0,0 +1,197 @@
+package Admin
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func callWithID(handler http.HandlerFunc, method string, id uint, payload interface{}) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequest(method, "/admin/admins/"+fmt.Sprint(id), bytes.NewBuffer(body))
+	req = mux.SetURLVars(req, map[string]string{"id": fmt.Sprint(id)})
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestBootstrapAdmin_OnlyOnce(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	rr := postAdminJSON(h.BootstrapAdmin, "/admin/bootstrap", BootstrapRequest{Username: "root", Password: "correct-horse"})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	admin, _ := store.Admins().FindByUsername("root")
+	if admin.Role != DataBase.AdminRoleSuperAdmin || !isPasswordHash(admin.Password) {
+		t.Errorf("expected hashed super admin, got role %q", admin.Role)
+	}
+
+	rr = postAdminJSON(h.BootstrapAdmin, "/admin/bootstrap", BootstrapRequest{Username: "root2", Password: "correct-horse"})
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected status 409 on second bootstrap, got %d", rr.Code)
+	}
+
+	rr = postAdminJSON(h.AdminLogin, "/AdminLogin", LoginRequest{Username: "root", Password: "correct-horse"})
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected bootstrapped admin to log in, got %d", rr.Code)
+	}
+}
+
+func TestBootstrapAdmin_Token(t *testing.T) {
+	h := NewHandler(TestStore.Open(t))
+	h.BootstrapToken = "let-me-in"
+
+	for _, tc := range []struct {
+		token  string
+		status int
+	}{{"", http.StatusForbidden}, {"let-me-out", http.StatusForbidden}, {"let-me-in", http.StatusCreated}} {
+		body, _ := json.Marshal(BootstrapRequest{Username: "root", Password: "correct-horse"})
+		req, _ := http.NewRequest("POST", "/admin/bootstrap", bytes.NewBuffer(body))
+		req.Header.Set("X-Bootstrap-Token", tc.token)
+		rr := httptest.NewRecorder()
+		h.BootstrapAdmin(rr, req)
+		if rr.Code != tc.status {
+			t.Errorf("token %q: expected status %d, got %d", tc.token, tc.status, rr.Code)
+		}
+	}
+}
+
+func TestInviteAdmin_AcceptAndLogin(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	rr := postAdminJSON(h.InviteAdmin, "/admin/admins", InviteAdminRequest{Username: "newbie"})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var invite AdminTokenResponse
+	json.Unmarshal(rr.Body.Bytes(), &invite)
+
+	// Invited admins cannot log in yet
+	rr = postAdminJSON(h.AdminLogin, "/AdminLogin", LoginRequest{Username: "newbie", Password: ""})
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected invited admin login to fail, got %d", rr.Code)
+	}
+
+	rr = postAdminJSON(h.SetAdminPassword, "/admin/admins/password", SetPasswordRequest{Token: invite.Token, Password: "a-long-password"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 setting password, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	// The token is single use
+	rr = postAdminJSON(h.SetAdminPassword, "/admin/admins/password", SetPasswordRequest{Token: invite.Token, Password: "another-password"})
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected reused token to be rejected, got %d", rr.Code)
+	}
+
+	rr = postAdminJSON(h.AdminLogin, "/AdminLogin", LoginRequest{Username: "newbie", Password: "a-long-password"})
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected activated admin to log in, got %d", rr.Code)
+	}
+
+	req, _ := http.NewRequest("GET", "/admin/admins", nil)
+	list := httptest.NewRecorder()
+	http.HandlerFunc(h.ListAdmins).ServeHTTP(list, req)
+	var admins []AdminSummary
+	json.Unmarshal(list.Body.Bytes(), &admins)
+	if len(admins) != 1 || admins[0].Last_Login == nil || admins[0].Status != DataBase.AdminStatusActive {
+		t.Errorf("expected one active admin with a last login, got %+v", admins)
+	}
+}
+
+func TestLastSuperAdminIsProtected(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	root := DataBase.Admin{Username: "root", Password: "x", Role: DataBase.AdminRoleSuperAdmin}
+	store.Admins().Create(&root)
+
+	if rr := callWithID(h.DeactivateAdmin, "POST", root.Admin_ID, nil); rr.Code != http.StatusConflict {
+		t.Errorf("expected deactivating the last super admin to fail with 409, got %d", rr.Code)
+	}
+	if rr := callWithID(h.SetAdminRole, "PUT", root.Admin_ID, SetRoleRequest{Role: DataBase.AdminRoleAdmin}); rr.Code != http.StatusConflict {
+		t.Errorf("expected demoting the last super admin to fail with 409, got %d", rr.Code)
+	}
+
+	second := DataBase.Admin{Username: "second", Password: "x", Role: DataBase.AdminRoleAdmin}
+	store.Admins().Create(&second)
+	if rr := callWithID(h.SetAdminRole, "PUT", second.Admin_ID, SetRoleRequest{Role: DataBase.AdminRoleSuperAdmin}); rr.Code != http.StatusOK {
+		t.Fatalf("expected promotion to succeed, got %d", rr.Code)
+	}
+	if rr := callWithID(h.DeactivateAdmin, "POST", root.Admin_ID, nil); rr.Code != http.StatusOK {
+		t.Errorf("expected deactivation to succeed once another super admin exists, got %d", rr.Code)
+	}
+}
+
+func TestLastSuperAdminSurvivesConcurrentDemotions(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	// Each of two super admins is demoted or deactivated at the same time; one must stay
+	for round := 0; round < 10; round++ {
+		first := DataBase.Admin{Username: fmt.Sprintf("first-%d", round), Password: "x", Role: DataBase.AdminRoleSuperAdmin}
+		store.Admins().Create(&first)
+		second := DataBase.Admin{Username: fmt.Sprintf("second-%d", round), Password: "x", Role: DataBase.AdminRoleSuperAdmin}
+		store.Admins().Create(&second)
+
+		var wg sync.WaitGroup
+		codes := make([]int, 2)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			codes[0] = callWithID(h.SetAdminRole, "PUT", first.Admin_ID, SetRoleRequest{Role: DataBase.AdminRoleAdmin}).Code
+		}()
+		go func() {
+			defer wg.Done()
+			codes[1] = callWithID(h.DeactivateAdmin, "POST", second.Admin_ID, nil).Code
+		}()
+		wg.Wait()
+
+		if left, err := store.Admins().CountActiveSuperAdmins(0); err != nil || left != 1 {
+			t.Fatalf("round %d: expected exactly one super admin left, got %d (statuses %v, error: %v)", round, left, codes, err)
+		}
+		// Leave no super admin from this round for the next one to count
+		remaining := first
+		if codes[0] == http.StatusOK {
+			remaining = second
+		}
+		remaining, _ = store.Admins().FindByID(remaining.Admin_ID)
+		remaining.Role = DataBase.AdminRoleAdmin
+		store.Admins().Save(&remaining)
+	}
+}
+
+func TestResetAdminPassword_InvalidatesOldPassword(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	admin := DataBase.Admin{Username: "forgetful", Password: "old-password"}
+	store.Admins().Create(&admin)
+
+	rr := callWithID(h.ResetAdminPassword, "POST", admin.Admin_ID, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var reset AdminTokenResponse
+	json.Unmarshal(rr.Body.Bytes(), &reset)
+
+	if rr := postAdminJSON(h.AdminLogin, "/AdminLogin", LoginRequest{Username: "forgetful", Password: "old-password"}); rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected old password to stop working, got %d", rr.Code)
+	}
+
+	postAdminJSON(h.SetAdminPassword, "/admin/admins/password", SetPasswordRequest{Token: reset.Token, Password: "new-password-1"})
+	if rr := postAdminJSON(h.AdminLogin, "/AdminLogin", LoginRequest{Username: "forgetful", Password: "new-password-1"}); rr.Code != http.StatusOK {
+		t.Errorf("expected new password to work, got %d", rr.Code)
+	}
+}
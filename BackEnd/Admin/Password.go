@@ -0,0 +1,56 @@
+package Admin
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// minPasswordLength is enforced whenever an admin chooses a new password.
+const minPasswordLength = 10
+
+// hashPassword returns the bcrypt hash stored in Admin.Password.
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// isPasswordHash reports whether a stored password is a bcrypt hash rather than a legacy plain text value.
+func isPasswordHash(stored string) bool {
+	return strings.HasPrefix(stored, "$2a$") || strings.HasPrefix(stored, "$2b$") || strings.HasPrefix(stored, "$2y$")
+}
+
+// checkPassword compares a candidate against the stored value.
+// Admins seeded before hashing was introduced still have plain text passwords; those are compared in constant time.
+func checkPassword(stored, candidate string) bool {
+	if stored == "" {
+		return false
+	}
+	if isPasswordHash(stored) {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(candidate)) == nil
+	}
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(candidate)) == 1
+}
+
+// newToken returns a random token for invites and password resets along with the hash that gets stored.
+func newToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+// hashToken returns the SHA-256 hex digest of an invite or reset token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,40 @@
+package Admin
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/Availability"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// ReconcileAvailability checks bookings and blackouts for inconsistencies.
+// @Summary Check availability consistency (Admin)
+// @Description Reports active bookings on missing courts or invalid slots, bookings on blacked out slots, and blackouts on missing courts. With apply=true the repairable issues are fixed: such bookings are cancelled and orphaned blackouts deleted.
+// @Tags admin
+// @Produce json
+// @Param  apply query bool false "Repair the issues instead of only reporting them"
+// @Success 200 {object} Availability.Report
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid apply flag"
+// @Failure 500 {object} DataBase.ErrorResponse "Failed to reconcile availability"
+// @Router /admin/reconcile [post]
+func (h *Handler) ReconcileAvailability(w http.ResponseWriter, r *http.Request) {
+	apply := false
+	if raw := r.URL.Query().Get("apply"); raw != "" {
+		var err error
+		if apply, err = strconv.ParseBool(raw); err != nil {
+			APIError.Validation(w, r, "apply must be true or false", APIError.Field("apply", "must be true or false"))
+			return
+		}
+	}
+
+	report, err := Availability.Reconcile(h.Store, apply)
+	if err != nil {
+		APIError.Internal(w, r, "Failed to reconcile availability", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,123 @@
+package Admin
+
+import (
+	"BackEnd/Availability"
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"BackEnd/Repository/TestStore"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func reconcile(t *testing.T, h *Handler, query string) Availability.Report {
+	t.Helper()
+	req, _ := http.NewRequest("POST", "/admin/reconcile"+query, nil)
+	rr := httptest.NewRecorder()
+	h.ReconcileAvailability(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var report Availability.Report
+	if err := json.NewDecoder(rr.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	return report
+}
+
+func seedInconsistencies(t *testing.T, store Repository.Store) {
+	t.Helper()
+	court := DataBase.Court{Court_ID: 1, Court_Name: "Court A", Court_Location: "Downtown", Court_Status: 1}
+	if err := store.Courts().Create(&court); err != nil {
+		t.Fatalf("failed to create court: %v", err)
+	}
+	date := "2026-05-01"
+	slot := 4
+	records := []DataBase.Bookings{
+		{Booking_ID: 1, Customer_ID: 1, Sport_ID: 1, Court_ID: 1, Booking_Status: DataBase.BookingConfirmed, Booking_Time: 3, Booking_Date: &date},
+		{Booking_ID: 2, Customer_ID: 1, Sport_ID: 1, Court_ID: 99, Booking_Status: DataBase.BookingConfirmed, Booking_Time: 3, Booking_Date: &date},
+		{Booking_ID: 3, Customer_ID: 1, Sport_ID: 1, Court_ID: 1, Booking_Status: DataBase.BookingConfirmed, Booking_Time: 12, Booking_Date: &date},
+		{Booking_ID: 4, Customer_ID: 1, Sport_ID: 1, Court_ID: 1, Booking_Status: DataBase.BookingConfirmed, Booking_Time: slot, Booking_Date: &date},
+		{Booking_ID: 5, Customer_ID: 1, Sport_ID: 1, Court_ID: 99, Booking_Status: DataBase.BookingCancelledByUser, Booking_Time: 5, Booking_Date: &date},
+	}
+	for i := range records {
+		if err := store.Bookings().Create(&records[i]); err != nil {
+			t.Fatalf("failed to create booking: %v", err)
+		}
+	}
+	blackouts := []DataBase.Court_Blackout{
+		{Court_ID: 1, Blackout_Date: date, Slot_Index: &slot},
+		{Court_ID: 99, Blackout_Date: date},
+	}
+	for i := range blackouts {
+		if err := store.Blackouts().Create(&blackouts[i]); err != nil {
+			t.Fatalf("failed to create blackout: %v", err)
+		}
+	}
+}
+
+func TestReconcileAvailability(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+	seedInconsistencies(t, store)
+
+	// A dry run reports every issue and changes nothing
+	report := reconcile(t, h, "")
+	kinds := map[string]int{}
+	for _, issue := range report.Issues {
+		kinds[issue.Kind]++
+		if issue.Repaired {
+			t.Errorf("dry run repaired %+v", issue)
+		}
+	}
+	expected := map[string]int{
+		Availability.IssueMissingCourt:   1,
+		Availability.IssueInvalidSlot:    1,
+		Availability.IssueBlackedOut:     1,
+		Availability.IssueOrphanBlackout: 1,
+	}
+	if report.Applied || len(report.Issues) != 4 {
+		t.Errorf("expected 4 unapplied issues, got %+v", report)
+	}
+	for kind, count := range expected {
+		if kinds[kind] != count {
+			t.Errorf("expected %d %s issue(s), got %d", count, kind, kinds[kind])
+		}
+	}
+	if booking, _ := store.Bookings().FindByID(2); booking.Booking_Status != DataBase.BookingConfirmed {
+		t.Errorf("dry run changed booking status to %q", booking.Booking_Status)
+	}
+
+	// Applying repairs all but the blacked out booking, which is left for an admin
+	report = reconcile(t, h, "?apply=true")
+	for _, issue := range report.Issues {
+		if issue.Repaired == (issue.Kind == Availability.IssueBlackedOut) {
+			t.Errorf("unexpected repair state for %+v", issue)
+		}
+	}
+	for _, id := range []uint{2, 3} {
+		if booking, _ := store.Bookings().FindByID(id); booking.Booking_Status != DataBase.BookingCancelledBySystem {
+			t.Errorf("expected booking %d to be cancelled, got %q", id, booking.Booking_Status)
+		}
+	}
+	if blackouts, _ := store.Blackouts().List([]uint{99}, ""); len(blackouts) != 0 {
+		t.Errorf("expected the orphaned blackout to be deleted, got %d", len(blackouts))
+	}
+
+	report = reconcile(t, h, "?apply=true")
+	if len(report.Issues) != 1 || report.Issues[0].Kind != Availability.IssueBlackedOut {
+		t.Errorf("expected only the blacked out booking to remain, got %+v", report.Issues)
+	}
+}
+
+func TestReconcileAvailability_InvalidApply(t *testing.T) {
+	h := NewHandler(TestStore.Open(t))
+
+	req, _ := http.NewRequest("POST", "/admin/reconcile?apply=maybe", nil)
+	rr := httptest.NewRecorder()
+	h.ReconcileAvailability(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
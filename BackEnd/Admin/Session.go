@@ -0,0 +1,120 @@
+package Admin
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/DataBase"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultSessionTTL is how long an admin session lasts unless the handler is told otherwise.
+const DefaultSessionTTL = 12 * time.Hour
+
+// sessionIssuer tells admin sessions apart from tokens signed by anyone else.
+const sessionIssuer = "courtlink-admin"
+
+type sessionClaims struct {
+	// Credential is derived from the password hash, so resetting the password ends every session.
+	Credential string `json:"cred"`
+	jwt.RegisteredClaims
+}
+
+type adminKey struct{}
+
+// newSessionSecret returns a random signing key for admin sessions.
+func newSessionSecret() []byte {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic("admin session secret: " + err.Error())
+	}
+	return secret
+}
+
+// sessionCredential fingerprints the admin's password hash without revealing it.
+func sessionCredential(admin DataBase.Admin) string {
+	sum := sha256.Sum256([]byte(admin.Password))
+	return hex.EncodeToString(sum[:8])
+}
+
+// issueSession signs a session token for an admin who has just logged in.
+func (h *Handler) issueSession(admin DataBase.Admin, now time.Time) (string, time.Time, error) {
+	expires := now.Add(h.SessionTTL)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, sessionClaims{
+		Credential: sessionCredential(admin),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    sessionIssuer,
+			Subject:   strconv.FormatUint(uint64(admin.Admin_ID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expires),
+		},
+	})
+	signed, err := token.SignedString(h.SessionSecret)
+	return signed, expires, err
+}
+
+// sessionAdmin returns the admin whose session the request carries. The admin is read again on
+// every request, so deactivating an admin or resetting their password ends their sessions at once.
+func (h *Handler) sessionAdmin(w http.ResponseWriter, r *http.Request) (DataBase.Admin, bool) {
+	tokenString, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || tokenString == "" {
+		APIError.Write(w, r, http.StatusUnauthorized, APIError.Unauthorized, "Missing admin session")
+		return DataBase.Admin{}, false
+	}
+
+	var claims sessionClaims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(*jwt.Token) (interface{}, error) {
+		return h.SessionSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(sessionIssuer), jwt.WithExpirationRequired())
+	if err != nil {
+		APIError.Write(w, r, http.StatusUnauthorized, APIError.InvalidToken, "Invalid admin session")
+		return DataBase.Admin{}, false
+	}
+
+	id, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		APIError.Write(w, r, http.StatusUnauthorized, APIError.InvalidToken, "Invalid admin session")
+		return DataBase.Admin{}, false
+	}
+	admin, err := h.Store.Admins().FindByID(uint(id))
+	if err != nil || admin.Status != DataBase.AdminStatusActive || sessionCredential(admin) != claims.Credential {
+		APIError.Write(w, r, http.StatusUnauthorized, APIError.InvalidToken, "Admin session is no longer valid")
+		return DataBase.Admin{}, false
+	}
+	return admin, true
+}
+
+// RequireAdmin only lets requests through that carry the session of an active admin.
+func (h *Handler) RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		admin, ok := h.sessionAdmin(w, r)
+		if !ok {
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), adminKey{}, admin)))
+	})
+}
+
+// RequireSuperAdmin is RequireAdmin for routes only an active super admin may use.
+func (h *Handler) RequireSuperAdmin(next http.Handler) http.Handler {
+	return h.RequireAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if admin, _ := CurrentAdmin(r); admin.Role != DataBase.AdminRoleSuperAdmin {
+			APIError.Write(w, r, http.StatusForbidden, APIError.Forbidden, "Only a super admin may do this")
+			return
+		}
+		next.ServeHTTP(w, r)
+	}))
+}
+
+// CurrentAdmin returns the admin that RequireAdmin let through.
+func CurrentAdmin(r *http.Request) (DataBase.Admin, bool) {
+	admin, ok := r.Context().Value(adminKey{}).(DataBase.Admin)
+	return admin, ok
+}
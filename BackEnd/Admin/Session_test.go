@@ -0,0 +1,132 @@
+package Admin
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// loginToken logs an admin in and returns the session token.
+func loginToken(t *testing.T, h *Handler, username, password string) string {
+	t.Helper()
+	rr := postAdminJSON(h.AdminLogin, "/AdminLogin", LoginRequest{Username: username, Password: password})
+	var login LoginResponse
+	json.Unmarshal(rr.Body.Bytes(), &login)
+	if rr.Code != http.StatusOK || login.Token == "" {
+		t.Fatalf("login as %s: expected a session, got %d: %s", username, rr.Code, rr.Body.String())
+	}
+	return login.Token
+}
+
+func TestRequireSuperAdmin(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	root := DataBase.Admin{Username: "root", Password: "rootpass", Role: DataBase.AdminRoleSuperAdmin}
+	store.Admins().Create(&root)
+	plain := DataBase.Admin{Username: "plain", Password: "plainpass"}
+	store.Admins().Create(&plain)
+
+	rootToken := loginToken(t, h, "root", "rootpass")
+	plainToken := loginToken(t, h, "plain", "plainpass")
+
+	// A session signed with another key, as by another server without the shared secret
+	other := NewHandler(store)
+	forged, _, _ := other.issueSession(root, time.Now())
+	expired, _, _ := h.issueSession(root, time.Now().Add(-2*h.SessionTTL))
+
+	routes := []struct {
+		name    string
+		method  string
+		handler http.HandlerFunc
+	}{
+		{"list", "GET", h.ListAdmins},
+		{"invite", "POST", h.InviteAdmin},
+		{"role", "PUT", h.SetAdminRole},
+		{"deactivate", "POST", h.DeactivateAdmin},
+		{"reset-password", "POST", h.ResetAdminPassword},
+	}
+	cases := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"anonymous", "", http.StatusUnauthorized},
+		{"not a bearer token", "Basic " + rootToken, http.StatusUnauthorized},
+		{"garbage", "Bearer not-a-token", http.StatusUnauthorized},
+		{"foreign key", "Bearer " + forged, http.StatusUnauthorized},
+		{"expired", "Bearer " + expired, http.StatusUnauthorized},
+		{"plain admin", "Bearer " + plainToken, http.StatusForbidden},
+	}
+	for _, route := range routes {
+		for _, tc := range cases {
+			called := false
+			guarded := h.RequireSuperAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				route.handler(w, r)
+			}))
+			req, _ := http.NewRequest(route.method, "/admin/admins/1", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rr := httptest.NewRecorder()
+			guarded.ServeHTTP(rr, req)
+			if rr.Code != tc.status || called {
+				t.Errorf("%s as %s: expected status %d without reaching the handler, got %d (reached=%t)",
+					route.name, tc.name, tc.status, rr.Code, called)
+			}
+		}
+	}
+
+	list := func(token string) int {
+		req, _ := http.NewRequest("GET", "/admin/admins", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		h.RequireSuperAdmin(http.HandlerFunc(h.ListAdmins)).ServeHTTP(rr, req)
+		return rr.Code
+	}
+	if code := list(rootToken); code != http.StatusOK {
+		t.Fatalf("super admin: expected status 200, got %d", code)
+	}
+
+	// Resetting the password ends the sessions issued with the old one
+	callWithID(h.ResetAdminPassword, "POST", root.Admin_ID, nil)
+	if code := list(rootToken); code != http.StatusUnauthorized {
+		t.Errorf("after a password reset: expected status 401, got %d", code)
+	}
+}
+
+func TestRequireAdmin_EndsWithDeactivation(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	root := DataBase.Admin{Username: "root", Password: "rootpass", Role: DataBase.AdminRoleSuperAdmin}
+	store.Admins().Create(&root)
+	store.Admins().Create(&DataBase.Admin{Username: "backup", Password: "x", Role: DataBase.AdminRoleSuperAdmin})
+	token := loginToken(t, h, "root", "rootpass")
+
+	var seen DataBase.Admin
+	guarded := h.RequireAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = CurrentAdmin(r)
+	}))
+	call := func() int {
+		req, _ := http.NewRequest("GET", "/admin/allBookings", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		guarded.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if code := call(); code != http.StatusOK || seen.Admin_ID != root.Admin_ID {
+		t.Fatalf("expected the session to reach the handler as root, got %d as %+v", code, seen)
+	}
+
+	callWithID(h.DeactivateAdmin, "POST", root.Admin_ID, nil)
+	if code := call(); code != http.StatusUnauthorized {
+		t.Errorf("after deactivation: expected status 401, got %d", code)
+	}
+}
@@ -0,0 +1,135 @@
+package Admin
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/Repository"
+	"encoding/json"
+	"net/http"
+)
+
+type TOTPEnrollRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type TOTPEnrollResponse struct {
+	Message       string   `json:"message"`
+	Secret        string   `json:"secret"`
+	OTPAuthURL    string   `json:"otpauth_url"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TOTPVerifyRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// AdminEnrollTOTP provisions a new TOTP secret and recovery codes for an admin.
+// @Summary Start TOTP enrolment
+// @Description Generates a TOTP secret and a fresh set of recovery codes. The second factor is only enforced once confirmed via /admin/totp/verify.
+// @Tags admins
+// @Accept json
+// @Produce json
+// @Param credentials body TOTPEnrollRequest true "Admin credentials"
+// @Success 200 {object} TOTPEnrollResponse "Secret and recovery codes"
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid request body"
+// @Failure 401 {object} DataBase.ErrorResponse "Invalid username or password"
+// @Failure 409 {object} DataBase.ErrorResponse "TOTP already enabled"
+// @Failure 429 {object} DataBase.ErrorResponse "Too many failed attempts"
+// @Router /admin/totp/enroll [post]
+func (h *Handler) AdminEnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	var req TOTPEnrollRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		APIError.Malformed(w, r, "Invalid request body")
+		return
+	}
+
+	admin, keys, ok := h.authenticateAdmin(w, r, req.Username, req.Password)
+	if !ok {
+		return
+	}
+
+	if admin.TOTP_Enabled {
+		APIError.Write(w, r, http.StatusConflict, APIError.SecondFactorEnrolled, "TOTP is already enabled for this admin")
+		return
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		APIError.Internal(w, r, "Failed to generate TOTP secret", err)
+		return
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		APIError.Internal(w, r, "Failed to generate recovery codes", err)
+		return
+	}
+
+	err = h.Store.Transaction(func(tx Repository.Store) error {
+		admin.TOTP_Secret = secret
+		if err := tx.Admins().Save(&admin); err != nil {
+			return err
+		}
+		// Replace any codes left over from an earlier, unfinished enrolment
+		return tx.Admins().ReplaceRecoveryCodes(admin.Admin_ID, hashes)
+	})
+	if err != nil {
+		APIError.Internal(w, r, "Failed to store TOTP secret and recovery codes", err)
+		return
+	}
+	h.clearLoginFailures(keys)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TOTPEnrollResponse{
+		Message:       "Scan the secret with an authenticator app, then confirm with /admin/totp/verify",
+		Secret:        secret,
+		OTPAuthURL:    totpURL(admin.Username, secret),
+		RecoveryCodes: codes,
+	})
+}
+
+// AdminVerifyTOTP confirms a pending TOTP enrolment and turns on the second factor.
+// @Summary Confirm TOTP enrolment
+// @Description Verifies a code from the authenticator app against the pending secret and enables TOTP for the admin.
+// @Tags admins
+// @Accept json
+// @Produce json
+// @Param verification body TOTPVerifyRequest true "Admin credentials and TOTP code"
+// @Success 200 {object} map[string]string "TOTP enabled"
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid request body or no pending enrolment"
+// @Failure 401 {object} DataBase.ErrorResponse "Invalid credentials or code"
+// @Failure 429 {object} DataBase.ErrorResponse "Too many failed attempts"
+// @Router /admin/totp/verify [post]
+func (h *Handler) AdminVerifyTOTP(w http.ResponseWriter, r *http.Request) {
+	var req TOTPVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		APIError.Malformed(w, r, "Invalid request body")
+		return
+	}
+
+	admin, keys, ok := h.authenticateAdmin(w, r, req.Username, req.Password)
+	if !ok {
+		return
+	}
+
+	if admin.TOTP_Secret == "" {
+		APIError.Write(w, r, http.StatusBadRequest, APIError.NoPendingEnrolment, "No pending TOTP enrolment, call /admin/totp/enroll first")
+		return
+	}
+
+	if !h.useTOTP(admin, req.Code) {
+		APIError.Write(w, r, http.StatusUnauthorized, APIError.InvalidSecondFactor, "Invalid or already used TOTP code")
+		return
+	}
+
+	admin.TOTP_Enabled = true
+	if err := h.Store.Admins().Save(&admin); err != nil {
+		APIError.Internal(w, r, "Failed to enable TOTP", err)
+		return
+	}
+	h.clearLoginFailures(keys)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "TOTP enabled"})
+}
@@ -0,0 +1,103 @@
+package Admin
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
+	"bytes"
+	"encoding/base32"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode_RFC6238Vector(t *testing.T) {
+	// RFC 6238 appendix B, SHA-1 seed "12345678901234567890" at T = 59s, truncated to 6 digits
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	code, err := totpCode(secret, 59/30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != "287082" {
+		t.Errorf("expected 287082, got %s", code)
+	}
+}
+
+func postAdminJSON(handler http.HandlerFunc, path string, payload interface{}) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequest("POST", path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestAdminTOTP_EnrollVerifyAndLogin(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	store.Admins().Create(&DataBase.Admin{Username: "mfauser", Password: "mfapass"})
+
+	// 1. Enrol
+	rr := postAdminJSON(h.AdminEnrollTOTP, "/admin/totp/enroll", TOTPEnrollRequest{Username: "mfauser", Password: "mfapass"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("enroll: expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var enrolled TOTPEnrollResponse
+	json.Unmarshal(rr.Body.Bytes(), &enrolled)
+	if enrolled.Secret == "" || len(enrolled.RecoveryCodes) != recoveryCodeCount {
+		t.Fatalf("unexpected enrolment response: %+v", enrolled)
+	}
+
+	// 2. Login is not yet gated on the second factor
+	rr = postAdminJSON(h.AdminLogin, "/AdminLogin", LoginRequest{Username: "mfauser", Password: "mfapass"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("login before verify: expected status 200, got %d", rr.Code)
+	}
+
+	// 3. Confirm with a current code
+	step := uint64(time.Now().Unix()) / 30
+	code, _ := totpCode(enrolled.Secret, step)
+	rr = postAdminJSON(h.AdminVerifyTOTP, "/admin/totp/verify", TOTPVerifyRequest{Username: "mfauser", Password: "mfapass", Code: code})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("verify: expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	// 4. Password alone now asks for the second factor
+	rr = postAdminJSON(h.AdminLogin, "/AdminLogin", LoginRequest{Username: "mfauser", Password: "mfapass"})
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("login without code: expected status 401, got %d", rr.Code)
+	}
+	var challenge DataBase.ErrorResponse
+	json.Unmarshal(rr.Body.Bytes(), &challenge)
+	if challenge.Code != "SECOND_FACTOR_REQUIRED" {
+		t.Errorf("expected the second factor to be required, got %+v", challenge)
+	}
+
+	// 5. The code used to confirm cannot be replayed, but the next one succeeds once
+	rr = postAdminJSON(h.AdminLogin, "/AdminLogin", LoginRequest{Username: "mfauser", Password: "mfapass", TOTPCode: code})
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("login with the confirmation code: expected status 401, got %d", rr.Code)
+	}
+	next, _ := totpCode(enrolled.Secret, step+1)
+	rr = postAdminJSON(h.AdminLogin, "/AdminLogin", LoginRequest{Username: "mfauser", Password: "mfapass", TOTPCode: next})
+	if rr.Code != http.StatusOK {
+		t.Errorf("login with code: expected status 200, got %d", rr.Code)
+	}
+	rr = postAdminJSON(h.AdminLogin, "/AdminLogin", LoginRequest{Username: "mfauser", Password: "mfapass", TOTPCode: next})
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("replayed code: expected status 401, got %d", rr.Code)
+	}
+
+	// 6. A recovery code works exactly once
+	recovery := enrolled.RecoveryCodes[0]
+	rr = postAdminJSON(h.AdminLogin, "/AdminLogin", LoginRequest{Username: "mfauser", Password: "mfapass", RecoveryCode: recovery})
+	if rr.Code != http.StatusOK {
+		t.Errorf("login with recovery code: expected status 200, got %d", rr.Code)
+	}
+	rr = postAdminJSON(h.AdminLogin, "/AdminLogin", LoginRequest{Username: "mfauser", Password: "mfapass", RecoveryCode: recovery})
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("reused recovery code: expected status 401, got %d", rr.Code)
+	}
+}
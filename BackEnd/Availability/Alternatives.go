@@ -0,0 +1,72 @@
+package Availability
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"sort"
+)
+
+// AlternativeOptions bounds the free slots offered in place of one that cannot be booked.
+type AlternativeOptions struct {
+	// Radius is how many slots before and after the requested one are tried on the same court.
+	Radius int
+	// Limit is the most alternatives offered; zero offers none.
+	Limit int
+}
+
+// DefaultAlternatives tries two slots either side and offers at most five alternatives.
+var DefaultAlternatives = AlternativeOptions{Radius: 2, Limit: 5}
+
+// Alternatives returns the free slots nearest to slotIndex of the court on date: the other open
+// courts of its sport at the same time, then its own slots up to opts.Radius away, nearest first
+// and earlier first between equally near ones.
+func Alternatives(store Repository.Store, courtID uint, date string, slotIndex int, opts AlternativeOptions) ([]DataBase.SlotAlternative, error) {
+	alternatives := []DataBase.SlotAlternative{}
+	if opts.Limit <= 0 || !ValidSlot(slotIndex) {
+		return alternatives, nil
+	}
+	court, err := store.Courts().FindByID(courtID)
+	if err != nil {
+		return nil, err
+	}
+
+	open := 1
+	courts, _, err := store.Courts().Page(Repository.CourtFilter{SportID: court.Sport_id, Status: &open}, Repository.ListQuery{})
+	if err != nil {
+		return nil, err
+	}
+	courtIDs := []uint{court.Court_ID}
+	for _, other := range courts {
+		if other.Court_ID != court.Court_ID {
+			courtIDs = append(courtIDs, other.Court_ID)
+		}
+	}
+	slots, err := ForCourts(store, courtIDs, date)
+	if err != nil {
+		return nil, err
+	}
+
+	offer := func(c DataBase.Court, slot, distance int) {
+		if ValidSlot(slot) && slots[c.Court_ID][slot] == DataBase.SlotAvailable {
+			alternatives = append(alternatives, DataBase.SlotAlternative{
+				CourtID: c.Court_ID, CourtName: c.Court_Name, Date: date,
+				SlotIndex: slot, Slot: DataBase.SlotLabel(slot), Distance: distance,
+			})
+		}
+	}
+	for _, other := range courts {
+		if other.Court_ID != court.Court_ID {
+			offer(other, slotIndex, 0)
+		}
+	}
+	for distance := 1; distance <= opts.Radius; distance++ {
+		offer(court, slotIndex-distance, distance)
+		offer(court, slotIndex+distance, distance)
+	}
+
+	sort.SliceStable(alternatives, func(i, j int) bool { return alternatives[i].Distance < alternatives[j].Distance })
+	if len(alternatives) > opts.Limit {
+		alternatives = alternatives[:opts.Limit]
+	}
+	return alternatives, nil
+}
@@ -0,0 +1,144 @@
+// Package Availability works out which court slots can be booked. Nothing about a slot is
+// stored: its state comes from the active bookings and blackouts for that court and day, so it
+// can never disagree with them.
+package Availability
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ValidSlot reports whether slotIndex falls inside a court's day.
+func ValidSlot(slotIndex int) bool {
+	return slotIndex >= 0 && slotIndex < DataBase.SlotCount
+}
+
+// ForCourts returns the slot states of each court on date (a Booking_Date), indexed by slot.
+// A blackout, or a slot outside the court's hours, makes a slot unavailable even if it is also
+// booked.
+func ForCourts(store Repository.Store, courtIDs []uint, date string) (map[uint][]int, error) {
+	slots := make(map[uint][]int, len(courtIDs))
+	for _, id := range courtIDs {
+		day := make([]int, DataBase.SlotCount)
+		for i := range day {
+			day[i] = DataBase.SlotAvailable
+		}
+		slots[id] = day
+	}
+
+	bookings, err := store.Bookings().ListActiveOn(courtIDs, date)
+	if err != nil {
+		return nil, err
+	}
+	for _, booking := range bookings {
+		if ValidSlot(booking.Booking_Time) {
+			slots[booking.Court_ID][booking.Booking_Time] = DataBase.SlotBooked
+		}
+	}
+
+	blackouts, err := store.Blackouts().List(courtIDs, date)
+	if err != nil {
+		return nil, err
+	}
+	for _, blackout := range blackouts {
+		day := slots[blackout.Court_ID]
+		if blackout.Slot_Index == nil {
+			for i := range day {
+				day[i] = DataBase.SlotUnavailable
+			}
+		} else if ValidSlot(*blackout.Slot_Index) {
+			day[*blackout.Slot_Index] = DataBase.SlotUnavailable
+		}
+	}
+
+	hours, err := store.Courts().Hours(courtIDs)
+	if err != nil {
+		return nil, err
+	}
+	for id, h := range hours {
+		for i, closed := range closedSlots(h) {
+			if closed {
+				slots[id][i] = DataBase.SlotUnavailable
+			}
+		}
+	}
+	return slots, nil
+}
+
+// Slot returns the state of one slot of a court on date.
+func Slot(store Repository.Store, courtID uint, slotIndex int, date string) (int, error) {
+	slots, err := ForCourts(store, []uint{courtID}, date)
+	if err != nil {
+		return 0, err
+	}
+	if !ValidSlot(slotIndex) {
+		return DataBase.SlotUnavailable, nil
+	}
+	return slots[courtID][slotIndex], nil
+}
+
+// Hours returns when every court's day opens and closes, such as "08:00" and "18:00".
+func Hours() (opens, closes string) {
+	times := slotTimes()
+	return FormatMinutes(times[0][0]), FormatMinutes(times[len(times)-1][1])
+}
+
+// ParseHours checks a court's hours such as "09:00-17:00" and returns them as Court_Hours
+// stores them. They must start when a slot starts and end when a slot ends, so they fall
+// inside the day.
+func ParseHours(raw string) (string, error) {
+	opens, closes, ok := parseHours(raw)
+	if !ok {
+		return "", errors.New("must look like 09:00-17:00")
+	}
+	dayOpens, dayCloses := Hours()
+	if opens >= closes {
+		return "", errors.New("must close after they open")
+	}
+	first, last := -1, -1
+	for i, t := range slotTimes() {
+		if t[0] == opens {
+			first = i
+		}
+		if t[1] == closes {
+			last = i
+		}
+	}
+	if first < 0 || last < first {
+		return "", fmt.Errorf("must open when a slot starts and close when one ends, inside %s-%s", dayOpens, dayCloses)
+	}
+	return FormatMinutes(opens) + "-" + FormatMinutes(closes), nil
+}
+
+// parseHours reads hours such as "09:00-17:00" as minutes after midnight.
+func parseHours(raw string) (opens, closes int, ok bool) {
+	from, to, found := strings.Cut(raw, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err1 := time.Parse("15:04", strings.TrimSpace(from))
+	end, err2 := time.Parse("15:04", strings.TrimSpace(to))
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return start.Hour()*60 + start.Minute(), end.Hour()*60 + end.Minute(), true
+}
+
+// closedSlots reports which slots fall outside a court's hours. It returns nil for a court
+// open all day, including one whose hours cannot be read.
+func closedSlots(hours string) []bool {
+	opens, closes, ok := parseHours(hours)
+	if hours == "" || !ok {
+		return nil
+	}
+	times := slotTimes()
+	closed := make([]bool, len(times))
+	for i, t := range times {
+		closed[i] = t[0] < opens || t[1] > closes
+	}
+	return closed
+}
@@ -0,0 +1,17 @@
+package Availability
+
+import (
+	"BackEnd/DataBase"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// ETag tags the availability of sport's courts on date, a Booking_Date. It changes whenever the
+// sport's availability version moves on, and with the configured day, so a restart with
+// different slots never matches a tag handed out before.
+func ETag(sport DataBase.Sport, date string) string {
+	day := fnv.New32a()
+	day.Write([]byte(strings.Join(DataBase.SlotLabels, "\n")))
+	return fmt.Sprintf(`W/"%d.%d.%s.%x"`, sport.Sport_ID, sport.Availability_Version, date, day.Sum32())
+}
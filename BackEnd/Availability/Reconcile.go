@@ -0,0 +1,161 @@
+package Availability
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"errors"
+	"fmt"
+)
+
+// Kinds of inconsistency reported by Reconcile. The checker was first asked to compare the
+// Court_TimeSlots flags with active bookings, but availability is derived from bookings and
+// blackouts since 0005_court_blackouts dropped that table, so flags can no longer disagree with
+// bookings. That migration settles the old flags once, turning closed slots without a booking
+// into blackouts; Reconcile checks what can still drift between bookings, blackouts and courts.
+const (
+	// IssueMissingCourt is an active booking on a court that no longer exists.
+	IssueMissingCourt = "booking_on_missing_court"
+	// IssueInvalidSlot is an active booking whose Booking_Time is outside the court's day.
+	IssueInvalidSlot = "booking_on_invalid_slot"
+	// IssueBlackedOut is an active booking on a slot that has since been blacked out.
+	IssueBlackedOut = "booking_on_blacked_out_slot"
+	// IssueOrphanBlackout is a blackout on a court that no longer exists.
+	IssueOrphanBlackout = "blackout_on_missing_court"
+)
+
+// Issue is one inconsistency between bookings, blackouts and courts.
+type Issue struct {
+	Kind        string `json:"kind"`
+	Court_ID    uint   `json:"Court_ID"`
+	Booking_ID  uint   `json:"Booking_ID,omitempty"`
+	Blackout_ID uint   `json:"Blackout_ID,omitempty"`
+	Detail      string `json:"detail"`
+	// Repaired is set once apply mode has fixed the issue. Bookings on blacked out slots are
+	// never repaired automatically: an admin decides whether to cancel them or lift the blackout.
+	Repaired bool `json:"repaired"`
+}
+
+// Report is the outcome of one Reconcile run.
+type Report struct {
+	Applied bool    `json:"applied"`
+	Issues  []Issue `json:"issues"`
+}
+
+// Reconcile scans active bookings and blackouts for records that slot availability cannot
+// account for. In dry-run mode (apply false) it only reports them; in apply mode it also
+// cancels bookings on missing courts or invalid slots and deletes orphaned blackouts, all in one
+// transaction.
+func Reconcile(store Repository.Store, apply bool) (Report, error) {
+	report := Report{Applied: apply, Issues: []Issue{}}
+	err := store.Transaction(func(tx Repository.Store) error {
+		issues, err := findIssues(tx)
+		if err != nil {
+			return err
+		}
+		if apply {
+			for i := range issues {
+				if err := repair(tx, &issues[i]); err != nil {
+					return err
+				}
+			}
+		}
+		report.Issues = issues
+		return nil
+	})
+	if err != nil {
+		return Report{Applied: apply, Issues: []Issue{}}, err
+	}
+	return report, nil
+}
+
+func findIssues(store Repository.Store) ([]Issue, error) {
+	courts, err := store.Courts().List()
+	if err != nil {
+		return nil, err
+	}
+	courtExists := make(map[uint]bool, len(courts))
+	for _, court := range courts {
+		courtExists[court.Court_ID] = true
+	}
+
+	blackouts, err := store.Blackouts().List(nil, "")
+	if err != nil {
+		return nil, err
+	}
+	issues := []Issue{}
+	// blocked maps court and date to the blacked out slots, with every slot set for a whole day
+	type courtDay struct {
+		courtID uint
+		date    string
+	}
+	blocked := make(map[courtDay][]bool)
+	for _, blackout := range blackouts {
+		if !courtExists[blackout.Court_ID] {
+			issues = append(issues, Issue{
+				Kind:        IssueOrphanBlackout,
+				Court_ID:    blackout.Court_ID,
+				Blackout_ID: blackout.Blackout_ID,
+				Detail:      fmt.Sprintf("blackout on %s for court %d, which does not exist", blackout.Blackout_Date, blackout.Court_ID),
+			})
+			continue
+		}
+		key := courtDay{blackout.Court_ID, blackout.Blackout_Date}
+		if blocked[key] == nil {
+			blocked[key] = make([]bool, DataBase.SlotCount)
+		}
+		for i := range blocked[key] {
+			if blackout.Slot_Index == nil || *blackout.Slot_Index == i {
+				blocked[key][i] = true
+			}
+		}
+	}
+
+	bookings, err := store.Bookings().ListActive()
+	if err != nil {
+		return nil, err
+	}
+	for _, booking := range bookings {
+		var blockedDay []bool
+		if booking.Booking_Date != nil {
+			blockedDay = blocked[courtDay{booking.Court_ID, *booking.Booking_Date}]
+		}
+
+		issue := Issue{Court_ID: booking.Court_ID, Booking_ID: booking.Booking_ID}
+		switch {
+		case !courtExists[booking.Court_ID]:
+			issue.Kind = IssueMissingCourt
+			issue.Detail = fmt.Sprintf("booking %d is on court %d, which does not exist", booking.Booking_ID, booking.Court_ID)
+		case !ValidSlot(booking.Booking_Time):
+			issue.Kind = IssueInvalidSlot
+			issue.Detail = fmt.Sprintf("booking %d is for slot %d, outside 0-%d", booking.Booking_ID, booking.Booking_Time, DataBase.SlotCount-1)
+		case blockedDay != nil && blockedDay[booking.Booking_Time]:
+			issue.Kind = IssueBlackedOut
+			issue.Detail = fmt.Sprintf("booking %d holds slot %d on %s, which is blacked out", booking.Booking_ID, booking.Booking_Time, *booking.Booking_Date)
+		default:
+			continue
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+func repair(store Repository.Store, issue *Issue) error {
+	switch issue.Kind {
+	case IssueMissingCourt, IssueInvalidSlot:
+		err := store.Bookings().Transition(issue.Booking_ID, DataBase.BookingCancelledBySystem, issue.Detail)
+		if errors.Is(err, Repository.ErrInvalidTransition) {
+			// Checked in bookings cannot be cancelled; they are left for an admin
+			return nil
+		} else if err != nil {
+			return err
+		}
+	case IssueOrphanBlackout:
+		if err := store.Blackouts().Delete(issue.Blackout_ID); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+	issue.Repaired = true
+	return nil
+}
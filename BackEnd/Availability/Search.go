@@ -0,0 +1,175 @@
+package Availability
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SearchQuery asks for free time on any court of some sports. Times are minutes after midnight.
+type SearchQuery struct {
+	// SportIDs lists the sports to search, the preferred one first; empty searches every sport.
+	SportIDs []uint
+	// From and To bound the Booking_Dates searched, inclusive.
+	From, To string
+	// Days keeps only these days of the week; empty keeps every day.
+	Days []time.Weekday
+	// A match starts at Start or later and ends by End.
+	Start, End int
+	// Duration is how long a match lasts at least; zero asks for a single slot.
+	Duration int
+	Indoor   *bool
+}
+
+// Match is a run of consecutive free slots on one court and date.
+type Match struct {
+	Court DataBase.Court
+	Date  string
+	Slots []int
+	// Start and End are the times the run starts and ends, in minutes after midnight.
+	Start, End int
+}
+
+// Search returns every match for q on the open courts inside their hours, ranked by date, then
+// start time, then the order of q.SportIDs, then court. All occupied slots come from one query,
+// whatever the number of courts and dates.
+func Search(store Repository.Store, q SearchQuery) ([]Match, error) {
+	open := 1
+	courts, _, err := store.Courts().Page(Repository.CourtFilter{SportIDs: q.SportIDs, Status: &open, Indoor: q.Indoor}, Repository.ListQuery{})
+	if err != nil {
+		return nil, err
+	}
+	dates := searchDates(q)
+	if len(courts) == 0 || len(dates) == 0 {
+		return []Match{}, nil
+	}
+
+	courtIDs := make([]uint, len(courts))
+	for i, court := range courts {
+		courtIDs[i] = court.Court_ID
+	}
+	occupied, err := store.Courts().Occupancy(courtIDs, dates[0], dates[len(dates)-1])
+	if err != nil {
+		return nil, err
+	}
+	type courtDay struct {
+		court uint
+		date  string
+	}
+	taken := map[courtDay][]bool{}
+	for _, o := range occupied {
+		key := courtDay{o.Court_ID, o.Date}
+		if taken[key] == nil {
+			taken[key] = make([]bool, DataBase.SlotCount)
+		}
+		if o.Slot == nil {
+			for i := range taken[key] {
+				taken[key][i] = true
+			}
+		} else if ValidSlot(*o.Slot) {
+			taken[key][*o.Slot] = true
+		}
+	}
+
+	times := slotTimes()
+	matches := []Match{}
+	for _, date := range dates {
+		for _, court := range courts {
+			day := taken[courtDay{court.Court_ID, date}]
+			if closed := closedSlots(court.Court_Hours); closed != nil {
+				for i := range day {
+					closed[i] = closed[i] || day[i]
+				}
+				day = closed
+			}
+			for first := range times {
+				if match, ok := freeRun(q, times, day, first); ok {
+					match.Court, match.Date = court, date
+					matches = append(matches, match)
+				}
+			}
+		}
+	}
+
+	preference := map[uint]int{}
+	for i, id := range q.SportIDs {
+		preference[id] = i
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		switch {
+		case a.Date != b.Date:
+			return a.Date < b.Date
+		case a.Start != b.Start:
+			return a.Start < b.Start
+		case preference[a.Court.Sport_id] != preference[b.Court.Sport_id]:
+			return preference[a.Court.Sport_id] < preference[b.Court.Sport_id]
+		}
+		return a.Court.Court_ID < b.Court.Court_ID
+	})
+	return matches, nil
+}
+
+// freeRun returns the shortest run of free, back to back slots starting at slot first that fits
+// q's window and lasts q.Duration, if there is one. taken is nil when nothing is occupied.
+func freeRun(q SearchQuery, times [][2]int, taken []bool, first int) (Match, bool) {
+	if times[first][0] < q.Start {
+		return Match{}, false
+	}
+	match := Match{Start: times[first][0]}
+	for i := first; i < len(times); i++ {
+		if (taken != nil && taken[i]) || times[i][1] > q.End || (i > first && times[i][0] != times[i-1][1]) {
+			return Match{}, false
+		}
+		match.Slots = append(match.Slots, i)
+		match.End = times[i][1]
+		if match.End-match.Start >= q.Duration {
+			return match, true
+		}
+	}
+	return Match{}, false
+}
+
+// searchDates lists the Booking_Dates from q.From to q.To that fall on one of q.Days.
+func searchDates(q SearchQuery) []string {
+	from, err1 := time.Parse(DataBase.BookingDateLayout, q.From)
+	to, err2 := time.Parse(DataBase.BookingDateLayout, q.To)
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+	var dates []string
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if len(q.Days) == 0 || containsDay(q.Days, day.Weekday()) {
+			dates = append(dates, day.Format(DataBase.BookingDateLayout))
+		}
+	}
+	return dates
+}
+
+func containsDay(days []time.Weekday, day time.Weekday) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// slotTimes returns when each slot of the day starts and ends, in minutes after midnight, read
+// from labels such as "08:00 - 09:00".
+func slotTimes() [][2]int {
+	times := make([][2]int, DataBase.SlotCount)
+	for i, label := range DataBase.SlotLabels {
+		var startHour, startMinute, endHour, endMinute int
+		fmt.Sscanf(label, "%d:%d - %d:%d", &startHour, &startMinute, &endHour, &endMinute)
+		times[i] = [2]int{startHour*60 + startMinute, endHour*60 + endMinute}
+	}
+	return times
+}
+
+// FormatMinutes formats minutes after midnight as a time such as 17:00.
+func FormatMinutes(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
@@ -0,0 +1,247 @@
+package Backup
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"BackEnd/Repository/TestStore"
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// seedSource fills a store with a little of everything a bundle carries.
+func seedSource(t *testing.T) Repository.Store {
+	t.Helper()
+	store := TestStore.Open(t)
+	capacity, slot := 4, 3
+	date := "2026-05-01"
+	sports := []DataBase.Sport{{Sport_name: "Tennis", Sport_Description: "Singles, doubles"}, {Sport_name: "Squash"}}
+	for i := range sports {
+		if err := store.Sports().Create(&sports[i]); err != nil {
+			t.Fatalf("failed to create sport: %v", err)
+		}
+	}
+	courts := []DataBase.Court{
+		{Court_Name: "Court A", Court_Location: "Downtown", Court_Capacity: &capacity, Court_Status: 1, Sport_id: sports[0].Sport_ID},
+		{Court_Name: "Court B", Court_Location: "Uptown, east wing", Court_Status: 0, Sport_id: sports[1].Sport_ID},
+	}
+	for i := range courts {
+		if err := store.Courts().Create(&courts[i]); err != nil {
+			t.Fatalf("failed to create court: %v", err)
+		}
+	}
+	blackouts := []DataBase.Court_Blackout{
+		{Court_ID: courts[0].Court_ID, Blackout_Date: date, Slot_Index: &slot, Reason: "Resurfacing"},
+		{Court_ID: courts[1].Court_ID, Blackout_Date: date},
+	}
+	for i := range blackouts {
+		if err := store.Blackouts().Create(&blackouts[i]); err != nil {
+			t.Fatalf("failed to create blackout: %v", err)
+		}
+	}
+	customers := []DataBase.Customer{
+		{Name: "John Doe", UFID: "1234", Contact: "555", Email: "john@example.com"},
+		{Name: "Jane \"JJ\" Roe", Email: "jane@example.com"},
+	}
+	for i := range customers {
+		if err := store.Customers().Create(&customers[i]); err != nil {
+			t.Fatalf("failed to create customer: %v", err)
+		}
+	}
+	bookings := []DataBase.Bookings{
+		{Customer_ID: customers[0].Customer_ID, Sport_ID: sports[0].Sport_ID, Court_ID: courts[0].Court_ID,
+			Booking_Status: DataBase.BookingConfirmed, Booking_Time: 2, Booking_Date: &date},
+		{Customer_ID: customers[1].Customer_ID, Sport_ID: sports[0].Sport_ID, Court_ID: courts[0].Court_ID,
+			Booking_Status: DataBase.BookingCancelledByUser, Booking_Time: 2, Booking_Date: &date},
+		{Customer_ID: customers[1].Customer_ID, Sport_ID: sports[1].Sport_ID, Court_ID: courts[1].Court_ID,
+			Booking_Status: DataBase.BookingConfirmed, Booking_Time: 5},
+	}
+	for i := range bookings {
+		if err := store.Bookings().Create(&bookings[i]); err != nil {
+			t.Fatalf("failed to create booking: %v", err)
+		}
+	}
+	return store
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	bundle, err := Export(seedSource(t))
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if len(bundle.Sports) != 2 || len(bundle.Courts) != 2 || len(bundle.Blackouts) != 2 ||
+		len(bundle.Customers) != 2 || len(bundle.Bookings) != 3 {
+		t.Fatalf("expected every record to be exported, got %+v", bundle)
+	}
+
+	for _, format := range []string{FormatJSON, FormatZIP} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, bundle, format); err != nil {
+				t.Fatalf("Encode failed: %v", err)
+			}
+			decoded, err := Decode(buf.Bytes())
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+			if !decoded.Exported_At.Equal(bundle.Exported_At) {
+				t.Errorf("expected exported_at %v, got %v", bundle.Exported_At, decoded.Exported_At)
+			}
+			decoded.Exported_At = bundle.Exported_At
+			if !reflect.DeepEqual(decoded, bundle) {
+				t.Errorf("bundle changed in a round trip:\nwant %+v\ngot  %+v", bundle, decoded)
+			}
+		})
+	}
+
+	if err := Encode(&bytes.Buffer{}, bundle, "xml"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+	if _, err := Decode([]byte("not a bundle")); err == nil {
+		t.Error("expected an error decoding garbage")
+	}
+}
+
+func TestImport(t *testing.T) {
+	bundle, err := Export(seedSource(t))
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	// The target already has Tennis, under another ID, and John
+	target := TestStore.Open(t)
+	for _, name := range []string{"Badminton", "Tennis"} {
+		if err := target.Sports().Create(&DataBase.Sport{Sport_name: name}); err != nil {
+			t.Fatalf("failed to create sport: %v", err)
+		}
+	}
+	if err := target.Customers().Create(&DataBase.Customer{Name: "John", Email: "JOHN@example.com"}); err != nil {
+		t.Fatalf("failed to create customer: %v", err)
+	}
+
+	report, err := Import(target, bundle, true)
+	if err != nil {
+		t.Fatalf("dry run failed: %v (%+v)", err, report)
+	}
+	if !report.Dry_Run || report.Sports.Matched != 1 || report.Sports.Created != 1 || report.Courts.Created != 2 ||
+		report.Customers.Matched != 1 || report.Bookings.Created != 3 {
+		t.Errorf("unexpected dry run report: %+v", report)
+	}
+	if courts, _ := target.Courts().List(); len(courts) != 0 {
+		t.Fatalf("expected a dry run to write nothing, got %d courts", len(courts))
+	}
+
+	report, err = Import(target, bundle, false)
+	if err != nil {
+		t.Fatalf("import failed: %v (%+v)", err, report)
+	}
+	if report.Sports.Remapped != 1 || report.Courts.Remapped != 0 || report.Bookings.Created != 3 || len(report.Skipped) != 0 {
+		t.Errorf("unexpected import report: %+v", report)
+	}
+
+	// References follow the records to their new IDs
+	tennis, _ := target.Sports().FindByName("Tennis")
+	courtA, err := target.Courts().FindByName("Court A")
+	if err != nil || courtA.Sport_id != tennis.Sport_ID || courtA.Court_Capacity == nil || *courtA.Court_Capacity != 4 {
+		t.Errorf("expected Court A under the existing Tennis, got %+v (error: %v)", courtA, err)
+	}
+	john, _ := target.Customers().FindByEmail("john@example.com")
+	bookings, _ := target.Bookings().ListByCustomer(john.Customer_ID)
+	if len(bookings) != 1 || bookings[0].Court_ID != courtA.Court_ID || bookings[0].Sport_ID != tennis.Sport_ID {
+		t.Errorf("expected John's booking on the imported Court A, got %+v", bookings)
+	}
+	if blackouts, _ := target.Blackouts().List([]uint{courtA.Court_ID}, ""); len(blackouts) != 1 || blackouts[0].Reason != "Resurfacing" {
+		t.Errorf("expected Court A's blackout, got %+v", blackouts)
+	}
+
+	// Importing the same bundle again only matches
+	report, err = Import(target, bundle, false)
+	if err != nil {
+		t.Fatalf("second import failed: %v", err)
+	}
+	for name, table := range map[string]TableReport{"sports": report.Sports, "courts": report.Courts,
+		"blackouts": report.Blackouts, "customers": report.Customers, "bookings": report.Bookings} {
+		if table.Created != 0 || table.Skipped != 0 {
+			t.Errorf("expected every %s to be matched on a second import, got %+v", name, table)
+		}
+	}
+}
+
+func TestImport_SkipsTakenSlots(t *testing.T) {
+	bundle, err := Export(seedSource(t))
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	bundle.Bookings = bundle.Bookings[:1]
+
+	target := TestStore.Open(t)
+	if _, err := Import(target, Bundle{Format_Version: FormatVersion, Sports: bundle.Sports, Courts: bundle.Courts}, false); err != nil {
+		t.Fatalf("failed to import courts: %v", err)
+	}
+	court, _ := target.Courts().FindByName("Court A")
+	booking := bundle.Bookings[0]
+	if err := target.Bookings().Create(&DataBase.Bookings{Customer_ID: 99, Sport_ID: court.Sport_id, Court_ID: court.Court_ID,
+		Booking_Status: DataBase.BookingConfirmed, Booking_Time: booking.Slot, Booking_Date: booking.Date}); err != nil {
+		t.Fatalf("failed to create booking: %v", err)
+	}
+
+	report, err := Import(target, bundle, false)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if report.Bookings.Skipped != 1 || len(report.Skipped) != 1 {
+		t.Errorf("expected the clashing booking to be skipped, got %+v", report)
+	}
+}
+
+func TestImport_Validation(t *testing.T) {
+	valid, err := Export(seedSource(t))
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		breaks func(b *Bundle)
+	}{
+		{"Unknown version", func(b *Bundle) { b.Format_Version = 2 }},
+		{"Repeated sport name", func(b *Bundle) { b.Sports[1].Name = b.Sports[0].Name }},
+		{"Court of a missing sport", func(b *Bundle) { b.Courts[0].Sport_ID = 99 }},
+		{"Blackout date", func(b *Bundle) { b.Blackouts[0].Date = "May 1st" }},
+		{"Repeated email", func(b *Bundle) { b.Customers[1].Email = "John@Example.com" }},
+		{"Booking status", func(b *Bundle) { b.Bookings[0].Status = "Confirmed" }},
+		{"Booking slot", func(b *Bundle) { b.Bookings[0].Slot = DataBase.SlotCount }},
+		{"Booking of a missing customer", func(b *Bundle) { b.Bookings[0].Customer_ID = 99 }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			Encode(&buf, valid, FormatJSON)
+			bundle, _ := Decode(buf.Bytes())
+			tc.breaks(&bundle)
+
+			target := TestStore.Open(t)
+			report, err := Import(target, bundle, false)
+			if !errors.Is(err, ErrInvalidBundle) || len(report.Problems) != 1 {
+				t.Errorf("expected one problem, got %v: %+v", err, report.Problems)
+			}
+			if sports, _ := target.Sports().List(); len(sports) != 0 {
+				t.Errorf("expected an invalid bundle to write nothing, got %d sports", len(sports))
+			}
+		})
+	}
+
+	// A conflict with existing data is only found while importing, and rolls everything back
+	target := TestStore.Open(t)
+	if err := target.Courts().Create(&DataBase.Court{Court_Name: "Court B", Court_Location: "Elsewhere", Sport_id: 42}); err != nil {
+		t.Fatalf("failed to create court: %v", err)
+	}
+	report, err := Import(target, valid, false)
+	if !errors.Is(err, ErrInvalidBundle) || len(report.Problems) != 1 {
+		t.Errorf("expected a court conflict, got %v: %+v", err, report.Problems)
+	}
+	if sports, _ := target.Sports().List(); len(sports) != 0 {
+		t.Errorf("expected the import to be rolled back, got %d sports", len(sports))
+	}
+}
@@ -0,0 +1,156 @@
+// Package Backup exports the application's data to a portable bundle and imports it again,
+// so data can move between databases without raw SQL. A bundle is either one JSON document or
+// a ZIP holding a manifest and one CSV file per table; both carry the same records.
+package Backup
+
+import (
+	"BackEnd/Repository"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// FormatVersion is the bundle format written by Export. Import rejects other versions.
+const FormatVersion = 1
+
+// Formats accepted by Encode.
+const (
+	FormatJSON = "json"
+	FormatZIP  = "zip"
+)
+
+// ErrUnsupportedFormat is returned by Encode for a format other than FormatJSON or FormatZIP.
+var ErrUnsupportedFormat = errors.New("unsupported bundle format")
+
+// Bundle is a snapshot of sports, courts, their blackouts (the court schedules), customers and
+// bookings. IDs are those of the exporting database and only link records within the bundle.
+// Soft deleted and archived records are not included.
+type Bundle struct {
+	Format_Version int        `json:"format_version"`
+	Exported_At    time.Time  `json:"exported_at"`
+	Sports         []Sport    `json:"sports"`
+	Courts         []Court    `json:"courts"`
+	Blackouts      []Blackout `json:"blackouts"`
+	Customers      []Customer `json:"customers"`
+	Bookings       []Booking  `json:"bookings"`
+}
+
+type Sport struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type Court struct {
+	ID       uint   `json:"id"`
+	Sport_ID uint   `json:"sport_id"`
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	Capacity *int   `json:"capacity"`
+	Status   int    `json:"status"`
+}
+
+type Blackout struct {
+	ID       uint   `json:"id"`
+	Court_ID uint   `json:"court_id"`
+	Date     string `json:"date"`
+	Slot     *int   `json:"slot"`
+	Reason   string `json:"reason"`
+}
+
+type Customer struct {
+	ID      uint   `json:"id"`
+	Name    string `json:"name"`
+	UFID    string `json:"ufid"`
+	Contact string `json:"contact"`
+	Email   string `json:"email"`
+}
+
+type Booking struct {
+	ID          uint    `json:"id"`
+	Customer_ID uint    `json:"customer_id"`
+	Sport_ID    uint    `json:"sport_id"`
+	Court_ID    uint    `json:"court_id"`
+	Status      string  `json:"status"`
+	Slot        int     `json:"slot"`
+	Date        *string `json:"date"`
+}
+
+// Export reads every live record from store into a bundle.
+func Export(store Repository.Store) (Bundle, error) {
+	bundle := Bundle{Format_Version: FormatVersion, Exported_At: time.Now().UTC()}
+
+	sports, err := store.Sports().List()
+	if err != nil {
+		return bundle, err
+	}
+	for _, s := range sports {
+		bundle.Sports = append(bundle.Sports, Sport{ID: s.Sport_ID, Name: s.Sport_name, Description: s.Sport_Description})
+	}
+
+	courts, err := store.Courts().List()
+	if err != nil {
+		return bundle, err
+	}
+	for _, c := range courts {
+		bundle.Courts = append(bundle.Courts, Court{ID: c.Court_ID, Sport_ID: c.Sport_id, Name: c.Court_Name,
+			Location: c.Court_Location, Capacity: c.Court_Capacity, Status: c.Court_Status})
+	}
+
+	blackouts, err := store.Blackouts().List(nil, "")
+	if err != nil {
+		return bundle, err
+	}
+	for _, b := range blackouts {
+		bundle.Blackouts = append(bundle.Blackouts, Blackout{ID: b.Blackout_ID, Court_ID: b.Court_ID,
+			Date: b.Blackout_Date, Slot: b.Slot_Index, Reason: b.Reason})
+	}
+
+	customers, err := store.Customers().List()
+	if err != nil {
+		return bundle, err
+	}
+	for _, c := range customers {
+		bundle.Customers = append(bundle.Customers, Customer{ID: c.Customer_ID, Name: c.Name, UFID: c.UFID,
+			Contact: c.Contact, Email: c.Email})
+	}
+
+	bookings, err := store.Bookings().List()
+	if err != nil {
+		return bundle, err
+	}
+	for _, b := range bookings {
+		bundle.Bookings = append(bundle.Bookings, Booking{ID: b.Booking_ID, Customer_ID: b.Customer_ID,
+			Sport_ID: b.Sport_ID, Court_ID: b.Court_ID, Status: string(b.Booking_Status), Slot: b.Booking_Time,
+			Date: b.Booking_Date})
+	}
+	return bundle, nil
+}
+
+// Encode writes bundle to w as FormatJSON or FormatZIP.
+func Encode(w io.Writer, bundle Bundle, format string) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(bundle)
+	case FormatZIP:
+		return encodeZIP(w, bundle)
+	}
+	return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+// Decode reads a bundle in either format, telling them apart by the ZIP file signature.
+func Decode(data []byte) (Bundle, error) {
+	var bundle Bundle
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return decodeZIP(data)
+	}
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return bundle, fmt.Errorf("bundle is neither a ZIP nor valid JSON: %w", err)
+	}
+	return bundle, nil
+}
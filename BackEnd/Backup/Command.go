@@ -0,0 +1,79 @@
+package Backup
+
+import (
+	"BackEnd/Repository"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Usage describes the export and import subcommands.
+const Usage = `usage: export [-format json|zip] [-o file]
+       import [-dry-run] file
+
+export writes every sport, court, blackout, customer and booking to a bundle, on standard
+output unless -o is given. import adds a bundle's records to the database and prints a report;
+with -dry-run nothing is written.`
+
+// Run executes the export or import subcommand of the server binary, writing the bundle or
+// the import report to out.
+func Run(store Repository.Store, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", Usage)
+	}
+
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	switch args[0] {
+	case "export":
+		format := flags.String("format", FormatJSON, "bundle format")
+		path := flags.String("o", "", "output file")
+		if err := flags.Parse(args[1:]); err != nil {
+			return fmt.Errorf("%v\n%s", err, Usage)
+		}
+		bundle, err := Export(store)
+		if err != nil {
+			return err
+		}
+		if *path == "" {
+			return Encode(out, bundle, *format)
+		}
+		file, err := os.Create(*path)
+		if err != nil {
+			return err
+		}
+		if err := Encode(file, bundle, *format); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+
+	case "import":
+		dryRun := flags.Bool("dry-run", false, "report without writing")
+		if err := flags.Parse(args[1:]); err != nil {
+			return fmt.Errorf("%v\n%s", err, Usage)
+		}
+		if flags.NArg() != 1 {
+			return fmt.Errorf("import expects one bundle file\n%s", Usage)
+		}
+		data, err := os.ReadFile(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		bundle, err := Decode(data)
+		if err != nil {
+			return err
+		}
+		report, err := Import(store, bundle, *dryRun)
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if encodeErr := encoder.Encode(report); encodeErr != nil && err == nil {
+			err = encodeErr
+		}
+		return err
+	}
+
+	return fmt.Errorf("unknown command %q\n%s", args[0], Usage)
+}
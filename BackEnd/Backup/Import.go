@@ -0,0 +1,334 @@
+package Backup
+
+import (
+	"BackEnd/Availability"
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidBundle is returned by Import when the bundle fails validation or conflicts with
+// existing data. Nothing is written and the report's Problems say what is wrong.
+var ErrInvalidBundle = errors.New("invalid bundle")
+
+// errDryRun rolls back a dry run once its report is complete.
+var errDryRun = errors.New("dry run")
+
+// Report describes what Import did, or would do in a dry run.
+type Report struct {
+	Dry_Run   bool        `json:"dry_run"`
+	Sports    TableReport `json:"sports"`
+	Courts    TableReport `json:"courts"`
+	Blackouts TableReport `json:"blackouts"`
+	Customers TableReport `json:"customers"`
+	Bookings  TableReport `json:"bookings"`
+	// Problems are validation errors and conflicts with existing data; any problem stops the
+	// whole import.
+	Problems []string `json:"problems"`
+	// Skipped explains each record counted as skipped.
+	Skipped []string `json:"skipped"`
+}
+
+// TableReport counts the records of one table. Created records were inserted, and Remapped
+// counts those that got an ID other than the one in the bundle. Matched records already
+// existed and were reused; Skipped ones could not be imported and were left out.
+type TableReport struct {
+	Created  int `json:"created"`
+	Remapped int `json:"remapped"`
+	Matched  int `json:"matched"`
+	Skipped  int `json:"skipped"`
+}
+
+// created counts a new record that was stored under id instead of bundleID.
+func (t *TableReport) created(bundleID, id uint) {
+	t.Created++
+	if id != bundleID {
+		t.Remapped++
+	}
+}
+
+// Import validates bundle and adds its records to store in one transaction. Records are
+// matched to existing ones by their natural key (sport and court name, customer email, and
+// the booking's customer, court, date and slot) and references are remapped to the IDs the
+// records end up with, so a bundle can be imported into a database that already has data.
+// With dryRun the import runs and is rolled back, so the report shows exactly what would
+// happen.
+func Import(store Repository.Store, bundle Bundle, dryRun bool) (Report, error) {
+	report := Report{Dry_Run: dryRun, Problems: validate(bundle), Skipped: []string{}}
+	if len(report.Problems) > 0 {
+		return report, ErrInvalidBundle
+	}
+
+	err := store.Transaction(func(tx Repository.Store) error {
+		imp := importer{tx: tx, report: &report}
+		if err := imp.run(bundle); err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+	return report, err
+}
+
+// validate checks the bundle on its own: required fields, values in range, and that every
+// reference points at a record in the bundle.
+func validate(bundle Bundle) []string {
+	problems := []string{}
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	if bundle.Format_Version != FormatVersion {
+		add("format_version %d is not supported, expected %d", bundle.Format_Version, FormatVersion)
+		return problems
+	}
+
+	sports, names := map[uint]bool{}, map[string]bool{}
+	for _, s := range bundle.Sports {
+		switch {
+		case s.ID == 0 || sports[s.ID]:
+			add("sport %q: id %d is missing or repeated", s.Name, s.ID)
+		case strings.TrimSpace(s.Name) == "" || names[s.Name]:
+			add("sport %d: name %q is empty or repeated", s.ID, s.Name)
+		}
+		sports[s.ID], names[s.Name] = true, true
+	}
+
+	courts, names := map[uint]bool{}, map[string]bool{}
+	for _, c := range bundle.Courts {
+		switch {
+		case c.ID == 0 || courts[c.ID]:
+			add("court %q: id %d is missing or repeated", c.Name, c.ID)
+		case strings.TrimSpace(c.Name) == "" || names[c.Name]:
+			add("court %d: name %q is empty or repeated", c.ID, c.Name)
+		case strings.TrimSpace(c.Location) == "":
+			add("court %d: location is empty", c.ID)
+		case !sports[c.Sport_ID]:
+			add("court %d: sport %d is not in the bundle", c.ID, c.Sport_ID)
+		}
+		courts[c.ID], names[c.Name] = true, true
+	}
+
+	for _, b := range bundle.Blackouts {
+		switch {
+		case !courts[b.Court_ID]:
+			add("blackout %d: court %d is not in the bundle", b.ID, b.Court_ID)
+		case !validDate(b.Date):
+			add("blackout %d: date %q is not YYYY-MM-DD", b.ID, b.Date)
+		case b.Slot != nil && !Availability.ValidSlot(*b.Slot):
+			add("blackout %d: slot %d is outside the court's day", b.ID, *b.Slot)
+		}
+	}
+
+	customers, emails := map[uint]bool{}, map[string]bool{}
+	for _, c := range bundle.Customers {
+		email := strings.ToLower(strings.TrimSpace(c.Email))
+		switch {
+		case c.ID == 0 || customers[c.ID]:
+			add("customer %q: id %d is missing or repeated", c.Email, c.ID)
+		case email == "" || emails[email]:
+			add("customer %d: email %q is empty or repeated", c.ID, c.Email)
+		}
+		customers[c.ID], emails[email] = true, true
+	}
+
+	bookings := map[uint]bool{}
+	for _, b := range bundle.Bookings {
+		switch {
+		case b.ID == 0 || bookings[b.ID]:
+			add("booking id %d is missing or repeated", b.ID)
+		case !customers[b.Customer_ID]:
+			add("booking %d: customer %d is not in the bundle", b.ID, b.Customer_ID)
+		case !sports[b.Sport_ID]:
+			add("booking %d: sport %d is not in the bundle", b.ID, b.Sport_ID)
+		case !courts[b.Court_ID]:
+			add("booking %d: court %d is not in the bundle", b.ID, b.Court_ID)
+		case !DataBase.BookingStatus(b.Status).Valid():
+			add("booking %d: status %q is not a booking status", b.ID, b.Status)
+		case !Availability.ValidSlot(b.Slot):
+			add("booking %d: slot %d is outside the court's day", b.ID, b.Slot)
+		case b.Date != nil && !validDate(*b.Date):
+			add("booking %d: date %q is not YYYY-MM-DD", b.ID, *b.Date)
+		}
+		bookings[b.ID] = true
+	}
+	return problems
+}
+
+func validDate(date string) bool {
+	_, err := time.Parse(DataBase.BookingDateLayout, date)
+	return err == nil
+}
+
+// importer writes a validated bundle, keeping the bundle ID to stored ID maps per table.
+type importer struct {
+	tx        Repository.Store
+	report    *Report
+	sports    map[uint]uint
+	courts    map[uint]uint
+	customers map[uint]uint
+}
+
+// problem records a conflict with existing data that makes the bundle unimportable.
+func (imp *importer) problem(format string, args ...interface{}) error {
+	imp.report.Problems = append(imp.report.Problems, fmt.Sprintf(format, args...))
+	return ErrInvalidBundle
+}
+
+func (imp *importer) skip(table *TableReport, format string, args ...interface{}) {
+	table.Skipped++
+	imp.report.Skipped = append(imp.report.Skipped, fmt.Sprintf(format, args...))
+}
+
+func (imp *importer) run(bundle Bundle) error {
+	imp.sports, imp.courts, imp.customers = map[uint]uint{}, map[uint]uint{}, map[uint]uint{}
+	steps := []func(Bundle) error{imp.importSports, imp.importCourts, imp.importBlackouts, imp.importCustomers, imp.importBookings}
+	for _, step := range steps {
+		if err := step(bundle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (imp *importer) importSports(bundle Bundle) error {
+	for _, s := range bundle.Sports {
+		if existing, err := imp.tx.Sports().FindByName(s.Name); err == nil {
+			imp.sports[s.ID] = existing.Sport_ID
+			imp.report.Sports.Matched++
+			continue
+		} else if !errors.Is(err, Repository.ErrNotFound) {
+			return err
+		}
+		sport := DataBase.Sport{Sport_name: s.Name, Sport_Description: s.Description}
+		if err := imp.tx.Sports().Create(&sport); errors.Is(err, Repository.ErrDuplicate) {
+			return imp.problem("sport %q was created by someone else during the import; try again", s.Name)
+		} else if err != nil {
+			return err
+		}
+		imp.sports[s.ID] = sport.Sport_ID
+		imp.report.Sports.created(s.ID, sport.Sport_ID)
+	}
+	return nil
+}
+
+func (imp *importer) importCourts(bundle Bundle) error {
+	for _, c := range bundle.Courts {
+		if existing, err := imp.tx.Courts().FindByName(c.Name); err == nil {
+			if existing.Sport_id != imp.sports[c.Sport_ID] {
+				return imp.problem("court %q already exists for a different sport", c.Name)
+			}
+			imp.courts[c.ID] = existing.Court_ID
+			imp.report.Courts.Matched++
+			continue
+		} else if !errors.Is(err, Repository.ErrNotFound) {
+			return err
+		}
+		court := DataBase.Court{Court_Name: c.Name, Court_Location: c.Location, Court_Capacity: c.Capacity,
+			Court_Status: c.Status, Sport_id: imp.sports[c.Sport_ID]}
+		if err := imp.tx.Courts().Create(&court); errors.Is(err, Repository.ErrDuplicate) {
+			return imp.problem("court %q was created by someone else during the import; try again", c.Name)
+		} else if err != nil {
+			return err
+		}
+		imp.courts[c.ID] = court.Court_ID
+		imp.report.Courts.created(c.ID, court.Court_ID)
+	}
+	return nil
+}
+
+func (imp *importer) importBlackouts(bundle Bundle) error {
+	for _, b := range bundle.Blackouts {
+		courtID := imp.courts[b.Court_ID]
+		existing, err := imp.tx.Blackouts().List([]uint{courtID}, b.Date)
+		if err != nil {
+			return err
+		}
+		if containsBlackout(existing, b.Slot) {
+			imp.report.Blackouts.Matched++
+			continue
+		}
+		blackout := DataBase.Court_Blackout{Court_ID: courtID, Blackout_Date: b.Date, Slot_Index: b.Slot, Reason: b.Reason}
+		if err := imp.tx.Blackouts().Create(&blackout); err != nil {
+			return err
+		}
+		imp.report.Blackouts.created(b.ID, blackout.Blackout_ID)
+	}
+	return nil
+}
+
+func containsBlackout(blackouts []DataBase.Court_Blackout, slot *int) bool {
+	for _, b := range blackouts {
+		if (b.Slot_Index == nil && slot == nil) || (b.Slot_Index != nil && slot != nil && *b.Slot_Index == *slot) {
+			return true
+		}
+	}
+	return false
+}
+
+func (imp *importer) importCustomers(bundle Bundle) error {
+	for _, c := range bundle.Customers {
+		if existing, err := imp.tx.Customers().FindByEmail(c.Email); err == nil {
+			imp.customers[c.ID] = existing.Customer_ID
+			imp.report.Customers.Matched++
+			continue
+		} else if !errors.Is(err, Repository.ErrNotFound) {
+			return err
+		}
+		customer := DataBase.Customer{Name: c.Name, UFID: c.UFID, Contact: c.Contact, Email: c.Email}
+		if err := imp.tx.Customers().Create(&customer); err != nil {
+			return err
+		}
+		imp.customers[c.ID] = customer.Customer_ID
+		imp.report.Customers.created(c.ID, customer.Customer_ID)
+	}
+	return nil
+}
+
+func (imp *importer) importBookings(bundle Bundle) error {
+	for _, b := range bundle.Bookings {
+		booking := DataBase.Bookings{
+			Customer_ID:    imp.customers[b.Customer_ID],
+			Sport_ID:       imp.sports[b.Sport_ID],
+			Court_ID:       imp.courts[b.Court_ID],
+			Booking_Status: DataBase.BookingStatus(b.Status),
+			Booking_Time:   b.Slot,
+			Booking_Date:   b.Date,
+		}
+		existing, err := imp.tx.Bookings().ListByCustomer(booking.Customer_ID)
+		if err != nil {
+			return err
+		}
+		if containsBooking(existing, booking) {
+			imp.report.Bookings.Matched++
+			continue
+		}
+		if err := imp.tx.Bookings().Create(&booking); errors.Is(err, Repository.ErrDuplicate) {
+			imp.skip(&imp.report.Bookings, "booking %d: its slot is already taken by another active booking", b.ID)
+			continue
+		} else if err != nil {
+			return err
+		}
+		imp.report.Bookings.created(b.ID, booking.Booking_ID)
+	}
+	return nil
+}
+
+// containsBooking reports whether bookings has one for the same court, date, slot and status as b.
+func containsBooking(bookings []DataBase.Bookings, b DataBase.Bookings) bool {
+	for _, other := range bookings {
+		sameDate := (other.Booking_Date == nil && b.Booking_Date == nil) ||
+			(other.Booking_Date != nil && b.Booking_Date != nil && *other.Booking_Date == *b.Booking_Date)
+		if sameDate && other.Court_ID == b.Court_ID && other.Booking_Time == b.Booking_Time && other.Booking_Status == b.Booking_Status {
+			return true
+		}
+	}
+	return false
+}
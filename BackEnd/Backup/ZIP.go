@@ -0,0 +1,263 @@
+package Backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const manifestFile = "manifest.json"
+
+// manifest is the part of a bundle that is not a table.
+type manifest struct {
+	Format_Version int       `json:"format_version"`
+	Exported_At    time.Time `json:"exported_at"`
+}
+
+// table maps one record type to a CSV file. The columns match the record's JSON names.
+type table[T any] struct {
+	file    string
+	columns []string
+	toRow   func(T) []string
+	fromRow func(*row) T
+}
+
+var (
+	sportTable = table[Sport]{
+		file:    "sports.csv",
+		columns: []string{"id", "name", "description"},
+		toRow: func(s Sport) []string {
+			return []string{formatID(s.ID), s.Name, s.Description}
+		},
+		fromRow: func(r *row) Sport {
+			return Sport{ID: r.id("id"), Name: r.get("name"), Description: r.get("description")}
+		},
+	}
+	courtTable = table[Court]{
+		file:    "courts.csv",
+		columns: []string{"id", "sport_id", "name", "location", "capacity", "status"},
+		toRow: func(c Court) []string {
+			return []string{formatID(c.ID), formatID(c.Sport_ID), c.Name, c.Location, formatOptionalInt(c.Capacity), strconv.Itoa(c.Status)}
+		},
+		fromRow: func(r *row) Court {
+			return Court{ID: r.id("id"), Sport_ID: r.id("sport_id"), Name: r.get("name"), Location: r.get("location"),
+				Capacity: r.optionalInt("capacity"), Status: r.int("status")}
+		},
+	}
+	blackoutTable = table[Blackout]{
+		file:    "blackouts.csv",
+		columns: []string{"id", "court_id", "date", "slot", "reason"},
+		toRow: func(b Blackout) []string {
+			return []string{formatID(b.ID), formatID(b.Court_ID), b.Date, formatOptionalInt(b.Slot), b.Reason}
+		},
+		fromRow: func(r *row) Blackout {
+			return Blackout{ID: r.id("id"), Court_ID: r.id("court_id"), Date: r.get("date"), Slot: r.optionalInt("slot"),
+				Reason: r.get("reason")}
+		},
+	}
+	customerTable = table[Customer]{
+		file:    "customers.csv",
+		columns: []string{"id", "name", "ufid", "contact", "email"},
+		toRow: func(c Customer) []string {
+			return []string{formatID(c.ID), c.Name, c.UFID, c.Contact, c.Email}
+		},
+		fromRow: func(r *row) Customer {
+			return Customer{ID: r.id("id"), Name: r.get("name"), UFID: r.get("ufid"), Contact: r.get("contact"),
+				Email: r.get("email")}
+		},
+	}
+	bookingTable = table[Booking]{
+		file:    "bookings.csv",
+		columns: []string{"id", "customer_id", "sport_id", "court_id", "status", "slot", "date"},
+		toRow: func(b Booking) []string {
+			date := ""
+			if b.Date != nil {
+				date = *b.Date
+			}
+			return []string{formatID(b.ID), formatID(b.Customer_ID), formatID(b.Sport_ID), formatID(b.Court_ID),
+				b.Status, strconv.Itoa(b.Slot), date}
+		},
+		fromRow: func(r *row) Booking {
+			booking := Booking{ID: r.id("id"), Customer_ID: r.id("customer_id"), Sport_ID: r.id("sport_id"),
+				Court_ID: r.id("court_id"), Status: r.get("status"), Slot: r.int("slot")}
+			if date := r.get("date"); date != "" {
+				booking.Date = &date
+			}
+			return booking
+		},
+	}
+)
+
+func encodeZIP(w io.Writer, bundle Bundle) error {
+	archive := zip.NewWriter(w)
+
+	file, err := archive.Create(manifestFile)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(file).Encode(manifest{bundle.Format_Version, bundle.Exported_At}); err != nil {
+		return err
+	}
+
+	if err := writeTable(archive, sportTable, bundle.Sports); err != nil {
+		return err
+	}
+	if err := writeTable(archive, courtTable, bundle.Courts); err != nil {
+		return err
+	}
+	if err := writeTable(archive, blackoutTable, bundle.Blackouts); err != nil {
+		return err
+	}
+	if err := writeTable(archive, customerTable, bundle.Customers); err != nil {
+		return err
+	}
+	if err := writeTable(archive, bookingTable, bundle.Bookings); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+func decodeZIP(data []byte) (Bundle, error) {
+	var bundle Bundle
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return bundle, fmt.Errorf("invalid ZIP bundle: %w", err)
+	}
+
+	file, err := archive.Open(manifestFile)
+	if err != nil {
+		return bundle, fmt.Errorf("ZIP bundle has no %s", manifestFile)
+	}
+	var m manifest
+	err = json.NewDecoder(file).Decode(&m)
+	file.Close()
+	if err != nil {
+		return bundle, fmt.Errorf("invalid %s: %w", manifestFile, err)
+	}
+	bundle.Format_Version, bundle.Exported_At = m.Format_Version, m.Exported_At
+
+	if bundle.Sports, err = readTable(archive, sportTable); err != nil {
+		return bundle, err
+	}
+	if bundle.Courts, err = readTable(archive, courtTable); err != nil {
+		return bundle, err
+	}
+	if bundle.Blackouts, err = readTable(archive, blackoutTable); err != nil {
+		return bundle, err
+	}
+	if bundle.Customers, err = readTable(archive, customerTable); err != nil {
+		return bundle, err
+	}
+	if bundle.Bookings, err = readTable(archive, bookingTable); err != nil {
+		return bundle, err
+	}
+	return bundle, nil
+}
+
+func writeTable[T any](archive *zip.Writer, t table[T], records []T) error {
+	file, err := archive.Create(t.file)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(file)
+	if err := w.Write(t.columns); err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := w.Write(t.toRow(record)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// readTable reads t's CSV file. A missing file is an empty table; columns are matched by the
+// header, so their order does not matter and unknown columns are ignored.
+func readTable[T any](archive *zip.Reader, t table[T]) ([]T, error) {
+	file, err := archive.Open(t.file)
+	if err != nil {
+		return nil, nil
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.file, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	index := map[string]int{}
+	for i, column := range records[0] {
+		index[column] = i
+	}
+	for _, column := range t.columns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("%s: missing column %q", t.file, column)
+		}
+	}
+
+	var result []T
+	for line, values := range records[1:] {
+		r := row{index: index, values: values}
+		record := t.fromRow(&r)
+		if r.err != nil {
+			// Line 1 is the header
+			return nil, fmt.Errorf("%s line %d: %w", t.file, line+2, r.err)
+		}
+		result = append(result, record)
+	}
+	return result, nil
+}
+
+// row reads the fields of one CSV record by column name, keeping the first parse error.
+type row struct {
+	index  map[string]int
+	values []string
+	err    error
+}
+
+func (r *row) get(column string) string {
+	return r.values[r.index[column]]
+}
+
+func (r *row) int(column string) int {
+	n, err := strconv.Atoi(r.get(column))
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("%s must be a number", column)
+	}
+	return n
+}
+
+func (r *row) optionalInt(column string) *int {
+	if r.get(column) == "" {
+		return nil
+	}
+	n := r.int(column)
+	return &n
+}
+
+func (r *row) id(column string) uint {
+	n, err := strconv.ParseUint(r.get(column), 10, 64)
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("%s must be a positive number", column)
+	}
+	return uint(n)
+}
+
+func formatID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func formatOptionalInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
@@ -0,0 +1,90 @@
+package Bookings
+
+import (
+	"BackEnd/Availability"
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrInvalidSlot means the slot index falls outside a court's day.
+	ErrInvalidSlot = errors.New("invalid slot index")
+	// ErrSlotTaken means the slot is booked or blacked out.
+	ErrSlotTaken = errors.New("slot is already booked or unavailable")
+	// ErrCourtClosed means the court is not open for bookings.
+	ErrCourtClosed = errors.New("court is closed")
+	// ErrUnknownCustomer means no customer has the email given.
+	ErrUnknownCustomer = errors.New("customer not found")
+	// ErrNotOwner means the booking belongs to another customer.
+	ErrNotOwner = errors.New("booking belongs to another customer")
+	// errCreateBooking marks a failure to insert the booking, as opposed to checking the slot.
+	errCreateBooking = errors.New("failed to create booking")
+)
+
+// Book records booking as confirmed if its court is open and its slot is free. The caller fills
+// in the customer, sport, court, slot and date. The court and slot are checked and the booking
+// created in one transaction, and the unique index on active bookings guarantees that of several
+// concurrent requests only one gets the slot. It returns Repository.ErrNotFound for an unknown
+// court and ErrCourtClosed for one that is not open (Court_Status 1).
+func Book(store Repository.Store, booking *DataBase.Bookings) error {
+	const openStatus = 1
+
+	if !Availability.ValidSlot(booking.Booking_Time) {
+		return ErrInvalidSlot
+	}
+	booking.Booking_Status = DataBase.BookingConfirmed
+
+	return store.Transaction(func(tx Repository.Store) error {
+		court, err := tx.Courts().FindByID(booking.Court_ID)
+		if err != nil {
+			return err
+		}
+		if court.Court_Status != openStatus {
+			return ErrCourtClosed
+		}
+
+		state, err := Availability.Slot(tx, booking.Court_ID, booking.Booking_Time, *booking.Booking_Date)
+		if err != nil {
+			return err
+		}
+		if state != DataBase.SlotAvailable {
+			return ErrSlotTaken
+		}
+
+		if err := tx.Bookings().Create(booking); errors.Is(err, Repository.ErrDuplicate) {
+			return ErrSlotTaken
+		} else if err != nil {
+			return errCreateBooking
+		}
+		return nil
+	})
+}
+
+// Cancel cancels a booking on behalf of the customer with email, which frees its slot, and
+// returns it. The booking is kept so that its history stays visible to the customer and admins.
+// It returns Repository.ErrNotFound for an unknown booking, ErrUnknownCustomer, ErrNotOwner, or
+// Repository.ErrInvalidTransition if the booking can no longer be cancelled.
+func Cancel(store Repository.Store, bookingID uint, email string) (DataBase.Bookings, error) {
+	booking, err := store.Bookings().FindByID(bookingID)
+	if err != nil {
+		return booking, err
+	}
+
+	customer, err := store.Customers().FindByEmail(strings.ToLower(strings.TrimSpace(email)))
+	if errors.Is(err, Repository.ErrNotFound) {
+		return booking, ErrUnknownCustomer
+	} else if err != nil {
+		return booking, err
+	}
+
+	if booking.Customer_ID != customer.Customer_ID {
+		return booking, ErrNotOwner
+	}
+	if err := store.Bookings().Transition(booking.Booking_ID, DataBase.BookingCancelledByUser, ""); err != nil {
+		return booking, err
+	}
+	booking.Booking_Status = DataBase.BookingCancelledByUser
+	return booking, nil
+}
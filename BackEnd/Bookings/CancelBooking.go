@@ -1,11 +1,12 @@
 package Bookings
 
 import (
-	"BackEnd/DataBase"
+	"BackEnd/APIError"
+	"BackEnd/Events"
+	"BackEnd/Repository"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
-	"strings"
 )
 
 type CancelBookingRequest struct {
@@ -15,7 +16,7 @@ type CancelBookingRequest struct {
 
 // CancelBooking cancels a booking and frees up the slot.
 // @Summary Cancel a booking
-// @Description Verified ownership by email, deletes booking, and resets slot to available.
+// @Description Verifies ownership by email and marks the booking cancelled, which frees the slot.
 // @Tags bookings
 // @Accept json
 // @Produce json
@@ -24,65 +25,35 @@ type CancelBookingRequest struct {
 // @Failure 400 {object} DataBase.ErrorResponse "Invalid request"
 // @Failure 403 {object} DataBase.ErrorResponse "Unauthorized"
 // @Failure 404 {object} DataBase.ErrorResponse "Booking not found"
+// @Failure 409 {object} DataBase.ErrorResponse "Booking can no longer be cancelled"
+// @Deprecated
 // @Router /CancelBooking [post]
-func CancelBooking(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CancelBooking(w http.ResponseWriter, r *http.Request) {
 	var req CancelBookingRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		APIError.Malformed(w, r, "Invalid request body")
 		return
 	}
 
-	// 1. Get Booking and Customer Validation
-	var booking DataBase.Bookings
-	if err := DataBase.DB.First(&booking, req.BookingID).Error; err != nil {
-		http.Error(w, "Booking not found", http.StatusNotFound)
+	booking, err := Cancel(h.Store, req.BookingID, req.Email)
+	switch {
+	case errors.Is(err, Repository.ErrNotFound):
+		APIError.Write(w, r, http.StatusNotFound, APIError.BookingNotFound, "Booking not found")
 		return
-	}
-
-	normalizedEmail := strings.ToLower(strings.TrimSpace(req.Email))
-	var customer DataBase.Customer
-	if err := DataBase.DB.Where("LOWER(\"Email\") = ?", normalizedEmail).First(&customer).Error; err != nil {
-		http.Error(w, "Customer not found", http.StatusNotFound)
+	case errors.Is(err, ErrUnknownCustomer):
+		APIError.Write(w, r, http.StatusNotFound, APIError.CustomerNotFound, "Customer not found")
 		return
-	}
-
-	if booking.Customer_ID != customer.Customer_ID {
-		http.Error(w, "Unauthorized to cancel this booking", http.StatusForbidden)
-		return
-	}
-
-	// 2. Identify Slot Column
-	slotColumns := []string{
-		"slot_08_09", "slot_09_10", "slot_10_11", "slot_11_12", "slot_12_13",
-		"slot_13_14", "slot_14_15", "slot_15_16", "slot_16_17", "slot_17_18",
-	}
-	if booking.Booking_Time < 0 || booking.Booking_Time >= len(slotColumns) {
-		// Should not happen if data integrity is maintaned
-		http.Error(w, "Invalid booking time index in record", http.StatusInternalServerError)
+	case errors.Is(err, ErrNotOwner):
+		APIError.Write(w, r, http.StatusForbidden, APIError.NotBookingOwner, "Unauthorized to cancel this booking")
 		return
-	}
-	columnName := slotColumns[booking.Booking_Time]
-
-	// 3. Start Transaction
-	tx := DataBase.DB.Begin()
-
-	// 3a. Update Booking Status to "Cancelled" (Soft Cancel)
-	// We do NOT delete so that history is preserved for Admin/User
-	if err := tx.Model(&booking).Update("Booking_Status", "Cancelled").Error; err != nil {
-		tx.Rollback()
-		http.Error(w, "Failed to cancel booking", http.StatusInternalServerError)
+	case errors.Is(err, Repository.ErrInvalidTransition):
+		APIError.Write(w, r, http.StatusConflict, APIError.InvalidStatusTransition, "Booking can no longer be cancelled")
 		return
-	}
-
-	// 3b. Update Court_TimeSlots (Set to 1 - Available)
-	updateQuery := fmt.Sprintf("UPDATE \"Court_TimeSlots\" SET \"%s\" = 1 WHERE \"Court_ID\" = ?", columnName)
-	if err := tx.Exec(updateQuery, booking.Court_ID).Error; err != nil {
-		tx.Rollback()
-		http.Error(w, "Failed to update court availability", http.StatusInternalServerError)
+	case err != nil:
+		APIError.Internal(w, r, "Failed to cancel booking", err)
 		return
 	}
-
-	tx.Commit()
+	h.Events.Publish(Events.ForBooking(Events.Cancelled, booking))
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
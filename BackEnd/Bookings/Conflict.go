@@ -0,0 +1,21 @@
+package Bookings
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/Availability"
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"log"
+	"net/http"
+)
+
+// WriteSlotTaken answers 409 for a booking whose slot could not be booked, offering the nearest
+// free slots instead. Failing to work them out is only logged, since the conflict is still the
+// answer.
+func WriteSlotTaken(w http.ResponseWriter, r *http.Request, store Repository.Store, opts Availability.AlternativeOptions, booking DataBase.Bookings) {
+	alternatives, err := Availability.Alternatives(store, booking.Court_ID, *booking.Booking_Date, booking.Booking_Time, opts)
+	if err != nil {
+		log.Printf("request %s: failed to find alternatives: %v", APIError.RequestIDFrom(r.Context()), err)
+	}
+	APIError.SlotTaken(w, r, alternatives)
+}
@@ -1,10 +1,13 @@
 package Bookings
 
 import (
+	"BackEnd/APIError"
 	"BackEnd/DataBase"
+	"BackEnd/Events"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
+	"time"
 )
 
 type BookingRequest struct {
@@ -16,7 +19,7 @@ type BookingRequest struct {
 
 // CreateBooking creates a new booking after validating customer, sport, and court.
 // @Summary Create a new booking
-// @Description Creates a new booking, updates court slot availability, and records the booking.
+// @Description Creates a booking for today if the slot is neither booked nor blacked out. Otherwise the 409 answer lists the nearest free alternatives: other courts of the sport at the same time, and the court's own slots shortly before and after.
 // @Tags bookings
 // @Accept json
 // @Produce json
@@ -24,109 +27,72 @@ type BookingRequest struct {
 // @Success 201 {object} map[string]interface{} "Booking successful"
 // @Failure 400 {object} DataBase.ErrorResponse "Invalid request"
 // @Failure 404 {object} DataBase.ErrorResponse "Resource not found"
+// @Failure 409 {object} DataBase.ErrorResponse "Slot is already booked or unavailable, with the nearest free alternatives, or the court is closed"
 // @Failure 500 {object} DataBase.ErrorResponse "Internal server error"
+// @Deprecated
 // @Router /CreateBooking [post]
-func CreateBooking(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateBooking(w http.ResponseWriter, r *http.Request) {
 	var req BookingRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		APIError.Malformed(w, r, "Invalid request body")
 		return
 	}
 
 	// 1. Look up Customer by Email, Create if not exists
-	var customer DataBase.Customer
-	if err := DataBase.DB.Where("\"Email\" = ?", req.Email).First(&customer).Error; err != nil {
+	customer, err := h.Store.Customers().FindByEmail(req.Email)
+	if err != nil {
 		// Auto-create customer
 		customer = DataBase.Customer{
 			Email: req.Email,
 			Name:  "Gator User", // Default name, can be updated later
 		}
-		if createErr := DataBase.DB.Create(&customer).Error; createErr != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(DataBase.ErrorResponse{Message: "Failed to create customer profile"})
+		if createErr := h.Store.Customers().Create(&customer); createErr != nil {
+			APIError.Internal(w, r, "Failed to create customer profile", createErr)
 			return
 		}
 	}
 
 	// 2. Validate Sport and Court
-	var sport DataBase.Sport
-	if err := DataBase.DB.First(&sport, req.SportID).Error; err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(DataBase.ErrorResponse{Message: "Sport not found"})
+	if _, err := h.Store.Sports().FindByID(req.SportID); err != nil {
+		APIError.Write(w, r, http.StatusNotFound, APIError.SportNotFound, "Sport not found")
 		return
 	}
-	var court DataBase.Court
-	if err := DataBase.DB.First(&court, req.CourtID).Error; err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(DataBase.ErrorResponse{Message: "Court not found"})
+	court, err := h.Store.Courts().FindByID(req.CourtID)
+	if err != nil {
+		APIError.Write(w, r, http.StatusNotFound, APIError.CourtNotFound, "Court not found")
 		return
 	}
 
-	// 3. Map Slot Index to Column Name and Time String
-	slotColumns := []string{
-		"slot_08_09", "slot_09_10", "slot_10_11", "slot_11_12", "slot_12_13",
-		"slot_13_14", "slot_14_15", "slot_15_16", "slot_16_17", "slot_17_18",
+	// 3. Check the slot is free and create the booking record together
+	booking := DataBase.Bookings{
+		Customer_ID:  customer.Customer_ID,
+		Sport_ID:     req.SportID,
+		Court_ID:     req.CourtID,
+		Booking_Time: req.SlotIndex,
+		Booking_Date: DataBase.BookingDate(time.Now()),
 	}
-
-	if req.SlotIndex < 0 || req.SlotIndex >= len(slotColumns) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(DataBase.ErrorResponse{Message: "Invalid slot index"})
+	err = Book(h.Store, &booking)
+	if errors.Is(err, ErrInvalidSlot) {
+		APIError.Validation(w, r, "Invalid slot index", APIError.Field("slot_index", "must be one of the day's slots"))
 		return
 	}
-	columnName := slotColumns[req.SlotIndex]
-
-	// 4. Check if Slot is Available (Status 1)
-	var count int64
-	checkQuery := fmt.Sprintf("SELECT count(*) FROM \"Court_TimeSlots\" WHERE \"Court_ID\" = ? AND \"%s\" = 1", columnName)
-	if err := DataBase.DB.Raw(checkQuery, req.CourtID).Count(&count).Error; err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(DataBase.ErrorResponse{Message: "Database error checking availability"})
+	if errors.Is(err, ErrSlotTaken) {
+		WriteSlotTaken(w, r, h.Store, h.Alternatives, booking)
 		return
 	}
-
-	if count == 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusConflict) // 409 Conflict
-		json.NewEncoder(w).Encode(DataBase.ErrorResponse{Message: "Slot is already booked or unavailable"})
-		return
-	}
-
-	// 5. Start Transaction
-	tx := DataBase.DB.Begin()
-
-	// 5a. Create Booking Record
-	booking := DataBase.Bookings{
-		Customer_ID:    customer.Customer_ID,
-		Sport_ID:       req.SportID,
-		Court_ID:       req.CourtID,
-		Booking_Status: "Confirmed",
-		Booking_Time:   req.SlotIndex,
-	}
-
-	if err := tx.Create(&booking).Error; err != nil {
-		tx.Rollback()
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(DataBase.ErrorResponse{Message: "Failed to create booking"})
+	if errors.Is(err, ErrCourtClosed) {
+		APIError.Write(w, r, http.StatusConflict, APIError.CourtUnavailable, "Court is closed")
 		return
 	}
-
-	// 5b. Update Court_TimeSlots (Set to 2 - Booked)
-	updateQuery := fmt.Sprintf("UPDATE \"Court_TimeSlots\" SET \"%s\" = 2 WHERE \"Court_ID\" = ?", columnName)
-	if err := tx.Exec(updateQuery, req.CourtID).Error; err != nil {
-		tx.Rollback()
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(DataBase.ErrorResponse{Message: "Failed to update court availability"})
+	if err != nil {
+		message := "Database error checking availability"
+		if errors.Is(err, errCreateBooking) {
+			message = "Failed to create booking"
+		}
+		APIError.Internal(w, r, message, err)
 		return
 	}
-
-	tx.Commit()
+	h.Events.Publish(Events.ForBooking(Events.Booked, booking))
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -1,39 +1,36 @@
 package Bookings
 
 import (
+	"BackEnd/Availability"
 	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"BackEnd/Repository/TestStore"
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
-
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
+	"time"
 )
 
-func setupTestDB() *gorm.DB {
-	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
-	if err != nil {
-		panic("failed to connect to the database")
-	}
-
-	// Migrate the schema.
-	db.AutoMigrate(&DataBase.Customer{}, &DataBase.Sport{}, &DataBase.Court{}, &DataBase.Bookings{})
+func setupTestStore(t *testing.T) Repository.Store {
+	store := TestStore.Open(t)
 
 	// Insert test data.
-	db.Create(&DataBase.Customer{
+	store.Customers().Create(&DataBase.Customer{
 		Customer_ID: 122,
 		Name:        "John Doe",
 		Email:       "john@example.com",
 		Contact:     "1234567890",
 	})
-	db.Create(&DataBase.Sport{
+	store.Sports().Create(&DataBase.Sport{
 		Sport_ID:          122,
 		Sport_name:        "Tennis",
 		Sport_Description: "Tennis Sport",
 	})
-	db.Create(&DataBase.Court{
+	store.Courts().Create(&DataBase.Court{
 		Court_ID:       122,
 		Court_Name:     "Court A",
 		Court_Location: "Downtown",
@@ -41,26 +38,28 @@ func setupTestDB() *gorm.DB {
 		Sport_id:       122,
 	})
 	// Insert a booking record with the correct Booking_Time.
-	db.Create(&DataBase.Bookings{
+	store.Bookings().Create(&DataBase.Bookings{
 		Booking_ID:     1,
 		Customer_ID:    122,
 		Court_ID:       122,
 		Sport_ID:       122,
-		Booking_Status: "Confirmed",
+		Booking_Status: DataBase.BookingConfirmed,
 		Booking_Time:   2, // This corresponds to "10-11 AM"
+		Booking_Date:   DataBase.BookingDate(time.Now()),
 	})
 
-	return db
+	return store
 }
 
 func TestCreateBooking(t *testing.T) {
-	DataBase.DB = setupTestDB()
+	store := setupTestStore(t)
+	h := NewHandler(store)
 
 	bookingRequest := map[string]interface{}{
-		"Customer_ID":    122,
-		"Sport_ID":       122,
-		"Court_ID":       122,
-		"Booking_Status": "Confirmed",
+		"email":      "john@example.com",
+		"sport_id":   122,
+		"court_id":   122,
+		"slot_index": 0,
 	}
 
 	body, _ := json.Marshal(bookingRequest)
@@ -71,7 +70,7 @@ func TestCreateBooking(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	recorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(CreateBooking)
+	handler := http.HandlerFunc(h.CreateBooking)
 	handler.ServeHTTP(recorder, req)
 
 	t.Logf("Response Body: %s", recorder.Body.String())
@@ -83,18 +82,198 @@ func TestCreateBooking(t *testing.T) {
 	var response map[string]interface{}
 	json.Unmarshal(recorder.Body.Bytes(), &response)
 
-	if response["message"] != "Booking record added successfully" {
+	if response["message"] != "Booking successful" {
 		t.Errorf("unexpected response message: %v", response["message"])
 	}
 
-	var savedBooking DataBase.Bookings
-	result := DataBase.DB.First(&savedBooking, "customer_id = ? AND court_id = ?", 122, 122)
+	bookingID, _ := response["booking_id"].(float64)
+	savedBooking, err := store.Bookings().FindByID(uint(bookingID))
 
-	t.Logf("Database Query Result: %+v", savedBooking)
+	t.Logf("Stored Booking: %+v", savedBooking)
 
-	if result.Error != nil {
-		t.Errorf("Booking not found in database: %v", result.Error)
-	} else if savedBooking.Customer_ID != 122 || savedBooking.Court_ID != 122 {
+	if err != nil {
+		t.Errorf("Booking not found in store: %v", err)
+	} else if savedBooking.Customer_ID != 122 || savedBooking.Court_ID != 122 || savedBooking.Booking_Time != 0 {
 		t.Errorf("Booking data mismatch: got %+v", savedBooking)
 	}
+
+	if value, _ := Availability.Slot(store, 122, 0, *DataBase.BookingDate(time.Now())); value != DataBase.SlotBooked {
+		t.Errorf("expected slot 0 to show as booked (2), got %d", value)
+	}
+}
+
+func TestCreateBookingSlotTaken(t *testing.T) {
+	store := setupTestStore(t)
+	h := NewHandler(store)
+
+	// Court B is free at the same time, Court C is closed, and Court A is blacked out at 11:00
+	store.Courts().Create(&DataBase.Court{Court_ID: 123, Court_Name: "Court B", Court_Location: "Downtown", Court_Status: 1, Sport_id: 122})
+	store.Courts().Create(&DataBase.Court{Court_ID: 124, Court_Name: "Court C", Court_Location: "Downtown", Court_Status: 0, Sport_id: 122})
+	slot := 3
+	store.Blackouts().Create(&DataBase.Court_Blackout{Court_ID: 122, Blackout_Date: *DataBase.BookingDate(time.Now()), Slot_Index: &slot})
+
+	conflict := func() []string {
+		body, _ := json.Marshal(map[string]interface{}{
+			"email":      "john@example.com",
+			"sport_id":   122,
+			"court_id":   122,
+			"slot_index": 2,
+		})
+		req, _ := http.NewRequest("POST", "/CreateBooking", bytes.NewBuffer(body))
+		recorder := httptest.NewRecorder()
+		h.CreateBooking(recorder, req)
+
+		if recorder.Code != http.StatusConflict {
+			t.Errorf("expected status %d, got %d", http.StatusConflict, recorder.Code)
+		}
+		var response DataBase.ErrorResponse
+		json.Unmarshal(recorder.Body.Bytes(), &response)
+		if response.Code != "SLOT_UNAVAILABLE" {
+			t.Errorf("expected code SLOT_UNAVAILABLE, got %+v", response)
+		}
+		var alternatives []string
+		for _, a := range response.Alternatives {
+			alternatives = append(alternatives, fmt.Sprintf("%s %s %d", a.CourtName, a.Slot, a.Distance))
+		}
+		return alternatives
+	}
+
+	want := []string{"Court B 10:00 - 11:00 0", "Court A 09:00 - 10:00 1", "Court A 08:00 - 09:00 2", "Court A 12:00 - 13:00 2"}
+	if got := conflict(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected alternatives %v, got %v", want, got)
+	}
+
+	h.Alternatives = Availability.AlternativeOptions{Radius: 1, Limit: 1}
+	if got, want := conflict(), []string{"Court B 10:00 - 11:00 0"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected alternatives %v, got %v", want, got)
+	}
+}
+
+func createBookingRequest(email string, slotIndex int) *http.Request {
+	body, _ := json.Marshal(map[string]interface{}{
+		"email":      email,
+		"sport_id":   122,
+		"court_id":   122,
+		"slot_index": slotIndex,
+	})
+	req, _ := http.NewRequest("POST", "/CreateBooking", bytes.NewBuffer(body))
+	return req
+}
+
+// TestCreateBookingSameSlotHasOneWinner only checks the outcome of many requests for one slot.
+// SQLite keeps a single connection and the memory store runs one transaction at a time, so
+// there the requests are served one after another and never actually race; the race itself is
+// exercised by TestCreateBookingRacesOnPostgres.
+func TestCreateBookingSameSlotHasOneWinner(t *testing.T) {
+	bookSlotConcurrently(t, setupTestStore(t), 20)
+}
+
+// TestCreateBookingRacesOnPostgres runs the requests in parallel transactions, so it only
+// proves that a slot cannot be booked twice when TEST_DB_DRIVER=postgres.
+func TestCreateBookingRacesOnPostgres(t *testing.T) {
+	if TestStore.Driver() != DataBase.DriverPostgres {
+		t.Skip("needs TEST_DB_DRIVER=postgres; other backends serialize the requests")
+	}
+	bookSlotConcurrently(t, setupTestStore(t), 20)
+}
+
+// bookSlotConcurrently sends requests for the same slot at once and checks that exactly one
+// of them books it while the others get a conflict.
+func bookSlotConcurrently(t *testing.T, store Repository.Store, requests int) {
+	t.Helper()
+	h := NewHandler(store)
+
+	codes := make(chan int, requests)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			recorder := httptest.NewRecorder()
+			h.CreateBooking(recorder, createBookingRequest(fmt.Sprintf("player%d@example.com", i), 5))
+			codes <- recorder.Code
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	close(codes)
+
+	created, conflicts := 0, 0
+	for code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+			conflicts++
+		default:
+			t.Errorf("unexpected status %d", code)
+		}
+	}
+	if created != 1 || conflicts != requests-1 {
+		t.Errorf("expected exactly one winner, got %d created and %d conflicts", created, conflicts)
+	}
+
+	active, _ := store.Bookings().ListActive()
+	onSlot := 0
+	for _, booking := range active {
+		if booking.Booking_Time == 5 {
+			onSlot++
+		}
+	}
+	if onSlot != 1 {
+		t.Errorf("expected one active booking on the slot, got %d", onSlot)
+	}
+}
+
+func TestCreateBookingBlackedOutSlot(t *testing.T) {
+	store := setupTestStore(t)
+	h := NewHandler(store)
+
+	slot := 4
+	store.Blackouts().Create(&DataBase.Court_Blackout{
+		Court_ID:      122,
+		Blackout_Date: *DataBase.BookingDate(time.Now()),
+		Slot_Index:    &slot,
+		Reason:        "Resurfacing",
+	})
+
+	recorder := httptest.NewRecorder()
+	h.CreateBooking(recorder, createBookingRequest("john@example.com", 4))
+	if recorder.Code != http.StatusConflict {
+		t.Errorf("expected status %d, got %d", http.StatusConflict, recorder.Code)
+	}
+
+	// The neighbouring slot is unaffected
+	recorder = httptest.NewRecorder()
+	h.CreateBooking(recorder, createBookingRequest("john@example.com", 5))
+	if recorder.Code != http.StatusCreated {
+		t.Errorf("expected status %d, got %d", http.StatusCreated, recorder.Code)
+	}
+}
+
+func TestCreateBookingClosedCourt(t *testing.T) {
+	store := setupTestStore(t)
+	h := NewHandler(store)
+
+	store.Courts().Create(&DataBase.Court{Court_ID: 123, Court_Name: "Court B", Court_Location: "Downtown", Court_Status: 0, Sport_id: 122})
+	body, _ := json.Marshal(map[string]interface{}{
+		"email":      "john@example.com",
+		"sport_id":   122,
+		"court_id":   123,
+		"slot_index": 0,
+	})
+	req, _ := http.NewRequest("POST", "/CreateBooking", bytes.NewBuffer(body))
+	recorder := httptest.NewRecorder()
+	h.CreateBooking(recorder, req)
+
+	var response DataBase.ErrorResponse
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	if recorder.Code != http.StatusConflict || response.Code != "COURT_UNAVAILABLE" {
+		t.Errorf("expected 409 COURT_UNAVAILABLE, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if bookings, _ := store.Bookings().ListActiveOn([]uint{123}, *DataBase.BookingDate(time.Now())); len(bookings) != 0 {
+		t.Errorf("expected no booking on the closed court, got %+v", bookings)
+	}
 }
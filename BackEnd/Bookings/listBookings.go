@@ -1,74 +1,180 @@
 package Bookings
 
 import (
+	"BackEnd/APIError"
 	"BackEnd/DataBase"
+	"BackEnd/Pagination"
+	"BackEnd/Repository"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 )
 
 type BookingResponse struct {
 	BookingID     uint   `json:"booking_id"`
 	CourtName     string `json:"court_name"`
 	SportName     string `json:"sport_name"`
+	BookingDate   string `json:"booking_date,omitempty"`
 	SlotTime      string `json:"slot_time"`
 	BookingStatus string `json:"booking_status"`
 }
 
+// Archived bookings are listed a page at a time.
+const (
+	defaultArchivePageSize = 20
+	maxArchivePageSize     = 100
+)
+
 // ListBookings godoc
 // @Summary      List bookings for a customer
-// @Description  Retrieves a list of bookings for a customer by email. Returns booking details including court name, sport name, slot time, and booking status.
+// @Description  Retrieves a customer's bookings by email, a page at a time. Returns booking details including court name, sport name, slot time, and booking status. X-Next-Cursor and the Link header give the next page, and X-Total-Count the number of matching bookings. Past bookings are moved to an archive each night. With **archived=true** the archive is listed instead, newest first and paged with **page** and **page_size**; only **from** and **to** filter it.
 // @Tags         bookings
 // @Accept       json
 // @Produce      json
 // @Param        email  query     string  true  "Customer email"  default(john@example.com)
+// @Param        archived  query  bool    false  "List archived past bookings instead"
+// @Param        from      query  string  false  "Earliest booking date, inclusive"  example(2026-01-01)
+// @Param        to        query  string  false  "Latest booking date, inclusive"  example(2026-01-31)
+// @Param        sport_id  query  int     false  "Only bookings for this sport"
+// @Param        court_id  query  int     false  "Only bookings on this court"
+// @Param        status    query  string  false  "Only bookings in these statuses, comma separated"  example(confirmed,checked_in)
+// @Param        limit     query  int     false  "Bookings per page, at most 500"  default(100)
+// @Param        cursor    query  string  false  "Cursor of the page, from X-Next-Cursor"
+// @Param        sort      query  string  false  "id, date, slot, status, sport_id, court_id or customer_id, prefixed with - for descending order"  default(id)
+// @Param        page      query  int     false  "Archive page, starting at 1"  default(1)
+// @Param        page_size query  int     false  "Archived bookings per page, at most 100"  default(20)
 // @Success      200    {array}   BookingResponse  "List of bookings for the customer"  example([{"booking_id":1,"court_name":"Court A","sport_name":"Tennis","slot_time":"10-11 AM","booking_status":"Confirmed"}])
-// @Failure      400    {string}  string  "Email query parameter is required"
-// @Failure      404    {string}  string  "Customer not found"
-// @Failure      500    {string}  string  "Database error while fetching bookings"
+// @Failure      400    {object}  DataBase.ErrorResponse  "Email query parameter is required, or an archive parameter is invalid"
+// @Failure      404    {object}  DataBase.ErrorResponse  "Customer not found"
+// @Failure      500    {object}  DataBase.ErrorResponse  "Database error while fetching bookings"
+// @Deprecated
 // @Router       /listBookings [get]
-func ListBookings(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) ListBookings(w http.ResponseWriter, r *http.Request) {
 
 	email := r.URL.Query().Get("email")
 	if email == "" {
-		http.Error(w, "Email query parameter is required", http.StatusBadRequest)
+		APIError.Validation(w, r, "Email query parameter is required", APIError.Field("email", "is required"))
 		return
 	}
 
 	// 1. Find Customer
-	var customer DataBase.Customer
-	if err := DataBase.DB.Where("\"Email\" = ?", email).First(&customer).Error; err != nil {
-		http.Error(w, "Customer not found", http.StatusNotFound)
+	customer, err := h.Store.Customers().FindByEmail(email)
+	if err != nil {
+		APIError.Write(w, r, http.StatusNotFound, APIError.CustomerNotFound, "Customer not found")
 		return
 	}
 
-	// 2. Find Bookings with Associations
-	var bookings []DataBase.Bookings
-	if err := DataBase.DB.Preload("Court").Preload("Sport").Where("\"Customer_ID\" = ?", customer.Customer_ID).Find(&bookings).Error; err != nil {
-		http.Error(w, "Database error while fetching bookings", http.StatusInternalServerError)
+	if archived, _ := strconv.ParseBool(r.URL.Query().Get("archived")); archived {
+		h.listArchivedBookings(w, r, customer.Customer_ID)
+		return
+	}
+
+	params := Pagination.New(r)
+	filter := Filter(params)
+	filter.CustomerID = customer.Customer_ID
+	q := params.List(Repository.BookingSorts)
+	if !params.Check(w, r) {
 		return
 	}
 
-	slots := []string{
-		"08:00 - 09:00", "09:00 - 10:00", "10:00 - 11:00", "11:00 - 12:00",
-		"12:00 - 13:00", "13:00 - 14:00", "14:00 - 15:00", "15:00 - 16:00",
-		"16:00 - 17:00", "17:00 - 18:00",
+	// 2. Find Bookings with Associations
+	bookings, next, err := h.Store.Bookings().Page(filter, q)
+	var total int64
+	if err == nil {
+		total, err = h.Store.Bookings().Count(filter)
+	}
+	if err != nil {
+		Pagination.Failed(w, r, err, "Database error while fetching bookings")
+		return
 	}
 
 	var responseBookings []BookingResponse
 	for _, b := range bookings {
-		slotTime := ""
-		if b.Booking_Time >= 0 && b.Booking_Time < len(slots) {
-			slotTime = slots[b.Booking_Time]
-		}
-		responseBookings = append(responseBookings, BookingResponse{
+		response := BookingResponse{
 			BookingID:     b.Booking_ID,
 			CourtName:     b.Court.Court_Name,
 			SportName:     b.Sport.Sport_name,
-			SlotTime:      slotTime,
-			BookingStatus: b.Booking_Status,
+			SlotTime:      DataBase.SlotLabel(b.Booking_Time),
+			BookingStatus: string(b.Booking_Status),
+		}
+		if b.Booking_Date != nil {
+			response.BookingDate = *b.Booking_Date
+		}
+		responseBookings = append(responseBookings, response)
+	}
+
+	Pagination.SetTotal(w, total)
+	Pagination.SetNext(w, r, q, next)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(responseBookings)
+}
+
+// Filter reads the booking filters every booking list accepts: the from and to dates, sport_id,
+// court_id and a comma separated status list.
+func Filter(params *Pagination.Params) Repository.BookingFilter {
+	return Repository.BookingFilter{
+		From:     params.Date("from"),
+		To:       params.Date("to"),
+		SportID:  params.ID("sport_id"),
+		CourtID:  params.ID("court_id"),
+		Statuses: params.Statuses("status"),
+	}
+}
+
+// listArchivedBookings writes one page of a customer's archived bookings.
+func (h *Handler) listArchivedBookings(w http.ResponseWriter, r *http.Request, customerID uint) {
+	from, to, offset, limit, err := ArchivePage(r.URL.Query())
+	if err != nil {
+		APIError.Validation(w, r, err.Error())
+		return
+	}
+
+	archived, total, err := h.Store.Bookings().ListArchived(customerID, from, to, offset, limit)
+	if err != nil {
+		APIError.Internal(w, r, "Database error while fetching bookings", err)
+		return
+	}
+
+	responseBookings := []BookingResponse{}
+	for _, b := range archived {
+		responseBookings = append(responseBookings, BookingResponse{
+			BookingID:     b.Booking_ID,
+			CourtName:     b.Court_Name,
+			SportName:     b.Sport_Name,
+			BookingDate:   b.Booking_Date,
+			SlotTime:      DataBase.SlotLabel(b.Booking_Time),
+			BookingStatus: string(b.Booking_Status),
 		})
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
 	json.NewEncoder(w).Encode(responseBookings)
 }
+
+// ArchivePage reads the from, to, page and page_size parameters of an archive listing and
+// returns the date range and the rows of the page. The error describes the invalid parameter.
+func ArchivePage(query url.Values) (from, to string, offset, limit int, err error) {
+	from, to = query.Get("from"), query.Get("to")
+	for _, date := range []string{from, to} {
+		if _, err := time.Parse(DataBase.BookingDateLayout, date); date != "" && err != nil {
+			return "", "", 0, 0, errors.New("from and to must be dates formatted as YYYY-MM-DD")
+		}
+	}
+
+	page, pageSize := 1, defaultArchivePageSize
+	if raw := query.Get("page"); raw != "" {
+		if page, err = strconv.Atoi(raw); err != nil || page < 1 {
+			return "", "", 0, 0, errors.New("page must be a positive number")
+		}
+	}
+	if raw := query.Get("page_size"); raw != "" {
+		if pageSize, err = strconv.Atoi(raw); err != nil || pageSize < 1 || pageSize > maxArchivePageSize {
+			return "", "", 0, 0, errors.New("page_size must be between 1 and 100")
+		}
+	}
+	return from, to, (page - 1) * pageSize, pageSize, nil
+}
@@ -6,10 +6,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestListBookings(t *testing.T) {
-	DataBase.DB = setupTestDB()
+	h := NewHandler(setupTestStore(t))
 
 	req, err := http.NewRequest("GET", "/ListBookings?email=john@example.com", nil)
 	if err != nil {
@@ -18,7 +19,7 @@ func TestListBookings(t *testing.T) {
 
 	recorder := httptest.NewRecorder()
 
-	handler := http.HandlerFunc(ListBookings)
+	handler := http.HandlerFunc(h.ListBookings)
 	handler.ServeHTTP(recorder, req)
 
 	if recorder.Code != http.StatusOK {
@@ -37,7 +38,7 @@ func TestListBookings(t *testing.T) {
 	}
 
 	if len(responseBookings) != 1 {
-		t.Errorf("expected 1 booking, got %d", len(responseBookings))
+		t.Fatalf("expected 1 booking, got %d", len(responseBookings))
 	}
 
 	booking := responseBookings[0]
@@ -50,10 +51,67 @@ func TestListBookings(t *testing.T) {
 	if booking.SportName != "Tennis" {
 		t.Errorf("expected SportName %s, got %s", "Tennis", booking.SportName)
 	}
-	if booking.SlotTime != "10-11 AM" {
-		t.Errorf("expected SlotTime %s, got %s", "10-11 AM", booking.SlotTime)
+	if booking.SlotTime != "10:00 - 11:00" {
+		t.Errorf("expected SlotTime %s, got %s", "10:00 - 11:00", booking.SlotTime)
 	}
-	if booking.BookingStatus != "Confirmed" {
-		t.Errorf("expected BookingStatus %s, got %s", "Confirmed", booking.BookingStatus)
+	if booking.BookingStatus != "confirmed" {
+		t.Errorf("expected BookingStatus %s, got %s", "confirmed", booking.BookingStatus)
+	}
+}
+
+func TestListBookings_Archived(t *testing.T) {
+	store := setupTestStore(t)
+	h := NewHandler(store)
+
+	today := time.Now()
+	statuses := []DataBase.BookingStatus{DataBase.BookingConfirmed, DataBase.BookingCancelledByUser, DataBase.BookingConfirmed}
+	for i, status := range statuses {
+		booking := DataBase.Bookings{Customer_ID: 122, Court_ID: 122, Sport_ID: 122, Booking_Status: status,
+			Booking_Time: i, Booking_Date: DataBase.BookingDate(today.AddDate(0, 0, i-3))}
+		if err := store.Bookings().Create(&booking); err != nil {
+			t.Fatalf("failed to create booking: %v", err)
+		}
+	}
+	if _, err := store.Bookings().Archive(*DataBase.BookingDate(today), ""); err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+
+	list := func(query string) ([]BookingResponse, *httptest.ResponseRecorder) {
+		req, _ := http.NewRequest("GET", "/ListBookings?email=john@example.com"+query, nil)
+		recorder := httptest.NewRecorder()
+		h.ListBookings(recorder, req)
+		var bookings []BookingResponse
+		json.Unmarshal(recorder.Body.Bytes(), &bookings)
+		return bookings, recorder
+	}
+
+	if current, _ := list(""); len(current) != 1 || current[0].BookingID != 1 {
+		t.Errorf("expected only today's booking in the current list, got %+v", current)
+	}
+
+	page, recorder := list("&archived=true&page_size=2")
+	if recorder.Code != http.StatusOK || recorder.Header().Get("X-Total-Count") != "3" {
+		t.Fatalf("expected 3 archived bookings in total, got status %d and count %q", recorder.Code, recorder.Header().Get("X-Total-Count"))
+	}
+	if len(page) != 2 || page[0].BookingDate != *DataBase.BookingDate(today.AddDate(0, 0, -1)) || page[0].BookingStatus != "completed" {
+		t.Errorf("expected the newest completed booking first, got %+v", page)
+	}
+	if page[0].CourtName != "Court A" || page[0].SportName != "Tennis" {
+		t.Errorf("expected court and sport names to be archived, got %+v", page[0])
+	}
+
+	if last, _ := list("&archived=true&page_size=2&page=2"); len(last) != 1 || last[0].BookingStatus != "completed" {
+		t.Errorf("expected the oldest booking on the second page, got %+v", last)
+	}
+
+	day := *DataBase.BookingDate(today.AddDate(0, 0, -2))
+	if ranged, _ := list("&archived=true&from=" + day + "&to=" + day); len(ranged) != 1 || ranged[0].BookingStatus != "cancelled_by_user" {
+		t.Errorf("expected the cancellation to keep its status, got %+v", ranged)
+	}
+
+	for _, query := range []string{"&archived=true&from=yesterday", "&archived=true&page=0", "&archived=true&page_size=500"} {
+		if _, recorder := list(query); recorder.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %s, got %d", query, recorder.Code)
+		}
 	}
 }
@@ -0,0 +1,38 @@
+// Package Caching lets polling clients revalidate a response instead of downloading it again.
+// Handlers tag a response with an ETag that changes whenever its content may have changed; a
+// request whose If-None-Match still holds that tag is answered 304 Not Modified with no body.
+package Caching
+
+import (
+	"net/http"
+	"strings"
+)
+
+// CacheControl makes clients revalidate on every use, so they never show stale availability
+// but only transfer it when it has changed.
+const CacheControl = "no-cache"
+
+// NotModified sets the ETag and Cache-Control headers for a response tagged etag. If the
+// request's If-None-Match already holds etag it answers 304 and returns true, and the caller
+// must not write anything else.
+func NotModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", CacheControl)
+	if !matches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// matches reports whether the If-None-Match header holds etag. It uses the weak comparison
+// RFC 9110 asks for: W/ prefixes are ignored, and * matches any tag.
+func matches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate != "" && strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package Caching
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNotModified(t *testing.T) {
+	const etag = `W/"1.4.2026-01-31.abc"`
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        bool
+	}{
+		{"No header", "", false},
+		{"Same tag", etag, true},
+		{"Strong form of the tag", `"1.4.2026-01-31.abc"`, true},
+		{"Tag in a list", `"other", ` + etag, true},
+		{"Any tag", "*", true},
+		{"Older tag", `W/"1.3.2026-01-31.abc"`, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tc.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			if got := NotModified(w, req, etag); got != tc.want {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+			if w.Header().Get("ETag") != etag || w.Header().Get("Cache-Control") != CacheControl {
+				t.Errorf("expected caching headers, got %v", w.Header())
+			}
+			if tc.want && w.Code != http.StatusNotModified {
+				t.Errorf("expected 304, got %d", w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package Config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Usage describes the config subcommand.
+const Usage = `usage: config <command>
+
+commands:
+  print      show the effective configuration, with secrets redacted`
+
+// Run executes the config subcommand of the server binary, writing to out.
+func Run(config Config, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing config command\n%s", Usage)
+	}
+
+	switch args[0] {
+	case "print":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(config.Redacted())
+	}
+
+	return fmt.Errorf("unknown config command %q\n%s", args[0], Usage)
+}
@@ -0,0 +1,320 @@
+// Package Config holds every setting of the server in one typed structure. Settings start from
+// Defaults, are overridden by an optional JSON file (CONFIG_FILE) and then by environment
+// variables, and are validated before anything else starts.
+package Config
+
+import (
+	"BackEnd/Availability"
+	"BackEnd/DataBase"
+	httpserver "BackEnd/Server"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Config is the effective configuration. The env tag of each field names the environment
+// variable that overrides it.
+type Config struct {
+	Database Database `json:"database"`
+	Server   Server   `json:"server"`
+	Auth     Auth     `json:"auth"`
+	CORS     CORS     `json:"cors"`
+	Schedule Schedule `json:"schedule"`
+	Slots    Slots    `json:"slots"`
+	// Alternatives bounds the free slots offered when a slot cannot be booked.
+	Alternatives Alternatives `json:"alternatives"`
+	Idempotency  Idempotency  `json:"idempotency"`
+	Events       Events       `json:"events"`
+}
+
+type Database struct {
+	Driver string `json:"driver" env:"DB_DRIVER"`
+	// URL is the connection string, or the database file for SQLite. Left empty, it defaults
+	// to a local database for the driver.
+	URL             string   `json:"url" env:"DATABASE_URL"`
+	MaxOpenConns    int      `json:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int      `json:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime Duration `json:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime Duration `json:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+	ConnectAttempts int      `json:"connect_attempts" env:"DB_CONNECT_ATTEMPTS"`
+	// ConnectBackoff is the wait after the first failed connection, doubled after each further one.
+	ConnectBackoff Duration `json:"connect_backoff" env:"DB_CONNECT_BACKOFF"`
+}
+
+type Server struct {
+	Port              int      `json:"port" env:"PORT"`
+	ReadTimeout       Duration `json:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	ReadHeaderTimeout Duration `json:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	WriteTimeout      Duration `json:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       Duration `json:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	MaxHeaderBytes    int      `json:"max_header_bytes" env:"HTTP_MAX_HEADER_BYTES"`
+	ShutdownTimeout   Duration `json:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+}
+
+type Auth struct {
+	// JWKSURL is where the Cognito signing keys are fetched from. Without it customer
+	// tokens cannot be verified.
+	JWKSURL             string   `json:"cognito_jwks_url" env:"COGNITO_JWKS_URL"`
+	JWKSRefreshInterval Duration `json:"jwks_refresh_interval" env:"JWKS_REFRESH_INTERVAL"`
+	// AdminBootstrapToken, when set, must accompany the request creating the first admin.
+	AdminBootstrapToken string `json:"admin_bootstrap_token" env:"ADMIN_BOOTSTRAP_TOKEN"`
+	// AdminSessionSecret signs the sessions issued at admin login. Left empty, a random one is
+	// used, so sessions end when the server restarts and are not shared between instances.
+	AdminSessionSecret string   `json:"admin_session_secret" env:"ADMIN_SESSION_SECRET"`
+	AdminSessionTTL    Duration `json:"admin_session_ttl" env:"ADMIN_SESSION_TTL"`
+}
+
+type CORS struct {
+	// AllowedOrigins is a list in the file and comma separated in the environment.
+	AllowedOrigins []string `json:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+}
+
+// Schedule holds the background jobs as standard five-field cron specs.
+type Schedule struct {
+	Rollover  string `json:"rollover" env:"SCHEDULE_ROLLOVER"`
+	Reconcile string `json:"reconcile" env:"SCHEDULE_RECONCILE"`
+	Purge     string `json:"purge" env:"SCHEDULE_PURGE"`
+	// IdempotencyPurge deletes the expired idempotency keys.
+	IdempotencyPurge string `json:"idempotency_purge" env:"SCHEDULE_IDEMPOTENCY_PURGE"`
+	// ReconcileMode is "report" to only log inconsistencies or "apply" to repair them too.
+	ReconcileMode           string `json:"reconcile_mode" env:"RECONCILE_MODE"`
+	SoftDeleteRetentionDays int    `json:"soft_delete_retention_days" env:"SOFT_DELETE_RETENTION_DAYS"`
+}
+
+// Slots describes a court's day: Count slots of Length each, the first starting at Opens.
+type Slots struct {
+	Opens  string   `json:"opens" env:"SLOTS_OPEN"`
+	Count  int      `json:"count" env:"SLOTS_COUNT"`
+	Length Duration `json:"length" env:"SLOTS_LENGTH"`
+}
+
+// Alternatives tries Radius slots either side of a taken slot and offers at most Limit of them;
+// a Limit of 0 offers none.
+type Alternatives struct {
+	Radius int `json:"radius" env:"ALTERNATIVES_RADIUS"`
+	Limit  int `json:"limit" env:"ALTERNATIVES_LIMIT"`
+}
+
+// Idempotency keeps the response to a request sent with an Idempotency-Key header for TTL.
+type Idempotency struct {
+	TTL Duration `json:"ttl" env:"IDEMPOTENCY_TTL"`
+}
+
+// Events sends a heartbeat on idle availability streams every Heartbeat and keeps the last
+// Backlog changes for clients that reconnect.
+type Events struct {
+	Heartbeat Duration `json:"heartbeat" env:"EVENTS_HEARTBEAT"`
+	Backlog   int      `json:"backlog" env:"EVENTS_BACKLOG"`
+}
+
+// Reconcile modes.
+const (
+	ReconcileReport = "report"
+	ReconcileApply  = "apply"
+)
+
+// Defaults returns the configuration used when neither the file nor the environment sets a value.
+func Defaults() Config {
+	return Config{
+		Database: Database{
+			Driver:          DataBase.DriverPostgres,
+			MaxOpenConns:    20,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: Duration(30 * time.Minute),
+			ConnMaxIdleTime: Duration(5 * time.Minute),
+			ConnectAttempts: 8,
+			ConnectBackoff:  Duration(time.Second),
+		},
+		// The read and write timeouts leave room for uploading and downloading backup bundles
+		Server: Server{
+			Port:              8080,
+			ReadTimeout:       Duration(30 * time.Second),
+			ReadHeaderTimeout: Duration(5 * time.Second),
+			WriteTimeout:      Duration(60 * time.Second),
+			IdleTimeout:       Duration(2 * time.Minute),
+			MaxHeaderBytes:    1 << 20,
+			ShutdownTimeout:   Duration(30 * time.Second),
+		},
+		Auth: Auth{JWKSRefreshInterval: Duration(time.Hour), AdminSessionTTL: Duration(12 * time.Hour)},
+		CORS: CORS{AllowedOrigins: []string{"*"}},
+		Schedule: Schedule{
+			Rollover:                "0 0 * * *",
+			Reconcile:               "30 0 * * *",
+			Purge:                   "0 1 * * *",
+			IdempotencyPurge:        "15 * * * *",
+			ReconcileMode:           ReconcileReport,
+			SoftDeleteRetentionDays: 30,
+		},
+		Slots:        Slots{Opens: "08:00", Count: 10, Length: Duration(time.Hour)},
+		Alternatives: Alternatives(Availability.DefaultAlternatives),
+		Idempotency:  Idempotency{TTL: Duration(24 * time.Hour)},
+		Events:       Events{Heartbeat: Duration(15 * time.Second), Backlog: 1000},
+	}
+}
+
+// defaultURLs are the local databases used when Database.URL is empty.
+var defaultURLs = map[string]string{
+	DataBase.DriverPostgres: "host=localhost user=postgres password=postgres dbname=courtlink port=5432 sslmode=disable",
+	DataBase.DriverSQLite:   "../CourtLink.db",
+}
+
+// Load builds the configuration from Defaults, the JSON file at path (skipped when path is
+// empty) and the variables found by lookup, then validates it. Unknown keys in the file and
+// malformed variables are errors, so a typo does not silently fall back to a default.
+func Load(path string, lookup func(name string) (string, bool)) (Config, error) {
+	config := Defaults()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return config, err
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&config); err != nil {
+			return config, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(reflect.ValueOf(&config).Elem(), lookup); err != nil {
+		return config, err
+	}
+	if config.Database.URL == "" {
+		config.Database.URL = defaultURLs[config.Database.Driver]
+	}
+	return config, config.Validate()
+}
+
+// FromEnvironment loads the configuration from the file named by CONFIG_FILE, if any, and
+// the process environment.
+func FromEnvironment() (Config, error) {
+	return Load(os.Getenv("CONFIG_FILE"), os.LookupEnv)
+}
+
+// Validate reports every invalid setting at once.
+func (c Config) Validate() error {
+	var problems []error
+	check := func(ok bool, setting, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Errorf("%s: %s", setting, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	db := c.Database
+	check(db.Driver == DataBase.DriverPostgres || db.Driver == DataBase.DriverSQLite,
+		"database.driver", "must be %q or %q, got %q", DataBase.DriverPostgres, DataBase.DriverSQLite, db.Driver)
+	check(db.MaxOpenConns >= 0, "database.max_open_conns", "must not be negative")
+	check(db.MaxIdleConns >= 0, "database.max_idle_conns", "must not be negative")
+	check(db.MaxOpenConns == 0 || db.MaxIdleConns <= db.MaxOpenConns,
+		"database.max_idle_conns", "must not exceed max_open_conns (%d)", db.MaxOpenConns)
+	check(db.ConnMaxLifetime >= 0, "database.conn_max_lifetime", "must not be negative")
+	check(db.ConnMaxIdleTime >= 0, "database.conn_max_idle_time", "must not be negative")
+	check(db.ConnectAttempts >= 1, "database.connect_attempts", "must be at least 1")
+	check(db.ConnectBackoff > 0, "database.connect_backoff", "must be positive")
+
+	s := c.Server
+	check(s.Port >= 1 && s.Port <= 65535, "server.port", "must be between 1 and 65535, got %d", s.Port)
+	check(s.ReadTimeout > 0, "server.read_timeout", "must be positive")
+	check(s.ReadHeaderTimeout > 0, "server.read_header_timeout", "must be positive")
+	check(s.WriteTimeout > 0, "server.write_timeout", "must be positive")
+	check(s.IdleTimeout > 0, "server.idle_timeout", "must be positive")
+	check(s.MaxHeaderBytes > 0, "server.max_header_bytes", "must be positive")
+	check(s.ShutdownTimeout > 0, "server.shutdown_timeout", "must be positive")
+
+	if c.Auth.JWKSURL != "" {
+		u, err := url.Parse(c.Auth.JWKSURL)
+		check(err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != "",
+			"auth.cognito_jwks_url", "must be an http(s) URL")
+	}
+	check(c.Auth.JWKSRefreshInterval >= Duration(time.Minute), "auth.jwks_refresh_interval", "must be at least 1m")
+	check(c.Auth.AdminSessionSecret == "" || len(c.Auth.AdminSessionSecret) >= 32,
+		"auth.admin_session_secret", "must be at least 32 characters")
+	check(c.Auth.AdminSessionTTL >= Duration(time.Minute), "auth.admin_session_ttl", "must be at least 1m")
+
+	check(len(c.CORS.AllowedOrigins) > 0, "cors.allowed_origins", "must list at least one origin")
+
+	for _, job := range []struct{ setting, spec string }{{"schedule.rollover", c.Schedule.Rollover},
+		{"schedule.reconcile", c.Schedule.Reconcile}, {"schedule.purge", c.Schedule.Purge},
+		{"schedule.idempotency_purge", c.Schedule.IdempotencyPurge}} {
+		_, err := cron.ParseStandard(job.spec)
+		check(err == nil, job.setting, "invalid cron spec %q: %v", job.spec, err)
+	}
+	check(c.Schedule.ReconcileMode == ReconcileReport || c.Schedule.ReconcileMode == ReconcileApply,
+		"schedule.reconcile_mode", "must be %q or %q, got %q", ReconcileReport, ReconcileApply, c.Schedule.ReconcileMode)
+	check(c.Schedule.SoftDeleteRetentionDays >= 1, "schedule.soft_delete_retention_days", "must be at least 1")
+
+	opens, err := time.Parse("15:04", c.Slots.Opens)
+	check(err == nil, "slots.opens", "must be a time such as 08:00, got %q", c.Slots.Opens)
+	check(c.Slots.Count >= 1, "slots.count", "must be at least 1")
+	check(c.Slots.Length >= Duration(time.Minute), "slots.length", "must be at least 1m")
+	if err == nil {
+		start := time.Duration(opens.Hour())*time.Hour + time.Duration(opens.Minute())*time.Minute
+		check(start+time.Duration(c.Slots.Count)*time.Duration(c.Slots.Length) <= 24*time.Hour,
+			"slots", "the last slot must end by midnight")
+	}
+
+	check(c.Alternatives.Radius >= 0, "alternatives.radius", "must not be negative")
+	check(c.Alternatives.Limit >= 0, "alternatives.limit", "must not be negative")
+
+	check(c.Idempotency.TTL >= Duration(time.Minute), "idempotency.ttl", "must be at least 1m")
+
+	check(c.Events.Heartbeat >= Duration(time.Second), "events.heartbeat", "must be at least 1s")
+	check(c.Events.Backlog >= 0, "events.backlog", "must not be negative")
+
+	return errors.Join(problems...)
+}
+
+// Labels names each slot of the day, such as "08:00 - 09:00". The configuration must be valid.
+func (s Slots) Labels() []string {
+	opens, _ := time.Parse("15:04", s.Opens)
+	labels := make([]string, s.Count)
+	for i := range labels {
+		start := opens.Add(time.Duration(i) * time.Duration(s.Length))
+		labels[i] = start.Format("15:04") + " - " + start.Add(time.Duration(s.Length)).Format("15:04")
+	}
+	return labels
+}
+
+// Options returns the bounds of the alternatives offered.
+func (a Alternatives) Options() Availability.AlternativeOptions {
+	return Availability.AlternativeOptions(a)
+}
+
+// Pool returns the connection pool settings.
+func (d Database) Pool() DataBase.PoolConfig {
+	return DataBase.PoolConfig{
+		MaxOpenConns:    d.MaxOpenConns,
+		MaxIdleConns:    d.MaxIdleConns,
+		ConnMaxLifetime: time.Duration(d.ConnMaxLifetime),
+		ConnMaxIdleTime: time.Duration(d.ConnMaxIdleTime),
+	}
+}
+
+// Retry returns how to retry the first connection. Waits between attempts stop growing at 30s.
+func (d Database) Retry() DataBase.RetryConfig {
+	return DataBase.RetryConfig{
+		Attempts:   d.ConnectAttempts,
+		Backoff:    time.Duration(d.ConnectBackoff),
+		MaxBackoff: 30 * time.Second,
+	}
+}
+
+// HTTP returns the HTTP server settings.
+func (s Server) HTTP() httpserver.Config {
+	return httpserver.Config{
+		Port:              s.Port,
+		ReadTimeout:       time.Duration(s.ReadTimeout),
+		ReadHeaderTimeout: time.Duration(s.ReadHeaderTimeout),
+		WriteTimeout:      time.Duration(s.WriteTimeout),
+		IdleTimeout:       time.Duration(s.IdleTimeout),
+		MaxHeaderBytes:    s.MaxHeaderBytes,
+		ShutdownTimeout:   time.Duration(s.ShutdownTimeout),
+	}
+}
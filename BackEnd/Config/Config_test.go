@@ -0,0 +1,180 @@
+package Config
+
+import (
+	"BackEnd/DataBase"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// env returns a lookup over the given variables only.
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	config, err := Load("", env(nil))
+	if err != nil {
+		t.Fatalf("expected the defaults to be valid, got %v", err)
+	}
+	want := Defaults()
+	want.Database.URL = defaultURLs[DataBase.DriverPostgres]
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("expected the defaults, got %+v", config)
+	}
+	if !reflect.DeepEqual(config.Slots.Labels(), DataBase.SlotLabels) {
+		t.Errorf("expected the default slots to match the built-in day, got %v", config.Slots.Labels())
+	}
+}
+
+func TestLoad_FileThenEnvironment(t *testing.T) {
+	path := writeFile(t, `{
+		"database": {"driver": "sqlite", "conn_max_lifetime": "10m"},
+		"server": {"port": 9000, "write_timeout": "2m"},
+		"cors": {"allowed_origins": ["https://courtlink.example"]},
+		"slots": {"opens": "09:30", "count": 4, "length": "30m"}
+	}`)
+	config, err := Load(path, env(map[string]string{
+		"PORT":                 "9100",
+		"CORS_ALLOWED_ORIGINS": "https://a.example, https://b.example,",
+		"RECONCILE_MODE":       "apply",
+		"DB_CONNECT_BACKOFF":   "",
+	}))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if config.Server.Port != 9100 || config.Server.WriteTimeout != Duration(2*time.Minute) {
+		t.Errorf("expected the port from the environment and the timeout from the file, got %+v", config.Server)
+	}
+	if config.Database.Driver != DataBase.DriverSQLite || config.Database.URL != defaultURLs[DataBase.DriverSQLite] ||
+		config.Database.ConnMaxLifetime != Duration(10*time.Minute) {
+		t.Errorf("expected the file's database settings with the SQLite default URL, got %+v", config.Database)
+	}
+	if config.Database.ConnectBackoff != Defaults().Database.ConnectBackoff {
+		t.Errorf("expected an empty variable to be ignored, got %s", config.Database.ConnectBackoff)
+	}
+	if want := []string{"https://a.example", "https://b.example"}; !reflect.DeepEqual(config.CORS.AllowedOrigins, want) {
+		t.Errorf("expected origins %v, got %v", want, config.CORS.AllowedOrigins)
+	}
+	if config.Schedule.ReconcileMode != ReconcileApply || config.Schedule.Rollover != Defaults().Schedule.Rollover {
+		t.Errorf("unexpected schedule %+v", config.Schedule)
+	}
+	if want := []string{"09:30 - 10:00", "10:00 - 10:30", "10:30 - 11:00", "11:00 - 11:30"}; !reflect.DeepEqual(config.Slots.Labels(), want) {
+		t.Errorf("expected slots %v, got %v", want, config.Slots.Labels())
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	cases := []struct {
+		name     string
+		file     string
+		vars     map[string]string
+		problems []string
+	}{
+		{"Unknown file key", `{"server": {"prot": 80}}`, nil, []string{`unknown field "prot"`}},
+		{"Duration as a number", `{"server": {"idle_timeout": 30}}`, nil, []string{"durations are strings"}},
+		{"Malformed variables", "", map[string]string{"PORT": "eighty", "SHUTDOWN_TIMEOUT": "soon"},
+			[]string{`PORT: invalid number "eighty"`, `SHUTDOWN_TIMEOUT: invalid duration "soon"`}},
+		{"Every invalid setting", "", map[string]string{
+			"DB_DRIVER": "mysql", "PORT": "70000", "DB_MAX_IDLE_CONNS": "50", "COGNITO_JWKS_URL": "not a url",
+			"SCHEDULE_PURGE": "at one", "RECONCILE_MODE": "fix", "SOFT_DELETE_RETENTION_DAYS": "0",
+			"SLOTS_OPEN": "8am", "CORS_ALLOWED_ORIGINS": ",", "ALTERNATIVES_RADIUS": "-1",
+			"IDEMPOTENCY_TTL": "30s", "EVENTS_HEARTBEAT": "10ms", "ADMIN_SESSION_SECRET": "short", "ADMIN_SESSION_TTL": "0s",
+		}, []string{"database.driver", "database.max_idle_conns", "server.port", "auth.cognito_jwks_url",
+			"auth.admin_session_secret", "auth.admin_session_ttl",
+			"cors.allowed_origins", "schedule.purge", "schedule.reconcile_mode", "schedule.soft_delete_retention_days",
+			"slots.opens", "alternatives.radius", "idempotency.ttl", "events.heartbeat"}},
+		{"Day past midnight", "", map[string]string{"SLOTS_OPEN": "20:00", "SLOTS_COUNT": "5"}, []string{"slots: the last slot must end by midnight"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := ""
+			if tc.file != "" {
+				path = writeFile(t, tc.file)
+			}
+			_, err := Load(path, env(tc.vars))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, problem := range tc.problems {
+				if !strings.Contains(err.Error(), problem) {
+					t.Errorf("expected %q in:\n%v", problem, err)
+				}
+			}
+			if lines := strings.Count(err.Error(), "\n") + 1; len(tc.problems) > 1 && lines != len(tc.problems) {
+				t.Errorf("expected %d problems, got %d:\n%v", len(tc.problems), lines, err)
+			}
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cases := []struct{ url, want string }{
+		{"host=db user=app password=s3cret dbname=courtlink", "host=db user=app password=[redacted] dbname=courtlink"},
+		{"host=db password = 'two words' dbname=x", "host=db password = [redacted] dbname=x"},
+		{"postgres://app:s3cret@db:5432/courtlink?sslmode=disable", "postgres://app:%5Bredacted%5D@db:5432/courtlink?sslmode=disable"},
+		{"postgres://app@db/courtlink", "postgres://app@db/courtlink"},
+		{"../CourtLink.db", "../CourtLink.db"},
+	}
+	for _, tc := range cases {
+		config := Defaults()
+		config.Database.URL = tc.url
+		if got := config.Redacted().Database.URL; got != tc.want {
+			t.Errorf("redacting %q: expected %q, got %q", tc.url, tc.want, got)
+		}
+	}
+
+	config := Defaults()
+	config.Auth.AdminBootstrapToken = "let-me-in"
+	if config.Redacted().Auth.AdminBootstrapToken != redacted || config.Auth.AdminBootstrapToken != "let-me-in" {
+		t.Error("expected the token to be redacted in a copy only")
+	}
+	config.Auth.AdminSessionSecret = strings.Repeat("k", 32)
+	if config.Redacted().Auth.AdminSessionSecret != redacted {
+		t.Error("expected the session secret to be redacted")
+	}
+}
+
+func TestRun(t *testing.T) {
+	config := Defaults()
+	config.Database.URL = "host=db password=s3cret"
+	config.Auth.AdminBootstrapToken = "let-me-in"
+
+	var out bytes.Buffer
+	if err := Run(config, []string{"print"}, &out); err != nil {
+		t.Fatalf("config print failed: %v", err)
+	}
+	if strings.Contains(out.String(), "s3cret") || strings.Contains(out.String(), "let-me-in") {
+		t.Errorf("expected secrets to be redacted:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), `"shutdown_timeout": "30s"`) {
+		t.Errorf("expected durations to print as strings:\n%s", out.String())
+	}
+
+	// The printed configuration loads back as a file
+	reloaded, err := Load(writeFile(t, out.String()), env(nil))
+	if err != nil || reloaded.Server != config.Server || reloaded.Schedule != config.Schedule {
+		t.Errorf("expected the printed configuration to load back, got %v", err)
+	}
+
+	if err := Run(config, []string{"edit"}, &out); err == nil {
+		t.Error("expected an unknown command to fail")
+	}
+}
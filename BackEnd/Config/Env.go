@@ -0,0 +1,91 @@
+package Config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is a time.Duration written as a string such as "30s" in the file, the environment
+// and the printed configuration.
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return errors.New(`durations are strings such as "30s" or "5m"`)
+	}
+	return d.parse(raw)
+}
+
+func (d *Duration) parse(raw string) error {
+	parsed, err := time.ParseDuration(raw)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q", raw)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+var durationType = reflect.TypeOf(Duration(0))
+
+// applyEnv overrides the fields of the struct v whose env variable is set and not empty,
+// descending into nested sections.
+func applyEnv(v reflect.Value, lookup func(name string) (string, bool)) error {
+	var errs []error
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			errs = append(errs, applyEnv(value, lookup))
+			continue
+		}
+		name := field.Tag.Get("env")
+		raw, ok := lookup(name)
+		if name == "" || !ok || raw == "" {
+			continue
+		}
+		if err := setFromEnv(value, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func setFromEnv(value reflect.Value, raw string) error {
+	if value.Type() == durationType {
+		return value.Addr().Interface().(*Duration).parse(raw)
+	}
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		value.SetInt(int64(n))
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", value.Type())
+	}
+	return nil
+}
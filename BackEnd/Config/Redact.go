@@ -0,0 +1,35 @@
+package Config
+
+import (
+	"net/url"
+	"regexp"
+)
+
+const redacted = "[redacted]"
+
+// dsnPassword matches the password of a key=value Postgres connection string.
+var dsnPassword = regexp.MustCompile(`(password\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+
+// Redacted returns a copy of c that is safe to log: the bootstrap token is hidden and so is
+// any password in the database URL.
+func (c Config) Redacted() Config {
+	if c.Auth.AdminBootstrapToken != "" {
+		c.Auth.AdminBootstrapToken = redacted
+	}
+	if c.Auth.AdminSessionSecret != "" {
+		c.Auth.AdminSessionSecret = redacted
+	}
+	c.Database.URL = redactURL(c.Database.URL)
+	return c
+}
+
+// redactURL hides the password of a connection string in either the URL or key=value form.
+func redactURL(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+			return u.String()
+		}
+	}
+	return dsnPassword.ReplaceAllString(dsn, "${1}"+redacted)
+}
@@ -0,0 +1,149 @@
+package Court
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/Availability"
+	"BackEnd/DataBase"
+	"BackEnd/Events"
+	"BackEnd/Repository"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type BlackoutRequest struct {
+	Court_ID      uint   `json:"Court_ID"`
+	Blackout_Date string `json:"Blackout_Date"` // YYYY-MM-DD, defaults to today
+	Slot_Index    *int   `json:"Slot_Index"`    // omit to block the whole day
+	Reason        string `json:"Reason"`
+}
+
+// CreateBlackout godoc
+// @Summary      Black out a court
+// @Description  Takes a court out of service for one slot, or the whole day when Slot_Index is omitted. Blacked out slots show as unavailable (0).
+// @Tags         courts
+// @Accept       json
+// @Produce      json
+// @Param        blackout  body      BlackoutRequest  true  "Blackout"
+// @Success      201       {object}  DataBase.Court_Blackout
+// @Failure      400       {object}  DataBase.ErrorResponse  "Invalid date or slot index"
+// @Failure      404       {object}  DataBase.ErrorResponse  "Court not found"
+// @Failure      500       {object}  DataBase.ErrorResponse  "Failed to create blackout"
+// @Router       /admin/blackouts [post]
+func (h *Handler) CreateBlackout(w http.ResponseWriter, r *http.Request) {
+	var req BlackoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		APIError.Malformed(w, r, "Invalid request body")
+		return
+	}
+
+	court, err := h.Store.Courts().FindByID(req.Court_ID)
+	if err != nil {
+		APIError.Write(w, r, http.StatusNotFound, APIError.CourtNotFound, "Court not found")
+		return
+	}
+
+	date := *DataBase.BookingDate(time.Now())
+	if req.Blackout_Date != "" {
+		if _, err := time.Parse(DataBase.BookingDateLayout, req.Blackout_Date); err != nil {
+			APIError.Validation(w, r, "Blackout_Date must be formatted as YYYY-MM-DD", APIError.Field("Blackout_Date", "must be formatted as YYYY-MM-DD"))
+			return
+		}
+		date = req.Blackout_Date
+	}
+	if req.Slot_Index != nil && !Availability.ValidSlot(*req.Slot_Index) {
+		APIError.Validation(w, r, "Invalid Slot_Index", APIError.Field("Slot_Index", "must be one of the day's slots"))
+		return
+	}
+
+	blackout := DataBase.Court_Blackout{
+		Court_ID:      req.Court_ID,
+		Blackout_Date: date,
+		Slot_Index:    req.Slot_Index,
+		Reason:        req.Reason,
+	}
+	if err := h.Store.Blackouts().Create(&blackout); err != nil {
+		APIError.Internal(w, r, "Failed to create blackout", err)
+		return
+	}
+	h.Events.Publish(Events.ForBlackout(Events.BlackedOut, blackout, court))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(blackout)
+}
+
+// ListBlackouts godoc
+// @Summary      List court blackouts
+// @Description  Lists blackouts, optionally for one court and/or one date.
+// @Tags         courts
+// @Produce      json
+// @Param        court_id  query     int     false  "Court ID"
+// @Param        date      query     string  false  "Date (YYYY-MM-DD)"
+// @Success      200       {array}   DataBase.Court_Blackout
+// @Failure      400       {object}  DataBase.ErrorResponse  "Invalid court_id"
+// @Failure      500       {object}  DataBase.ErrorResponse  "Failed to list blackouts"
+// @Router       /admin/blackouts [get]
+func (h *Handler) ListBlackouts(w http.ResponseWriter, r *http.Request) {
+	var courtIDs []uint
+	if raw := r.URL.Query().Get("court_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			APIError.Validation(w, r, "Invalid court_id", APIError.Field("court_id", "must be a court ID"))
+			return
+		}
+		courtIDs = []uint{uint(id)}
+	}
+
+	blackouts, err := h.Store.Blackouts().List(courtIDs, r.URL.Query().Get("date"))
+	if err != nil {
+		APIError.Internal(w, r, "Failed to list blackouts", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(blackouts)
+}
+
+// DeleteBlackout godoc
+// @Summary      Remove a court blackout
+// @Description  Deletes a blackout, making its slots bookable again unless they are booked.
+// @Tags         courts
+// @Produce      json
+// @Param        id   path      int  true  "Blackout ID"
+// @Success      200  {object}  map[string]string  "Blackout removed"
+// @Failure      400  {object}  DataBase.ErrorResponse  "Invalid blackout ID"
+// @Failure      404  {object}  DataBase.ErrorResponse  "Blackout not found"
+// @Failure      500  {object}  DataBase.ErrorResponse  "Failed to delete blackout"
+// @Router       /admin/blackouts/{id} [delete]
+func (h *Handler) DeleteBlackout(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		APIError.Malformed(w, r, "Invalid blackout ID")
+		return
+	}
+
+	blackout, err := h.Store.Blackouts().FindByID(uint(id))
+	if errors.Is(err, Repository.ErrNotFound) {
+		APIError.Write(w, r, http.StatusNotFound, APIError.BlackoutNotFound, "Blackout not found")
+		return
+	} else if err != nil {
+		APIError.Internal(w, r, "Failed to delete blackout", err)
+		return
+	}
+
+	if err := h.Store.Blackouts().Delete(uint(id)); err != nil {
+		APIError.Internal(w, r, "Failed to delete blackout", err)
+		return
+	}
+	// The court may be deleted already; the change then concerns the court alone
+	court, _ := h.Store.Courts().FindByID(blackout.Court_ID)
+	h.Events.Publish(Events.ForBlackout(Events.BlackoutLifted, blackout, court))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Blackout removed"})
+}
@@ -0,0 +1,106 @@
+package Court
+
+import (
+	"BackEnd/Availability"
+	"BackEnd/DataBase"
+	"BackEnd/Events"
+	"BackEnd/Repository/TestStore"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestBlackouts(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+	h.Events = Events.NewBroker(Events.DefaultBacklog)
+	changes := h.Events.Subscribe(Events.Filter{}, "")
+	defer changes.Close()
+
+	court := DataBase.Court{Court_Name: "Court A", Court_Location: "Downtown", Court_Status: 1, Sport_id: 1}
+	if err := store.Courts().Create(&court); err != nil {
+		t.Fatalf("failed to create court: %v", err)
+	}
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/admin/blackouts", bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		h.CreateBlackout(rr, req)
+		return rr
+	}
+
+	// Invalid requests are rejected
+	if rr := post(`{"Court_ID": 999}`); rr.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for a missing court, got %d", http.StatusNotFound, rr.Code)
+	}
+	rr := post(fmt.Sprintf(`{"Court_ID": %d, "Blackout_Date": "05/01/2026"}`, court.Court_ID))
+	var invalid DataBase.ErrorResponse
+	json.Unmarshal(rr.Body.Bytes(), &invalid)
+	if rr.Code != http.StatusBadRequest || invalid.Code != "VALIDATION_FAILED" ||
+		len(invalid.Details) != 1 || invalid.Details[0].Field != "Blackout_Date" {
+		t.Errorf("expected a validation error on Blackout_Date, got %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := post(fmt.Sprintf(`{"Court_ID": %d, "Slot_Index": 10}`, court.Court_ID)); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for a bad slot, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	// A whole-day blackout defaults to today and makes every slot unavailable
+	rr = post(fmt.Sprintf(`{"Court_ID": %d, "Reason": "Resurfacing"}`, court.Court_ID))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	var created DataBase.Court_Blackout
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	today := *DataBase.BookingDate(time.Now())
+	if created.Blackout_Date != today || created.Slot_Index != nil {
+		t.Errorf("unexpected blackout: %+v", created)
+	}
+	if change := <-changes.C; change.Kind != Events.BlackedOut || change.CourtID != court.Court_ID || change.Date != today || change.Slot != nil {
+		t.Errorf("expected the blackout to be published, got %+v", change)
+	}
+	slots, _ := Availability.ForCourts(store, []uint{court.Court_ID}, today)
+	for i, slot := range slots[court.Court_ID] {
+		if slot != DataBase.SlotUnavailable {
+			t.Errorf("expected slot %d to be unavailable, got %d", i, slot)
+		}
+	}
+
+	// Listing filters by court and date
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/admin/blackouts?court_id=%d&date=%s", court.Court_ID, today), nil)
+	rr = httptest.NewRecorder()
+	h.ListBlackouts(rr, req)
+	var listed []DataBase.Court_Blackout
+	json.NewDecoder(rr.Body).Decode(&listed)
+	if rr.Code != http.StatusOK || len(listed) != 1 {
+		t.Errorf("expected one blackout, got status %d and %d blackouts", rr.Code, len(listed))
+	}
+
+	// Deleting it frees the court again
+	req, _ = http.NewRequest("DELETE", fmt.Sprintf("/admin/blackouts/%d", created.Blackout_ID), nil)
+	req = mux.SetURLVars(req, map[string]string{"id": fmt.Sprint(created.Blackout_ID)})
+	rr = httptest.NewRecorder()
+	h.DeleteBlackout(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if slot, _ := Availability.Slot(store, court.Court_ID, 0, today); slot != DataBase.SlotAvailable {
+		t.Errorf("expected slot to be available after removing the blackout, got %d", slot)
+	}
+	if change := <-changes.C; change.Kind != Events.BlackoutLifted || change.CourtID != court.Court_ID || change.SportID != 1 {
+		t.Errorf("expected the lifted blackout to be published, got %+v", change)
+	}
+
+	rr = httptest.NewRecorder()
+	h.DeleteBlackout(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for a deleted blackout, got %d", http.StatusNotFound, rr.Code)
+	}
+}
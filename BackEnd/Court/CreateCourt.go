@@ -1,8 +1,11 @@
 package Court
 
 import (
+	"BackEnd/APIError"
 	"BackEnd/DataBase"
+	"BackEnd/Repository"
 	"encoding/json"
+	"errors"
 	"net/http"
 )
 
@@ -18,36 +21,35 @@ type CourtRequest struct {
 	Court_Location string `json:"Court_Location"`
 	Court_Capacity *int   `json:"Court_Capacity"`
 	Court_Status   int    `json:"Court_Status"`
+	Court_Indoor   bool   `json:"Court_Indoor"`
 	Sport_name     string `json:"Sport_name"`
 }
 
 // CreateCourtWithTimeSlots godoc
-// @Summary Create a new court with associated time slots
-// @Description Creates a new court and assigns time slots for bookings
+// @Summary Create a new court
+// @Description Creates a new court whose daily time slots are open for booking
 // @Tags courts
 // @Accept json
 // @Produce json
 // @Param court body DataBase.Court true "Court data"
 // @Success 201 {object} CourtCreationResponse "Court created successfully"
-// @Failure 400 {object} map[string]string "Invalid request body"
-// @Failure 500 {object} map[string]string "Failed to create court"
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid request body"
+// @Failure 500 {object} DataBase.ErrorResponse "Failed to create court"
+// @Deprecated
 // @Router /CreateCourt [post]
-func CreateCourtWithTimeSlots(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateCourtWithTimeSlots(w http.ResponseWriter, r *http.Request) {
 	var c DataBase.Court
 	var requestData CourtRequest
 
 	err := json.NewDecoder(r.Body).Decode(&requestData)
 	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		APIError.Malformed(w, r, "Invalid request body")
 		return
 	}
 
-	var sport DataBase.Sport
-	err = DataBase.DB.Where("\"Sport_name\" = ?", requestData.Sport_name).First(&sport).Error
+	sport, err := h.Store.Sports().FindByName(requestData.Sport_name)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Sport not found"})
+		APIError.Write(w, r, http.StatusNotFound, APIError.SportNotFound, "Sport not found")
 		return
 	}
 
@@ -55,42 +57,15 @@ func CreateCourtWithTimeSlots(w http.ResponseWriter, r *http.Request) {
 	c.Court_Location = requestData.Court_Location
 	c.Court_Capacity = requestData.Court_Capacity
 	c.Sport_id = sport.Sport_ID
+	c.Court_Status = requestData.Court_Status
+	c.Court_Indoor = requestData.Court_Indoor
 
-	if requestData.Court_Status == 0 {
-		c.Court_Status = 1
-	} else {
-		c.Court_Status = requestData.Court_Status
-	}
-
-	var existingCourt DataBase.Court
-	result := DataBase.DB.Where("Court_Name = ?", c.Court_Name).First(&existingCourt)
-	if result.RowsAffected > 0 {
-		http.Error(w, "The court record already exists", http.StatusBadRequest)
+	err = Create(h.Store, &c)
+	if errors.Is(err, Repository.ErrDuplicate) {
+		APIError.Write(w, r, http.StatusBadRequest, APIError.CourtAlreadyExists, "The court record already exists")
 		return
-	}
-
-	if err := DataBase.DB.Create(&c).Error; err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	courtTimeSlots := DataBase.Court_TimeSlots{
-		Court_ID:   c.Court_ID,
-		Slot_08_09: 1,
-		Slot_09_10: 1,
-		Slot_10_11: 1,
-		Slot_11_12: 1,
-		Slot_12_13: 1,
-		Slot_13_14: 1,
-		Slot_14_15: 1,
-		Slot_15_16: 1,
-		Slot_16_17: 1,
-		Slot_17_18: 1,
-		Court_Name: c.Court_Name,
-	}
-
-	if err := DataBase.DB.Create(&courtTimeSlots).Error; err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	} else if err != nil {
+		APIError.Internal(w, r, "Failed to create court", err)
 		return
 	}
 
@@ -98,8 +73,21 @@ func CreateCourtWithTimeSlots(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	response := CourtCreationResponse{
-		Message: "Court record and TimeSlots added successfully!!",
+		Message: "Court record added successfully!!",
 		Court:   c,
 	}
 	json.NewEncoder(w).Encode(response)
 }
+
+// Create adds a court, open (Court_Status 1) unless another status is given. It returns
+// Repository.ErrDuplicate if the name is taken. Every court has the same daily slots; their
+// availability comes from bookings and blackouts.
+func Create(store Repository.Store, court *DataBase.Court) error {
+	if court.Court_Status == 0 {
+		court.Court_Status = 1
+	}
+	if _, err := store.Courts().FindByName(court.Court_Name); err == nil {
+		return Repository.ErrDuplicate
+	}
+	return store.Courts().Create(court)
+}
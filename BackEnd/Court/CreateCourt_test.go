@@ -2,31 +2,24 @@ package Court
 
 import (
 	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"BackEnd/Repository/TestStore"
 	"bytes"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
-
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 )
 
-func setupTestDBForCreateCourt() *gorm.DB {
-	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
-	if err != nil {
-		panic("failed to connect to the database")
-	}
-
-	db.AutoMigrate(&DataBase.Sport{}, &DataBase.Court{}, &DataBase.Court_TimeSlots{})
-
-	db.Create(&DataBase.Sport{Sport_ID: 1, Sport_name: "Tennis", Sport_Description: "Tennis Sport"})
-
-	return db
+func setupTestStoreForCreateCourt(t *testing.T) Repository.Store {
+	store := TestStore.Open(t)
+	store.Sports().Create(&DataBase.Sport{Sport_ID: 1, Sport_name: "Tennis", Sport_Description: "Tennis Sport"})
+	return store
 }
 
 func TestCreateCourtWithTimeSlots(t *testing.T) {
-	DataBase.DB = setupTestDBForCreateCourt()
+	store := setupTestStoreForCreateCourt(t)
+	h := NewHandler(store)
 
 	courtRequest := map[string]interface{}{
 		"Court_Name":     "Court A",
@@ -44,7 +37,7 @@ func TestCreateCourtWithTimeSlots(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	recorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(CreateCourtWithTimeSlots)
+	handler := http.HandlerFunc(h.CreateCourtWithTimeSlots)
 	handler.ServeHTTP(recorder, req)
 
 	t.Logf("Response Body: %s", recorder.Body.String())
@@ -56,31 +49,17 @@ func TestCreateCourtWithTimeSlots(t *testing.T) {
 	var response map[string]interface{}
 	json.Unmarshal(recorder.Body.Bytes(), &response)
 
-	if response["message"] != "Court record and TimeSlots added successfully!!" {
+	if response["message"] != "Court record added successfully!!" {
 		t.Errorf("unexpected response message: %v", response["message"])
 	}
 
-	var savedCourt DataBase.Court
-	result := DataBase.DB.First(&savedCourt, "court_name = ?", "Court A")
-
-	if result.Error != nil {
-		t.Errorf("Court not found in database: %v", result.Error)
-	}
-
-	var savedTimeSlots DataBase.Court_TimeSlots
-	result = DataBase.DB.First(&savedTimeSlots, "court_id = ?", savedCourt.Court_ID)
-
-	if result.Error != nil {
-		t.Errorf("Court timeslots not found in database: %v", result.Error)
+	if _, err := store.Courts().FindByName("Court A"); err != nil {
+		t.Fatalf("Court not found in store: %v", err)
 	}
 }
 
 func TestCreateCourtWithInvalidSport(t *testing.T) {
-	var err error
-	DataBase.DB, err = setupTestDB()
-	if err != nil {
-		t.Fatalf("failed to set up test database: %v", err)
-	}
+	h := NewHandler(setupTestStoreForCreateCourt(t))
 
 	courtRequest := map[string]interface{}{
 		"Court_Name":     "Court B",
@@ -95,20 +74,16 @@ func TestCreateCourtWithInvalidSport(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	recorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(CreateCourtWithTimeSlots)
+	handler := http.HandlerFunc(h.CreateCourtWithTimeSlots)
 	handler.ServeHTTP(recorder, req)
 
-	if status := recorder.Code; status != http.StatusBadRequest {
-		t.Errorf("expected status %d, got %d", http.StatusBadRequest, status)
+	if status := recorder.Code; status != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, status)
 	}
 }
 
 func TestCreateDuplicateCourt(t *testing.T) {
-	var err error
-	DataBase.DB, err = setupTestDB()
-	if err != nil {
-		t.Fatalf("failed to set up test database: %v", err)
-	}
+	h := NewHandler(setupTestStoreForCreateCourt(t))
 
 	courtRequest := map[string]interface{}{
 		"Court_Name":     "Court C",
@@ -123,7 +98,7 @@ func TestCreateDuplicateCourt(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	recorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(CreateCourtWithTimeSlots)
+	handler := http.HandlerFunc(h.CreateCourtWithTimeSlots)
 	handler.ServeHTTP(recorder, req)
 
 	recorder = httptest.NewRecorder()
@@ -1,55 +1,43 @@
 package Court
 
 import (
-	"BackEnd/DataBase"
+	"BackEnd/APIError"
+	"BackEnd/Repository"
 	"encoding/json"
 	"net/http"
 )
 
 // DeleteCourt godoc
 // @Summary      Delete a court record
-// @Description  Deletes a court record from the database based on the court name.
+// @Description  Soft deletes a court and its bookings based on the court name. They can be restored by an admin until purged.
 // @Tags         courts
 // @Accept       json
 // @Produce      json
 // @Param        court_name  query     string  true  "Court Name to be deleted"
 // @Success      200  {object}  map[string]string  "Court deleted successfully"
-// @Failure      400  {object}  map[string]string  "Invalid court name"
-// @Failure      404  {object}  map[string]string  "Court not found"
-// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Failure      400  {object}  DataBase.ErrorResponse  "Invalid court name"
+// @Failure      404  {object}  DataBase.ErrorResponse  "Court not found"
+// @Failure      500  {object}  DataBase.ErrorResponse  "Internal server error"
+// @Deprecated
 // @Router       /DeleteCourt [delete]
-func DeleteCourt(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) DeleteCourt(w http.ResponseWriter, r *http.Request) {
 	var requestData struct {
 		Court_Name string `json:"Court_Name"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid request body"})
+		APIError.Malformed(w, r, "Invalid request body")
 		return
 	}
 
-	var court DataBase.Court
-	result := DataBase.DB.Where("Court_Name = ?", requestData.Court_Name).First(&court)
-	if result.RowsAffected == 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Court not found"})
+	court, err := h.Store.Courts().FindByName(requestData.Court_Name)
+	if err != nil {
+		APIError.Write(w, r, http.StatusNotFound, APIError.CourtNotFound, "Court not found")
 		return
 	}
 
-	if err := DataBase.DB.Where("Court_ID = ?", court.Court_ID).Delete(&DataBase.Court_TimeSlots{}).Error; err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to delete court time slots"})
-		return
-	}
-
-	if err := DataBase.DB.Delete(&court).Error; err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to delete court"})
+	if err := Delete(h.Store, court.Court_ID); err != nil {
+		APIError.Internal(w, r, "Failed to delete court", err)
 		return
 	}
 
@@ -58,3 +46,14 @@ func DeleteCourt(w http.ResponseWriter, r *http.Request) {
 	response := map[string]string{"message": "Court deleted successfully"}
 	json.NewEncoder(w).Encode(response)
 }
+
+// Delete removes a court's blackouts and soft deletes the court together with its bookings,
+// in a single transaction.
+func Delete(store Repository.Store, courtID uint) error {
+	return store.Transaction(func(tx Repository.Store) error {
+		if err := tx.Blackouts().DeleteByCourt(courtID); err != nil {
+			return err
+		}
+		return tx.Courts().Delete(courtID)
+	})
+}
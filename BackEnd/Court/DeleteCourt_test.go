@@ -2,32 +2,18 @@ package Court
 
 import (
 	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
 	"bytes"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
-
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 )
 
-func setupTestDBForDeleteCourt() *gorm.DB {
-
-	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
-	if err != nil {
-		panic("failed to connect to the database")
-	}
-
-	db.AutoMigrate(&DataBase.Court{}, &DataBase.Court_TimeSlots{})
-	return db
-}
-
 func TestDeleteCourt(t *testing.T) {
 
-	db := setupTestDBForDeleteCourt()
-
-	DataBase.DB = db
+	store := TestStore.Open(t)
+	h := NewHandler(store)
 
 	court := DataBase.Court{
 		Court_Name:     "Court A",
@@ -35,15 +21,13 @@ func TestDeleteCourt(t *testing.T) {
 		Court_Status:   1,
 		Sport_id:       1,
 	}
-	if err := DataBase.DB.Create(&court).Error; err != nil {
+	if err := store.Courts().Create(&court); err != nil {
 		t.Fatalf("failed to create court: %v", err)
 	}
 
-	courtTimeSlots := DataBase.Court_TimeSlots{
-		Court_ID: court.Court_ID,
-	}
-	if err := DataBase.DB.Create(&courtTimeSlots).Error; err != nil {
-		t.Fatalf("failed to create court time slots: %v", err)
+	blackout := DataBase.Court_Blackout{Court_ID: court.Court_ID, Blackout_Date: "2026-05-01"}
+	if err := store.Blackouts().Create(&blackout); err != nil {
+		t.Fatalf("failed to create court blackout: %v", err)
 	}
 
 	requestData := map[string]string{
@@ -56,7 +40,7 @@ func TestDeleteCourt(t *testing.T) {
 	}
 
 	recorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(DeleteCourt)
+	handler := http.HandlerFunc(h.DeleteCourt)
 	handler.ServeHTTP(recorder, req)
 
 	if status := recorder.Code; status != http.StatusOK {
@@ -67,4 +51,11 @@ func TestDeleteCourt(t *testing.T) {
 	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
 		t.Errorf("failed to decode response body: %v", err)
 	}
+
+	if _, err := store.Courts().FindByID(court.Court_ID); err == nil {
+		t.Errorf("expected court to be deleted")
+	}
+	if blackouts, _ := store.Blackouts().List([]uint{court.Court_ID}, ""); len(blackouts) != 0 {
+		t.Errorf("expected court blackouts to be deleted, got %d", len(blackouts))
+	}
 }
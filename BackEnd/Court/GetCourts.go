@@ -1,103 +1,71 @@
 package Court
 
 import (
+	"BackEnd/APIError"
+	"BackEnd/Availability"
+	"BackEnd/Caching"
 	"BackEnd/DataBase"
+	"BackEnd/Repository"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // GetCourt retrieves available courts for a given sport.
 //
 // @Summary Get court availability
-// @Description Fetches courts based on the selected sport and provides their availability status along with time slots.
+// @Description Fetches courts based on the selected sport with today's time slots: 1 available, 2 booked, 0 blacked out.
+// @Description The response carries an ETag; send it back in If-None-Match to get 304 while nothing has changed.
 // @Tags courts
 // @Accept  json
 // @Produce  json
 // @Param  sport query string true "Sport name"
+// @Param  If-None-Match header string false "ETag of the availability already held"
 // @Success 200 {array} DataBase.CourtAvailability "List of available courts with time slots"
+// @Success 304 "Availability has not changed since the ETag in If-None-Match"
 // @Failure 400 {object} DataBase.ErrorResponse "Missing 'sport' query parameter"
 // @Failure 404 {object} DataBase.ErrorResponse "Sport not found or no courts available"
+// @Failure 500 {object} DataBase.ErrorResponse "Failed to load court availability"
+// @Deprecated
 // @Router /getCourts [get]
-func GetCourt(w http.ResponseWriter, r *http.Request) {
-	var sport DataBase.Sport
-	type CourtInfo struct {
-		CourtID       uint   `gorm:"column:Court_ID"`
-		CourtName     string `gorm:"column:Court_Name"`
-		CourtLocation string `gorm:"column:Court_Location"`
-		CourtStatus   uint   `gorm:"column:Court_Status"`
-		SportID       uint   `gorm:"column:Sport_id"`
-	}
-	var courtData []CourtInfo
+func (h *Handler) GetCourt(w http.ResponseWriter, r *http.Request) {
 	sportName := r.URL.Query().Get("sport")
 
 	if sportName == "" {
-		http.Error(w, "Missing 'sport' query parameter", http.StatusBadRequest)
+		APIError.Validation(w, r, "Missing 'sport' query parameter", APIError.Field("sport", "is required"))
 		return
 	}
 
 	fmt.Println("Sport Selection:", sportName)
 
 	// Check if sport exists
-	if err := DataBase.DB.Where("\"Sport_name\" = ?", sportName).First(&sport).Error; err != nil {
+	sport, err := h.Store.Sports().FindByName(sportName)
+	if err != nil {
 		fmt.Println("Sport not found:", err)
-		http.Error(w, "Sport not found", http.StatusNotFound)
+		APIError.Write(w, r, http.StatusNotFound, APIError.SportNotFound, "Sport not found")
 		return
 	}
 
-	// Fetch courts for the given sport
-	if err := DataBase.DB.Model(&DataBase.Court{}).
-		Select("\"Court_ID\", \"Court_Name\", \"Court_Location\", \"Court_Status\", \"Sport_id\"").
-		Where("\"Sport_id\" = ?", sport.Sport_ID).
-		Find(&courtData).Error; err != nil || len(courtData) == 0 { // Fix: Check for empty result
-		fmt.Println("No courts found for the sport")
-		http.Error(w, "No courts available for the selected sport", http.StatusNotFound)
+	// Polling clients that already hold today's availability get a 304 without any more queries
+	date := *DataBase.BookingDate(time.Now())
+	if Caching.NotModified(w, r, Availability.ETag(sport, date)) {
 		return
 	}
 
-	var courtIDs []uint
-	for _, court := range courtData {
-		courtIDs = append(courtIDs, court.CourtID)
-	}
-
-	// Fetch time slots
-	var courtTimeSlots []DataBase.Court_TimeSlots
-	if err := DataBase.DB.
-		Where("\"Court_ID\" IN (?)", courtIDs).
-		Find(&courtTimeSlots).Error; err != nil {
-		fmt.Println("Court TimeSlots not found:", err)
-		http.Error(w, "Court TimeSlots not found", http.StatusNotFound)
+	// Fetch courts for the given sport
+	courtData, err := h.Store.Courts().ListBySport(sport.Sport_ID)
+	if err != nil || len(courtData) == 0 { // Fix: Check for empty result
+		fmt.Println("No courts found for the sport")
+		APIError.Write(w, r, http.StatusNotFound, APIError.CourtNotFound, "No courts available for the selected sport")
 		return
 	}
 
-	// Map Court_ID to time slots
-	courtTimeSlotMap := make(map[uint]DataBase.Court_TimeSlots)
-	for _, slot := range courtTimeSlots {
-		courtTimeSlotMap[slot.Court_ID] = slot
-	}
-
-	var courts []DataBase.CourtAvailability
-	for _, court := range courtData {
-		timeSlot, exists := courtTimeSlotMap[court.CourtID]
-		if !exists {
-			fmt.Println("No time slots found for Court ID:", court.CourtID)
-			continue // Instead of returning 404, continue with other courts
-		}
-
-		courtAvailability := DataBase.CourtAvailability{
-			CourtID:       court.CourtID,
-			CourtName:     court.CourtName,
-			CourtLocation: court.CourtLocation,
-			CourtStatus:   uint(court.CourtStatus),
-			SportID:       court.SportID,
-			Slots:         []int{timeSlot.Slot_08_09, timeSlot.Slot_09_10, timeSlot.Slot_10_11, timeSlot.Slot_11_12, timeSlot.Slot_12_13, timeSlot.Slot_13_14, timeSlot.Slot_14_15, timeSlot.Slot_15_16, timeSlot.Slot_16_17, timeSlot.Slot_17_18},
-		}
-		courts = append(courts, courtAvailability)
-	}
-
-	// If no courts were found with time slots, return 404
-	if len(courts) == 0 {
-		http.Error(w, "No courts with available timeslots found", http.StatusNotFound)
+	// Work out today's slots from bookings and blackouts
+	courts, err := WithAvailability(h.Store, courtData, date)
+	if err != nil {
+		fmt.Println("Failed to compute availability:", err)
+		APIError.Internal(w, r, "Failed to load court availability", err)
 		return
 	}
 
@@ -107,3 +75,29 @@ func GetCourt(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(courts)
 	fmt.Println("Court Info Successful for Sport:", sportName)
 }
+
+// WithAvailability pairs each court with its slot states on date, a Booking_Date.
+func WithAvailability(store Repository.Store, courtData []DataBase.Court, date string) ([]DataBase.CourtAvailability, error) {
+	courtIDs := make([]uint, len(courtData))
+	for i, court := range courtData {
+		courtIDs[i] = court.Court_ID
+	}
+
+	slots, err := Availability.ForCourts(store, courtIDs, date)
+	if err != nil {
+		return nil, err
+	}
+
+	courts := make([]DataBase.CourtAvailability, 0, len(courtData))
+	for _, court := range courtData {
+		courts = append(courts, DataBase.CourtAvailability{
+			CourtID:       court.Court_ID,
+			CourtName:     court.Court_Name,
+			CourtLocation: court.Court_Location,
+			CourtStatus:   uint(court.Court_Status),
+			SportID:       court.Sport_id,
+			Slots:         slots[court.Court_ID],
+		})
+	}
+	return courts, nil
+}
@@ -2,78 +2,57 @@ package Court
 
 import (
 	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
-
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
+	"time"
 )
 
-func setupTestDB() (*gorm.DB, error) {
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
-	if err != nil {
-		return nil, err
-	}
-
-	db.AutoMigrate(&DataBase.Sport{}, &DataBase.Court{}, &DataBase.Court_TimeSlots{})
-
-	return db, nil
-}
-
 func TestGetCourt(t *testing.T) {
-	db, err := setupTestDB()
-	if err != nil {
-		t.Fatalf("Failed to connect to test database: %v", err)
-	}
-	DataBase.DB = db
+	store := TestStore.Open(t)
+	h := NewHandler(store)
 
 	// Seed test data
 	testSport := DataBase.Sport{Sport_ID: 1, Sport_name: "tennis"}
-	db.Create(&testSport)
+	store.Sports().Create(&testSport)
 
 	testCourt := DataBase.Court{Court_ID: 1, Court_Name: "Court A", Court_Status: 1, Sport_id: 1}
-	db.Create(&testCourt)
-
-	testTimeSlot := DataBase.Court_TimeSlots{
-		Court_ID:   1,
-		Slot_08_09: 1,
-		Slot_09_10: 0,
-		Slot_10_11: 1,
-		Slot_11_12: 0,
-		Slot_12_13: 1,
-		Slot_13_14: 0,
-		Slot_14_15: 1,
-		Slot_15_16: 0,
-		Slot_16_17: 1,
-		Slot_17_18: 0,
-	}
-	db.Create(&testTimeSlot)
+	store.Courts().Create(&testCourt)
+
+	// Slot 1 is booked today and slot 3 is blacked out; the rest are free
+	today := *DataBase.BookingDate(time.Now())
+	store.Bookings().Create(&DataBase.Bookings{Customer_ID: 1, Sport_ID: 1, Court_ID: 1, Booking_Status: DataBase.BookingConfirmed, Booking_Time: 1, Booking_Date: &today})
+	blackoutSlot := 3
+	store.Blackouts().Create(&DataBase.Court_Blackout{Court_ID: 1, Blackout_Date: today, Slot_Index: &blackoutSlot})
+	expectedSlots := []int{1, 2, 1, 0, 1, 1, 1, 1, 1, 1}
 
 	tests := []struct {
 		name           string
 		query          string
 		expectedCode   int
 		expectedCourts int
+		expectedCourt  string
 	}{
-		{"Valid sport with court", "/getCourts?sport=tennis", http.StatusOK, 1},
-		{"Invalid sport", "/getCourts?sport=badminton", http.StatusNotFound, 0},
-		{"Missing sport param", "/getCourts", http.StatusBadRequest, 0},
-		{"Sport with no courts", "/getCourts?sport=squash", http.StatusNotFound, 0},
-		{"Courts exist but no timeslots", "/getCourts?sport=football", http.StatusNotFound, 0},
+		{"Valid sport with court", "/getCourts?sport=tennis", http.StatusOK, 1, "Court A"},
+		{"Invalid sport", "/getCourts?sport=badminton", http.StatusNotFound, 0, ""},
+		{"Missing sport param", "/getCourts", http.StatusBadRequest, 0, ""},
+		{"Sport with no courts", "/getCourts?sport=squash", http.StatusNotFound, 0, ""},
+		{"Court with no bookings", "/getCourts?sport=football", http.StatusOK, 1, "Court B"},
 	}
 
 	// Create a sport with no courts
 	testSportNoCourts := DataBase.Sport{Sport_ID: 2, Sport_name: "squash"}
-	db.Create(&testSportNoCourts)
+	store.Sports().Create(&testSportNoCourts)
 
-	// Create a sport with a court but no timeslots
+	// Create a sport with a court but no bookings
 	testSportNoSlots := DataBase.Sport{Sport_ID: 3, Sport_name: "football"}
-	db.Create(&testSportNoSlots)
+	store.Sports().Create(&testSportNoSlots)
 	testCourtNoSlots := DataBase.Court{Court_ID: 2, Court_Name: "Court B", Court_Status: 1, Sport_id: 3}
-	db.Create(&testCourtNoSlots)
+	store.Courts().Create(&testCourtNoSlots)
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -83,7 +62,7 @@ func TestGetCourt(t *testing.T) {
 			}
 
 			w := httptest.NewRecorder()
-			GetCourt(w, req)
+			h.GetCourt(w, req)
 
 			resp := w.Result()
 			defer resp.Body.Close()
@@ -102,10 +81,59 @@ func TestGetCourt(t *testing.T) {
 					t.Errorf("Expected %d courts, got %d", tc.expectedCourts, len(courts))
 				}
 
-				if tc.expectedCourts > 0 && (courts[0].CourtID != 1 || !strings.Contains(courts[0].CourtName, "Court A")) {
+				if tc.expectedCourts > 0 && !strings.Contains(courts[0].CourtName, tc.expectedCourt) {
 					t.Errorf("Unexpected court data: %v", courts[0])
 				}
+
+				if tc.expectedCourt == "Court A" && !reflect.DeepEqual(courts[0].Slots, expectedSlots) {
+					t.Errorf("Expected slots %v, got %v", expectedSlots, courts[0].Slots)
+				}
 			}
 		})
 	}
 }
+
+func TestGetCourt_NotModified(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	sport := DataBase.Sport{Sport_name: "tennis"}
+	store.Sports().Create(&sport)
+	court := DataBase.Court{Court_Name: "Court A", Court_Status: 1, Sport_id: sport.Sport_ID}
+	store.Courts().Create(&court)
+
+	get := func(etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/getCourts?sport=tennis", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		h.GetCourt(w, req)
+		return w
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || first.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatalf("expected 200 with an ETag, got %d %v", first.Code, first.Header())
+	}
+
+	// Nothing changed, so polling again transfers nothing
+	if w := get(etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("ETag") != etag {
+		t.Fatalf("expected 304 for an unchanged sport, got %d %q", w.Code, w.Body.String())
+	}
+
+	// A booking changes today's slots and with them the tag
+	today := *DataBase.BookingDate(time.Now())
+	store.Bookings().Create(&DataBase.Bookings{Customer_ID: 1, Sport_ID: sport.Sport_ID, Court_ID: court.Court_ID,
+		Booking_Status: DataBase.BookingConfirmed, Booking_Time: 0, Booking_Date: &today})
+	w := get(etag)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Fatalf("expected fresh availability after a booking, got %d with tag %s", w.Code, w.Header().Get("ETag"))
+	}
+	var courts []DataBase.CourtAvailability
+	json.NewDecoder(w.Body).Decode(&courts)
+	if len(courts) != 1 || courts[0].Slots[0] != DataBase.SlotBooked {
+		t.Errorf("expected slot 0 to be booked, got %+v", courts)
+	}
+}
@@ -0,0 +1,21 @@
+package Court
+
+import (
+	"BackEnd/Availability"
+	"BackEnd/Events"
+	"BackEnd/Repository"
+)
+
+// Handler serves the court endpoints using the injected repositories.
+type Handler struct {
+	Store Repository.Store
+	// Alternatives bounds the free slots offered when a slot cannot be booked.
+	Alternatives Availability.AlternativeOptions
+	// Events receives every change to availability; nil ignores them.
+	Events *Events.Broker
+}
+
+// NewHandler returns a Handler backed by the given store.
+func NewHandler(store Repository.Store) *Handler {
+	return &Handler{Store: store, Alternatives: Availability.DefaultAlternatives}
+}
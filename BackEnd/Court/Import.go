@@ -0,0 +1,273 @@
+package Court
+
+import (
+	"BackEnd/Availability"
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidImport is returned by Import when a row fails validation or conflicts with an
+// existing court. Nothing is written and the report's Errors say what is wrong.
+var ErrInvalidImport = errors.New("invalid court import")
+
+// errDryRun rolls back a dry run once its report is complete.
+var errDryRun = errors.New("dry run")
+
+// maxImportRows caps the courts one file may add.
+const maxImportRows = 1000
+
+// Import columns. name and sport are required; the others may be left out of the file or
+// empty in a row.
+var (
+	importColumns  = []string{"name", "location", "capacity", "status", "indoor", "sport", "hours"}
+	requiredColumn = map[string]bool{"name": true, "sport": true}
+)
+
+// ImportReport describes what Import did, or would do in a dry run.
+type ImportReport struct {
+	Dry_Run bool `json:"dry_run"`
+	// Rows counts the data rows read, leaving out the header.
+	Rows    int              `json:"rows"`
+	Created int              `json:"created"`
+	Courts  []DataBase.Court `json:"courts"`
+	// Errors lists every problem found; any error stops the whole import.
+	Errors []RowError `json:"errors"`
+}
+
+// RowError is a problem with one row of the file, or with the file itself when Row is 0. Rows
+// are numbered as lines of the file, so the first court is on row 2 after the header.
+type RowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// Import reads courts from CSV with a header row naming the columns: name, location,
+// capacity, status (open or closed, 1 or 0), indoor (true or false), sport (a sport's name) and
+// hours (such as 09:00-17:00, inside the configured day; empty for the whole day). Every row is
+// validated before anything is written, and the courts are then created with their hours in
+// one transaction. With dryRun the import runs and is rolled back, so the
+// report shows exactly what would happen.
+func Import(store Repository.Store, r io.Reader, dryRun bool) (ImportReport, error) {
+	report := ImportReport{Dry_Run: dryRun, Courts: []DataBase.Court{}, Errors: []RowError{}}
+	courts, err := parseImport(store, r, &report)
+	if err != nil {
+		return report, err
+	}
+	if len(report.Errors) > 0 {
+		return report, ErrInvalidImport
+	}
+
+	err = store.Transaction(func(tx Repository.Store) error {
+		for i := range courts {
+			err := tx.Courts().Create(&courts[i].court)
+			if errors.Is(err, Repository.ErrDuplicate) {
+				// Taken by a court created since the rows were checked
+				report.Errors = append(report.Errors, RowError{Row: courts[i].row, Column: "name",
+					Message: fmt.Sprintf("a court named %q already exists", courts[i].court.Court_Name)})
+				continue
+			} else if err != nil {
+				return err
+			}
+			report.Courts = append(report.Courts, courts[i].court)
+		}
+		if len(report.Errors) > 0 {
+			return ErrInvalidImport
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, ErrInvalidImport):
+		report.Courts = []DataBase.Court{}
+		return report, err
+	case err != nil && !errors.Is(err, errDryRun):
+		return report, err
+	}
+	report.Created = len(report.Courts)
+	return report, nil
+}
+
+// importRow is a valid court read from the file, with the row it came from.
+type importRow struct {
+	row   int
+	court DataBase.Court
+}
+
+// parseImport reads and validates every row, adding a RowError to report for each problem. The
+// error returned is a failure to read the file or look up existing data, not a problem with
+// its contents.
+func parseImport(store Repository.Store, r io.Reader, report *ImportReport) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	fileError := func(message string, args ...interface{}) {
+		report.Errors = append(report.Errors, RowError{Message: fmt.Sprintf(message, args...)})
+	}
+
+	header, err := reader.Read()
+	var parseErr *csv.ParseError
+	switch {
+	case err == io.EOF:
+		fileError("the file is empty")
+		return nil, nil
+	case errors.As(err, &parseErr):
+		fileError("invalid CSV: %v", err)
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !containsColumn(name) {
+			fileError("unknown column %q, expected %s", name, strings.Join(importColumns, ", "))
+		} else if _, repeated := columns[name]; repeated {
+			fileError("column %q appears twice", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range importColumns {
+		if _, ok := columns[name]; requiredColumn[name] && !ok {
+			fileError("missing column %q", name)
+		}
+	}
+	if len(report.Errors) > 0 {
+		return nil, nil
+	}
+
+	sports, err := store.Sports().List()
+	if err != nil {
+		return nil, err
+	}
+	sportsByName := map[string]DataBase.Sport{}
+	for _, sport := range sports {
+		sportsByName[strings.ToLower(sport.Sport_name)] = sport
+	}
+	existing, err := store.Courts().List()
+	if err != nil {
+		return nil, err
+	}
+	taken := map[string]int{}
+	for _, court := range existing {
+		taken[court.Court_Name] = 0
+	}
+	var courts []importRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if errors.As(err, &parseErr) {
+			report.Errors = append(report.Errors, RowError{Row: parseErr.Line, Message: fmt.Sprintf("invalid CSV: %v", err)})
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if isBlank(record) {
+			continue
+		}
+		report.Rows++
+		if report.Rows > maxImportRows {
+			fileError("the file has more than %d courts", maxImportRows)
+			break
+		}
+
+		rowError := func(column, message string, args ...interface{}) {
+			report.Errors = append(report.Errors, RowError{Row: line, Column: column, Message: fmt.Sprintf(message, args...)})
+		}
+		get := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		court := DataBase.Court{Court_Name: get("name"), Court_Location: get("location"), Court_Status: 1}
+		errorsBefore := len(report.Errors)
+		if court.Court_Name == "" {
+			rowError("name", "is required")
+		} else if first, ok := taken[court.Court_Name]; ok && first == 0 {
+			rowError("name", "a court named %q already exists", court.Court_Name)
+		} else if ok {
+			rowError("name", "%q is also on row %d", court.Court_Name, first)
+		} else {
+			taken[court.Court_Name] = line
+		}
+
+		if raw := get("capacity"); raw != "" {
+			capacity, err := strconv.Atoi(raw)
+			if err != nil || capacity < 1 {
+				rowError("capacity", "must be a whole number of at least 1, got %q", raw)
+			}
+			court.Court_Capacity = &capacity
+		}
+
+		switch raw := strings.ToLower(get("status")); raw {
+		case "", "open", "1":
+		case "closed", "0":
+			court.Court_Status = 0
+		default:
+			rowError("status", "must be open or closed, got %q", raw)
+		}
+
+		if raw := get("indoor"); raw != "" {
+			indoor, err := strconv.ParseBool(raw)
+			if err != nil {
+				rowError("indoor", "must be true or false, got %q", raw)
+			}
+			court.Court_Indoor = indoor
+		}
+
+		if name := get("sport"); name == "" {
+			rowError("sport", "is required")
+		} else if sport, ok := sportsByName[strings.ToLower(name)]; !ok {
+			rowError("sport", "no sport is named %q", name)
+		} else {
+			court.Sport_id = sport.Sport_ID
+		}
+
+		if raw := get("hours"); raw != "" {
+			hours, err := Availability.ParseHours(raw)
+			if err != nil {
+				rowError("hours", "%v, got %q", err, raw)
+			}
+			court.Court_Hours = hours
+		}
+
+		if len(report.Errors) == errorsBefore {
+			courts = append(courts, importRow{row: line, court: court})
+		}
+	}
+	if report.Rows == 0 && len(report.Errors) == 0 {
+		fileError("the file has no courts")
+	}
+	return courts, nil
+}
+
+func containsColumn(name string) bool {
+	for _, column := range importColumns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
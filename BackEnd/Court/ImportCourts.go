@@ -0,0 +1,87 @@
+package Court
+
+import (
+	"BackEnd/APIError"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxImportSize caps the size of an uploaded court file.
+const maxImportSize = 1 << 20
+
+// ImportCourts godoc
+// @Summary      Import courts from CSV (Admin)
+// @Description  Adds courts from a CSV file, sent as the request body or as the "file" field of a multipart form. The header row names the columns: name and sport (a sport's name) are required, and location, capacity, status (open or closed), indoor (true or false) and hours are optional. hours, such as 09:00-17:00, must open when a slot starts and close when one ends; slots outside them cannot be booked, and a court without hours is open all day. Every row is validated first and the report lists each problem by row; if there are any, nothing is created. Otherwise the courts are created in one transaction. With dry_run=true nothing is written and the report shows what would happen.
+// @Tags         courts
+// @Accept       text/csv
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        dry_run  query     bool  false  "Only report what the import would do"
+// @Success      200      {object}  ImportReport  "Dry run"
+// @Success      201      {object}  ImportReport  "Courts created"
+// @Failure      400      {object}  DataBase.ErrorResponse{report=ImportReport}  "INVALID_IMPORT, with the problems found in report"
+// @Failure      500      {object}  DataBase.ErrorResponse  "Failed to import courts"
+// @Router       /admin/courts/import [post]
+func (h *Handler) ImportCourts(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			APIError.Validation(w, r, "dry_run must be true or false", APIError.Field("dry_run", "must be true or false"))
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(maxImportSize); err != nil {
+			if !tooLarge(w, r, err) {
+				APIError.Malformed(w, r, "Invalid multipart form")
+			}
+			return
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			APIError.Validation(w, r, "file is required", APIError.Field("file", "is required"))
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	report, err := Import(h.Store, body, dryRun)
+	if tooLarge(w, r, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, ErrInvalidImport):
+		APIError.ImportRejected(w, r, "The file has problems, so no courts were imported", report)
+	case err != nil:
+		APIError.Internal(w, r, "Failed to import courts", err)
+	case dryRun:
+		writeReport(w, http.StatusOK, report)
+	default:
+		writeReport(w, http.StatusCreated, report)
+	}
+}
+
+// tooLarge reports whether err comes from an upload over maxImportSize, answering 413 if so.
+func tooLarge(w http.ResponseWriter, r *http.Request, err error) bool {
+	var maxBytes *http.MaxBytesError
+	if !errors.As(err, &maxBytes) {
+		return false
+	}
+	APIError.Write(w, r, http.StatusRequestEntityTooLarge, APIError.MalformedRequest, "The file is larger than 1 MiB")
+	return true
+}
+
+func writeReport(w http.ResponseWriter, status int, report ImportReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,154 @@
+package Court
+
+import (
+	"BackEnd/Availability"
+	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportCourts(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	store.Sports().Create(&DataBase.Sport{Sport_name: "Tennis"})
+	store.Sports().Create(&DataBase.Sport{Sport_name: "Pickleball"})
+	store.Courts().Create(&DataBase.Court{Court_Name: "Court A", Court_Location: "Downtown", Court_Status: 1, Sport_id: 1})
+
+	post := func(query, body string) (*httptest.ResponseRecorder, ImportReport) {
+		req, _ := http.NewRequest("POST", "/admin/courts/import"+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		rr := httptest.NewRecorder()
+		h.ImportCourts(rr, req)
+		var report ImportReport
+		if rr.Code == http.StatusBadRequest {
+			// A rejected file's report comes inside the error envelope
+			rejected := DataBase.ErrorResponse{Report: &report}
+			json.Unmarshal(rr.Body.Bytes(), &rejected)
+			if len(report.Errors) > 0 && rejected.Code != "INVALID_IMPORT" {
+				t.Errorf("expected a rejected file to answer INVALID_IMPORT, got %s", rr.Body.String())
+			}
+		} else {
+			json.Unmarshal(rr.Body.Bytes(), &report)
+		}
+		return rr, report
+	}
+	describe := func(report ImportReport) []string {
+		described := []string{}
+		for _, e := range report.Errors {
+			described = append(described, fmt.Sprintf("%d %s", e.Row, e.Column))
+		}
+		return described
+	}
+
+	// Every bad row is reported and nothing is created
+	invalid := "name,location,capacity,status,sport\n" +
+		"Court A,Downtown,4,open,Tennis\n" +
+		"Court B,Downtown,zero,open,Tennis\n" +
+		"Court C,Downtown,4,maybe,Curling\n" +
+		"Court D,Downtown,4,open,tennis\n" +
+		"Court E,Downtown,,,Tennis\n" +
+		"Court E,Uptown,,,Tennis\n" +
+		",Uptown,,,\n"
+	rr, report := post("", invalid)
+	want := []string{"2 name", "3 capacity", "4 status", "4 sport", "7 name", "8 name", "8 sport"}
+	if rr.Code != http.StatusBadRequest || fmt.Sprint(describe(report)) != fmt.Sprint(want) || report.Rows != 7 {
+		t.Errorf("expected errors %v on 7 rows, got %d %v: %s", want, rr.Code, describe(report), rr.Body.String())
+	}
+	if courts, _ := store.Courts().List(); len(courts) != 1 {
+		t.Errorf("expected nothing to be created, got %d courts", len(courts))
+	}
+
+	for _, body := range []string{"", "name,location\nCourt B,Downtown\n", "name,sport,colour\nCourt B,Tennis,red\n", "name,sport\n"} {
+		if rr, report := post("", body); rr.Code != http.StatusBadRequest || len(report.Errors) == 0 || report.Errors[0].Row != 0 {
+			t.Errorf("%q: expected a file error, got %d: %s", body, rr.Code, rr.Body.String())
+		}
+	}
+
+	valid := "Name, Sport, Location, Capacity, Status, Indoor\n" +
+		"Court B,tennis,Downtown,4,open,false\n" +
+		"\n" +
+		"Pickle 1,Pickleball,\"Rec Center, Hall 2\",,closed,true\n"
+
+	// A dry run reports the courts without creating them
+	rr, report = post("?dry_run=true", valid)
+	if rr.Code != http.StatusOK || !report.Dry_Run || report.Created != 2 || len(report.Courts) != 2 {
+		t.Errorf("expected a dry run of two courts, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if courts, _ := store.Courts().List(); len(courts) != 1 {
+		t.Errorf("expected a dry run to create nothing, got %d courts", len(courts))
+	}
+
+	rr, report = post("", valid)
+	if rr.Code != http.StatusCreated || report.Created != 2 || report.Rows != 2 {
+		t.Fatalf("expected two courts created, got %d: %s", rr.Code, rr.Body.String())
+	}
+	pickle, err := store.Courts().FindByName("Pickle 1")
+	if err != nil || pickle.Court_Location != "Rec Center, Hall 2" || pickle.Court_Capacity != nil || pickle.Court_Status != 0 ||
+		!pickle.Court_Indoor || pickle.Sport_id != 2 {
+		t.Errorf("expected the closed indoor pickleball court, got %+v (%v)", pickle, err)
+	}
+	if court, _ := store.Courts().FindByName("Court B"); court.Court_Capacity == nil || *court.Court_Capacity != 4 || court.Court_Status != 1 {
+		t.Errorf("expected an open court with capacity 4, got %+v", court)
+	}
+
+	// The same file again clashes with the courts it created
+	if rr, report := post("", valid); rr.Code != http.StatusBadRequest || fmt.Sprint(describe(report)) != "[2 name 4 name]" {
+		t.Errorf("expected both names to clash, got %d %v", rr.Code, describe(report))
+	}
+
+	// Hours must fall on the slots of the day, from 08:00 to 18:00 by default
+	hours := "name,sport,hours\n" +
+		"Court H1,Tennis,9-5\n" +
+		"Court H2,Tennis,07:00-12:00\n" +
+		"Court H3,Tennis,09:30-12:00\n" +
+		"Court H4,Tennis,12:00-09:00\n"
+	if rr, report := post("", hours); rr.Code != http.StatusBadRequest || fmt.Sprint(describe(report)) != "[2 hours 3 hours 4 hours 5 hours]" {
+		t.Errorf("expected every row's hours rejected, got %d %v", rr.Code, describe(report))
+	}
+	rr, report = post("", "name,sport,hours\nCourt H,Tennis, 09:00 - 12:00\nCourt I,Tennis,\n")
+	if rr.Code != http.StatusCreated || report.Created != 2 {
+		t.Fatalf("expected the courts with and without hours created, got %d: %s", rr.Code, rr.Body.String())
+	}
+	scheduled, _ := store.Courts().FindByName("Court H")
+	allDay, _ := store.Courts().FindByName("Court I")
+	if scheduled.Court_Hours != "09:00-12:00" || allDay.Court_Hours != "" {
+		t.Errorf("expected hours 09:00-12:00 and none, got %q and %q", scheduled.Court_Hours, allDay.Court_Hours)
+	}
+	// Only 09:00 to 12:00 can be booked on the scheduled court
+	slots, _ := Availability.ForCourts(store, []uint{scheduled.Court_ID, allDay.Court_ID}, *DataBase.BookingDate(time.Now()))
+	for i, state := range slots[scheduled.Court_ID] {
+		if open := i >= 1 && i <= 3; open != (state == DataBase.SlotAvailable) || slots[allDay.Court_ID][i] != DataBase.SlotAvailable {
+			t.Errorf("slot %d: expected the scheduled court open only from 09:00 to 12:00, got %d and %d", i, state, slots[allDay.Court_ID][i])
+		}
+	}
+
+	// A multipart upload works the same way
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, _ := writer.CreateFormFile("file", "courts.csv")
+	part.Write([]byte("name,sport\nCourt Z,Tennis\n"))
+	writer.Close()
+	req, _ := http.NewRequest("POST", "/admin/courts/import", &form)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rr = httptest.NewRecorder()
+	h.ImportCourts(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Errorf("expected a multipart upload to create the court, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr, _ := post("", "name,sport\n"+strings.Repeat("x", maxImportSize)+",Tennis\n"); rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected an oversized file to answer 413, got %d", rr.Code)
+	}
+	if rr, _ := post("?dry_run=maybe", valid); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected an invalid dry_run to answer 400, got %d", rr.Code)
+	}
+}
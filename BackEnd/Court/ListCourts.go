@@ -1,7 +1,8 @@
 package Court
 
 import (
-	"BackEnd/DataBase"
+	"BackEnd/Pagination"
+	"BackEnd/Repository"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -14,25 +15,43 @@ type CourtData struct {
 
 // ListCourts godoc
 // @Summary      List all courts with their associated sports
-// @Description  Retrieves a list of all courts along with the corresponding sport details.
+// @Description  Retrieves the courts along with the corresponding sport details, a page at a time. X-Total-Count gives the number of matching courts, and X-Next-Cursor and the Link header the next page.
 // @Tags         courts
 // @Accept       json
 // @Produce      json
+// @Param        sport_id  query  int     false  "Only courts of this sport"
+// @Param        status    query  int     false  "Only courts with this status, 1 for open"
+// @Param        indoor    query  bool    false  "Only indoor courts when true, only outdoor ones when false"
+// @Param        limit     query  int     false  "Courts per page, at most 500"  default(100)
+// @Param        cursor    query  string  false  "Cursor of the page, from X-Next-Cursor"
+// @Param        sort      query  string  false  "id, name, location, status or sport_id, prefixed with - for descending order"  default(id)
 // @Success      200    {array}   DataBase.Court  "List of courts and their associated sports"
-// @Failure      500    {string}  string  "Database error while fetching courts"
+// @Failure      400    {object}  DataBase.ErrorResponse  "Invalid list parameters"
+// @Failure      500    {object}  DataBase.ErrorResponse  "Database error while fetching courts"
+// @Deprecated
 // @Router       /ListCourts [get]
-func ListCourts(w http.ResponseWriter, r *http.Request) {
-	var courts []DataBase.Court
-
-	// Use GORM Preload to fetch the associated Sport for each court
-	err := DataBase.DB.Preload("Sport").Find(&courts).Error
+func (h *Handler) ListCourts(w http.ResponseWriter, r *http.Request) {
+	params := Pagination.New(r)
+	filter := Repository.CourtFilter{SportID: params.ID("sport_id"), Status: params.Int("status"), Indoor: params.Bool("indoor")}
+	q := params.List(Repository.CourtSorts)
+	if !params.Check(w, r) {
+		return
+	}
 
+	// The repository loads the associated Sport for each court
+	courts, next, err := h.Store.Courts().Page(filter, q)
+	var total int64
+	if err == nil {
+		total, err = h.Store.Courts().Count(filter)
+	}
 	if err != nil {
 		fmt.Println("Failed to fetch courts:", err)
-		http.Error(w, "Failed to fetch courts", http.StatusInternalServerError)
+		Pagination.Failed(w, r, err, "Failed to fetch courts")
 		return
 	}
 
+	Pagination.SetTotal(w, total)
+	Pagination.SetNext(w, r, q, next)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(courts)
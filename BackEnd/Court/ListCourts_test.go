@@ -2,39 +2,35 @@ package Court
 
 import (
 	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"BackEnd/Repository/TestStore"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
-
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 )
 
-func setupListCourtsTestDB() *gorm.DB {
-	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
-	if err != nil {
-		panic("failed to connect to the database")
-	}
-
-	db.AutoMigrate(&DataBase.Sport{}, &DataBase.Court{})
+func setupListCourtsTestStore(t *testing.T, withCourt bool) Repository.Store {
+	store := TestStore.Open(t)
 
-	db.Create(&DataBase.Sport{
+	store.Sports().Create(&DataBase.Sport{
 		Sport_name:        "Football",
 		Sport_Description: "A popular team sport",
 	})
-	db.Create(&DataBase.Court{
-		Court_Name:     "Court A",
-		Court_Location: "Downtown",
-		Court_Status:   1,
-		Sport_id:       1,
-	})
+	if withCourt {
+		store.Courts().Create(&DataBase.Court{
+			Court_Name:     "Court A",
+			Court_Location: "Downtown",
+			Court_Status:   1,
+			Sport_id:       1,
+		})
+	}
 
-	return db
+	return store
 }
 
 func TestListCourts(t *testing.T) {
-	DataBase.DB = setupListCourtsTestDB()
+	h := NewHandler(setupListCourtsTestStore(t, true))
 
 	req, err := http.NewRequest("GET", "/ListCourts", nil)
 	if err != nil {
@@ -42,40 +38,40 @@ func TestListCourts(t *testing.T) {
 	}
 
 	recorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(ListCourts)
+	handler := http.HandlerFunc(h.ListCourts)
 	handler.ServeHTTP(recorder, req)
 
 	if status := recorder.Code; status != http.StatusOK {
 		t.Errorf("expected status %d, got %d", http.StatusOK, status)
 	}
 
-	var courts []CourtData
+	var courts []DataBase.Court
 	err = json.Unmarshal(recorder.Body.Bytes(), &courts)
 	if err != nil {
 		t.Errorf("Failed to unmarshal response body: %v", err)
 	}
 
 	if len(courts) == 0 {
-		t.Errorf("Expected courts to be listed, but got an empty response")
+		t.Fatalf("Expected courts to be listed, but got an empty response")
 	}
 
-	if courts[0].CourtName != "Court A" || courts[0].SportName != "Football" {
+	if courts[0].Court_Name != "Court A" || courts[0].Sport.Sport_name != "Football" {
 		t.Errorf("unexpected court data: %+v", courts)
 	}
 }
 
 func TestListMultipleCourts(t *testing.T) {
-	db := setupListCourtsTestDB()
-	DataBase.DB = db
-
-	db.Exec("DELETE FROM Court")
+	store := setupListCourtsTestStore(t, false)
+	h := NewHandler(store)
 
 	courts := []DataBase.Court{
 		{Court_Name: "Test Court A", Court_Location: "Rietzz", Court_Status: 1, Sport_id: 1},
 		{Court_Name: "Test Court B", Court_Location: "Southwest", Court_Status: 1, Sport_id: 1},
 	}
-	if err := db.Create(&courts).Error; err != nil {
-		t.Fatalf("failed to insert test courts: %v", err)
+	for i := range courts {
+		if err := store.Courts().Create(&courts[i]); err != nil {
+			t.Fatalf("failed to insert test courts: %v", err)
+		}
 	}
 
 	req, err := http.NewRequest("GET", "/ListCourts", nil)
@@ -84,7 +80,7 @@ func TestListMultipleCourts(t *testing.T) {
 	}
 
 	recorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(ListCourts)
+	handler := http.HandlerFunc(h.ListCourts)
 	handler.ServeHTTP(recorder, req)
 
 	resp := recorder.Result()
@@ -94,27 +90,25 @@ func TestListMultipleCourts(t *testing.T) {
 		t.Errorf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
 	}
 
-	var courtsResponse []CourtData
+	var courtsResponse []DataBase.Court
 	if err := json.NewDecoder(resp.Body).Decode(&courtsResponse); err != nil {
 		t.Fatalf("failed to decode response: %v", err)
 	}
 
 	if len(courtsResponse) != 2 {
-		t.Errorf("expected 2 courts, got %d", len(courtsResponse))
+		t.Fatalf("expected 2 courts, got %d", len(courtsResponse))
 	}
 
 	expectedNames := []string{"Test Court A", "Test Court B"}
 	for i, court := range courtsResponse {
-		if court.CourtName != expectedNames[i] || court.SportName != "Football" {
+		if court.Court_Name != expectedNames[i] || court.Sport.Sport_name != "Football" {
 			t.Errorf("unexpected court data at index %d: %+v", i, court)
 		}
 	}
 }
 
 func TestListCourtsEmpty(t *testing.T) {
-	DataBase.DB = setupListCourtsTestDB()
-
-	DataBase.DB.Exec("DELETE FROM Court")
+	h := NewHandler(setupListCourtsTestStore(t, false))
 
 	req, err := http.NewRequest("GET", "/ListCourts", nil)
 	if err != nil {
@@ -122,14 +116,14 @@ func TestListCourtsEmpty(t *testing.T) {
 	}
 
 	recorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(ListCourts)
+	handler := http.HandlerFunc(h.ListCourts)
 	handler.ServeHTTP(recorder, req)
 
 	if status := recorder.Code; status != http.StatusOK {
 		t.Errorf("expected status %d, got %d", http.StatusOK, status)
 	}
 
-	var courts []CourtData
+	var courts []DataBase.Court
 	err = json.Unmarshal(recorder.Body.Bytes(), &courts)
 	if err != nil {
 		t.Errorf("Failed to unmarshal response body: %v", err)
@@ -139,3 +133,70 @@ func TestListCourtsEmpty(t *testing.T) {
 		t.Errorf("Expected 0 courts to be listed, but got %d", len(courts))
 	}
 }
+
+func TestListCourtsPaged(t *testing.T) {
+	store := setupListCourtsTestStore(t, false)
+	h := NewHandler(store)
+
+	store.Sports().Create(&DataBase.Sport{Sport_name: "Tennis"})
+	courts := []DataBase.Court{
+		{Court_Name: "Court C", Court_Location: "North", Court_Status: 1, Sport_id: 1},
+		{Court_Name: "Court A", Court_Location: "North", Court_Status: 1, Sport_id: 1},
+		{Court_Name: "Court B", Court_Location: "South", Court_Status: 0, Sport_id: 1},
+		{Court_Name: "Court D", Court_Location: "South", Court_Status: 1, Sport_id: 2},
+	}
+	for i := range courts {
+		if err := store.Courts().Create(&courts[i]); err != nil {
+			t.Fatalf("failed to insert test courts: %v", err)
+		}
+	}
+
+	list := func(url string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		h.ListCourts(recorder, httptest.NewRequest("GET", url, nil))
+		return recorder
+	}
+
+	// Football courts by name, two to a page
+	first := list("/ListCourts?sport_id=1&sort=name&limit=2")
+	var page []DataBase.Court
+	json.Unmarshal(first.Body.Bytes(), &page)
+	if first.Code != http.StatusOK || len(page) != 2 || page[0].Court_Name != "Court A" || page[1].Court_Name != "Court B" {
+		t.Fatalf("unexpected first page: %d %+v", first.Code, page)
+	}
+	if total := first.Header().Get("X-Total-Count"); total != "3" {
+		t.Errorf("expected a total of 3 courts, got %q", total)
+	}
+	cursor := first.Header().Get("X-Next-Cursor")
+	if cursor == "" || first.Header().Get("Link") == "" {
+		t.Fatalf("expected a next page, got headers %v", first.Header())
+	}
+
+	second := list("/ListCourts?sport_id=1&sort=name&limit=2&cursor=" + cursor)
+	page = nil
+	json.Unmarshal(second.Body.Bytes(), &page)
+	if len(page) != 1 || page[0].Court_Name != "Court C" || second.Header().Get("X-Next-Cursor") != "" {
+		t.Errorf("unexpected last page: %+v, next %q", page, second.Header().Get("X-Next-Cursor"))
+	}
+
+	// Open courts only, newest first
+	open := list("/ListCourts?status=1&sort=-id")
+	page = nil
+	json.Unmarshal(open.Body.Bytes(), &page)
+	if len(page) != 3 || page[0].Court_Name != "Court D" || page[2].Court_Name != "Court C" {
+		t.Errorf("unexpected open courts: %+v", page)
+	}
+
+	// A cursor only continues the order it was issued for
+	for _, url := range []string{
+		"/ListCourts?sort=name&cursor=" + cursor + "x",
+		"/ListCourts?sort=-name&cursor=" + cursor,
+		"/ListCourts?sort=capacity",
+		"/ListCourts?limit=0",
+		"/ListCourts?sport_id=tennis",
+	} {
+		if recorder := list(url); recorder.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", url, http.StatusBadRequest, recorder.Code)
+		}
+	}
+}
@@ -1,21 +1,26 @@
 package Court
 
 import (
+	"BackEnd/APIError"
+	"BackEnd/Availability"
+	"BackEnd/Bookings"
 	"BackEnd/DataBase"
+	"BackEnd/Events"
+	"BackEnd/Repository"
 	"BackEnd/Utils"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-
-	"gorm.io/gorm"
+	"time"
 )
 
-// UpdateCourtSlotandBooking updates the availability status of a specific court slot and creates a corresponding booking record.
+// UpdateCourtSlotandBooking books a specific court slot if it is free.
 //
 // @Summary Update court slot and create booking
-// @Description Toggles the availability of a court time slot and, based on the provided customer email and sport name,
+// @Description Books a free court time slot for today on behalf of the customer with the provided email,
 //
-//	creates a booking record if the slot update is successful. Both operations are executed within a single transaction.
+//	for the named sport. The availability check and the booking are executed within a single transaction.
 //
 // @Tags courts
 // @Accept json
@@ -23,99 +28,71 @@ import (
 // @Param updateRequest body DataBase.CourtUpdate true "Court slot update request including Customer_email and Sport_name"
 // @Success 200 {string} string "Slot updated and booking created successfully for Court_ID: {Court_ID}, Slot_Index: {Slot_Index}"
 // @Failure 400 {object} DataBase.ErrorResponse "Invalid request body or Slot_Index out of range"
-// @Failure 404 {object} DataBase.ErrorResponse "Court time slots, Customer, or Sport not found"
+// @Failure 404 {object} DataBase.ErrorResponse "Court, Customer, or Sport not found"
+// @Failure 409 {object} DataBase.ErrorResponse "Slot is already booked or unavailable, with the nearest free alternatives, or the court is closed"
 // @Failure 500 {object} DataBase.ErrorResponse "Database error or failed to update slot/booking"
+// @Deprecated
 // @Router /UpdateCourtSlotandBooking [put]
-func UpdateCourtSlotandBooking(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateCourtSlotandBooking(w http.ResponseWriter, r *http.Request) {
 	var updateRequest DataBase.CourtUpdate
 
 	// Decode JSON request.
 	if err := json.NewDecoder(r.Body).Decode(&updateRequest); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		APIError.Malformed(w, r, "Invalid request body")
 		return
 	}
 
-	// Start a transaction.
-	tx := DataBase.DB.Begin()
-	if tx.Error != nil {
-		http.Error(w, "Failed to start transaction", http.StatusInternalServerError)
+	if _, err := h.Store.Courts().FindByID(updateRequest.Court_ID); errors.Is(err, Repository.ErrNotFound) {
+		APIError.Write(w, r, http.StatusNotFound, APIError.CourtNotFound, "Court not found")
 		return
-	}
-
-	// ----- Step 1: Update the Court Slot -----
-	var timeSlot DataBase.Court_TimeSlots
-	if err := tx.Model(&DataBase.Court_TimeSlots{}).
-		Where("Court_ID = ?", updateRequest.Court_ID).
-		First(&timeSlot).Error; err != nil {
-		tx.Rollback()
-		if err == gorm.ErrRecordNotFound {
-			http.Error(w, "Court TimeSlots not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Database error", http.StatusInternalServerError)
-		}
+	} else if err != nil {
+		APIError.Internal(w, r, "Database error", err)
 		return
 	}
 
-	slotFields := []string{
-		"slot_08_09", "slot_09_10", "slot_10_11", "slot_11_12",
-		"slot_12_13", "slot_13_14", "slot_14_15", "slot_15_16",
-		"slot_16_17", "slot_17_18",
-	}
-
-	if updateRequest.Slot_Index < 0 || updateRequest.Slot_Index >= len(slotFields) {
-		tx.Rollback()
-		http.Error(w, "Invalid Slot_Index", http.StatusBadRequest)
+	if !Availability.ValidSlot(updateRequest.Slot_Index) {
+		APIError.Validation(w, r, "Invalid Slot_Index", APIError.Field("Slot_Index", "must be one of the day's slots"))
 		return
 	}
 
-	fieldName := slotFields[updateRequest.Slot_Index]
-
-	if err := tx.Model(&DataBase.Court_TimeSlots{}).
-		Where("Court_ID = ?", updateRequest.Court_ID).
-		UpdateColumn(fieldName, 0).Error; err != nil {
-		tx.Rollback()
-		http.Error(w, "Failed to update slot", http.StatusInternalServerError)
+	customer, err := h.Store.Customers().FindByEmail(updateRequest.Customer_email)
+	if errors.Is(err, Repository.ErrNotFound) {
+		APIError.Write(w, r, http.StatusNotFound, APIError.CustomerNotFound, "Customer not found in the database")
 		return
-	}
-
-	var customer DataBase.Customer
-	if err := tx.Where("email = ?", updateRequest.Customer_email).First(&customer).Error; err != nil {
-		tx.Rollback()
-		if err == gorm.ErrRecordNotFound {
-			http.Error(w, "Customer not found in the database", http.StatusNotFound)
-		} else {
-			http.Error(w, "Failed to fetch customer", http.StatusInternalServerError)
-		}
+	} else if err != nil {
+		APIError.Internal(w, r, "Failed to fetch customer", err)
 		return
 	}
 
-	// Lookup the sport by name.
-	var sport DataBase.Sport
-	if tx.Where("Sport_name = ?", updateRequest.Sport_name).First(&sport).RowsAffected == 0 {
-		tx.Rollback()
-		http.Error(w, "Sport not found", http.StatusNotFound)
+	sport, err := h.Store.Sports().FindByName(updateRequest.Sport_name)
+	if err != nil {
+		APIError.Write(w, r, http.StatusNotFound, APIError.SportNotFound, "Sport not found")
 		return
 	}
 
+	// Checking the slot and creating the booking happen in a single transaction
 	booking := DataBase.Bookings{
-		Customer_ID:    customer.Customer_ID,
-		Sport_ID:       sport.Sport_ID,
-		Court_ID:       updateRequest.Court_ID,
-		Booking_Status: "booked",
-		Booking_Time:   updateRequest.Slot_Index,
+		Customer_ID:  customer.Customer_ID,
+		Sport_ID:     sport.Sport_ID,
+		Court_ID:     updateRequest.Court_ID,
+		Booking_Time: updateRequest.Slot_Index,
+		Booking_Date: DataBase.BookingDate(time.Now()),
 	}
-
-	if err := tx.Create(&booking).Error; err != nil {
-		tx.Rollback()
-		http.Error(w, "Failed to create booking", http.StatusInternalServerError)
+	err = Bookings.Book(h.Store, &booking)
+	if errors.Is(err, Bookings.ErrSlotTaken) {
+		Bookings.WriteSlotTaken(w, r, h.Store, h.Alternatives, booking)
 		return
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		tx.Rollback()
-		http.Error(w, "Transaction commit failed", http.StatusInternalServerError)
+	} else if errors.Is(err, Bookings.ErrCourtClosed) {
+		APIError.Write(w, r, http.StatusConflict, APIError.CourtUnavailable, "Court is closed")
+		return
+	} else if errors.Is(err, Repository.ErrNotFound) {
+		APIError.Write(w, r, http.StatusNotFound, APIError.CourtNotFound, "Court not found")
+		return
+	} else if err != nil {
+		APIError.Internal(w, r, "Failed to create booking", err)
 		return
 	}
+	h.Events.Publish(Events.ForBooking(Events.Booked, booking))
 
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintf(w, "Slot updated and booking created successfully for Court_ID: %d, Slot_Index: %d", updateRequest.Court_ID, updateRequest.Slot_Index)
@@ -124,93 +101,53 @@ func UpdateCourtSlotandBooking(w http.ResponseWriter, r *http.Request) {
 
 // CancelBookingandUpdateSlot godoc
 // @Summary      Cancel a booking and update court time slot
-// @Description  Cancels a booking by updating its status to "Cancelled" and marks the corresponding time slot (based on Booking_Time) as available (sets it to 1) in the Court_TimeSlots record.
+// @Description  Cancels a booking on the customer's behalf, which makes its time slot available again.
 // @Tags         courts
 // @Accept       json
 // @Produce      plain
 // @Param        cancelRequest  body      DataBase.CancelRequest  true  "Cancel Booking Request"  example({"Booking_ID": 123})
 // @Success      200            {string}  string  "Booking cancelled and slot updated successfully for Booking_ID: 123"
-// @Failure      400            {string}  string  "Invalid request body or Invalid Slot_Index"
-// @Failure      404            {string}  string  "Booking not found or Court TimeSlots not found"
-// @Failure      500            {string}  string  "Failed to start transaction, database error, or transaction commit failed"
+// @Failure      400            {object}  DataBase.ErrorResponse  "Invalid request body"
+// @Failure      404            {object}  DataBase.ErrorResponse  "Booking not found"
+// @Failure      409            {object}  DataBase.ErrorResponse  "Booking can no longer be cancelled"
+// @Failure      500            {object}  DataBase.ErrorResponse  "Database error"
+// @Deprecated
 // @Router       /CancelBookingandUpdateSlot [put]
-func CancelBookingandUpdateSlot(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CancelBookingandUpdateSlot(w http.ResponseWriter, r *http.Request) {
 	var cancelRequest DataBase.CancelRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&cancelRequest); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		APIError.Malformed(w, r, "Invalid request body")
 		return
 	}
 
-	tx := DataBase.DB.Begin()
-	if tx.Error != nil {
-		http.Error(w, "Failed to start transaction", http.StatusInternalServerError)
+	booking, err := h.Store.Bookings().FindByID(cancelRequest.Booking_ID)
+	if errors.Is(err, Repository.ErrNotFound) {
+		APIError.Write(w, r, http.StatusNotFound, APIError.BookingNotFound, "Booking not found")
 		return
-	}
-
-	var booking DataBase.Bookings
-	if err := tx.First(&booking, cancelRequest.Booking_ID).Error; err != nil {
-		tx.Rollback()
-		if err == gorm.ErrRecordNotFound {
-			http.Error(w, "Booking not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Database error while fetching booking", http.StatusInternalServerError)
-		}
-		return
-	}
-
-	if err := tx.Model(&booking).UpdateColumn("Booking_Status", "Cancelled").Error; err != nil {
-		tx.Rollback()
-		http.Error(w, "Failed to update the booking status", http.StatusInternalServerError)
-		return
-	}
-
-	var timeSlot DataBase.Court_TimeSlots
-	if err := tx.Model(&DataBase.Court_TimeSlots{}).
-		Where("Court_ID = ?", booking.Court_ID).
-		First(&timeSlot).Error; err != nil {
-		tx.Rollback()
-		if err == gorm.ErrRecordNotFound {
-			http.Error(w, "Court TimeSlots not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Database error while fetching timeslot", http.StatusInternalServerError)
-		}
+	} else if err != nil {
+		APIError.Internal(w, r, "Database error while fetching booking", err)
 		return
 	}
 
-	slotFields := []string{
-		"slot_08_09", "slot_09_10", "slot_10_11", "slot_11_12",
-		"slot_12_13", "slot_13_14", "slot_14_15", "slot_15_16",
-		"slot_16_17", "slot_17_18",
-	}
-	if booking.Booking_Time < 0 || booking.Booking_Time >= len(slotFields) {
-		tx.Rollback()
-		http.Error(w, "Invalid Slot_Index", http.StatusBadRequest)
+	// Cancelling the booking is all it takes to free its slot
+	err = h.Store.Bookings().Transition(booking.Booking_ID, DataBase.BookingCancelledByUser, "")
+	if errors.Is(err, Repository.ErrInvalidTransition) {
+		APIError.Write(w, r, http.StatusConflict, APIError.InvalidStatusTransition, "Booking can no longer be cancelled")
 		return
-	}
-	fieldName := slotFields[booking.Booking_Time]
-
-	if err := tx.Model(&DataBase.Court_TimeSlots{}).
-		Where("Court_ID = ?", booking.Court_ID).
-		UpdateColumn(fieldName, 1).Error; err != nil {
-		tx.Rollback()
-		http.Error(w, "Failed to update slot", http.StatusInternalServerError)
-		return
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		tx.Rollback()
-		http.Error(w, "Transaction commit failed", http.StatusInternalServerError)
+	} else if err != nil {
+		APIError.Internal(w, r, "Failed to update the booking status", err)
 		return
 	}
+	h.Events.Publish(Events.ForBooking(Events.Cancelled, booking))
 
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintf(w, "Booking cancelled and slot updated successfully for Booking_ID: %d", cancelRequest.Booking_ID)
 }
 
-func ResetCourtSlotsHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) ResetCourtSlotsHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		APIError.Write(w, r, http.StatusMethodNotAllowed, APIError.MethodNotAllowed, "Method not allowed")
 		return
 	}
 
@@ -222,16 +159,23 @@ func ResetCourtSlotsHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Parse JSON body
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		APIError.Malformed(w, r, "Invalid JSON body")
 		return
 	}
 
 	// Call reset function with optional court name
-	err := Utils.ResetTimeSlotsForAvailableCourts(body.CourtName)
+	err := Utils.ResetTimeSlotsForAvailableCourts(h.Store, body.CourtName, DataBase.BookingCancelledByAdmin)
 	if err != nil {
-		http.Error(w, "Failed to reset court slots", http.StatusInternalServerError)
+		APIError.Internal(w, r, "Failed to reset court slots", err)
 		return
 	}
+	// The reset matches the name case insensitively and FindByName exactly, so when the lookup
+	// misses, every court is announced as reset; clients only reload more than they need
+	if court, err := h.Store.Courts().FindByName(body.CourtName); err == nil {
+		h.Events.Publish(Events.ForCourt(Events.Reset, court))
+	} else {
+		h.Events.Publish(Events.Change{Kind: Events.Reset})
+	}
 
 	// Craft response message
 	msg := "Court slots reset successfully!"
@@ -1,7 +1,9 @@
 package Court
 
 import (
+	"BackEnd/Availability"
 	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
 	"bytes"
 	"fmt"
 	"io"
@@ -12,41 +14,26 @@ import (
 )
 
 func TestUpdateCourtSlotandBooking(t *testing.T) {
-	// Setup test database.
-	db, err := setupTestDB()
-	if err != nil {
-		t.Fatalf("Failed to connect to test database: %v", err)
-	}
-	err = db.AutoMigrate(&DataBase.Customer{}, &DataBase.Sport{}, &DataBase.Court_TimeSlots{}, &DataBase.Bookings{})
-	if err != nil {
-		t.Fatalf("AutoMigrate failed: %v", err)
-	}
-	DataBase.DB = db
+	// Setup test store.
+	store := TestStore.Open(t)
+	h := NewHandler(store)
 
-	// Create a test court timeslot record.
-	testTimeSlot := DataBase.Court_TimeSlots{
-		Court_ID:   1,
-		Court_Name: "Court A",
-		Slot_08_09: 1,
-		Slot_09_10: 1,
-		Slot_10_11: 1,
-		Slot_11_12: 1,
-		Slot_12_13: 1,
-		Slot_13_14: 1,
-		Slot_14_15: 1,
-		Slot_15_16: 1,
-		Slot_16_17: 1,
-		Slot_17_18: 1,
+	// Create the court being booked.
+	testCourt := DataBase.Court{
+		Court_ID:       1,
+		Court_Name:     "Court A",
+		Court_Location: "Test Location",
+		Court_Status:   1,
 	}
-	if err := db.Create(&testTimeSlot).Error; err != nil {
-		t.Fatalf("Failed to create test timeslot: %v", err)
+	if err := store.Courts().Create(&testCourt); err != nil {
+		t.Fatalf("Failed to create test court: %v", err)
 	}
 
 	// Create a test sport record needed for booking.
 	testSport := DataBase.Sport{
 		Sport_name: "Tennis",
 	}
-	if err := db.Create(&testSport).Error; err != nil {
+	if err := store.Sports().Create(&testSport); err != nil {
 		t.Fatalf("Failed to create test sport: %v", err)
 	}
 
@@ -57,7 +44,7 @@ func TestUpdateCourtSlotandBooking(t *testing.T) {
 		Email:       "customer@example.com",
 		Contact:     "1234567890",
 	}
-	if err := db.Create(&testCustomer).Error; err != nil {
+	if err := store.Customers().Create(&testCustomer); err != nil {
 		t.Fatalf("Failed to create test customer: %v", err)
 	}
 
@@ -77,7 +64,7 @@ func TestUpdateCourtSlotandBooking(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	rr := httptest.NewRecorder()
-	UpdateCourtSlotandBooking(rr, req)
+	h.UpdateCourtSlotandBooking(rr, req)
 
 	resp := rr.Result()
 	defer resp.Body.Close()
@@ -89,22 +76,23 @@ func TestUpdateCourtSlotandBooking(t *testing.T) {
 		t.Errorf("Expected status OK, got %v", resp.StatusCode)
 	}
 
-	// Verify the court slot is updated.
-	var updatedTimeSlot DataBase.Court_TimeSlots
-	if err := db.First(&updatedTimeSlot, "Court_ID = ?", 1).Error; err != nil {
-		t.Fatalf("Failed to fetch updated timeslot: %v", err)
+	// Verify the court slot now shows as booked.
+	slot, err := Availability.Slot(store, 1, 0, *DataBase.BookingDate(time.Now()))
+	if err != nil {
+		t.Fatalf("Failed to fetch slot availability: %v", err)
 	}
-	if updatedTimeSlot.Slot_08_09 != 0 {
-		t.Errorf("Expected Slot_08_09 to be flipped to 0, got %d", updatedTimeSlot.Slot_08_09)
+	if slot != DataBase.SlotBooked {
+		t.Errorf("Expected Slot_08_09 to be booked, got %d", slot)
 	}
 
 	// Verify a booking record was created.
-	var booking DataBase.Bookings
-	if err := db.First(&booking, "Court_ID = ?", 1).Error; err != nil {
-		t.Errorf("Expected booking record to be created, got error: %v", err)
+	bookings, err := store.Bookings().ListByCustomer(testCustomer.Customer_ID)
+	if err != nil || len(bookings) != 1 {
+		t.Errorf("Expected booking record to be created, got %d (error: %v)", len(bookings), err)
 	} else {
-		if booking.Booking_Status != "booked" {
-			t.Errorf("Expected Booking_Status 'booked', got '%s'", booking.Booking_Status)
+		booking := bookings[0]
+		if booking.Booking_Status != DataBase.BookingConfirmed {
+			t.Errorf("Expected Booking_Status 'confirmed', got '%s'", booking.Booking_Status)
 		}
 		// According to the API code, Booking_Time is set to the Slot_Index (0 in this case).
 		if booking.Booking_Time != 0 {
@@ -116,37 +104,34 @@ func TestUpdateCourtSlotandBooking(t *testing.T) {
 		}
 	}
 
+	// The slot is now taken, so booking it again is a conflict.
+	req, _ = http.NewRequest("PUT", "/UpdateCourtSlotandBooking", bytes.NewBufferString(requestBodyStr))
+	rr = httptest.NewRecorder()
+	h.UpdateCourtSlotandBooking(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected status Conflict for a taken slot, got %v", rr.Code)
+	}
+
 	// Allow time for any asynchronous operations (if needed).
 	time.Sleep(100 * time.Millisecond)
 }
 
 func TestCancelBookingandUpdateSlot(t *testing.T) {
-	// Initialize a fresh test DB and assign it to the global variable.
-	db, err := setupTestDB()
-	if err != nil {
-		t.Fatalf("failed to setup test database: %v", err)
-	}
-	DataBase.DB = db
+	// Initialize a fresh test store.
+	store := TestStore.Open(t)
+	h := NewHandler(store)
 
 	// Insert test data.
-	// Create a Court_TimeSlots record for Court_ID = 101.
-	timeSlots := DataBase.Court_TimeSlots{
-		Court_ID:   102,
-		Court_Name: "Court B", // Provide a unique court name to avoid UNIQUE constraint error.
-		Slot_08_09: 0,
-		Slot_09_10: 0,
-		Slot_10_11: 0,
-		Slot_11_12: 0, // This slot corresponds to index 3.
-		Slot_12_13: 0,
-		Slot_13_14: 0,
-		Slot_14_15: 0,
-		Slot_15_16: 0,
-		Slot_16_17: 0,
-		Slot_17_18: 0,
-	}
-	if err := DataBase.DB.Create(&timeSlots).Error; err != nil {
-		t.Fatalf("failed to create Court_TimeSlots record: %v", err)
+	court := DataBase.Court{
+		Court_ID:       102,
+		Court_Name:     "Court B",
+		Court_Location: "Test Location",
+		Court_Status:   1,
 	}
+	if err := store.Courts().Create(&court); err != nil {
+		t.Fatalf("failed to create Court record: %v", err)
+	}
+	today := *DataBase.BookingDate(time.Now())
 
 	// Create a booking record with Booking_ID=1, Court_ID=101, and Booking_Time=3 (maps to "slot_11_12").
 	booking := DataBase.Bookings{
@@ -154,10 +139,11 @@ func TestCancelBookingandUpdateSlot(t *testing.T) {
 		Customer_ID:    122,
 		Court_ID:       102,
 		Sport_ID:       122,
-		Booking_Status: "Confirmed",
+		Booking_Status: DataBase.BookingConfirmed,
 		Booking_Time:   3, // corresponds to "slot_11_12"
+		Booking_Date:   &today,
 	}
-	if err := DataBase.DB.Create(&booking).Error; err != nil {
+	if err := store.Bookings().Create(&booking); err != nil {
 		t.Fatalf("failed to create Booking record: %v", err)
 	}
 
@@ -173,7 +159,7 @@ func TestCancelBookingandUpdateSlot(t *testing.T) {
 
 	// Execute the handler.
 	rr := httptest.NewRecorder()
-	handler := http.HandlerFunc(CancelBookingandUpdateSlot)
+	handler := http.HandlerFunc(h.CancelBookingandUpdateSlot)
 	handler.ServeHTTP(rr, req)
 
 	// Check the response status code.
@@ -187,31 +173,28 @@ func TestCancelBookingandUpdateSlot(t *testing.T) {
 		t.Errorf("expected response message %q, got %q", expectedMessage, rr.Body.String())
 	}
 
-	// Confirm that the booking status was updated to "Cancelled" in the database.
-	var updatedBooking DataBase.Bookings
-	if err := DataBase.DB.First(&updatedBooking, 2).Error; err != nil {
+	// Confirm that the booking was cancelled on the customer's behalf in the database.
+	updatedBooking, err := store.Bookings().FindByID(2)
+	if err != nil {
 		t.Fatalf("failed to query updated booking: %v", err)
 	}
-	if updatedBooking.Booking_Status != "Cancelled" {
-		t.Errorf("expected booking status to be 'Cancelled', got %q", updatedBooking.Booking_Status)
+	if updatedBooking.Booking_Status != DataBase.BookingCancelledByUser {
+		t.Errorf("expected booking status to be 'cancelled_by_user', got %q", updatedBooking.Booking_Status)
 	}
 
-	// Verify that the correct timeslot (slot_11_12) was updated to 1.
-	var updatedTimeSlots DataBase.Court_TimeSlots
-	if err := DataBase.DB.Where("Court_ID = ?", 102).First(&updatedTimeSlots).Error; err != nil {
-		t.Fatalf("failed to query updated time slots: %v", err)
+	// Verify that the freed slot (slot_11_12) is available again.
+	slot, err := Availability.Slot(store, 102, 3, today)
+	if err != nil {
+		t.Fatalf("failed to query slot availability: %v", err)
 	}
-	if updatedTimeSlots.Slot_11_12 != 1 {
-		t.Errorf("expected Slot_11_12 to be 1, got %d", updatedTimeSlots.Slot_11_12)
+	if slot != DataBase.SlotAvailable {
+		t.Errorf("expected Slot_11_12 to be available, got %d", slot)
 	}
 }
 
 func TestResetCourtSlotsHandler(t *testing.T) {
-	db, err := setupTestDB()
-	if err != nil {
-		t.Fatalf("failed to setup test database: %v", err)
-	}
-	DataBase.DB = db
+	store := TestStore.Open(t)
+	h := NewHandler(store)
 
 	court := DataBase.Court{
 		Court_Name:     "Court C",
@@ -219,27 +202,22 @@ func TestResetCourtSlotsHandler(t *testing.T) {
 		Court_Status:   1,
 		Sport_id:       1,
 	}
-	if err := DataBase.DB.Create(&court).Error; err != nil {
+	if err := store.Courts().Create(&court); err != nil {
 		t.Fatalf("failed to create Court: %v", err)
 	}
 
-	// Create timeslots for that court
-	timeSlots := DataBase.Court_TimeSlots{
-		Court_ID:   court.Court_ID,
-		Court_Name: court.Court_Name,
-		Slot_08_09: 0,
-		Slot_09_10: 0,
-		Slot_10_11: 0,
-		Slot_11_12: 0,
-		Slot_12_13: 0,
-		Slot_13_14: 0,
-		Slot_14_15: 0,
-		Slot_15_16: 0,
-		Slot_16_17: 0,
-		Slot_17_18: 0,
+	// Book the last slot of that court for today
+	today := *DataBase.BookingDate(time.Now())
+	booking := DataBase.Bookings{
+		Customer_ID:    1,
+		Court_ID:       court.Court_ID,
+		Sport_ID:       1,
+		Booking_Status: DataBase.BookingConfirmed,
+		Booking_Time:   9,
+		Booking_Date:   &today,
 	}
-	if err := DataBase.DB.Create(&timeSlots).Error; err != nil {
-		t.Fatalf("failed to create Court_TimeSlots: %v", err)
+	if err := store.Bookings().Create(&booking); err != nil {
+		t.Fatalf("failed to create Booking: %v", err)
 	}
 
 	// Prepare JSON body for request
@@ -252,7 +230,7 @@ func TestResetCourtSlotsHandler(t *testing.T) {
 
 	// Perform the request
 	rr := httptest.NewRecorder()
-	handler := http.HandlerFunc(ResetCourtSlotsHandler)
+	handler := http.HandlerFunc(h.ResetCourtSlotsHandler)
 	handler.ServeHTTP(rr, req)
 
 	// Assert response status code
@@ -265,4 +243,14 @@ func TestResetCourtSlotsHandler(t *testing.T) {
 	if rr.Body.String() != expectedMessage {
 		t.Errorf("expected response message %q, got %q", expectedMessage, rr.Body.String())
 	}
+
+	// Assert the booking was cancelled and its slot is available again
+	if updated, err := store.Bookings().FindByID(booking.Booking_ID); err != nil {
+		t.Fatalf("failed to query booking: %v", err)
+	} else if updated.Booking_Status != DataBase.BookingCancelledByAdmin {
+		t.Errorf("expected booking to be cancelled, got %q", updated.Booking_Status)
+	}
+	if slot, _ := Availability.Slot(store, court.Court_ID, 9, today); slot != DataBase.SlotAvailable {
+		t.Errorf("expected Slot_17_18 to be available, got %d", slot)
+	}
 }
@@ -1,6 +1,7 @@
 package Customer
 
 import (
+	"BackEnd/APIError"
 	"BackEnd/DataBase"
 	"encoding/json"
 	"net/http"
@@ -15,45 +16,34 @@ import (
 // @Produce json
 // @Param customer body DataBase.Customer true "Customer data"
 // @Success 200 {object} map[string]interface{} "Customer record updated/added successfully"
-// @Failure 400 "Invalid request body"
-// @Failure 500 "Internal server error"
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid request body"
+// @Failure 500 {object} DataBase.ErrorResponse "Internal server error"
+// @Deprecated
 // @Router /Customer [post]
-func CreateCustomer(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateCustomer(w http.ResponseWriter, r *http.Request) {
 	var c DataBase.Customer
 	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		APIError.Malformed(w, r, "Invalid request body")
 		return
 	}
 	// Normalize email
 	c.Email = strings.ToLower(strings.TrimSpace(c.Email))
 
-	var existingCustomer DataBase.Customer
 	// Check if customer exists by Email (Case Insensitive just to be safe, but we lowercased input)
-	result := DataBase.DB.Where("LOWER(\"Email\") = ?", c.Email).First(&existingCustomer)
+	existingCustomer, err := h.Store.Customers().FindByEmail(c.Email)
 
-	if result.RowsAffected > 0 {
-		// Update existing customer info (UFID/Name)
-		// We use map to ensure zero values (if any) are respected if intended, but here we want to overwrite
-		updates := map[string]interface{}{}
-
-		// Force update if provided
-		if c.UFID != "" {
-			updates["UFID"] = c.UFID
-		}
-		if c.Name != "" {
-			updates["Name"] = c.Name
-		}
-
-		if len(updates) > 0 {
-			if err := DataBase.DB.Model(&existingCustomer).Updates(updates).Error; err != nil {
-				http.Error(w, "Failed to update customer profile", http.StatusInternalServerError)
+	if err == nil {
+		// Update existing customer info (UFID/Name); empty values leave the stored ones untouched
+		if c.UFID != "" || c.Name != "" {
+			if err := h.Store.Customers().UpdateProfile(existingCustomer.Customer_ID, c.Name, c.UFID); err != nil {
+				APIError.Internal(w, r, "Failed to update customer profile", err)
 				return
 			}
 		}
 
 		// Return the *updated* customer object to confirm changes to frontend
 		// Need to reload to be sure
-		DataBase.DB.First(&existingCustomer, existingCustomer.Customer_ID)
+		existingCustomer, _ = h.Store.Customers().FindByID(existingCustomer.Customer_ID)
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
@@ -65,8 +55,8 @@ func CreateCustomer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create new customer
-	if err := DataBase.DB.Create(&c).Error; err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.Store.Customers().Create(&c); err != nil {
+		APIError.Internal(w, r, "Failed to create customer", err)
 		return
 	}
 
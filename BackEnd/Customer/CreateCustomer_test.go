@@ -2,29 +2,17 @@ package Customer
 
 import (
 	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
 	"bytes"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
-
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 )
 
-func setupTestDB() {
-
-	var err error
-	DataBase.DB, err = gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
-	if err != nil {
-		panic("failed to connect to the database")
-	}
-
-	DataBase.DB.AutoMigrate(&DataBase.Customer{})
-}
-
 func TestCreateCustomer(t *testing.T) {
-	setupTestDB()
+	store := TestStore.Open(t)
+	h := NewHandler(store)
 
 	customerRequest := map[string]interface{}{
 		"Name":    "Rohi B",
@@ -40,7 +28,7 @@ func TestCreateCustomer(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	recorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(CreateCustomer)
+	handler := http.HandlerFunc(h.CreateCustomer)
 	handler.ServeHTTP(recorder, req)
 
 	t.Logf("Response Body: %s", recorder.Body.String())
@@ -55,24 +43,23 @@ func TestCreateCustomer(t *testing.T) {
 		t.Errorf("unexpected response message: %v", response["message"])
 	}
 
-	var savedCustomer DataBase.Customer
-	result := DataBase.DB.First(&savedCustomer, "email = ?", "rohb@example.com")
+	savedCustomer, err := store.Customers().FindByEmail("rohb@example.com")
 
-	t.Logf("Database Query Result: %+v", savedCustomer)
+	t.Logf("Stored Customer: %+v", savedCustomer)
 
-	if result.Error != nil {
-		t.Errorf("Customer not found in database: %v", result.Error)
+	if err != nil {
+		t.Errorf("Customer not found in store: %v", err)
 	} else if savedCustomer.Name != "Rohi B" || savedCustomer.Contact != "1234567890" {
 		t.Errorf("Customer data mismatch: got %+v", savedCustomer)
 	}
 }
 
 func TestCreateCustomerInvalidRequest(t *testing.T) {
-	setupTestDB()
+	h := NewHandler(TestStore.Open(t))
 
 	req, _ := http.NewRequest("POST", "/Customer", bytes.NewBuffer([]byte("invalid json")))
 	recorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(CreateCustomer)
+	handler := http.HandlerFunc(h.CreateCustomer)
 	handler.ServeHTTP(recorder, req)
 
 	if status := recorder.Code; status != http.StatusBadRequest {
@@ -80,7 +67,8 @@ func TestCreateCustomerInvalidRequest(t *testing.T) {
 	}
 }
 func TestCreateCustomerAlreadyExists(t *testing.T) {
-	setupTestDB()
+	store := TestStore.Open(t)
+	h := NewHandler(store)
 
 	// Pre-insert a customer
 	existing := DataBase.Customer{
@@ -88,13 +76,14 @@ func TestCreateCustomerAlreadyExists(t *testing.T) {
 		Email:   "rohb@example.com",
 		Contact: "1234567890",
 	}
-	if err := DataBase.DB.Create(&existing).Error; err != nil {
+	if err := store.Customers().Create(&existing); err != nil {
 		t.Fatalf("failed to insert existing customer: %v", err)
 	}
 
-	// Send the same data again
+	// Send the same email again with a new name and UFID
 	customerRequest := map[string]interface{}{
-		"Name":    "Rohi B",
+		"Name":    "Rohini B",
+		"UFID":    "12345678",
 		"Email":   "rohb@example.com",
 		"Contact": "1234567890",
 	}
@@ -103,14 +92,22 @@ func TestCreateCustomerAlreadyExists(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	recorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(CreateCustomer)
+	handler := http.HandlerFunc(h.CreateCustomer)
 	handler.ServeHTTP(recorder, req)
 
 	if recorder.Code != http.StatusOK {
 		t.Errorf("expected status %d, got %d", http.StatusOK, recorder.Code)
 	}
 
-	if recorder.Body.Len() != 0 {
-		t.Errorf("expected empty body for existing customer, got %q", recorder.Body.String())
+	var response struct {
+		Message  string            `json:"message"`
+		Customer DataBase.Customer `json:"customer"`
+	}
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	if response.Message != "Customer profile updated" {
+		t.Errorf("unexpected response message: %v", response.Message)
+	}
+	if response.Customer.Customer_ID != existing.Customer_ID || response.Customer.Name != "Rohini B" || response.Customer.UFID != "12345678" {
+		t.Errorf("expected the updated profile in the response, got %+v", response.Customer)
 	}
 }
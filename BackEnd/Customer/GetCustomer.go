@@ -1,7 +1,7 @@
 package Customer
 
 import (
-	"BackEnd/DataBase"
+	"BackEnd/APIError"
 	"encoding/json"
 	"net/http"
 	"strings"
@@ -15,19 +15,20 @@ import (
 // @Produce json
 // @Param email query string true "Customer email"
 // @Success 200 {object} DataBase.Customer "Customer profile"
-// @Failure 404 "Customer not found"
-// @Failure 400 "Email required"
+// @Failure 404 {object} DataBase.ErrorResponse "Customer not found"
+// @Failure 400 {object} DataBase.ErrorResponse "Email required"
+// @Deprecated
 // @Router /GetCustomer [get]
-func GetCustomer(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetCustomer(w http.ResponseWriter, r *http.Request) {
 	email := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("email")))
 	if email == "" {
-		http.Error(w, "Email query parameter is required", http.StatusBadRequest)
+		APIError.Validation(w, r, "Email query parameter is required", APIError.Field("email", "is required"))
 		return
 	}
 
-	var customer DataBase.Customer
-	if err := DataBase.DB.Where("LOWER(\"Email\") = ?", email).First(&customer).Error; err != nil {
-		http.Error(w, "Customer not found", http.StatusNotFound)
+	customer, err := h.Store.Customers().FindByEmail(email)
+	if err != nil {
+		APIError.Write(w, r, http.StatusNotFound, APIError.CustomerNotFound, "Customer not found")
 		return
 	}
 
@@ -0,0 +1,13 @@
+package Customer
+
+import "BackEnd/Repository"
+
+// Handler serves the customer endpoints using the injected repositories.
+type Handler struct {
+	Store Repository.Store
+}
+
+// NewHandler returns a Handler backed by the given store.
+func NewHandler(store Repository.Store) *Handler {
+	return &Handler{Store: store}
+}
@@ -1,17 +1,47 @@
 package DataBase
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"strings"
+	"time"
 
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
+// ErrorResponse is the body of every error answer. Code is stable and meant for programs;
+// Message is meant for people and may change. Details lists the offending fields of a
+// request that failed validation, Alternatives offers free slots in place of one that could
+// not be booked, Report describes everything wrong with a rejected import, and RequestID
+// matches the X-Request-ID header.
 type ErrorResponse struct {
+	Code         string            `json:"code"`
+	Message      string            `json:"message"`
+	Details      []FieldError      `json:"details,omitempty"`
+	Alternatives []SlotAlternative `json:"alternatives,omitempty"`
+	Report       interface{}       `json:"report,omitempty"`
+	RequestID    string            `json:"request_id,omitempty"`
+}
+
+// FieldError explains why one field of a request was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
 	Message string `json:"message"`
 }
 
+// SlotAlternative is a free slot near one that could not be booked. Distance counts the slots
+// between it and the requested one; another court at the same time is 0 away.
+type SlotAlternative struct {
+	CourtID   uint   `json:"court_id"`
+	CourtName string `json:"court_name"`
+	Date      string `json:"date"`
+	SlotIndex int    `json:"slot_index"`
+	Slot      string `json:"slot"`
+	Distance  int    `json:"distance"`
+}
+
 type SportSelection struct {
 	Sport string `json:"sport"`
 }
@@ -46,46 +76,168 @@ type Customer struct {
 	Email       string `gorm:"column:Email" json:"email"`
 }
 
+// Sport names are unique among sports that are not deleted, by a partial index.
 type Sport struct {
 	Sport_ID          uint   `gorm:"column:Sport_ID;primaryKey;autoIncrement;unique;not null" json:"Sport_ID"`
-	Sport_name        string `gorm:"column:Sport_name;unique;not null" json:"Sport_name"`
+	Sport_name        string `gorm:"column:Sport_name;not null" json:"Sport_name"`
 	Sport_Description string
+	// Availability_Version moves on with every write that may change the availability of one of
+	// the sport's courts; availability ETags are derived from it.
+	Availability_Version int64 `gorm:"column:Availability_Version;not null;default:0" json:"-"`
+	// Deleted_At is set when the sport is soft deleted; GORM then leaves it out of every query.
+	Deleted_At gorm.DeletedAt `gorm:"column:Deleted_At;index" json:"Deleted_At"`
 }
 
+// Court names are unique among courts that are not deleted, by a partial index. Court_Hours
+// limits when a court can be booked, such as "09:00-17:00"; empty means the whole day.
 type Court struct {
 	Court_ID       uint   `gorm:"column:Court_ID;primaryKey;autoIncrement" json:"Court_ID"`
-	Court_Name     string `gorm:"column:Court_Name;unique;not null" json:"Court_Name"`
+	Court_Name     string `gorm:"column:Court_Name;not null" json:"Court_Name"`
 	Court_Location string `gorm:"column:Court_Location;not null" json:"Court_Location"`
 	Court_Capacity *int
-	Court_Status   int    `gorm:"column:Court_Status;not null" json:"Court_Status"`
-	Sport_id       uint   `gorm:"column:Sport_id;index;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"Sport_id"`
-	Sport          *Sport `gorm:"foreignKey:Sport_ID; references:Sport_id"`
-}
-
-type Court_TimeSlots struct {
-	ID         uint   `gorm:"column:ID;primaryKey;autoIncrement" json:"ID"`
-	Court_ID   uint   `gorm:"column:Court_ID;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"Court_ID"`
-	Slot_08_09 int    `gorm:"column:slot_08_09;not null;default:1" json:"slot_08_09"`
-	Slot_09_10 int    `gorm:"column:slot_09_10;not null;default:1" json:"slot_09_10"`
-	Slot_10_11 int    `gorm:"column:slot_10_11;not null;default:1" json:"slot_10_11"`
-	Slot_11_12 int    `gorm:"column:slot_11_12;not null;default:1" json:"slot_11_12"`
-	Slot_12_13 int    `gorm:"column:slot_12_13;not null;default:1" json:"slot_12_13"`
-	Slot_13_14 int    `gorm:"column:slot_13_14;not null;default:1" json:"slot_13_14"`
-	Slot_14_15 int    `gorm:"column:slot_14_15;not null;default:1" json:"slot_14_15"`
-	Slot_15_16 int    `gorm:"column:slot_15_16;not null;default:1" json:"slot_15_16"`
-	Slot_16_17 int    `gorm:"column:slot_16_17;not null;default:1" json:"slot_16_17"`
-	Slot_17_18 int    `gorm:"column:slot_17_18;not null;default:1" json:"slot_17_18"`
-	Court_Name string `gorm:"column:Court_Name;unique;not null;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"Court_Name"`
-	Court      *Court `gorm:"foreignKey:Court_Name; references:Court_Name"`
-	CourtIDRef *Court `gorm:"foreignKey:Court_ID; references:Court_ID"`
+	Court_Status   int            `gorm:"column:Court_Status;not null" json:"Court_Status"`
+	Court_Indoor   bool           `gorm:"column:Court_Indoor;not null;default:false" json:"Court_Indoor"`
+	Court_Hours    string         `gorm:"column:Court_Hours;not null;default:''" json:"Court_Hours"`
+	Sport_id       uint           `gorm:"column:Sport_id;index;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"Sport_id"`
+	Sport          *Sport         `gorm:"foreignKey:Sport_ID; references:Sport_id"`
+	Deleted_At     gorm.DeletedAt `gorm:"column:Deleted_At;index" json:"Deleted_At"`
+}
+
+// SlotLabels names the slots of a court's day in order; a slot index points into it. The
+// default day has ten one-hour slots from 08:00 to 18:00; SetSlots replaces it at startup.
+var SlotLabels = []string{
+	"08:00 - 09:00", "09:00 - 10:00", "10:00 - 11:00", "11:00 - 12:00",
+	"12:00 - 13:00", "13:00 - 14:00", "14:00 - 15:00", "15:00 - 16:00",
+	"16:00 - 17:00", "17:00 - 18:00",
+}
+
+// SlotCount is the number of slots in a court's day.
+var SlotCount = len(SlotLabels)
+
+// SetSlots configures the slots of a court's day. It must be called before serving requests.
+func SetSlots(labels []string) {
+	SlotLabels = labels
+	SlotCount = len(labels)
+}
+
+// SlotLabel names the slot at index, or returns "" for an index outside the day.
+func SlotLabel(index int) string {
+	if index >= 0 && index < SlotCount {
+		return SlotLabels[index]
+	}
+	return ""
+}
+
+// Slot states reported in CourtAvailability.Slots. They are derived from active bookings
+// and blackouts, never stored.
+const (
+	SlotUnavailable = 0
+	SlotAvailable   = 1
+	SlotBooked      = 2
+)
+
+// Court_Blackout takes a court out of service on one day, for a single slot or the whole day.
+type Court_Blackout struct {
+	Blackout_ID   uint   `gorm:"column:Blackout_ID;primaryKey;autoIncrement" json:"Blackout_ID"`
+	Court_ID      uint   `gorm:"column:Court_ID;index;not null" json:"Court_ID"`
+	Blackout_Date string `gorm:"column:Blackout_Date;size:10;not null" json:"Blackout_Date"`
+	// Slot_Index is the blocked slot, or nil when the whole day is blocked.
+	Slot_Index *int   `gorm:"column:Slot_Index" json:"Slot_Index"`
+	Reason     string `gorm:"column:Reason" json:"Reason"`
 }
+
+// BookingDateLayout formats Bookings.Booking_Date. Dates in this layout sort chronologically.
+const BookingDateLayout = "2006-01-02"
+
+// BookingDate returns the Booking_Date value for the day containing t.
+func BookingDate(t time.Time) *string {
+	date := t.Format(BookingDateLayout)
+	return &date
+}
+
+// BookingStatus is where a booking is in its lifecycle. Transitions between statuses are
+// checked by CanTransition; nothing else should decide whether a status change is allowed.
+type BookingStatus string
+
+const (
+	// BookingHeld reserves a slot before the booking is confirmed.
+	BookingHeld              BookingStatus = "held"
+	BookingConfirmed         BookingStatus = "confirmed"
+	BookingCheckedIn         BookingStatus = "checked_in"
+	BookingCompleted         BookingStatus = "completed"
+	BookingNoShow            BookingStatus = "no_show"
+	BookingCancelledByUser   BookingStatus = "cancelled_by_user"
+	BookingCancelledByAdmin  BookingStatus = "cancelled_by_admin"
+	BookingCancelledBySystem BookingStatus = "cancelled_by_system"
+)
+
+// CancelledStatuses are the statuses that release a booking's slot.
+var CancelledStatuses = []BookingStatus{BookingCancelledByUser, BookingCancelledByAdmin, BookingCancelledBySystem}
+
+// bookingTransitions lists the statuses each status may move to. Statuses without an entry are final.
+var bookingTransitions = map[BookingStatus][]BookingStatus{
+	BookingHeld:      {BookingConfirmed, BookingCancelledByUser, BookingCancelledByAdmin, BookingCancelledBySystem},
+	BookingConfirmed: {BookingCheckedIn, BookingNoShow, BookingCancelledByUser, BookingCancelledByAdmin, BookingCancelledBySystem},
+	BookingCheckedIn: {BookingCompleted},
+}
+
+// Valid reports whether s is one of the defined booking statuses.
+func (s BookingStatus) Valid() bool {
+	switch s {
+	case BookingHeld, BookingConfirmed, BookingCheckedIn, BookingCompleted, BookingNoShow,
+		BookingCancelledByUser, BookingCancelledByAdmin, BookingCancelledBySystem:
+		return true
+	}
+	return false
+}
+
+// Cancelled reports whether s releases the booking's slot.
+func (s BookingStatus) Cancelled() bool {
+	for _, cancelled := range CancelledStatuses {
+		if s == cancelled {
+			return true
+		}
+	}
+	return false
+}
+
+// rolloverTransitions lists the changes only the nightly rollover may make on top of
+// bookingTransitions: a confirmed booking whose day has passed counts as played, though nobody
+// checked it in. Admins still have to check a booking in before completing it.
+var rolloverTransitions = map[BookingStatus][]BookingStatus{
+	BookingConfirmed: {BookingCompleted},
+}
+
+// CanTransition reports whether a booking may move from status from to status to.
+func CanTransition(from, to BookingStatus) bool {
+	return transitionListed(bookingTransitions, from, to)
+}
+
+// CanRollover reports whether the nightly rollover may move a booking from status from to status to.
+func CanRollover(from, to BookingStatus) bool {
+	return CanTransition(from, to) || transitionListed(rolloverTransitions, from, to)
+}
+
+func transitionListed(transitions map[BookingStatus][]BookingStatus, from, to BookingStatus) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
 type Bookings struct {
-	Booking_ID     uint   `gorm:"column:Booking_ID;primaryKey;autoIncrement" json:"Booking_ID"`
-	Customer_ID    uint   `gorm:"column:Customer_ID;index;not null" json:"Customer_ID"`
-	Sport_ID       uint   `gorm:"column:Sport_ID;index;not null" json:"Sport_ID"`
-	Court_ID       uint   `gorm:"column:Court_ID;index;not null" json:"Court_ID"`
-	Booking_Status string `gorm:"column:Booking_Status;not null" json:"Booking_Status"`
-	Booking_Time   int    `gorm:"column:Booking_Time;not null" json:"Booking_Time"`
+	Booking_ID     uint          `gorm:"column:Booking_ID;primaryKey;autoIncrement" json:"Booking_ID"`
+	Customer_ID    uint          `gorm:"column:Customer_ID;index;not null" json:"Customer_ID"`
+	Sport_ID       uint          `gorm:"column:Sport_ID;index;not null" json:"Sport_ID"`
+	Court_ID       uint          `gorm:"column:Court_ID;index;not null" json:"Court_ID"`
+	Booking_Status BookingStatus `gorm:"column:Booking_Status;not null" json:"Booking_Status"`
+	Booking_Time   int           `gorm:"column:Booking_Time;not null" json:"Booking_Time"`
+	// Booking_Date is the day the slot is booked for, formatted with BookingDateLayout.
+	// It is nil for bookings made before dates were recorded.
+	Booking_Date *string        `gorm:"column:Booking_Date;size:10" json:"Booking_Date"`
+	Deleted_At   gorm.DeletedAt `gorm:"column:Deleted_At;index" json:"Deleted_At"`
 
 	// Simplified tags to let GORM handle constraints correctly
 	Customer Customer `gorm:"foreignKey:Customer_ID;references:Customer_ID"`
@@ -93,13 +245,105 @@ type Bookings struct {
 	Court    Court    `gorm:"foreignKey:Court_ID;references:Court_ID"`
 }
 
+// RolloverStatus is the status a booking ends on once its day has passed: completed if
+// CanRollover allows it, so bookings that were still going ahead count as played, and otherwise
+// cancelled by the system if allowed, so unconfirmed holds lapse. Other statuses are kept.
+func RolloverStatus(s BookingStatus) BookingStatus {
+	for _, end := range []BookingStatus{BookingCompleted, BookingCancelledBySystem} {
+		if CanRollover(s, end) {
+			return end
+		}
+	}
+	return s
+}
+
+// Booking_Archive is a past booking moved out of Bookings by the nightly rollover. It keeps the
+// booking's ID, so its status history still applies, and copies the court and sport names so it
+// still reads the same once those are deleted.
+type Booking_Archive struct {
+	Booking_ID     uint          `gorm:"column:Booking_ID;primaryKey;autoIncrement:false" json:"Booking_ID"`
+	Customer_ID    uint          `gorm:"column:Customer_ID;index:idx_archive_customer_date;not null" json:"Customer_ID"`
+	Sport_ID       uint          `gorm:"column:Sport_ID;not null" json:"Sport_ID"`
+	Sport_Name     string        `gorm:"column:Sport_Name" json:"Sport_Name"`
+	Court_ID       uint          `gorm:"column:Court_ID;not null" json:"Court_ID"`
+	Court_Name     string        `gorm:"column:Court_Name" json:"Court_Name"`
+	Booking_Status BookingStatus `gorm:"column:Booking_Status;not null" json:"Booking_Status"`
+	Booking_Time   int           `gorm:"column:Booking_Time;not null" json:"Booking_Time"`
+	Booking_Date   string        `gorm:"column:Booking_Date;size:10;index:idx_archive_customer_date;not null" json:"Booking_Date"`
+	Archived_At    time.Time     `gorm:"column:Archived_At;not null" json:"Archived_At"`
+}
+
+// Booking_StatusHistory records one status change of a booking. The entry written when a
+// booking is created has an empty From_Status. Changed_By is the Admin_ID of the admin who
+// made the change, and null when the customer or the system made it.
+type Booking_StatusHistory struct {
+	History_ID  uint          `gorm:"column:History_ID;primaryKey;autoIncrement" json:"History_ID"`
+	Booking_ID  uint          `gorm:"column:Booking_ID;index;not null" json:"Booking_ID"`
+	From_Status BookingStatus `gorm:"column:From_Status" json:"From_Status"`
+	To_Status   BookingStatus `gorm:"column:To_Status;not null" json:"To_Status"`
+	Changed_At  time.Time     `gorm:"column:Changed_At;not null" json:"Changed_At"`
+	Note        string        `gorm:"column:Note" json:"Note"`
+	Changed_By  *uint         `gorm:"column:Changed_By" json:"Changed_By"`
+}
+
+// Admin roles. At least one active super admin must exist at all times.
+const (
+	AdminRoleSuperAdmin = "super_admin"
+	AdminRoleAdmin      = "admin"
+)
+
+// Admin account states. Invited admins have no usable password until they accept the invite.
+const (
+	AdminStatusActive      = "active"
+	AdminStatusInvited     = "invited"
+	AdminStatusDeactivated = "deactivated"
+)
+
 type Admin struct {
-	Admin_ID uint   `gorm:"column:Admin_ID;primaryKey;autoIncrement" json:"Admin_ID"`
-	Username string `gorm:"column:Username;unique;not null" json:"Username"`
-	Password string `gorm:"column:Password;not null" json:"Password"`
+	Admin_ID       uint       `gorm:"column:Admin_ID;primaryKey;autoIncrement" json:"Admin_ID"`
+	Username       string     `gorm:"column:Username;unique;not null" json:"Username"`
+	Password       string     `gorm:"column:Password;not null" json:"-"`
+	Role           string     `gorm:"column:Role;not null;default:admin" json:"Role"`
+	Status         string     `gorm:"column:Status;not null;default:active" json:"Status"`
+	TOTP_Secret    string     `gorm:"column:TOTP_Secret" json:"-"`
+	TOTP_Enabled   bool       `gorm:"column:TOTP_Enabled;not null;default:false" json:"TOTP_Enabled"`
+	TOTP_Last_Step int64      `gorm:"column:TOTP_Last_Step;not null;default:0" json:"-"`
+	Invite_Token   string     `gorm:"column:Invite_Token;index" json:"-"`
+	Invite_Expires *time.Time `gorm:"column:Invite_Expires" json:"-"`
+	Last_Login     *time.Time `gorm:"column:Last_Login" json:"Last_Login"`
+}
+
+// Admin_LoginAttempt tracks consecutive failed admin logins for one key,
+// where the key is either "user:<username>" or "ip:<address>".
+type Admin_LoginAttempt struct {
+	ID           uint       `gorm:"column:ID;primaryKey;autoIncrement" json:"ID"`
+	Attempt_Key  string     `gorm:"column:Attempt_Key;unique;not null" json:"Attempt_Key"`
+	Failures     int        `gorm:"column:Failures;not null;default:0" json:"Failures"`
+	Locked_Until *time.Time `gorm:"column:Locked_Until" json:"Locked_Until"`
+}
+
+// Admin_RecoveryCode stores the hash of a single-use TOTP recovery code.
+type Admin_RecoveryCode struct {
+	ID        uint       `gorm:"column:ID;primaryKey;autoIncrement" json:"ID"`
+	Admin_ID  uint       `gorm:"column:Admin_ID;index;not null" json:"Admin_ID"`
+	Code_Hash string     `gorm:"column:Code_Hash;not null" json:"-"`
+	Used_At   *time.Time `gorm:"column:Used_At" json:"Used_At"`
 }
 
-var DB *gorm.DB
+// Idempotency_Key stores the response to a mutating request sent with an Idempotency-Key header,
+// so that a retry under the same key is answered with it instead of repeating the change.
+type Idempotency_Key struct {
+	Key string `gorm:"column:Key;primaryKey;size:255" json:"Key"`
+	// Request_Hash identifies the method, path and body the key was first used with.
+	Request_Hash string `gorm:"column:Request_Hash;size:64;not null" json:"Request_Hash"`
+	// Status is the stored response's status code, or 0 while the first request is in flight.
+	Status int `gorm:"column:Status;not null;default:0" json:"Status"`
+	// Headers holds the stored response's headers as a JSON object.
+	Headers    string    `gorm:"column:Headers" json:"Headers"`
+	Body       []byte    `gorm:"column:Body" json:"Body"`
+	Created_At time.Time `gorm:"column:Created_At;not null" json:"Created_At"`
+	Expires_At time.Time `gorm:"column:Expires_At;index;not null" json:"Expires_At"`
+}
 
 func (Customer) TableName() string {
 	return "Customer"
@@ -113,48 +357,103 @@ func (Court) TableName() string {
 	return "Court"
 }
 
-func (Court_TimeSlots) TableName() string {
-	return "Court_TimeSlots"
+func (Court_Blackout) TableName() string {
+	return "Court_Blackouts"
 }
 
 func (Bookings) TableName() string {
 	return "Bookings"
 }
 
+func (Booking_StatusHistory) TableName() string {
+	return "Booking_StatusHistory"
+}
+
+func (Booking_Archive) TableName() string {
+	return "Bookings_Archive"
+}
+
 func (Admin) TableName() string {
 	return "Admin"
 }
 
-func init() {
-	var err error
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		// Fallback for local development or when env var is missing
-		// Warning: Defaults to localhost postgres. Ensure a local postgres is running or set DATABASE_URL.
-		dsn = "host=localhost user=postgres password=postgres dbname=courtlink port=5432 sslmode=disable"
-		fmt.Println("DATABASE_URL not set, using default: ", dsn)
-	}
+func (Admin_LoginAttempt) TableName() string {
+	return "Admin_LoginAttempts"
+}
+
+func (Admin_RecoveryCode) TableName() string {
+	return "Admin_RecoveryCodes"
+}
+
+func (Idempotency_Key) TableName() string {
+	return "Idempotency_Keys"
+}
 
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// Supported values of DB_DRIVER.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// ErrUnsupportedDriver is returned by OpenDriver for a driver other than DriverPostgres or DriverSQLite.
+var ErrUnsupportedDriver = errors.New("unsupported DB_DRIVER")
+
+// Open connects to dsn with the named driver, retrying a database that is not reachable yet
+// as retry allows, and sizes the connection pool. It does not touch the schema; run the migrate
+// subcommand for that.
+func Open(driver, dsn string, pool PoolConfig, retry RetryConfig) (*gorm.DB, error) {
+	var db *gorm.DB
+	err := retryConnect(retry, time.Sleep, func() error {
+		var err error
+		db, err = OpenDriver(driver, dsn, &gorm.Config{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
 	if err != nil {
-		fmt.Printf("Failed to connect to database: %v\n", err)
-	} else {
-		fmt.Println("Successfully connected to the database")
+		return nil, err
+	}
+	pool.Apply(sqlDB)
+	fmt.Printf("Successfully connected to the %s database\n", driver)
+	return db, nil
+}
 
-		// Migrate independent tables first
-		if err := DB.AutoMigrate(&Customer{}, &Sport{}); err != nil {
-			fmt.Printf("Failed to migrate Customer/Sport: %v\n", err)
-		}
+// OpenDriver connects to dsn with the named driver.
+func OpenDriver(driver, dsn string, config *gorm.Config) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch driver {
+	case DriverPostgres:
+		dialector = postgres.Open(dsn)
+	case DriverSQLite:
+		// Enforce foreign keys like Postgres does, and wait on locks rather than failing
+		dialector = sqlite.Open(withQuery(dsn, "_foreign_keys=on&_busy_timeout=5000"))
+	default:
+		return nil, fmt.Errorf("%w %q (want %q or %q)", ErrUnsupportedDriver, driver, DriverPostgres, DriverSQLite)
+	}
 
-		// Migrate Court (depends on Sport?) - check struct
-		// Court struct has 'Sport_id' and 'Sport *Sport'.
-		if err := DB.AutoMigrate(&Court{}); err != nil {
-			fmt.Printf("Failed to migrate Court: %v\n", err)
-		}
+	db, err := gorm.Open(dialector, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
 
-		// Migrate dependent tables
-		if err := DB.AutoMigrate(&Court_TimeSlots{}, &Admin{}, &Bookings{}); err != nil {
-			fmt.Printf("Failed to migrate dependent tables: %v\n", err)
+	if driver == DriverSQLite {
+		// SQLite allows one writer at a time; a single connection serialises writes instead of
+		// returning "database is locked", and keeps in-memory databases from splitting per connection
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
 		}
+		sqlDB.SetMaxOpenConns(1)
+	}
+	return db, nil
+}
+
+// withQuery appends query parameters to a SQLite file name or URI.
+func withQuery(dsn, query string) string {
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + query
 	}
+	return dsn + "?" + query
 }
@@ -0,0 +1,32 @@
+package DataBase
+
+import "testing"
+
+func TestRolloverStatus(t *testing.T) {
+	cases := []struct {
+		from, to BookingStatus
+	}{
+		{BookingHeld, BookingCancelledBySystem},
+		{BookingConfirmed, BookingCompleted},
+		{BookingCheckedIn, BookingCompleted},
+		{BookingCompleted, BookingCompleted},
+		{BookingNoShow, BookingNoShow},
+		{BookingCancelledByUser, BookingCancelledByUser},
+		{BookingCancelledByAdmin, BookingCancelledByAdmin},
+		{BookingCancelledBySystem, BookingCancelledBySystem},
+	}
+	for _, tc := range cases {
+		to := RolloverStatus(tc.from)
+		if to != tc.to {
+			t.Errorf("%s: expected the rollover to end on %s, got %s", tc.from, tc.to, to)
+		}
+		if to != tc.from && !CanRollover(tc.from, to) {
+			t.Errorf("%s: the rollover moves to %s, which the state machine does not allow", tc.from, to)
+		}
+	}
+
+	// Completing a booking that was never checked in is for the rollover alone
+	if CanTransition(BookingConfirmed, BookingCompleted) {
+		t.Error("expected admins to have to check a booking in before completing it")
+	}
+}
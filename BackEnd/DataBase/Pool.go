@@ -0,0 +1,52 @@
+package DataBase
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PoolConfig sizes the connection pool behind a *gorm.DB.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// Apply sets the pool limits on sqlDB. A driver limited to one connection, as SQLite is,
+// keeps that limit.
+func (p PoolConfig) Apply(sqlDB *sql.DB) {
+	if sqlDB.Stats().MaxOpenConnections != 1 {
+		sqlDB.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	sqlDB.SetMaxIdleConns(p.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(p.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(p.ConnMaxIdleTime)
+}
+
+// RetryConfig controls how long Open keeps trying to reach a database that is not up yet.
+type RetryConfig struct {
+	Attempts   int
+	Backoff    time.Duration // wait after the first failure, doubled after each one
+	MaxBackoff time.Duration
+}
+
+// retryConnect calls connect until it succeeds or the attempts run out, sleeping between tries,
+// and returns the last error. An unsupported driver is not worth retrying.
+func retryConnect(config RetryConfig, sleep func(time.Duration), connect func() error) error {
+	wait := config.Backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = connect()
+		if err == nil || errors.Is(err, ErrUnsupportedDriver) || attempt >= config.Attempts {
+			return err
+		}
+		fmt.Printf("Database not reachable (attempt %d of %d): %v; retrying in %s\n", attempt, config.Attempts, err, wait)
+		sleep(wait)
+		if wait *= 2; wait > config.MaxBackoff {
+			wait = config.MaxBackoff
+		}
+	}
+}
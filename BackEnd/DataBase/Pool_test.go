@@ -0,0 +1,63 @@
+package DataBase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestRetry(t *testing.T) {
+	config := RetryConfig{Attempts: 5, Backoff: time.Second, MaxBackoff: 3 * time.Second}
+
+	cases := []struct {
+		name     string
+		failures int
+		err      error
+		calls    int
+		waits    []time.Duration
+	}{
+		{"Up at once", 0, errors.New("down"), 1, nil},
+		{"Up on the third try", 2, errors.New("down"), 3, []time.Duration{time.Second, 2 * time.Second}},
+		{"Never up", 10, errors.New("down"), 5, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}},
+		{"Unsupported driver", 10, ErrUnsupportedDriver, 1, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var waits []time.Duration
+			calls := 0
+			err := retryConnect(config, func(d time.Duration) { waits = append(waits, d) }, func() error {
+				if calls++; calls <= tc.failures {
+					return tc.err
+				}
+				return nil
+			})
+			if (err != nil) != (tc.failures >= tc.calls) || calls != tc.calls {
+				t.Errorf("expected %d call(s), got %d (error: %v)", tc.calls, calls, err)
+			}
+			if len(waits) != len(tc.waits) {
+				t.Fatalf("expected waits %v, got %v", tc.waits, waits)
+			}
+			for i := range waits {
+				if waits[i] != tc.waits[i] {
+					t.Errorf("expected waits %v, got %v", tc.waits, waits)
+				}
+			}
+		})
+	}
+}
+
+func TestPoolApply_KeepsSQLiteToOneConnection(t *testing.T) {
+	db, err := OpenDriver(DriverSQLite, "file::memory:", &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	PoolConfig{MaxOpenConns: 20, MaxIdleConns: 10}.Apply(sqlDB)
+	if open := sqlDB.Stats().MaxOpenConnections; open != 1 {
+		t.Errorf("expected SQLite to keep one connection, got %d", open)
+	}
+}
@@ -0,0 +1,239 @@
+// Package Events tells connected clients when court availability changes, so they stop showing
+// slots that have just been taken. Handlers publish a Change to the Broker after every write
+// that takes or frees slots; the Broker numbers it, keeps the latest ones so a client that
+// reconnects can catch up, and passes it on to every subscriber it concerns.
+package Events
+
+import (
+	"BackEnd/DataBase"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind says what happened to the slots of a Change.
+type Kind string
+
+const (
+	Booked         Kind = "booked"
+	Cancelled      Kind = "cancelled"
+	Reset          Kind = "reset"
+	BlackedOut     Kind = "blackout"
+	BlackoutLifted Kind = "blackout_lifted"
+	// Resync tells a reconnecting client that changes it missed are no longer kept, so it must
+	// load availability again.
+	Resync Kind = "resync"
+)
+
+// DefaultBacklog is how many changes a Broker keeps for reconnecting clients by default.
+const DefaultBacklog = 1000
+
+// bufferSize is how many changes a subscriber may fall behind before it is dropped.
+const bufferSize = 64
+
+// Change reports that slots may have changed. Fields left zero widen it: no Slot means every
+// slot of the day, no Date every day, no CourtID every court of the sport, and no SportID
+// every court there is.
+type Change struct {
+	ID      string    `json:"id"`
+	Kind    Kind      `json:"kind"`
+	SportID uint      `json:"sport_id,omitempty"`
+	CourtID uint      `json:"court_id,omitempty"`
+	Date    string    `json:"date,omitempty"`
+	Slot    *int      `json:"slot,omitempty"`
+	At      time.Time `json:"at"`
+
+	seq uint64
+}
+
+// ForBooking is the change of kind to booking's slot.
+func ForBooking(kind Kind, booking DataBase.Bookings) Change {
+	change := Change{Kind: kind, SportID: booking.Sport_ID, CourtID: booking.Court_ID}
+	if booking.Booking_Date != nil {
+		slot := booking.Booking_Time
+		change.Date, change.Slot = *booking.Booking_Date, &slot
+	}
+	return change
+}
+
+// ForCourt is the change of kind to every slot of court on every day.
+func ForCourt(kind Kind, court DataBase.Court) Change {
+	return Change{Kind: kind, SportID: court.Sport_id, CourtID: court.Court_ID}
+}
+
+// ForBlackout is the change of kind to the slots blackout covers on court.
+func ForBlackout(kind Kind, blackout DataBase.Court_Blackout, court DataBase.Court) Change {
+	return Change{Kind: kind, SportID: court.Sport_id, CourtID: blackout.Court_ID, Date: blackout.Blackout_Date,
+		Slot: blackout.Slot_Index}
+}
+
+// Filter selects the changes a subscriber wants. A zero field matches anything; a subscriber
+// to one court sets its sport as well, so it also hears of changes to the whole sport.
+type Filter struct {
+	SportID uint
+	CourtID uint
+}
+
+// Matches reports whether change concerns the subscriber.
+func (f Filter) Matches(change Change) bool {
+	if f.SportID != 0 && change.SportID != 0 && f.SportID != change.SportID {
+		return false
+	}
+	return f.CourtID == 0 || change.CourtID == 0 || f.CourtID == change.CourtID
+}
+
+// Broker passes changes from the handlers that make them to the streams that report them. It
+// lives in the process, so only clients of the same server instance hear of a change. A nil
+// Broker ignores every change, and its subscriptions never receive one.
+type Broker struct {
+	mu          sync.Mutex
+	epoch       string
+	seq         uint64
+	backlog     []Change
+	limit       int
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+// NewBroker returns a Broker that keeps the last backlog changes for reconnecting clients.
+func NewBroker(backlog int) *Broker {
+	return &Broker{
+		// IDs handed out before a restart must not match changes made after it
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		limit:       backlog,
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+// Subscription receives the changes matching its filter on C until it is closed. C is also
+// closed if the subscriber falls too far behind, or when the Broker closes; the client then
+// reconnects and catches up from its last event ID.
+type Subscription struct {
+	C <-chan Change
+	// Missed lists the changes made since the last event ID given to Subscribe, or a single
+	// Resync change if they are no longer all kept.
+	Missed []Change
+
+	c      chan Change
+	filter Filter
+	broker *Broker
+}
+
+// Publish numbers change and passes it on. It never blocks: a subscriber whose buffer is full
+// is dropped instead.
+func (b *Broker) Publish(change Change) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	b.seq++
+	change.seq, change.ID = b.seq, b.id(b.seq)
+	if change.At.IsZero() {
+		change.At = time.Now().UTC()
+	}
+	if b.limit > 0 {
+		if len(b.backlog) == b.limit {
+			b.backlog = append(b.backlog[:0], b.backlog[1:]...)
+		}
+		b.backlog = append(b.backlog, change)
+	}
+
+	for sub := range b.subscribers {
+		if !sub.filter.Matches(change) {
+			continue
+		}
+		select {
+		case sub.c <- change:
+		default:
+			b.drop(sub)
+		}
+	}
+}
+
+// Subscribe starts a subscription to the changes matching filter. lastEventID is the ID of the
+// last change the client saw, or "" for a new client; the changes it missed since are in
+// Missed, and every later one arrives on C.
+func (b *Broker) Subscribe(filter Filter, lastEventID string) *Subscription {
+	sub := &Subscription{c: make(chan Change, bufferSize), filter: filter, broker: b}
+	sub.C = sub.c
+	if b == nil {
+		return sub
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(sub.c)
+		return sub
+	}
+	if lastEventID != "" {
+		sub.Missed = b.since(filter, lastEventID)
+	}
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// since returns the changes matching filter made after the one with ID lastEventID. Callers
+// hold the lock.
+func (b *Broker) since(filter Filter, lastEventID string) []Change {
+	epoch, seqText, _ := strings.Cut(lastEventID, "-")
+	seq, err := strconv.ParseUint(seqText, 10, 64)
+	oldest := b.seq + 1
+	if len(b.backlog) > 0 {
+		oldest = b.backlog[0].seq
+	}
+	// A restart, a garbled ID, or changes already dropped from the backlog
+	if err != nil || epoch != b.epoch || seq > b.seq || seq+1 < oldest {
+		return []Change{{ID: b.id(b.seq), Kind: Resync, At: time.Now().UTC(), seq: b.seq}}
+	}
+
+	missed := []Change{}
+	for _, change := range b.backlog {
+		if change.seq > seq && filter.Matches(change) {
+			missed = append(missed, change)
+		}
+	}
+	return missed
+}
+
+func (b *Broker) id(seq uint64) string {
+	return b.epoch + "-" + strconv.FormatUint(seq, 10)
+}
+
+// drop ends sub. Callers hold the lock.
+func (b *Broker) drop(sub *Subscription) {
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.c)
+	}
+}
+
+// Close ends the subscription.
+func (s *Subscription) Close() {
+	if s.broker == nil {
+		return
+	}
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.drop(s)
+}
+
+// Close ends every subscription and ignores changes published afterwards, so open streams
+// finish and the server can shut down.
+func (b *Broker) Close() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subscribers {
+		b.drop(sub)
+	}
+}
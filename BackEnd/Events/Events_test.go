@@ -0,0 +1,118 @@
+package Events
+
+import (
+	"BackEnd/DataBase"
+	"testing"
+	"time"
+)
+
+// receive waits briefly for the next change on sub.
+func receive(t *testing.T, sub *Subscription) (Change, bool) {
+	t.Helper()
+	select {
+	case change, ok := <-sub.C:
+		return change, ok
+	case <-time.After(time.Second):
+		t.Fatal("no change arrived")
+		return Change{}, false
+	}
+}
+
+func TestFilter(t *testing.T) {
+	court := Filter{SportID: 1, CourtID: 10}
+	sport := Filter{SportID: 1}
+	tests := []struct {
+		name              string
+		change            Change
+		court, sport, all bool
+	}{
+		{"Same court", Change{SportID: 1, CourtID: 10}, true, true, true},
+		{"Other court of the sport", Change{SportID: 1, CourtID: 11}, false, true, true},
+		{"Whole sport", Change{SportID: 1}, true, true, true},
+		{"Other sport", Change{SportID: 2, CourtID: 20}, false, false, true},
+		{"Everything", Change{}, true, true, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if court.Matches(tc.change) != tc.court || sport.Matches(tc.change) != tc.sport || (Filter{}).Matches(tc.change) != tc.all {
+				t.Errorf("unexpected match for %+v", tc.change)
+			}
+		})
+	}
+}
+
+func TestBroker(t *testing.T) {
+	broker := NewBroker(3)
+	tennis := broker.Subscribe(Filter{SportID: 1}, "")
+	defer tennis.Close()
+
+	date := "2026-01-31"
+	booking := DataBase.Bookings{Sport_ID: 1, Court_ID: 10, Booking_Time: 4, Booking_Date: &date}
+	broker.Publish(ForBooking(Booked, booking))
+	broker.Publish(Change{Kind: Reset, SportID: 2})
+	broker.Publish(ForBooking(Cancelled, booking))
+
+	first, _ := receive(t, tennis)
+	if first.Kind != Booked || first.CourtID != 10 || first.Date != date || first.Slot == nil || *first.Slot != 4 || first.ID == "" {
+		t.Fatalf("unexpected change %+v", first)
+	}
+	// The other sport's reset is skipped
+	if second, _ := receive(t, tennis); second.Kind != Cancelled {
+		t.Fatalf("expected the cancellation next, got %+v", second)
+	}
+
+	t.Run("Catches up from the last event ID", func(t *testing.T) {
+		sub := broker.Subscribe(Filter{SportID: 1}, first.ID)
+		defer sub.Close()
+		if len(sub.Missed) != 1 || sub.Missed[0].Kind != Cancelled {
+			t.Errorf("expected the cancellation to be replayed, got %+v", sub.Missed)
+		}
+	})
+
+	t.Run("Resyncs when changes are no longer kept", func(t *testing.T) {
+		broker.Publish(Change{Kind: Reset})
+		broker.Publish(Change{Kind: Reset})
+		for _, id := range []string{first.ID, "garbled", "0-1", first.ID + "9"} {
+			sub := broker.Subscribe(Filter{}, id)
+			if len(sub.Missed) != 1 || sub.Missed[0].Kind != Resync || sub.Missed[0].ID == "" {
+				t.Errorf("expected a resync for %q, got %+v", id, sub.Missed)
+			}
+			sub.Close()
+		}
+	})
+
+	t.Run("Drops subscribers that fall behind", func(t *testing.T) {
+		slow := broker.Subscribe(Filter{}, "")
+		for i := 0; i <= bufferSize; i++ {
+			broker.Publish(Change{Kind: Reset})
+		}
+		for range slow.C {
+		}
+	})
+
+	t.Run("Close ends every subscription", func(t *testing.T) {
+		broker.Close()
+		// Drains what was buffered before the close, then ends
+		for range tennis.C {
+		}
+		if _, ok := <-broker.Subscribe(Filter{}, "").C; ok {
+			t.Error("expected a subscription to a closed broker to be closed")
+		}
+		broker.Publish(Change{Kind: Reset})
+	})
+
+	// A nil broker ignores changes and its subscriptions stay open without receiving any
+	var none *Broker
+	sub := none.Subscribe(Filter{}, "stale-7")
+	none.Publish(Change{Kind: Reset})
+	select {
+	case change := <-sub.C:
+		t.Errorf("expected nothing from a nil broker, got %+v", change)
+	default:
+	}
+	if len(sub.Missed) != 0 {
+		t.Errorf("expected nothing missed from a nil broker, got %+v", sub.Missed)
+	}
+	sub.Close()
+	none.Close()
+}
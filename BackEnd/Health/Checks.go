@@ -0,0 +1,66 @@
+package Health
+
+import (
+	"BackEnd/Migrations"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Database checks that db answers a ping.
+func Database(db *gorm.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// Schema checks that no migration is pending, so a database migrated down under a running
+// server is noticed.
+func Schema(db *gorm.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return Migrations.EnsureCurrent(db.WithContext(ctx))
+	}
+}
+
+// Heartbeat records when something last showed it was alive, such as a successful JWKS
+// refresh or a scheduler job. The zero value has never beaten.
+type Heartbeat struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+// Beat records that the watched component is alive now.
+func (h *Heartbeat) Beat() {
+	h.mu.Lock()
+	h.last = time.Now()
+	h.mu.Unlock()
+}
+
+// Last returns the time of the latest beat, or the zero time if there has been none.
+func (h *Heartbeat) Last() time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.last
+}
+
+// Fresh checks that h has beaten within maxAge.
+func (h *Heartbeat) Fresh(maxAge time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		last := h.Last()
+		if last.IsZero() {
+			return errors.New("no heartbeat yet")
+		}
+		if age := time.Since(last); age > maxAge {
+			return fmt.Errorf("last heartbeat %s ago, more than %s", age.Round(time.Second), maxAge)
+		}
+		return nil
+	}
+}
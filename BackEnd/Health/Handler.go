@@ -0,0 +1,53 @@
+package Health
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Handler serves the probe endpoints. Live holds the checks whose failure means the process
+// should be restarted; Ready holds those that must pass before it is sent traffic.
+type Handler struct {
+	Live  *Checker
+	Ready *Checker
+}
+
+// NewHandler returns a Handler running the given checkers.
+func NewHandler(live, ready *Checker) *Handler {
+	return &Handler{Live: live, Ready: ready}
+}
+
+// Healthz reports whether the process is alive.
+// @Summary Liveness probe
+// @Description Passes while the process is running and its scheduler is still running jobs.
+// @Tags health
+// @Produce json
+// @Success 200 {object} Health.Report
+// @Failure 503 {object} Health.Report "A check failed"
+// @Router /healthz [get]
+func (h *Handler) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, h.Live.Run(r.Context()))
+}
+
+// Readyz reports whether the server can handle requests.
+// @Summary Readiness probe
+// @Description Passes when the database answers, no migration is pending, the JWKS keys have been refreshed recently and the scheduler is running.
+// @Tags health
+// @Produce json
+// @Success 200 {object} Health.Report
+// @Failure 503 {object} Health.Report "A check failed"
+// @Router /readyz [get]
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, h.Ready.Run(r.Context()))
+}
+
+func writeReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.OK() {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
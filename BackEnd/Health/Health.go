@@ -0,0 +1,75 @@
+// Package Health answers the liveness and readiness probes of the container orchestrator.
+// Each probe runs a list of named checks; the probe passes only when all of them do.
+package Health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Check is the outcome of one named check.
+type Check struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// Report is the outcome of a probe. Status is "ok" when every check passed, otherwise "unavailable".
+type Report struct {
+	Status string  `json:"status"`
+	Checks []Check `json:"checks"`
+}
+
+// OK reports whether every check passed.
+func (r Report) OK() bool {
+	return r.Status == "ok"
+}
+
+type namedCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// Checker runs a fixed list of checks, each bounded by Timeout.
+type Checker struct {
+	Timeout time.Duration
+	checks  []namedCheck
+}
+
+// NewChecker returns a Checker with no checks that gives each check timeout to finish.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{Timeout: timeout}
+}
+
+// Add registers a check. It should return promptly once ctx is done.
+func (c *Checker) Add(name string, check func(ctx context.Context) error) *Checker {
+	c.checks = append(c.checks, namedCheck{name, check})
+	return c
+}
+
+// Run runs every check concurrently and reports them in the order they were added.
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{Status: "ok", Checks: make([]Check, len(c.checks))}
+	var wg sync.WaitGroup
+	for i, nc := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+			defer cancel()
+			report.Checks[i] = Check{Name: nc.name, OK: true}
+			if err := nc.check(ctx); err != nil {
+				report.Checks[i] = Check{Name: nc.name, Error: err.Error()}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, check := range report.Checks {
+		if !check.OK {
+			report.Status = "unavailable"
+		}
+	}
+	return report
+}
@@ -0,0 +1,109 @@
+package Health
+
+import (
+	"BackEnd/Migrations"
+	"BackEnd/Repository/TestStore"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestChecker(t *testing.T) {
+	checker := NewChecker(50*time.Millisecond).
+		Add("passes", func(ctx context.Context) error { return nil }).
+		Add("fails", func(ctx context.Context) error { return errors.New("broken") }).
+		Add("hangs", func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() })
+
+	start := time.Now()
+	report := checker.Run(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected a hanging check to be cut off by the timeout, took %s", elapsed)
+	}
+	if report.OK() || len(report.Checks) != 3 {
+		t.Fatalf("expected a failed report with three checks, got %+v", report)
+	}
+	if !report.Checks[0].OK || report.Checks[1].Error != "broken" || report.Checks[2].OK {
+		t.Errorf("unexpected checks: %+v", report.Checks)
+	}
+
+	if report := NewChecker(time.Second).Run(context.Background()); !report.OK() {
+		t.Errorf("expected a checker without checks to pass, got %+v", report)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	var heartbeat Heartbeat
+	fresh := heartbeat.Fresh(time.Minute)
+	if err := fresh(context.Background()); err == nil {
+		t.Error("expected a heartbeat that never beat to fail")
+	}
+	heartbeat.Beat()
+	if err := fresh(context.Background()); err != nil {
+		t.Errorf("expected a fresh heartbeat to pass, got %v", err)
+	}
+	heartbeat.last = time.Now().Add(-2 * time.Minute)
+	if err := fresh(context.Background()); err == nil {
+		t.Error("expected a stale heartbeat to fail")
+	}
+}
+
+func TestDatabaseChecks(t *testing.T) {
+	db := TestStore.OpenDB(t)
+	ctx := context.Background()
+	if err := Database(db)(ctx); err != nil {
+		t.Errorf("expected the database to answer, got %v", err)
+	}
+	if err := Schema(db)(ctx); err != nil {
+		t.Errorf("expected a migrated schema to pass, got %v", err)
+	}
+
+	if _, err := Migrations.Down(db, 1); err != nil {
+		t.Fatalf("failed to migrate down: %v", err)
+	}
+	if err := Schema(db)(ctx); !errors.Is(err, Migrations.ErrSchemaBehind) {
+		t.Errorf("expected ErrSchemaBehind with a pending migration, got %v", err)
+	}
+
+	sqlDB, _ := db.DB()
+	sqlDB.Close()
+	if err := Database(db)(ctx); err == nil {
+		t.Error("expected a closed database to fail")
+	}
+}
+
+func TestProbes(t *testing.T) {
+	var heartbeat Heartbeat
+	h := NewHandler(NewChecker(time.Second), NewChecker(time.Second).Add("scheduler", heartbeat.Fresh(time.Minute)))
+
+	cases := []struct {
+		name    string
+		handler http.HandlerFunc
+		beat    bool
+		status  int
+	}{
+		{"Live", h.Healthz, false, http.StatusOK},
+		{"Not ready", h.Readyz, false, http.StatusServiceUnavailable},
+		{"Ready", h.Readyz, true, http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.beat {
+				heartbeat.Beat()
+			}
+			req, _ := http.NewRequest("GET", "/readyz", nil)
+			rr := httptest.NewRecorder()
+			tc.handler(rr, req)
+			if rr.Code != tc.status {
+				t.Fatalf("expected status %d, got %d", tc.status, rr.Code)
+			}
+			var report Report
+			if err := json.NewDecoder(rr.Body).Decode(&report); err != nil || report.OK() != (tc.status == http.StatusOK) {
+				t.Errorf("unexpected report %+v (error: %v)", report, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+// Package Idempotency lets clients retry mutating requests safely. A POST, PUT, PATCH or DELETE
+// sent with an Idempotency-Key header is handled once; a repeat under the same key within the
+// TTL is answered with the stored response instead of being handled again.
+package Idempotency
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// Header carries the client's key for a request.
+	Header = "Idempotency-Key"
+	// ReplayedHeader is set to "true" on a stored response answered again.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+	// maxBodySize caps the body kept to compare repeats by. It matches the largest upload the
+	// API takes, an import bundle.
+	maxBodySize = 32 << 20
+)
+
+// Middleware handles each keyed mutating request once. The key is reserved before next runs and
+// the response stored when it finishes; a repeat with the same method, path and body gets the
+// stored response back, a different request under the key is rejected with 422
+// IDEMPOTENCY_KEY_REUSED and a repeat while the first is still running with 409
+// IDEMPOTENCY_KEY_IN_FLIGHT. Server errors are not stored, so the request can be retried under the
+// same key. Keys belong to the caller that sent them, so another caller's key is never answered
+// with a stored response. Requests without the header pass straight through, and so do requests
+// to the routes that issue sessions and credentials, whose responses must never be kept.
+func Middleware(store Repository.Store, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(Header)
+			if key == "" || !mutating(r.Method) || issuesCredentials(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !validKey(key) {
+				APIError.Validation(w, r, "Invalid Idempotency-Key",
+					APIError.Field(Header, "must be 1 to 255 printable ASCII characters"))
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+			var maxBytes *http.MaxBytesError
+			if errors.As(err, &maxBytes) {
+				APIError.Write(w, r, http.StatusRequestEntityTooLarge, APIError.MalformedRequest, "The request body is larger than 32 MiB")
+				return
+			} else if err != nil {
+				APIError.Malformed(w, r, "Failed to read request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			key = scopedKey(r, key)
+			now := time.Now()
+			record := DataBase.Idempotency_Key{Key: key, Request_Hash: requestHash(r, body),
+				Created_At: now, Expires_At: now.Add(ttl)}
+			keys := store.IdempotencyKeys()
+			existing, err := reserve(keys, &record)
+			if err != nil {
+				APIError.Internal(w, r, "Failed to check Idempotency-Key", err)
+				return
+			}
+			if existing != nil {
+				answerRepeat(w, r, record, *existing)
+				return
+			}
+
+			rec := &recorder{ResponseWriter: w}
+			completed := false
+			// A handler that panics leaves the key free rather than in flight until it expires
+			defer func() {
+				if !completed {
+					if err := keys.Release(key); err != nil {
+						log.Printf("request %s: failed to release Idempotency-Key: %v", APIError.RequestIDFrom(r.Context()), err)
+					}
+				}
+			}()
+			next.ServeHTTP(rec, r)
+
+			status := rec.statusCode()
+			if status >= http.StatusInternalServerError {
+				return
+			}
+			if err := keys.Complete(key, status, storedHeaders(rec.header), rec.body.Bytes()); err != nil {
+				log.Printf("request %s: failed to store response for Idempotency-Key: %v", APIError.RequestIDFrom(r.Context()), err)
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+// Purge deletes the keys that expired before now.
+func Purge(store Repository.Store, now time.Time) error {
+	purged, err := store.IdempotencyKeys().Purge(now)
+	if err != nil {
+		return err
+	}
+	log.Printf("Purged %d expired idempotency key(s)", purged)
+	return nil
+}
+
+// reserve claims record's key. If an unexpired request already holds the key, its record is
+// returned instead; an expired one is released and the key claimed again.
+func reserve(keys Repository.IdempotencyRepository, record *DataBase.Idempotency_Key) (*DataBase.Idempotency_Key, error) {
+	for {
+		err := keys.Reserve(record)
+		if !errors.Is(err, Repository.ErrDuplicate) {
+			return nil, err
+		}
+		existing, err := keys.Find(record.Key)
+		switch {
+		case errors.Is(err, Repository.ErrNotFound):
+			// Released since the reservation failed
+			continue
+		case err != nil:
+			return nil, err
+		case existing.Expires_At.After(record.Created_At):
+			return &existing, nil
+		}
+		if err := keys.Release(record.Key); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// answerRepeat answers a request whose key is held by existing.
+func answerRepeat(w http.ResponseWriter, r *http.Request, record, existing DataBase.Idempotency_Key) {
+	switch {
+	case existing.Request_Hash != record.Request_Hash:
+		APIError.Write(w, r, http.StatusUnprocessableEntity, APIError.IdempotencyKeyReused,
+			"Idempotency-Key was already used for a different request")
+	case existing.Status == 0:
+		w.Header().Set("Retry-After", "1")
+		APIError.Write(w, r, http.StatusConflict, APIError.IdempotencyKeyInFlight,
+			"A request with this Idempotency-Key is still being processed")
+	default:
+		var headers map[string][]string
+		json.Unmarshal([]byte(existing.Headers), &headers)
+		for name, values := range headers {
+			w.Header()[name] = values
+		}
+		w.Header().Set(ReplayedHeader, "true")
+		w.WriteHeader(existing.Status)
+		w.Write(existing.Body)
+	}
+}
+
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// issuesCredentials reports whether path belongs to a route that answers with a session token,
+// a TOTP secret, recovery codes or an invite or reset token. Replaying a stored login would
+// also get around the TOTP replay guard and the lockout.
+func issuesCredentials(path string) bool {
+	return path == "/AdminLogin" || path == "/admin/bootstrap" || path == "/admin/admins" ||
+		strings.HasPrefix(path, "/admin/totp/") || strings.HasPrefix(path, "/admin/admins/")
+}
+
+func validKey(key string) bool {
+	if len(key) > maxKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// scopedKey is what a client's key is stored under: a hash of the key together with the
+// credentials in the request's Authorization header, so that callers cannot see or collide with
+// each other's keys. Requests without credentials, such as those of the legacy booking routes,
+// are scoped to the client's address instead.
+func scopedKey(r *http.Request, key string) string {
+	scope := r.Header.Get("Authorization")
+	if scope == "" {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		scope = "address " + host
+	}
+	sum := sha256.Sum256([]byte(scope + "\n" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// requestHash identifies a request by its method, path with query and body.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// storedHeaders encodes the response headers worth replaying. The request ID and the CORS
+// headers belong to each request and are set afresh on a replay.
+func storedHeaders(header http.Header) string {
+	stored := http.Header{}
+	for name, values := range header {
+		if name == APIError.RequestIDHeader || name == "Vary" || strings.HasPrefix(name, "Access-Control-") {
+			continue
+		}
+		stored[name] = values
+	}
+	encoded, _ := json.Marshal(stored)
+	return string(encoded)
+}
+
+// recorder passes a response through while keeping a copy of its status, headers and body.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+		r.header = r.ResponseWriter.Header().Clone()
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// statusCode returns the status written, which is 200 if the handler wrote nothing.
+func (r *recorder) statusCode() int {
+	if r.status == 0 {
+		r.header = r.ResponseWriter.Header().Clone()
+		return http.StatusOK
+	}
+	return r.status
+}
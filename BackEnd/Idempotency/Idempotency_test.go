@@ -0,0 +1,172 @@
+package Idempotency
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	store := TestStore.Open(t)
+
+	// The handler creates a numbered booking, failing once on request for "/flaky"
+	calls, failNext := 0, false
+	handler := Middleware(store, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if failNext {
+			failNext = false
+			APIError.Internal(w, r, "Failed to create booking", nil)
+			return
+		}
+		w.Header().Set("Location", fmt.Sprintf("/bookings/%d", calls))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"booking_id":%d}`, calls)
+	}))
+	sendAs := func(authorization, method, path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(Header, key)
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	send := func(method, path, key, body string) *httptest.ResponseRecorder {
+		return sendAs("", method, path, key, body)
+	}
+	anonymous := httptest.NewRequest("POST", "/CreateBooking", nil)
+
+	first := send("POST", "/CreateBooking", "retry-1", `{"slot_index":2}`)
+	again := send("POST", "/CreateBooking", "retry-1", `{"slot_index":2}`)
+	if calls != 1 {
+		t.Errorf("expected the handler to run once, ran %d times", calls)
+	}
+	if again.Code != http.StatusCreated || again.Body.String() != first.Body.String() ||
+		again.Header().Get("Location") != "/bookings/1" || again.Header().Get(ReplayedHeader) != "true" {
+		t.Errorf("expected the first response replayed, got %d %q %v", again.Code, again.Body.String(), again.Header())
+	}
+	if first.Header().Get(ReplayedHeader) != "" {
+		t.Errorf("expected the first response not to be marked replayed")
+	}
+
+	code := func(rr *httptest.ResponseRecorder) string {
+		var response DataBase.ErrorResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		return response.Code
+	}
+	for _, reuse := range []struct{ method, path, body string }{
+		{"POST", "/CreateBooking", `{"slot_index":3}`},
+		{"POST", "/cancelBooking", `{"slot_index":2}`},
+		{"PUT", "/CreateBooking", `{"slot_index":2}`},
+	} {
+		rr := send(reuse.method, reuse.path, "retry-1", reuse.body)
+		if rr.Code != http.StatusUnprocessableEntity || code(rr) != string(APIError.IdempotencyKeyReused) {
+			t.Errorf("%+v: expected 422 IDEMPOTENCY_KEY_REUSED, got %d %s", reuse, rr.Code, rr.Body.String())
+		}
+	}
+
+	// Keys belong to the caller: another caller's identical request under the same key is handled
+	// on its own and does not get the first caller's response
+	calls = 0
+	alice := sendAs("Bearer alice", "POST", "/CreateBooking", "retry-1", `{"slot_index":2}`)
+	bob := sendAs("Bearer bob", "POST", "/CreateBooking", "retry-1", `{"slot_index":2}`)
+	if calls != 2 || alice.Header().Get(ReplayedHeader) != "" || bob.Header().Get(ReplayedHeader) != "" {
+		t.Errorf("expected each caller's request handled on its own, got %d call(s): %q, %q", calls, alice.Body.String(), bob.Body.String())
+	}
+	if rr := sendAs("Bearer alice", "POST", "/CreateBooking", "retry-1", `{"slot_index":2}`); rr.Body.String() != alice.Body.String() || calls != 2 {
+		t.Errorf("expected the caller's own response replayed, got %q after %d call(s)", rr.Body.String(), calls)
+	}
+
+	// Without credentials the client's address tells callers apart
+	calls = 0
+	elsewhere := httptest.NewRequest("POST", "/CreateBooking", strings.NewReader(`{"slot_index":2}`))
+	elsewhere.RemoteAddr = "198.51.100.7:4321"
+	elsewhere.Header.Set(Header, "retry-1")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, elsewhere)
+	if calls != 1 || rr.Header().Get(ReplayedHeader) != "" {
+		t.Errorf("expected another client's request handled on its own, got %d call(s): %q", calls, rr.Body.String())
+	}
+
+	// Responses carrying credentials are never stored, so each request is handled again
+	for _, path := range []string{"/AdminLogin", "/admin/totp/enroll", "/admin/bootstrap", "/admin/admins", "/admin/admins/2/reset-password"} {
+		calls = 0
+		send("POST", path, "login-1", "{}")
+		again := send("POST", path, "login-1", "{}")
+		if calls != 2 || again.Header().Get(ReplayedHeader) != "" {
+			t.Errorf("%s: expected both requests handled, got %d call(s)", path, calls)
+		}
+		if _, err := store.IdempotencyKeys().Find(scopedKey(anonymous, "login-1")); err == nil {
+			t.Errorf("%s: expected nothing stored under the key", path)
+		}
+	}
+
+	// A request still in flight under the key
+	now := time.Now()
+	store.IdempotencyKeys().Reserve(&DataBase.Idempotency_Key{Key: scopedKey(anonymous, "in-flight"), Request_Hash: requestHash(
+		httptest.NewRequest("POST", "/CreateBooking", nil), []byte("{}")), Created_At: now, Expires_At: now.Add(time.Hour)})
+	if rr := send("POST", "/CreateBooking", "in-flight", "{}"); rr.Code != http.StatusConflict || code(rr) != string(APIError.IdempotencyKeyInFlight) {
+		t.Errorf("expected 409 IDEMPOTENCY_KEY_IN_FLIGHT, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	// An expired key is free again, even for a different request
+	store.IdempotencyKeys().Reserve(&DataBase.Idempotency_Key{Key: scopedKey(anonymous, "expired"), Request_Hash: "old", Status: 201,
+		Created_At: now.Add(-2 * time.Hour), Expires_At: now.Add(-time.Hour)})
+	calls = 0
+	if rr := send("POST", "/CreateBooking", "expired", "{}"); rr.Code != http.StatusCreated || calls != 1 {
+		t.Errorf("expected an expired key to be handled afresh, got %d after %d call(s)", rr.Code, calls)
+	}
+
+	// Server errors are not stored, so the retry goes through
+	calls, failNext = 0, true
+	if rr := send("POST", "/flaky", "retry-2", "{}"); rr.Code != http.StatusInternalServerError {
+		t.Errorf("expected the first attempt to fail, got %d", rr.Code)
+	}
+	if rr := send("POST", "/flaky", "retry-2", "{}"); rr.Code != http.StatusCreated || calls != 2 {
+		t.Errorf("expected the retry to be handled, got %d after %d call(s)", rr.Code, calls)
+	}
+
+	// Requests without a key, and reads, are always handled
+	calls = 0
+	send("POST", "/CreateBooking", "", "{}")
+	send("POST", "/CreateBooking", "", "{}")
+	send("GET", "/getCourts", "retry-1", "")
+	if calls != 3 {
+		t.Errorf("expected every unkeyed request and read to be handled, got %d call(s)", calls)
+	}
+
+	if rr := send("POST", "/CreateBooking", "has space", "{}"); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid key, got %d", rr.Code)
+	}
+	if rr := send("POST", "/admin/import", "too-large", strings.Repeat(" ", maxBodySize+1)); rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413 for a body over the limit, got %d", rr.Code)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	store := TestStore.Open(t)
+	now := time.Now()
+	store.IdempotencyKeys().Reserve(&DataBase.Idempotency_Key{Key: "old", Request_Hash: "a", Created_At: now, Expires_At: now.Add(-time.Minute)})
+	store.IdempotencyKeys().Reserve(&DataBase.Idempotency_Key{Key: "new", Request_Hash: "b", Created_At: now, Expires_At: now.Add(time.Minute)})
+
+	if err := Purge(store, now); err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if _, err := store.IdempotencyKeys().Find("old"); err == nil {
+		t.Errorf("expected the expired key to be purged")
+	}
+	if _, err := store.IdempotencyKeys().Find("new"); err != nil {
+		t.Errorf("expected the live key to be kept, got %v", err)
+	}
+}
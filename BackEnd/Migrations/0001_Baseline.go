@@ -0,0 +1,91 @@
+package Migrations
+
+import "gorm.io/gorm"
+
+// Schema as it stood before versioned migrations, when DataBase.init ran AutoMigrate.
+
+type customerV1 struct {
+	Customer_ID uint   `gorm:"column:Customer_ID;primaryKey;autoIncrement"`
+	Name        string `gorm:"column:Name"`
+	UFID        string `gorm:"column:UFID"`
+	Contact     string `gorm:"column:Contact"`
+	Email       string `gorm:"column:Email"`
+}
+
+func (customerV1) TableName() string { return "Customer" }
+
+type sportV1 struct {
+	Sport_ID          uint   `gorm:"column:Sport_ID;primaryKey;autoIncrement;unique;not null"`
+	Sport_name        string `gorm:"column:Sport_name;unique;not null"`
+	Sport_Description string
+}
+
+func (sportV1) TableName() string { return "Sport" }
+
+type courtV1 struct {
+	Court_ID       uint   `gorm:"column:Court_ID;primaryKey;autoIncrement"`
+	Court_Name     string `gorm:"column:Court_Name;unique;not null"`
+	Court_Location string `gorm:"column:Court_Location;not null"`
+	Court_Capacity *int
+	Court_Status   int      `gorm:"column:Court_Status;not null"`
+	Sport_id       uint     `gorm:"column:Sport_id;index;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Sport          *sportV1 `gorm:"foreignKey:Sport_ID; references:Sport_id"`
+}
+
+func (courtV1) TableName() string { return "Court" }
+
+type courtTimeSlotsV1 struct {
+	ID         uint     `gorm:"column:ID;primaryKey;autoIncrement"`
+	Court_ID   uint     `gorm:"column:Court_ID;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Slot_08_09 int      `gorm:"column:slot_08_09;not null;default:1"`
+	Slot_09_10 int      `gorm:"column:slot_09_10;not null;default:1"`
+	Slot_10_11 int      `gorm:"column:slot_10_11;not null;default:1"`
+	Slot_11_12 int      `gorm:"column:slot_11_12;not null;default:1"`
+	Slot_12_13 int      `gorm:"column:slot_12_13;not null;default:1"`
+	Slot_13_14 int      `gorm:"column:slot_13_14;not null;default:1"`
+	Slot_14_15 int      `gorm:"column:slot_14_15;not null;default:1"`
+	Slot_15_16 int      `gorm:"column:slot_15_16;not null;default:1"`
+	Slot_16_17 int      `gorm:"column:slot_16_17;not null;default:1"`
+	Slot_17_18 int      `gorm:"column:slot_17_18;not null;default:1"`
+	Court_Name string   `gorm:"column:Court_Name;unique;not null;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Court      *courtV1 `gorm:"foreignKey:Court_Name; references:Court_Name"`
+	CourtIDRef *courtV1 `gorm:"foreignKey:Court_ID; references:Court_ID"`
+}
+
+func (courtTimeSlotsV1) TableName() string { return "Court_TimeSlots" }
+
+type bookingsV1 struct {
+	Booking_ID     uint   `gorm:"column:Booking_ID;primaryKey;autoIncrement"`
+	Customer_ID    uint   `gorm:"column:Customer_ID;index;not null"`
+	Sport_ID       uint   `gorm:"column:Sport_ID;index;not null"`
+	Court_ID       uint   `gorm:"column:Court_ID;index;not null"`
+	Booking_Status string `gorm:"column:Booking_Status;not null"`
+	Booking_Time   int    `gorm:"column:Booking_Time;not null"`
+
+	Customer customerV1 `gorm:"foreignKey:Customer_ID;references:Customer_ID"`
+	Sport    sportV1    `gorm:"foreignKey:Sport_ID;references:Sport_ID"`
+	Court    courtV1    `gorm:"foreignKey:Court_ID;references:Court_ID"`
+}
+
+func (bookingsV1) TableName() string { return "Bookings" }
+
+type adminV1 struct {
+	Admin_ID uint   `gorm:"column:Admin_ID;primaryKey;autoIncrement"`
+	Username string `gorm:"column:Username;unique;not null"`
+	Password string `gorm:"column:Password;not null"`
+}
+
+func (adminV1) TableName() string { return "Admin" }
+
+func init() {
+	register(Migration{
+		Version: 1,
+		Name:    "baseline",
+		Up: func(tx *gorm.DB) error {
+			return createTables(tx, &customerV1{}, &sportV1{}, &courtV1{}, &courtTimeSlotsV1{}, &adminV1{}, &bookingsV1{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&bookingsV1{}, &adminV1{}, &courtTimeSlotsV1{}, &courtV1{}, &sportV1{}, &customerV1{})
+		},
+	})
+}
@@ -0,0 +1,56 @@
+package Migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Admin login lockout and TOTP second factor.
+
+type adminV2 struct {
+	Admin_ID     uint   `gorm:"column:Admin_ID;primaryKey;autoIncrement"`
+	Username     string `gorm:"column:Username;unique;not null"`
+	Password     string `gorm:"column:Password;not null"`
+	TOTP_Secret  string `gorm:"column:TOTP_Secret"`
+	TOTP_Enabled bool   `gorm:"column:TOTP_Enabled;not null;default:false"`
+}
+
+func (adminV2) TableName() string { return "Admin" }
+
+type adminLoginAttemptV2 struct {
+	ID           uint       `gorm:"column:ID;primaryKey;autoIncrement"`
+	Attempt_Key  string     `gorm:"column:Attempt_Key;unique;not null"`
+	Failures     int        `gorm:"column:Failures;not null;default:0"`
+	Locked_Until *time.Time `gorm:"column:Locked_Until"`
+}
+
+func (adminLoginAttemptV2) TableName() string { return "Admin_LoginAttempts" }
+
+type adminRecoveryCodeV2 struct {
+	ID        uint       `gorm:"column:ID;primaryKey;autoIncrement"`
+	Admin_ID  uint       `gorm:"column:Admin_ID;index;not null"`
+	Code_Hash string     `gorm:"column:Code_Hash;not null"`
+	Used_At   *time.Time `gorm:"column:Used_At"`
+}
+
+func (adminRecoveryCodeV2) TableName() string { return "Admin_RecoveryCodes" }
+
+func init() {
+	register(Migration{
+		Version: 2,
+		Name:    "admin_security",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumns(tx, &adminV2{}, "TOTP_Secret", "TOTP_Enabled"); err != nil {
+				return err
+			}
+			return createTables(tx, &adminLoginAttemptV2{}, &adminRecoveryCodeV2{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&adminRecoveryCodeV2{}, &adminLoginAttemptV2{}); err != nil {
+				return err
+			}
+			return dropColumns(tx, &adminV2{}, "TOTP_Enabled", "TOTP_Secret")
+		},
+	})
+}
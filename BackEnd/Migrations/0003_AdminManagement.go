@@ -0,0 +1,53 @@
+package Migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Admin roles, account status, invites and last login.
+
+type adminV3 struct {
+	Admin_ID       uint       `gorm:"column:Admin_ID;primaryKey;autoIncrement"`
+	Username       string     `gorm:"column:Username;unique;not null"`
+	Password       string     `gorm:"column:Password;not null"`
+	Role           string     `gorm:"column:Role;not null;default:admin"`
+	Status         string     `gorm:"column:Status;not null;default:active"`
+	TOTP_Secret    string     `gorm:"column:TOTP_Secret"`
+	TOTP_Enabled   bool       `gorm:"column:TOTP_Enabled;not null;default:false"`
+	Invite_Token   string     `gorm:"column:Invite_Token;index"`
+	Invite_Expires *time.Time `gorm:"column:Invite_Expires"`
+	Last_Login     *time.Time `gorm:"column:Last_Login"`
+}
+
+func (adminV3) TableName() string { return "Admin" }
+
+func init() {
+	register(Migration{
+		Version: 3,
+		Name:    "admin_management",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumns(tx, &adminV3{}, "Role", "Status", "Invite_Token", "Invite_Expires", "Last_Login"); err != nil {
+				return err
+			}
+			if !tx.Migrator().HasIndex(&adminV3{}, "Invite_Token") {
+				if err := tx.Migrator().CreateIndex(&adminV3{}, "Invite_Token"); err != nil {
+					return err
+				}
+			}
+
+			// Admins created before roles existed: promote the oldest one so a super admin always exists
+			return tx.Exec("UPDATE \"Admin\" SET \"Role\" = ? WHERE \"Admin_ID\" = (SELECT MIN(\"Admin_ID\") FROM \"Admin\") "+
+				"AND NOT EXISTS (SELECT 1 FROM \"Admin\" WHERE \"Role\" = ?)", "super_admin", "super_admin").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			if tx.Migrator().HasIndex(&adminV3{}, "Invite_Token") {
+				if err := tx.Migrator().DropIndex(&adminV3{}, "Invite_Token"); err != nil {
+					return err
+				}
+			}
+			return dropColumns(tx, &adminV3{}, "Last_Login", "Invite_Expires", "Invite_Token", "Status", "Role")
+		},
+	})
+}
@@ -0,0 +1,59 @@
+package Migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Booking dates, and a unique index so one slot on one day has at most one active booking.
+// Until now the nightly reset cancelled every booking, so the active ones are all for the day
+// the migration runs and are dated to it. Cancelled bookings keep a NULL date, which the index
+// treats as distinct, and so does any second active booking an older race let onto a slot.
+
+type bookingsV4 struct {
+	Booking_ID     uint    `gorm:"column:Booking_ID;primaryKey;autoIncrement"`
+	Customer_ID    uint    `gorm:"column:Customer_ID;index;not null"`
+	Sport_ID       uint    `gorm:"column:Sport_ID;index;not null"`
+	Court_ID       uint    `gorm:"column:Court_ID;index;not null"`
+	Booking_Status string  `gorm:"column:Booking_Status;not null"`
+	Booking_Time   int     `gorm:"column:Booking_Time;not null"`
+	Booking_Date   *string `gorm:"column:Booking_Date;size:10"`
+}
+
+func (bookingsV4) TableName() string { return "Bookings" }
+
+func init() {
+	register(Migration{
+		Version: 4,
+		Name:    "booking_date",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumns(tx, &bookingsV4{}, "Booking_Date"); err != nil {
+				return err
+			}
+			if err := dateActiveBookings(tx, time.Now().Format("2006-01-02")); err != nil {
+				return err
+			}
+			// Partial indexes are supported by both Postgres and SQLite
+			return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS \"idx_bookings_active_slot\" " +
+				"ON \"Bookings\" (\"Court_ID\", \"Booking_Date\", \"Booking_Time\") " +
+				"WHERE \"Booking_Status\" NOT LIKE 'Cancelled%'").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Exec("DROP INDEX IF EXISTS \"idx_bookings_active_slot\"").Error; err != nil {
+				return err
+			}
+			return dropColumns(tx, &bookingsV4{}, "Booking_Date")
+		},
+	})
+}
+
+// dateActiveBookings dates the undated active bookings to date, the first one on each court and
+// slot only, so that they keep their slots once availability goes by date.
+func dateActiveBookings(tx *gorm.DB, date string) error {
+	first := tx.Model(&bookingsV4{}).Select("MIN(\"Booking_ID\")").
+		Where("\"Booking_Date\" IS NULL AND \"Booking_Status\" NOT LIKE 'Cancelled%'").
+		Group("\"Court_ID\", \"Booking_Time\"")
+	return tx.Model(&bookingsV4{}).Where("\"Booking_ID\" IN (?)", first).
+		Update("Booking_Date", date).Error
+}
@@ -0,0 +1,88 @@
+package Migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Availability is derived from active bookings and blackouts, so the Court_TimeSlots flags go
+// and blackouts take over the one thing they recorded that bookings do not: a slot closed by an
+// admin. Flags were reset every night, so a closed slot becomes a blackout for the current day.
+
+type courtBlackoutV5 struct {
+	Blackout_ID   uint   `gorm:"column:Blackout_ID;primaryKey;autoIncrement"`
+	Court_ID      uint   `gorm:"column:Court_ID;index;not null"`
+	Blackout_Date string `gorm:"column:Blackout_Date;size:10;not null"`
+	Slot_Index    *int   `gorm:"column:Slot_Index"`
+	Reason        string `gorm:"column:Reason"`
+}
+
+func (courtBlackoutV5) TableName() string { return "Court_Blackouts" }
+
+func init() {
+	register(Migration{
+		Version: 5,
+		Name:    "court_blackouts",
+		Up: func(tx *gorm.DB) error {
+			if err := createTables(tx, &courtBlackoutV5{}); err != nil {
+				return err
+			}
+			if err := blackOutClosedSlots(tx, time.Now().Format("2006-01-02")); err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable(&courtTimeSlotsV1{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := createTables(tx, &courtTimeSlotsV1{}); err != nil {
+				return err
+			}
+			// Every slot column defaults to available; bookings made since cannot be mapped back
+			if err := tx.Exec("INSERT INTO \"Court_TimeSlots\" (\"Court_ID\", \"Court_Name\") " +
+				"SELECT \"Court_ID\", \"Court_Name\" FROM \"Court\"").Error; err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable(&courtBlackoutV5{})
+		},
+	})
+}
+
+// blackOutClosedSlots adds a blackout on date for every slot flagged unavailable that no active
+// booking explains. The only active bookings without a date are duplicates 0004_booking_date
+// left undated; they still explain their slot.
+func blackOutClosedSlots(tx *gorm.DB, date string) error {
+	if !tx.Migrator().HasTable(&courtTimeSlotsV1{}) {
+		return nil
+	}
+	var rows []courtTimeSlotsV1
+	if err := tx.Omit("Court", "CourtIDRef").Find(&rows).Error; err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		flags := []int{row.Slot_08_09, row.Slot_09_10, row.Slot_10_11, row.Slot_11_12, row.Slot_12_13,
+			row.Slot_13_14, row.Slot_14_15, row.Slot_15_16, row.Slot_16_17, row.Slot_17_18}
+		for slot, flag := range flags {
+			if flag != 0 {
+				continue
+			}
+			var booked int64
+			err := tx.Table("Bookings").
+				Where("\"Court_ID\" = ? AND \"Booking_Time\" = ? AND (\"Booking_Date\" = ? OR \"Booking_Date\" IS NULL) "+
+					"AND \"Booking_Status\" NOT LIKE 'Cancelled%'", row.Court_ID, slot, date).
+				Count(&booked).Error
+			if err != nil {
+				return err
+			}
+			if booked > 0 {
+				continue
+			}
+			index := slot
+			blackout := courtBlackoutV5{Court_ID: row.Court_ID, Blackout_Date: date, Slot_Index: &index, Reason: "Closed before blackouts"}
+			if err := tx.Create(&blackout).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
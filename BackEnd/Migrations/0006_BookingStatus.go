@@ -0,0 +1,76 @@
+package Migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Booking statuses become a fixed set with a recorded history. Free-form statuses are mapped
+// onto it: "Cancelled by UF CourtLink" was set by admins and resets, any other cancellation by
+// the customer, and everything else ("Confirmed", "booked") was a confirmed booking.
+
+type bookingStatusHistoryV6 struct {
+	History_ID  uint      `gorm:"column:History_ID;primaryKey;autoIncrement"`
+	Booking_ID  uint      `gorm:"column:Booking_ID;index;not null"`
+	From_Status string    `gorm:"column:From_Status"`
+	To_Status   string    `gorm:"column:To_Status;not null"`
+	Changed_At  time.Time `gorm:"column:Changed_At;not null"`
+	Note        string    `gorm:"column:Note"`
+}
+
+func (bookingStatusHistoryV6) TableName() string { return "Booking_StatusHistory" }
+
+const bookingStatusesV6 = "'held', 'confirmed', 'checked_in', 'completed', 'no_show', " +
+	"'cancelled_by_user', 'cancelled_by_admin', 'cancelled_by_system'"
+
+// recreateActiveSlotIndex replaces idx_bookings_active_slot, which only covers active bookings.
+func recreateActiveSlotIndex(tx *gorm.DB, activeCondition string) error {
+	if err := tx.Exec("DROP INDEX IF EXISTS \"idx_bookings_active_slot\"").Error; err != nil {
+		return err
+	}
+	return tx.Exec("CREATE UNIQUE INDEX \"idx_bookings_active_slot\" " +
+		"ON \"Bookings\" (\"Court_ID\", \"Booking_Date\", \"Booking_Time\") WHERE " + activeCondition).Error
+}
+
+func init() {
+	register(Migration{
+		Version: 6,
+		Name:    "booking_status",
+		Up: func(tx *gorm.DB) error {
+			if err := createTables(tx, &bookingStatusHistoryV6{}); err != nil {
+				return err
+			}
+			// Mapping only ever turns active bookings into cancelled ones, never the reverse,
+			// so it cannot create a clash under the new index
+			if err := tx.Exec("UPDATE \"Bookings\" SET \"Booking_Status\" = CASE " +
+				"WHEN \"Booking_Status\" = 'Cancelled by UF CourtLink' THEN 'cancelled_by_admin' " +
+				"WHEN LOWER(\"Booking_Status\") LIKE 'cancelled%' THEN 'cancelled_by_user' " +
+				"ELSE 'confirmed' END " +
+				"WHERE \"Booking_Status\" NOT IN (" + bookingStatusesV6 + ")").Error; err != nil {
+				return err
+			}
+			// Existing bookings start their history at their normalised status
+			if err := tx.Exec("INSERT INTO \"Booking_StatusHistory\" " +
+				"(\"Booking_ID\", \"From_Status\", \"To_Status\", \"Changed_At\", \"Note\") " +
+				"SELECT \"Booking_ID\", '', \"Booking_Status\", CURRENT_TIMESTAMP, 'Status normalised by migration 6' " +
+				"FROM \"Bookings\"").Error; err != nil {
+				return err
+			}
+			return recreateActiveSlotIndex(tx, "\"Booking_Status\" NOT IN "+
+				"('cancelled_by_user', 'cancelled_by_admin', 'cancelled_by_system')")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Exec("UPDATE \"Bookings\" SET \"Booking_Status\" = CASE " +
+				"WHEN \"Booking_Status\" = 'cancelled_by_user' THEN 'Cancelled' " +
+				"WHEN \"Booking_Status\" IN ('cancelled_by_admin', 'cancelled_by_system') THEN 'Cancelled by UF CourtLink' " +
+				"ELSE 'Confirmed' END").Error; err != nil {
+				return err
+			}
+			if err := recreateActiveSlotIndex(tx, "\"Booking_Status\" NOT LIKE 'Cancelled%'"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable(&bookingStatusHistoryV6{})
+		},
+	})
+}
@@ -0,0 +1,77 @@
+package Migrations
+
+import "gorm.io/gorm"
+
+// Sports, courts and bookings are soft deleted: Deleted_At is set instead of removing the row,
+// so they can be restored until a purge removes them for good.
+
+type sportV7 struct {
+	Sport_ID          uint   `gorm:"column:Sport_ID;primaryKey;autoIncrement;unique;not null"`
+	Sport_name        string `gorm:"column:Sport_name;unique;not null"`
+	Sport_Description string
+	Deleted_At        gorm.DeletedAt `gorm:"column:Deleted_At;index"`
+}
+
+func (sportV7) TableName() string { return "Sport" }
+
+type courtV7 struct {
+	Court_ID       uint   `gorm:"column:Court_ID;primaryKey;autoIncrement"`
+	Court_Name     string `gorm:"column:Court_Name;unique;not null"`
+	Court_Location string `gorm:"column:Court_Location;not null"`
+	Court_Capacity *int
+	Court_Status   int            `gorm:"column:Court_Status;not null"`
+	Sport_id       uint           `gorm:"column:Sport_id;index"`
+	Deleted_At     gorm.DeletedAt `gorm:"column:Deleted_At;index"`
+}
+
+func (courtV7) TableName() string { return "Court" }
+
+type bookingsV7 struct {
+	Booking_ID     uint           `gorm:"column:Booking_ID;primaryKey;autoIncrement"`
+	Customer_ID    uint           `gorm:"column:Customer_ID;index;not null"`
+	Sport_ID       uint           `gorm:"column:Sport_ID;index;not null"`
+	Court_ID       uint           `gorm:"column:Court_ID;index;not null"`
+	Booking_Status string         `gorm:"column:Booking_Status;not null"`
+	Booking_Time   int            `gorm:"column:Booking_Time;not null"`
+	Booking_Date   *string        `gorm:"column:Booking_Date;size:10"`
+	Deleted_At     gorm.DeletedAt `gorm:"column:Deleted_At;index"`
+}
+
+func (bookingsV7) TableName() string { return "Bookings" }
+
+func init() {
+	register(Migration{
+		Version: 7,
+		Name:    "soft_delete",
+		Up: func(tx *gorm.DB) error {
+			for _, model := range []interface{}{&sportV7{}, &courtV7{}, &bookingsV7{}} {
+				if err := addColumns(tx, model, "Deleted_At"); err != nil {
+					return err
+				}
+				if !tx.Migrator().HasIndex(model, "Deleted_At") {
+					if err := tx.Migrator().CreateIndex(model, "Deleted_At"); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, model := range []interface{}{&bookingsV7{}, &courtV7{}, &sportV7{}} {
+				// Soft deleted rows would come back to life once the column is gone
+				if err := tx.Unscoped().Where("\"Deleted_At\" IS NOT NULL").Delete(model).Error; err != nil {
+					return err
+				}
+				if tx.Migrator().HasIndex(model, "Deleted_At") {
+					if err := tx.Migrator().DropIndex(model, "Deleted_At"); err != nil {
+						return err
+					}
+				}
+				if err := dropColumns(tx, model, "Deleted_At"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
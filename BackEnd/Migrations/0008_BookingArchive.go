@@ -0,0 +1,46 @@
+package Migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Past bookings move to Bookings_Archive each night instead of being cancelled in place, so
+// Bookings only holds today's and future bookings.
+
+type bookingArchiveV8 struct {
+	Booking_ID     uint      `gorm:"column:Booking_ID;primaryKey;autoIncrement:false"`
+	Customer_ID    uint      `gorm:"column:Customer_ID;index:idx_archive_customer_date;not null"`
+	Sport_ID       uint      `gorm:"column:Sport_ID;not null"`
+	Sport_Name     string    `gorm:"column:Sport_Name"`
+	Court_ID       uint      `gorm:"column:Court_ID;not null"`
+	Court_Name     string    `gorm:"column:Court_Name"`
+	Booking_Status string    `gorm:"column:Booking_Status;not null"`
+	Booking_Time   int       `gorm:"column:Booking_Time;not null"`
+	Booking_Date   string    `gorm:"column:Booking_Date;size:10;index:idx_archive_customer_date;not null"`
+	Archived_At    time.Time `gorm:"column:Archived_At;not null"`
+}
+
+func (bookingArchiveV8) TableName() string { return "Bookings_Archive" }
+
+func init() {
+	register(Migration{
+		Version: 8,
+		Name:    "booking_archive",
+		Up: func(tx *gorm.DB) error {
+			return createTables(tx, &bookingArchiveV8{})
+		},
+		Down: func(tx *gorm.DB) error {
+			// Archived bookings go back to Bookings; they are all in the past, so none clash
+			// with an active booking on the slot index
+			if err := tx.Exec("INSERT INTO \"Bookings\" " +
+				"(\"Booking_ID\", \"Customer_ID\", \"Sport_ID\", \"Court_ID\", \"Booking_Status\", \"Booking_Time\", \"Booking_Date\") " +
+				"SELECT \"Booking_ID\", \"Customer_ID\", \"Sport_ID\", \"Court_ID\", \"Booking_Status\", \"Booking_Time\", \"Booking_Date\" " +
+				"FROM \"Bookings_Archive\"").Error; err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable(&bookingArchiveV8{})
+		},
+	})
+}
@@ -0,0 +1,36 @@
+package Migrations
+
+import "gorm.io/gorm"
+
+// Indexes for the columns the list endpoints filter and page on. Paging by date continues
+// after a (Booking_Date, Booking_ID) pair, so that index covers both the range and the order.
+
+var listIndexesV9 = []struct{ name, definition string }{
+	{"idx_bookings_date", "\"Bookings\" (\"Booking_Date\", \"Booking_ID\")"},
+	{"idx_bookings_status", "\"Bookings\" (\"Booking_Status\")"},
+	{"idx_customer_ufid", "\"Customer\" (\"UFID\")"},
+	{"idx_court_status", "\"Court\" (\"Court_Status\")"},
+}
+
+func init() {
+	register(Migration{
+		Version: 9,
+		Name:    "list_indexes",
+		Up: func(tx *gorm.DB) error {
+			for _, index := range listIndexesV9 {
+				if err := tx.Exec("CREATE INDEX IF NOT EXISTS \"" + index.name + "\" ON " + index.definition).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, index := range listIndexesV9 {
+				if err := tx.Exec("DROP INDEX IF EXISTS \"" + index.name + "\"").Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
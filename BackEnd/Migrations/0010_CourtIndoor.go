@@ -0,0 +1,26 @@
+package Migrations
+
+import "gorm.io/gorm"
+
+// Courts record whether they are indoors, so availability searches can ask for indoor courts.
+// Existing courts count as outdoor until an admin says otherwise.
+
+type courtV10 struct {
+	Court_ID     uint `gorm:"column:Court_ID;primaryKey;autoIncrement"`
+	Court_Indoor bool `gorm:"column:Court_Indoor;not null;default:false"`
+}
+
+func (courtV10) TableName() string { return "Court" }
+
+func init() {
+	register(Migration{
+		Version: 10,
+		Name:    "court_indoor",
+		Up: func(tx *gorm.DB) error {
+			return addColumns(tx, &courtV10{}, "Court_Indoor")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &courtV10{}, "Court_Indoor")
+		},
+	})
+}
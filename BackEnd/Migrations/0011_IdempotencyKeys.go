@@ -0,0 +1,35 @@
+package Migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Responses to mutating requests sent with an Idempotency-Key header are kept until they expire,
+// so a client retrying on a flaky connection gets the first response back.
+
+type idempotencyKeyV11 struct {
+	Key          string    `gorm:"column:Key;primaryKey;size:255"`
+	Request_Hash string    `gorm:"column:Request_Hash;size:64;not null"`
+	Status       int       `gorm:"column:Status;not null;default:0"`
+	Headers      string    `gorm:"column:Headers"`
+	Body         []byte    `gorm:"column:Body"`
+	Created_At   time.Time `gorm:"column:Created_At;not null"`
+	Expires_At   time.Time `gorm:"column:Expires_At;index;not null"`
+}
+
+func (idempotencyKeyV11) TableName() string { return "Idempotency_Keys" }
+
+func init() {
+	register(Migration{
+		Version: 11,
+		Name:    "idempotency_keys",
+		Up: func(tx *gorm.DB) error {
+			return createTables(tx, &idempotencyKeyV11{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&idempotencyKeyV11{})
+		},
+	})
+}
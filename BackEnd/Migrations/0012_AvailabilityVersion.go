@@ -0,0 +1,26 @@
+package Migrations
+
+import "gorm.io/gorm"
+
+// Sports carry a version that moves on whenever the availability of one of their courts may
+// have changed, so polling clients can be answered 304 Not Modified until it does.
+
+type sportV12 struct {
+	Sport_ID             uint  `gorm:"column:Sport_ID;primaryKey;autoIncrement"`
+	Availability_Version int64 `gorm:"column:Availability_Version;not null;default:0"`
+}
+
+func (sportV12) TableName() string { return "Sport" }
+
+func init() {
+	register(Migration{
+		Version: 12,
+		Name:    "availability_version",
+		Up: func(tx *gorm.DB) error {
+			return addColumns(tx, &sportV12{}, "Availability_Version")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &sportV12{}, "Availability_Version")
+		},
+	})
+}
@@ -0,0 +1,26 @@
+package Migrations
+
+import "gorm.io/gorm"
+
+// Admins remember the last TOTP time step they logged in with, so a code cannot be replayed
+// while it is still inside the accepted window.
+
+type adminV13 struct {
+	Admin_ID       uint  `gorm:"column:Admin_ID;primaryKey;autoIncrement"`
+	TOTP_Last_Step int64 `gorm:"column:TOTP_Last_Step;not null;default:0"`
+}
+
+func (adminV13) TableName() string { return "Admin" }
+
+func init() {
+	register(Migration{
+		Version: 13,
+		Name:    "totp_last_step",
+		Up: func(tx *gorm.DB) error {
+			return addColumns(tx, &adminV13{}, "TOTP_Last_Step")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &adminV13{}, "TOTP_Last_Step")
+		},
+	})
+}
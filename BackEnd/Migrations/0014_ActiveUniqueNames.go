@@ -0,0 +1,119 @@
+package Migrations
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Sport and court names only have to be unique among rows that are not soft deleted, so a
+// deleted sport or court no longer blocks creating a new one with its name. Restoring the old
+// one then fails while the name is taken.
+
+type sportV14 struct {
+	Sport_ID   uint           `gorm:"column:Sport_ID;primaryKey;autoIncrement;unique;not null"`
+	Sport_name string         `gorm:"column:Sport_name;unique;not null"`
+	Deleted_At gorm.DeletedAt `gorm:"column:Deleted_At;index"`
+}
+
+func (sportV14) TableName() string { return "Sport" }
+
+type courtV14 struct {
+	Court_ID   uint           `gorm:"column:Court_ID;primaryKey;autoIncrement"`
+	Court_Name string         `gorm:"column:Court_Name;unique;not null"`
+	Deleted_At gorm.DeletedAt `gorm:"column:Deleted_At;index"`
+}
+
+func (courtV14) TableName() string { return "Court" }
+
+// activeUniqueNames lists each name column with the partial index that replaces its constraint.
+var activeUniqueNames = []struct {
+	model         interface{}
+	table, column string
+	index         string
+}{
+	{&sportV14{}, "Sport", "Sport_name", "idx_sport_name_active"},
+	{&courtV14{}, "Court", "Court_Name", "idx_court_name_active"},
+}
+
+func init() {
+	register(Migration{
+		Version: 14,
+		Name:    "active_unique_names",
+		Up: func(tx *gorm.DB) error {
+			for _, n := range activeUniqueNames {
+				if err := keepingIndexes(tx, n.table, func() error {
+					return dropUniqueConstraint(tx, n.model, n.table, n.column)
+				}); err != nil {
+					return err
+				}
+				if err := tx.Exec(fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %q ON %q (%q) WHERE \"Deleted_At\" IS NULL",
+					n.index, n.table, n.column)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, n := range activeUniqueNames {
+				var clashes int64
+				err := tx.Table(n.table).
+					Where(fmt.Sprintf("\"Deleted_At\" IS NOT NULL AND %q IN (SELECT %q FROM %q GROUP BY %q HAVING COUNT(*) > 1)",
+						n.column, n.column, n.table, n.column)).
+					Count(&clashes).Error
+				if err != nil {
+					return err
+				}
+				if clashes > 0 {
+					return fmt.Errorf("%d deleted %s row(s) share a name with another row; restore, rename or purge them first",
+						clashes, n.table)
+				}
+				if err := tx.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %q", n.index)).Error; err != nil {
+					return err
+				}
+				if err := keepingIndexes(tx, n.table, func() error {
+					return tx.Migrator().CreateConstraint(n.model, n.column)
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
+
+// dropUniqueConstraint drops the unique constraint on column, whether GORM named it uni_<table>_<column>
+// when creating the table or Postgres named it <table>_<column>_key for an older AutoMigrate.
+func dropUniqueConstraint(tx *gorm.DB, model interface{}, table, column string) error {
+	if tx.Dialector.Name() == "postgres" {
+		if err := tx.Exec(fmt.Sprintf("ALTER TABLE %q DROP CONSTRAINT IF EXISTS %q", table, table+"_"+column+"_key")).Error; err != nil {
+			return err
+		}
+	}
+	if !tx.Migrator().HasConstraint(model, column) {
+		return nil
+	}
+	return tx.Migrator().DropConstraint(model, column)
+}
+
+// keepingIndexes runs change and then recreates the indexes table had before it. SQLite cannot
+// alter a table constraint, so GORM rebuilds the table, which drops every index on it.
+func keepingIndexes(tx *gorm.DB, table string, change func() error) error {
+	var indexes []string
+	if tx.Dialector.Name() == "sqlite" {
+		err := tx.Raw("SELECT \"sql\" FROM sqlite_master WHERE \"type\" = 'index' AND \"tbl_name\" = ? AND \"sql\" IS NOT NULL", table).
+			Scan(&indexes).Error
+		if err != nil {
+			return err
+		}
+	}
+	if err := change(); err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		if err := tx.Exec(index).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package Migrations
+
+import "gorm.io/gorm"
+
+// Status changes record the admin who made them. Earlier changes do not say who made them,
+// so they keep a null Changed_By.
+
+type bookingStatusHistoryV15 struct {
+	History_ID uint  `gorm:"column:History_ID;primaryKey;autoIncrement"`
+	Changed_By *uint `gorm:"column:Changed_By"`
+}
+
+func (bookingStatusHistoryV15) TableName() string { return "Booking_StatusHistory" }
+
+func init() {
+	register(Migration{
+		Version: 15,
+		Name:    "booking_history_admin",
+		Up: func(tx *gorm.DB) error {
+			return addColumns(tx, &bookingStatusHistoryV15{}, "Changed_By")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &bookingStatusHistoryV15{}, "Changed_By")
+		},
+	})
+}
@@ -0,0 +1,26 @@
+package Migrations
+
+import "gorm.io/gorm"
+
+// Courts may have hours of their own inside the day, set when they are imported. Existing
+// courts keep an empty Court_Hours, open for the whole day.
+
+type courtV16 struct {
+	Court_ID    uint   `gorm:"column:Court_ID;primaryKey;autoIncrement"`
+	Court_Hours string `gorm:"column:Court_Hours;not null;default:''"`
+}
+
+func (courtV16) TableName() string { return "Court" }
+
+func init() {
+	register(Migration{
+		Version: 16,
+		Name:    "court_hours",
+		Up: func(tx *gorm.DB) error {
+			return addColumns(tx, &courtV16{}, "Court_Hours")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &courtV16{}, "Court_Hours")
+		},
+	})
+}
@@ -0,0 +1,70 @@
+package Migrations
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// Usage describes the migrate subcommand.
+const Usage = `usage: migrate <command>
+
+commands:
+  up         apply every pending migration
+  down [n]   revert the last n applied migrations (default 1)
+  status     list migrations and whether they are applied`
+
+// Run executes the migrate subcommand of the server binary, writing progress to out.
+func Run(db *gorm.DB, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate command\n%s", Usage)
+	}
+
+	switch args[0] {
+	case "up":
+		ran, err := Up(db)
+		for _, m := range ran {
+			fmt.Fprintf(out, "applied  %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(ran) == 0 {
+			fmt.Fprintln(out, "schema is up to date")
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("down expects a positive number of steps, got %q", args[1])
+			}
+			steps = n
+		}
+		reverted, err := Down(db, steps)
+		for _, m := range reverted {
+			fmt.Fprintf(out, "reverted %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Fprintln(out, "nothing to revert")
+		}
+		return err
+
+	case "status":
+		status, err := Status(db)
+		if err != nil {
+			return err
+		}
+		for _, s := range status {
+			if s.Applied {
+				fmt.Fprintf(out, "%04d_%-20s applied %s\n", s.Version, s.Name, s.Applied_At.Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Fprintf(out, "%04d_%-20s pending\n", s.Version, s.Name)
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown migrate command %q\n%s", args[0], Usage)
+}
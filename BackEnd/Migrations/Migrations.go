@@ -0,0 +1,273 @@
+// Package Migrations owns the database schema. Every change is a numbered migration with an
+// up and a down step, and the versions applied so far are recorded in the schema_migrations table.
+//
+// Migrations never use the live models in DataBase: each one declares frozen copies of the
+// structs as they looked at that version, so later model changes cannot rewrite history.
+package Migrations
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrSchemaBehind is returned by EnsureCurrent when migrations are waiting to be applied.
+var ErrSchemaBehind = errors.New("database schema is behind")
+
+// ErrSchemaAhead is returned by EnsureCurrent and Up when the database has migrations applied
+// that this build does not know, so it was migrated by a newer build.
+var ErrSchemaAhead = errors.New("database schema is ahead of this build")
+
+// Migration is one numbered schema change. Up and Down both run inside a transaction.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// MigrationStatus reports whether a known migration has been applied, and when.
+type MigrationStatus struct {
+	Version    int
+	Name       string
+	Applied    bool
+	Applied_At *time.Time
+}
+
+// schemaMigration is a row of the schema_migrations table.
+type schemaMigration struct {
+	Version    int       `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name       string    `gorm:"column:name;not null"`
+	Applied_At time.Time `gorm:"column:applied_at;not null"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// registry holds every migration, filled in by the init function of each numbered file.
+var registry []Migration
+
+func register(m Migration) {
+	registry = append(registry, m)
+}
+
+// All returns the known migrations ordered by version.
+func All() []Migration {
+	all := append([]Migration(nil), registry...)
+	sort.Slice(all, func(i, j int) bool { return all[i].Version < all[j].Version })
+	return all
+}
+
+// applied returns the recorded migrations keyed by version. It only reads, so checking the
+// schema never changes it; a database without schema_migrations has nothing applied.
+func applied(db *gorm.DB) (map[int]schemaMigration, error) {
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return map[int]schemaMigration{}, nil
+	}
+
+	var rows []schemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	done := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+	return done, nil
+}
+
+// unknown returns the applied migrations that are not in the registry, oldest first.
+func unknown(done map[int]schemaMigration) []schemaMigration {
+	known := map[int]bool{}
+	for _, m := range registry {
+		known[m.Version] = true
+	}
+	var rows []schemaMigration
+	for version, row := range done {
+		if !known[version] {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Version < rows[j].Version })
+	return rows
+}
+
+// aheadError describes the migrations the database has beyond this build, or returns nil.
+func aheadError(done map[int]schemaMigration) error {
+	rows := unknown(done)
+	if len(rows) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %d unknown migration(s) applied, newest is %04d_%s",
+		ErrSchemaAhead, len(rows), rows[len(rows)-1].Version, rows[len(rows)-1].Name)
+}
+
+// Pending returns the migrations that have not been applied yet, oldest first.
+func Pending(db *gorm.DB) ([]Migration, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, m := range All() {
+		if _, ok := done[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies every pending migration in order, each in its own transaction, and returns the ones it ran.
+// It creates schema_migrations on first use, and refuses to run on a database a newer build migrated.
+func Up(db *gorm.DB) ([]Migration, error) {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+	if err := aheadError(done); err != nil {
+		return nil, err
+	}
+	pending, err := Pending(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for _, m := range pending {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, Applied_At: time.Now()}).Error
+		})
+		if err != nil {
+			return ran, fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		ran = append(ran, m)
+	}
+	return ran, nil
+}
+
+// Down rolls back the most recent steps applied migrations, newest first, and returns the ones it reverted.
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	all := All()
+	var reverted []Migration
+	for i := len(all) - 1; i >= 0 && len(reverted) < steps; i-- {
+		m := all[i]
+		if _, ok := done[m.Version]; !ok {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{}, m.Version).Error
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("revert %04d_%s: %w", m.Version, m.Name, err)
+		}
+		reverted = append(reverted, m)
+	}
+	return reverted, nil
+}
+
+// Status lists every known migration with its applied state, followed by any applied migration
+// this build does not know.
+func Status(db *gorm.DB) ([]MigrationStatus, error) {
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var status []MigrationStatus
+	for _, m := range All() {
+		s := MigrationStatus{Version: m.Version, Name: m.Name}
+		if row, ok := done[m.Version]; ok {
+			at := row.Applied_At
+			s.Applied = true
+			s.Applied_At = &at
+		}
+		status = append(status, s)
+	}
+	for _, row := range unknown(done) {
+		at := row.Applied_At
+		status = append(status, MigrationStatus{Version: row.Version, Name: row.Name, Applied: true, Applied_At: &at})
+	}
+	return status, nil
+}
+
+// EnsureCurrent fails with ErrSchemaBehind if any migration is still pending, and with
+// ErrSchemaAhead if the database has migrations this build does not know.
+func EnsureCurrent(db *gorm.DB) error {
+	done, err := applied(db)
+	if err != nil {
+		return err
+	}
+	if err := aheadError(done); err != nil {
+		return err
+	}
+	pending, err := Pending(db)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %d pending migration(s), oldest is %04d_%s",
+			ErrSchemaBehind, len(pending), pending[0].Version, pending[0].Name)
+	}
+	return nil
+}
+
+// createTables creates each table that does not exist yet, so the first migrations
+// also adopt databases that were previously set up by AutoMigrate.
+func createTables(tx *gorm.DB, models ...interface{}) error {
+	for _, model := range models {
+		if tx.Migrator().HasTable(model) {
+			continue
+		}
+		if err := tx.Migrator().CreateTable(model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumns adds each named field of model that is not a column yet.
+func addColumns(tx *gorm.DB, model interface{}, fields ...string) error {
+	for _, field := range fields {
+		if tx.Migrator().HasColumn(model, field) {
+			continue
+		}
+		if err := tx.Migrator().AddColumn(model, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropColumns removes each named field of model that is still a column.
+func dropColumns(tx *gorm.DB, model interface{}, fields ...string) error {
+	for _, field := range fields {
+		if !tx.Migrator().HasColumn(model, field) {
+			continue
+		}
+		if err := tx.Migrator().DropColumn(model, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package Migrations
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	return db
+}
+
+// models lists every table the application reads or writes.
+var models = []interface{}{
+	&DataBase.Customer{}, &DataBase.Sport{}, &DataBase.Court{}, &DataBase.Court_Blackout{},
+	&DataBase.Bookings{}, &DataBase.Booking_StatusHistory{}, &DataBase.Admin{}, &DataBase.Admin_LoginAttempt{},
+	&DataBase.Admin_RecoveryCode{}, &DataBase.Booking_Archive{}, &DataBase.Idempotency_Key{},
+}
+
+// assertSchemaMatchesModels fails if a model field has no column, which means a model
+// was changed without a migration.
+func assertSchemaMatchesModels(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatalf("failed to parse model: %v", err)
+		}
+		if !db.Migrator().HasTable(model) {
+			t.Errorf("table %s is missing", stmt.Schema.Table)
+			continue
+		}
+		for _, field := range stmt.Schema.Fields {
+			// Associations have no column of their own
+			if field.DBName == "" || field.IgnoreMigration {
+				continue
+			}
+			if !db.Migrator().HasColumn(model, field.DBName) {
+				t.Errorf("column %s.%s is missing", stmt.Schema.Table, field.DBName)
+			}
+		}
+	}
+}
+
+func TestUpBuildsTheCurrentSchema(t *testing.T) {
+	db := openTestDB(t)
+
+	if err := EnsureCurrent(db); !errors.Is(err, ErrSchemaBehind) {
+		t.Fatalf("expected ErrSchemaBehind on an empty database, got %v", err)
+	}
+
+	ran, err := Up(db)
+	if err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if len(ran) != len(All()) {
+		t.Errorf("expected %d migrations to run, got %d", len(All()), len(ran))
+	}
+	if err := EnsureCurrent(db); err != nil {
+		t.Errorf("expected schema to be current, got %v", err)
+	}
+	assertSchemaMatchesModels(t, db)
+
+	// A second run is a no-op
+	if ran, err := Up(db); err != nil || len(ran) != 0 {
+		t.Errorf("expected nothing to run, got %d (error: %v)", len(ran), err)
+	}
+}
+
+func TestDownRevertsEverything(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := Up(db); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+
+	reverted, err := Down(db, 1)
+	if err != nil || len(reverted) != 1 || reverted[0].Version != All()[len(All())-1].Version {
+		t.Fatalf("expected the newest migration to be reverted, got %+v (error: %v)", reverted, err)
+	}
+	if pending, _ := Pending(db); len(pending) != 1 {
+		t.Errorf("expected one pending migration after reverting one, got %d", len(pending))
+	}
+
+	if _, err := Down(db, len(All())); err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+	if db.Migrator().HasTable(&DataBase.Bookings{}) || db.Migrator().HasTable(&DataBase.Admin_LoginAttempt{}) {
+		t.Errorf("expected every table to be dropped")
+	}
+
+	// And back up again from scratch
+	if _, err := Up(db); err != nil {
+		t.Fatalf("Up after Down failed: %v", err)
+	}
+	assertSchemaMatchesModels(t, db)
+}
+
+func TestUpAdoptsAutoMigratedDatabase(t *testing.T) {
+	db := openTestDB(t)
+
+	// Databases created before migrations existed were built by AutoMigrate
+	if err := db.AutoMigrate(&customerV1{}, &sportV1{}, &courtV1{}, &courtTimeSlotsV1{}, &adminV1{}, &bookingsV1{}); err != nil {
+		t.Fatalf("AutoMigrate failed: %v", err)
+	}
+	db.Create(&adminV1{Username: "first", Password: "x"})
+	db.Create(&adminV1{Username: "second", Password: "y"})
+	for _, status := range []string{"Confirmed", "booked", "Cancelled", "Cancelled by UF CourtLink"} {
+		db.Omit("Customer", "Sport", "Court").Create(&bookingsV1{Customer_ID: 1, Sport_ID: 1, Court_ID: 1, Booking_Status: status})
+	}
+
+	if _, err := Up(db); err != nil {
+		t.Fatalf("Up failed on an existing database: %v", err)
+	}
+	assertSchemaMatchesModels(t, db)
+
+	var admins []DataBase.Admin
+	db.Order("\"Admin_ID\"").Find(&admins)
+	if len(admins) != 2 || admins[0].Role != DataBase.AdminRoleSuperAdmin || admins[1].Role != DataBase.AdminRoleAdmin {
+		t.Errorf("expected only the oldest admin to be promoted, got %+v", admins)
+	}
+	if admins[1].Status != DataBase.AdminStatusActive {
+		t.Errorf("expected existing admins to be active, got %q", admins[1].Status)
+	}
+
+	var statuses []DataBase.BookingStatus
+	db.Model(&DataBase.Bookings{}).Order("\"Booking_ID\"").Pluck("Booking_Status", &statuses)
+	expected := []DataBase.BookingStatus{DataBase.BookingConfirmed, DataBase.BookingConfirmed,
+		DataBase.BookingCancelledByUser, DataBase.BookingCancelledByAdmin}
+	if len(statuses) != len(expected) {
+		t.Fatalf("expected %d bookings, got %v", len(expected), statuses)
+	}
+	for i := range expected {
+		if statuses[i] != expected[i] {
+			t.Errorf("expected booking %d to be normalised to %s, got %s", i+1, expected[i], statuses[i])
+		}
+	}
+	var history int64
+	db.Model(&DataBase.Booking_StatusHistory{}).Count(&history)
+	if history != int64(len(expected)) {
+		t.Errorf("expected one history entry per existing booking, got %d", history)
+	}
+}
+
+func TestUpTurnsClosedSlotsIntoBlackouts(t *testing.T) {
+	db := openTestDB(t)
+
+	if err := db.AutoMigrate(&customerV1{}, &sportV1{}, &courtV1{}, &courtTimeSlotsV1{}, &adminV1{}, &bookingsV1{}); err != nil {
+		t.Fatalf("AutoMigrate failed: %v", err)
+	}
+	db.Omit("Sport").Create(&courtV1{Court_ID: 1, Court_Name: "Court A", Court_Location: "North", Court_Status: 1})
+	// Slot 2 was closed by an admin, slot 5 is taken by a booking and slot 7 by a cancelled one
+	db.Omit("Court", "CourtIDRef").Create(&courtTimeSlotsV1{Court_ID: 1, Court_Name: "Court A"})
+	db.Exec("UPDATE \"Court_TimeSlots\" SET \"slot_10_11\" = 0, \"slot_13_14\" = 0, \"slot_15_16\" = 0")
+	db.Omit("Customer", "Sport", "Court").Create(&bookingsV1{Customer_ID: 1, Sport_ID: 1, Court_ID: 1, Booking_Status: "Confirmed", Booking_Time: 5})
+	db.Omit("Customer", "Sport", "Court").Create(&bookingsV1{Customer_ID: 1, Sport_ID: 1, Court_ID: 1, Booking_Status: "Cancelled", Booking_Time: 7})
+
+	if _, err := Up(db); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if db.Migrator().HasTable(&courtTimeSlotsV1{}) {
+		t.Errorf("expected Court_TimeSlots to be dropped")
+	}
+
+	var blackouts []DataBase.Court_Blackout
+	db.Order("\"Slot_Index\"").Find(&blackouts)
+	if len(blackouts) != 2 {
+		t.Fatalf("expected blackouts on the two closed slots without a booking, got %+v", blackouts)
+	}
+	today := *DataBase.BookingDate(time.Now())
+	for i, slot := range []int{2, 7} {
+		b := blackouts[i]
+		if b.Court_ID != 1 || b.Slot_Index == nil || *b.Slot_Index != slot || b.Blackout_Date != today {
+			t.Errorf("expected court 1 slot %d blacked out on %s, got %+v", slot, today, b)
+		}
+	}
+}
+
+func TestRunStatus(t *testing.T) {
+	db := openTestDB(t)
+
+	var out bytes.Buffer
+	if err := Run(db, []string{"up"}, &out); err != nil {
+		t.Fatalf("migrate up failed: %v", err)
+	}
+	if err := Run(db, []string{"down"}, &out); err != nil {
+		t.Fatalf("migrate down failed: %v", err)
+	}
+
+	out.Reset()
+	if err := Run(db, []string{"status"}, &out); err != nil {
+		t.Fatalf("migrate status failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(All()) {
+		t.Fatalf("expected one line per migration, got %q", out.String())
+	}
+	if !strings.Contains(lines[0], "applied") || !strings.Contains(lines[len(lines)-1], "pending") {
+		t.Errorf("unexpected status output:\n%s", out.String())
+	}
+
+	if err := Run(db, []string{"sideways"}, &out); err == nil {
+		t.Errorf("expected an unknown command to fail")
+	}
+}
+
+func TestCheckingTheSchemaOnlyReads(t *testing.T) {
+	db := openTestDB(t)
+
+	if err := EnsureCurrent(db); !errors.Is(err, ErrSchemaBehind) {
+		t.Errorf("expected an empty database to be behind, got %v", err)
+	}
+	if pending, err := Pending(db); err != nil || len(pending) != len(All()) {
+		t.Errorf("expected every migration pending, got %d (error: %v)", len(pending), err)
+	}
+	if db.Migrator().HasTable(&schemaMigration{}) {
+		t.Errorf("expected checking the schema not to create schema_migrations")
+	}
+
+	if _, err := Up(db); err != nil {
+		t.Fatalf("migrate up failed: %v", err)
+	}
+	if err := EnsureCurrent(db); err != nil {
+		t.Fatalf("expected the schema to be current, got %v", err)
+	}
+
+	// A newer build has migrated the database further
+	db.Create(&schemaMigration{Version: 9999, Name: "from_the_future", Applied_At: time.Now()})
+	if err := EnsureCurrent(db); !errors.Is(err, ErrSchemaAhead) || !strings.Contains(err.Error(), "9999_from_the_future") {
+		t.Errorf("expected ErrSchemaAhead naming the unknown migration, got %v", err)
+	}
+	if _, err := Up(db); !errors.Is(err, ErrSchemaAhead) {
+		t.Errorf("expected Up to refuse a database from a newer build, got %v", err)
+	}
+	if status, _ := Status(db); len(status) != len(All())+1 || status[len(status)-1].Version != 9999 {
+		t.Errorf("expected the unknown migration in the status, got %+v", status[len(status)-1])
+	}
+}
+
+func TestUpKeepsTodaysBookingsTaken(t *testing.T) {
+	db := openTestDB(t)
+
+	if err := db.AutoMigrate(&customerV1{}, &sportV1{}, &courtV1{}, &courtTimeSlotsV1{}, &adminV1{}, &bookingsV1{}); err != nil {
+		t.Fatalf("AutoMigrate failed: %v", err)
+	}
+	db.Create(&sportV1{Sport_ID: 1, Sport_name: "Tennis"})
+	db.Create(&customerV1{Customer_ID: 1, Email: "a@ufl.edu"})
+	db.Omit("Sport").Create(&courtV1{Court_ID: 1, Court_Name: "Court A", Court_Location: "North", Court_Status: 1, Sport_id: 1})
+	db.Omit("Court", "CourtIDRef").Create(&courtTimeSlotsV1{Court_ID: 1, Court_Name: "Court A"})
+	// Slot 3 was booked before the upgrade, twice by the old race, and slot 4 was booked and cancelled
+	db.Exec("UPDATE \"Court_TimeSlots\" SET \"slot_11_12\" = 2")
+	for _, b := range []bookingsV1{{Booking_Time: 3, Booking_Status: "Confirmed"}, {Booking_Time: 3, Booking_Status: "booked"},
+		{Booking_Time: 4, Booking_Status: "Cancelled"}} {
+		b.Customer_ID, b.Sport_ID, b.Court_ID = 1, 1, 1
+		db.Omit("Customer", "Sport", "Court").Create(&b)
+	}
+
+	if _, err := Up(db); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+
+	today := *DataBase.BookingDate(time.Now())
+	store := Repository.NewGormStore(db)
+	occupied, err := store.Courts().Occupancy([]uint{1}, today, today)
+	if err != nil || len(occupied) != 1 || occupied[0].Slot == nil || *occupied[0].Slot != 3 {
+		t.Fatalf("expected only slot 3 taken today, got %+v (error: %v)", occupied, err)
+	}
+	again := DataBase.Bookings{Customer_ID: 1, Sport_ID: 1, Court_ID: 1, Booking_Time: 3, Booking_Date: &today,
+		Booking_Status: DataBase.BookingConfirmed}
+	if err := store.Bookings().Create(&again); !errors.Is(err, Repository.ErrDuplicate) {
+		t.Errorf("expected a second booking of the slot to be refused, got %v", err)
+	}
+
+	var bookings []DataBase.Bookings
+	db.Order("\"Booking_ID\"").Find(&bookings)
+	if len(bookings) != 3 || bookings[0].Booking_Date == nil || *bookings[0].Booking_Date != today ||
+		bookings[1].Booking_Date != nil || bookings[2].Booking_Date != nil {
+		t.Errorf("expected only the first active booking dated today, got %+v", bookings)
+	}
+}
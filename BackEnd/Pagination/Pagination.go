@@ -0,0 +1,210 @@
+// Package Pagination reads the paging, sorting and filter parameters shared by the list
+// endpoints, and writes the headers that lead to the next page.
+//
+// Lists are paged with opaque cursors rather than page numbers: limit sets the page size, the
+// X-Next-Cursor header and the Link header with rel="next" give the next page, and sort names
+// a field to order by, prefixed with - for descending order.
+package Pagination
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultLimit is the page size when the request gives none.
+	DefaultLimit = 100
+	// MaxLimit is the largest page size a request may ask for.
+	MaxLimit = 500
+)
+
+// Params reads list parameters from a request and collects what is wrong with them, so a
+// handler can report every invalid parameter at once.
+type Params struct {
+	query   url.Values
+	details []DataBase.FieldError
+}
+
+// New returns the list parameters of r.
+func New(r *http.Request) *Params {
+	return &Params{query: r.URL.Query()}
+}
+
+// Invalid reports that parameter name is wrong, for checks a handler makes itself.
+func (p *Params) Invalid(name, message string) {
+	p.details = append(p.details, APIError.Field(name, message))
+}
+
+// cursor is the content of a cursor parameter. It records the order it was issued for, since
+// it means nothing in another one.
+type cursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+// List reads limit, cursor and sort, which must be one of sorts.
+func (p *Params) List(sorts []string) Repository.ListQuery {
+	q := Repository.ListQuery{Sort: "id", Limit: DefaultLimit}
+
+	if raw := p.query.Get("sort"); raw != "" {
+		q.Sort, q.Desc = strings.TrimPrefix(raw, "-"), strings.HasPrefix(raw, "-")
+		known := false
+		for _, s := range sorts {
+			known = known || s == q.Sort
+		}
+		if !known {
+			p.Invalid("sort", "must be one of "+strings.Join(sorts, ", ")+", optionally prefixed with -")
+		}
+	}
+
+	if raw := p.query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			p.Invalid("limit", "must be a number from 1 to "+strconv.Itoa(MaxLimit))
+		}
+		q.Limit = limit
+	}
+
+	if raw := p.query.Get("cursor"); raw != "" {
+		var c cursor
+		data, err := base64.RawURLEncoding.DecodeString(raw)
+		if err == nil {
+			err = json.Unmarshal(data, &c)
+		}
+		switch {
+		case err != nil:
+			p.Invalid("cursor", "is not a cursor returned by this list")
+		case c.Sort != q.Sort || c.Desc != q.Desc:
+			p.Invalid("cursor", "was returned for another sort order")
+		default:
+			q.After = &Repository.Cursor{Value: c.Value, ID: c.ID}
+		}
+	}
+	return q
+}
+
+// ID reads an optional ID parameter, returning 0 when it is absent.
+func (p *Params) ID(name string) uint {
+	raw := p.query.Get(name)
+	if raw == "" {
+		return 0
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || id == 0 {
+		p.Invalid(name, "must be an ID")
+	}
+	return uint(id)
+}
+
+// Int reads an optional number parameter, returning nil when it is absent.
+func (p *Params) Int(name string) *int {
+	raw := p.query.Get(name)
+	if raw == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		p.Invalid(name, "must be a number")
+		return nil
+	}
+	return &n
+}
+
+// Bool reads an optional true or false parameter, returning nil when it is absent.
+func (p *Params) Bool(name string) *bool {
+	raw := p.query.Get(name)
+	if raw == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		p.Invalid(name, "must be true or false")
+		return nil
+	}
+	return &b
+}
+
+// Date reads an optional date parameter formatted as a Booking_Date.
+func (p *Params) Date(name string) string {
+	raw := p.query.Get(name)
+	if _, err := time.Parse(DataBase.BookingDateLayout, raw); raw != "" && err != nil {
+		p.Invalid(name, "must be a date formatted as YYYY-MM-DD")
+	}
+	return raw
+}
+
+// String reads an optional parameter as it is.
+func (p *Params) String(name string) string {
+	return p.query.Get(name)
+}
+
+// Statuses reads an optional comma separated list of booking statuses.
+func (p *Params) Statuses(name string) []DataBase.BookingStatus {
+	raw := p.query.Get(name)
+	if raw == "" {
+		return nil
+	}
+	var statuses []DataBase.BookingStatus
+	for _, s := range strings.Split(raw, ",") {
+		status := DataBase.BookingStatus(strings.TrimSpace(s))
+		if !status.Valid() {
+			p.Invalid(name, "has an unknown booking status "+strconv.Quote(string(status)))
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Check answers 400 listing every invalid parameter read so far, and reports whether they were
+// all valid.
+func (p *Params) Check(w http.ResponseWriter, r *http.Request) bool {
+	if len(p.details) == 0 {
+		return true
+	}
+	APIError.Validation(w, r, "Invalid list parameters", p.details...)
+	return false
+}
+
+// SetNext points to the page after the one q returned, when next is not nil. The Link header
+// repeats the request with the cursor replaced, so the filters carry over.
+func SetNext(w http.ResponseWriter, r *http.Request, q Repository.ListQuery, next *Repository.Cursor) {
+	if next == nil {
+		return
+	}
+	data, _ := json.Marshal(cursor{Sort: q.Sort, Desc: q.Desc, Value: next.Value, ID: next.ID})
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+
+	link := *r.URL
+	query := link.Query()
+	query.Set("cursor", encoded)
+	link.RawQuery = query.Encode()
+	w.Header().Set("X-Next-Cursor", encoded)
+	w.Header().Add("Link", "<"+link.RequestURI()+">; rel=\"next\"")
+}
+
+// SetTotal reports how many rows match the filters across all pages.
+func SetTotal(w http.ResponseWriter, total int64) {
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+}
+
+// Failed answers the error of a list query: 400 for a cursor the repository rejected, otherwise
+// 500 with message.
+func Failed(w http.ResponseWriter, r *http.Request, err error, message string) {
+	if errors.Is(err, Repository.ErrInvalidQuery) {
+		APIError.Validation(w, r, "Invalid list parameters", APIError.Field("cursor", "does not fit the sort order"))
+		return
+	}
+	APIError.Internal(w, r, message, err)
+}
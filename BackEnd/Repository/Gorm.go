@@ -0,0 +1,1038 @@
+package Repository
+
+import (
+	"BackEnd/DataBase"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gormStore implements Store on top of a *gorm.DB, which may itself be a transaction.
+// Column names are quoted in raw conditions because the schema uses mixed case identifiers;
+// Pluck and Update take bare names and let GORM quote them for the dialect.
+type gormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore returns a Store backed by the given database connection.
+func NewGormStore(db *gorm.DB) Store {
+	return &gormStore{db: db}
+}
+
+func (s *gormStore) Sports() SportRepository       { return gormSports{s.db} }
+func (s *gormStore) Courts() CourtRepository       { return gormCourts{s.db} }
+func (s *gormStore) Bookings() BookingRepository   { return gormBookings{s.db} }
+func (s *gormStore) Customers() CustomerRepository { return gormCustomers{s.db} }
+func (s *gormStore) Admins() AdminRepository       { return gormAdmins{s.db} }
+func (s *gormStore) Blackouts() BlackoutRepository { return gormBlackouts{s.db} }
+func (s *gormStore) IdempotencyKeys() IdempotencyRepository {
+	return gormIdempotencyKeys{s.db}
+}
+
+func (s *gormStore) Transaction(fn func(tx Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&gormStore{db: tx})
+	})
+}
+
+// translateError maps GORM errors onto the package level sentinel errors.
+func translateError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey),
+		strings.Contains(err.Error(), "UNIQUE constraint failed"),
+		strings.Contains(err.Error(), "duplicate key value"):
+		return ErrDuplicate
+	}
+	return err
+}
+
+// touchSports moves the availability version of the given sports on, so availability cached
+// for them is fetched again.
+func touchSports(tx *gorm.DB, sportIDs ...uint) error {
+	return translateError(tx.Exec("UPDATE \"Sport\" SET \"Availability_Version\" = \"Availability_Version\" + 1 "+
+		"WHERE \"Sport_ID\" IN ?", sportIDs).Error)
+}
+
+// touchCourts moves the availability version of the sports of the given courts on, deleted
+// courts included. courtIDs is a list of IDs or a subquery selecting them.
+func touchCourts(tx *gorm.DB, courtIDs interface{}) error {
+	return translateError(tx.Exec("UPDATE \"Sport\" SET \"Availability_Version\" = \"Availability_Version\" + 1 "+
+		"WHERE \"Sport_ID\" IN (SELECT \"Sport_id\" FROM \"Court\" WHERE \"Court_ID\" IN (?))", courtIDs).Error)
+}
+
+// touchAllSports moves the availability version of every sport on.
+func touchAllSports(tx *gorm.DB) error {
+	return translateError(tx.Exec("UPDATE \"Sport\" SET \"Availability_Version\" = \"Availability_Version\" + 1").Error)
+}
+
+// ---- Sports ----
+
+type gormSports struct{ db *gorm.DB }
+
+func (r gormSports) List() ([]DataBase.Sport, error) {
+	var sports []DataBase.Sport
+	err := r.db.Order("\"Sport_ID\"").Find(&sports).Error
+	return sports, translateError(err)
+}
+
+func (r gormSports) Page(q ListQuery) ([]DataBase.Sport, *Cursor, error) {
+	db, err := sportSorts.apply(r.db, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	var sports []DataBase.Sport
+	if err := db.Find(&sports).Error; err != nil {
+		return nil, nil, translateError(err)
+	}
+	sports, next := sportSorts.next(sports, q)
+	return sports, next, nil
+}
+
+func (r gormSports) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&DataBase.Sport{}).Count(&count).Error
+	return count, translateError(err)
+}
+
+func (r gormSports) FindByID(id uint) (DataBase.Sport, error) {
+	var sport DataBase.Sport
+	err := r.db.First(&sport, id).Error
+	return sport, translateError(err)
+}
+
+func (r gormSports) FindByName(name string) (DataBase.Sport, error) {
+	var sport DataBase.Sport
+	err := r.db.Where("\"Sport_name\" = ?", name).First(&sport).Error
+	return sport, translateError(err)
+}
+
+func (r gormSports) Create(sport *DataBase.Sport) error {
+	return translateError(r.db.Create(sport).Error)
+}
+
+func (r gormSports) Update(sport *DataBase.Sport) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(sport).Select("Sport_name", "Sport_Description").Updates(sport)
+		if result.Error != nil {
+			return translateError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return touchSports(tx, sport.Sport_ID)
+	})
+}
+
+func (r gormSports) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		stamp := deletedNow()
+		courtIDs := tx.Model(&DataBase.Court{}).Select("Court_ID").Where("\"Sport_id\" = ?", id)
+		if err := softDelete(tx.Where("\"Court_ID\" IN (?)", courtIDs), &DataBase.Bookings{}, stamp); err != nil {
+			return err
+		}
+		if err := softDelete(tx.Where("\"Sport_id\" = ?", id), &DataBase.Court{}, stamp); err != nil {
+			return err
+		}
+		if err := touchSports(tx, id); err != nil {
+			return err
+		}
+		return softDelete(tx.Where("\"Sport_ID\" = ?", id), &DataBase.Sport{}, stamp)
+	})
+}
+
+func (r gormSports) ListDeleted() ([]DataBase.Sport, error) {
+	var sports []DataBase.Sport
+	err := deletedRows(r.db).Order("\"Deleted_At\" DESC, \"Sport_ID\"").Find(&sports).Error
+	return sports, translateError(err)
+}
+
+func (r gormSports) Restore(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var sport DataBase.Sport
+		if err := deletedRows(tx).First(&sport, id).Error; err != nil {
+			return translateError(err)
+		}
+		stamp := sport.Deleted_At.Time
+		courtIDs := deletedRows(tx).Model(&DataBase.Court{}).Select("Court_ID").
+			Where("\"Sport_id\" = ? AND \"Deleted_At\" = ?", id, stamp)
+		if err := restore(tx.Where("\"Court_ID\" IN (?) AND \"Deleted_At\" = ?", courtIDs, stamp), &DataBase.Bookings{}); err != nil {
+			return err
+		}
+		if err := restore(tx.Where("\"Sport_id\" = ? AND \"Deleted_At\" = ?", id, stamp), &DataBase.Court{}); err != nil {
+			return err
+		}
+		if err := restore(tx.Where("\"Sport_ID\" = ?", id), &DataBase.Sport{}); err != nil {
+			return err
+		}
+		return touchSports(tx, id)
+	})
+}
+
+func (r gormSports) Purge(cutoff time.Time) (int64, error) {
+	result := deletedRows(r.db).Where("\"Deleted_At\" < ?", cutoff).Delete(&DataBase.Sport{})
+	return result.RowsAffected, translateError(result.Error)
+}
+
+// ---- Courts ----
+
+type gormCourts struct{ db *gorm.DB }
+
+func (r gormCourts) List() ([]DataBase.Court, error) {
+	var courts []DataBase.Court
+	err := r.db.Preload("Sport").Order("\"Court_ID\"").Find(&courts).Error
+	return courts, translateError(err)
+}
+
+func (r gormCourts) ListBySport(sportID uint) ([]DataBase.Court, error) {
+	var courts []DataBase.Court
+	err := r.db.Where("\"Sport_id\" = ?", sportID).Order("\"Court_ID\"").Find(&courts).Error
+	return courts, translateError(err)
+}
+
+func (r gormCourts) filtered(filter CourtFilter) *gorm.DB {
+	db := r.db.Model(&DataBase.Court{})
+	if filter.SportID != 0 {
+		db = db.Where("\"Sport_id\" = ?", filter.SportID)
+	}
+	if len(filter.SportIDs) > 0 {
+		db = db.Where("\"Sport_id\" IN ?", filter.SportIDs)
+	}
+	if filter.Status != nil {
+		db = db.Where("\"Court_Status\" = ?", *filter.Status)
+	}
+	if filter.Indoor != nil {
+		db = db.Where("\"Court_Indoor\" = ?", *filter.Indoor)
+	}
+	return db
+}
+
+func (r gormCourts) Page(filter CourtFilter, q ListQuery) ([]DataBase.Court, *Cursor, error) {
+	db, err := courtSorts.apply(r.filtered(filter), q)
+	if err != nil {
+		return nil, nil, err
+	}
+	var courts []DataBase.Court
+	if err := db.Preload("Sport").Find(&courts).Error; err != nil {
+		return nil, nil, translateError(err)
+	}
+	courts, next := courtSorts.next(courts, q)
+	return courts, next, nil
+}
+
+func (r gormCourts) Count(filter CourtFilter) (int64, error) {
+	var count int64
+	err := r.filtered(filter).Count(&count).Error
+	return count, translateError(err)
+}
+
+func (r gormCourts) Occupancy(courtIDs []uint, from, to string) ([]Occupied, error) {
+	occupied := []Occupied{}
+	if len(courtIDs) == 0 {
+		return occupied, nil
+	}
+	// Raw SQL skips the soft delete scope, so deleted bookings are left out by hand
+	err := r.db.Raw("SELECT \"Court_ID\", \"Booking_Date\" AS \"Date\", \"Booking_Time\" AS \"Slot\" FROM \"Bookings\" "+
+		"WHERE \"Court_ID\" IN ? AND \"Booking_Date\" BETWEEN ? AND ? AND \"Booking_Status\" NOT IN ? AND \"Deleted_At\" IS NULL "+
+		"UNION ALL "+
+		"SELECT \"Court_ID\", \"Blackout_Date\", \"Slot_Index\" FROM \"Court_Blackouts\" "+
+		"WHERE \"Court_ID\" IN ? AND \"Blackout_Date\" BETWEEN ? AND ?",
+		courtIDs, from, to, DataBase.CancelledStatuses, courtIDs, from, to).Scan(&occupied).Error
+	return occupied, translateError(err)
+}
+
+func (r gormCourts) Hours(courtIDs []uint) (map[uint]string, error) {
+	hours := map[uint]string{}
+	if len(courtIDs) == 0 {
+		return hours, nil
+	}
+	var courts []DataBase.Court
+	err := r.db.Select("Court_ID", "Court_Hours").
+		Where("\"Court_ID\" IN ? AND \"Court_Hours\" <> ''", courtIDs).
+		Find(&courts).Error
+	for _, court := range courts {
+		hours[court.Court_ID] = court.Court_Hours
+	}
+	return hours, translateError(err)
+}
+
+func (r gormCourts) FindByID(id uint) (DataBase.Court, error) {
+	var court DataBase.Court
+	err := r.db.First(&court, id).Error
+	return court, translateError(err)
+}
+
+func (r gormCourts) FindByName(name string) (DataBase.Court, error) {
+	var court DataBase.Court
+	err := r.db.Where("\"Court_Name\" = ?", name).First(&court).Error
+	return court, translateError(err)
+}
+
+func (r gormCourts) Create(court *DataBase.Court) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(court).Error; err != nil {
+			return translateError(err)
+		}
+		return touchSports(tx, court.Sport_id)
+	})
+}
+
+func (r gormCourts) Update(court *DataBase.Court) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// The sport the court leaves, if it moves
+		if err := touchCourts(tx, []uint{court.Court_ID}); err != nil {
+			return err
+		}
+		result := tx.Model(court).Omit("Sport").
+			Select("Court_Name", "Court_Location", "Court_Capacity", "Court_Status", "Court_Indoor", "Court_Hours", "Sport_id").
+			Updates(court)
+		if result.Error != nil {
+			return translateError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		err := tx.Model(&DataBase.Bookings{}).
+			Where("\"Court_ID\" = ? AND \"Sport_ID\" <> ? AND \"Booking_Date\" >= ? AND \"Booking_Status\" NOT IN ?",
+				court.Court_ID, court.Sport_id, *DataBase.BookingDate(time.Now()), DataBase.CancelledStatuses).
+			Update("Sport_ID", court.Sport_id).Error
+		if err != nil {
+			return translateError(err)
+		}
+		return touchSports(tx, court.Sport_id)
+	})
+}
+
+func (r gormCourts) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		stamp := deletedNow()
+		if err := softDelete(tx.Where("\"Court_ID\" = ?", id), &DataBase.Bookings{}, stamp); err != nil {
+			return err
+		}
+		if err := softDelete(tx.Where("\"Court_ID\" = ?", id), &DataBase.Court{}, stamp); err != nil {
+			return err
+		}
+		return touchCourts(tx, []uint{id})
+	})
+}
+
+func (r gormCourts) ListDeleted() ([]DataBase.Court, error) {
+	var courts []DataBase.Court
+	err := deletedRows(r.db).Order("\"Deleted_At\" DESC, \"Court_ID\"").Find(&courts).Error
+	return courts, translateError(err)
+}
+
+func (r gormCourts) Restore(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var court DataBase.Court
+		if err := deletedRows(tx).First(&court, id).Error; err != nil {
+			return translateError(err)
+		}
+		var deletedSports int64
+		if err := deletedRows(tx).Model(&DataBase.Sport{}).Where("\"Sport_ID\" = ?", court.Sport_id).Count(&deletedSports).Error; err != nil {
+			return translateError(err)
+		}
+		if deletedSports > 0 {
+			return ErrParentDeleted
+		}
+		stamp := court.Deleted_At.Time
+		if err := restore(tx.Where("\"Court_ID\" = ? AND \"Deleted_At\" = ?", id, stamp), &DataBase.Bookings{}); err != nil {
+			return err
+		}
+		if err := restore(tx.Where("\"Court_ID\" = ?", id), &DataBase.Court{}); err != nil {
+			return err
+		}
+		return touchCourts(tx, []uint{id})
+	})
+}
+
+func (r gormCourts) Purge(cutoff time.Time) (int64, error) {
+	var purged int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		courtIDs := deletedRows(tx).Model(&DataBase.Court{}).Select("Court_ID").Where("\"Deleted_At\" < ?", cutoff)
+		if err := tx.Where("\"Court_ID\" IN (?)", courtIDs).Delete(&DataBase.Court_Blackout{}).Error; err != nil {
+			return translateError(err)
+		}
+		result := deletedRows(tx).Where("\"Deleted_At\" < ?", cutoff).Delete(&DataBase.Court{})
+		purged = result.RowsAffected
+		return translateError(result.Error)
+	})
+	return purged, err
+}
+
+func (r gormCourts) AvailableIDs(courtName string) ([]uint, error) {
+	const AvailableStatus = 1
+
+	var courtIDs []uint
+	db := r.db.Model(&DataBase.Court{}).Where("\"Court_Status\" = ?", AvailableStatus)
+	if courtName != "" {
+		db = db.Where("LOWER(\"Court_Name\") = LOWER(?)", courtName)
+	}
+	err := db.Order("\"Court_ID\"").Pluck("Court_ID", &courtIDs).Error
+	return courtIDs, translateError(err)
+}
+
+// ---- Bookings ----
+
+type gormBookings struct{ db *gorm.DB }
+
+func (r gormBookings) List() ([]DataBase.Bookings, error) {
+	var bookings []DataBase.Bookings
+	err := r.db.Order("\"Booking_ID\"").Find(&bookings).Error
+	return bookings, translateError(err)
+}
+
+func (r gormBookings) FindByID(id uint) (DataBase.Bookings, error) {
+	var booking DataBase.Bookings
+	err := r.db.First(&booking, id).Error
+	return booking, translateError(err)
+}
+
+func (r gormBookings) ListByCustomer(customerID uint) ([]DataBase.Bookings, error) {
+	var bookings []DataBase.Bookings
+	err := r.db.Preload("Court").Preload("Sport").
+		Where("\"Customer_ID\" = ?", customerID).
+		Order("\"Booking_ID\"").
+		Find(&bookings).Error
+	return bookings, translateError(err)
+}
+
+func (r gormBookings) ListActive() ([]DataBase.Bookings, error) {
+	var bookings []DataBase.Bookings
+	err := r.db.
+		Where("\"Booking_Status\" NOT IN ?", DataBase.CancelledStatuses).
+		Preload("Customer").
+		Preload("Court").
+		Preload("Sport").
+		Order("\"Booking_ID\"").
+		Find(&bookings).Error
+	return bookings, translateError(err)
+}
+
+func (r gormBookings) filtered(filter BookingFilter) *gorm.DB {
+	db := r.db.Model(&DataBase.Bookings{})
+	if filter.From != "" {
+		db = db.Where("\"Booking_Date\" >= ?", filter.From)
+	}
+	if filter.To != "" {
+		db = db.Where("\"Booking_Date\" <= ?", filter.To)
+	}
+	if filter.SportID != 0 {
+		db = db.Where("\"Sport_ID\" = ?", filter.SportID)
+	}
+	if filter.CourtID != 0 {
+		db = db.Where("\"Court_ID\" = ?", filter.CourtID)
+	}
+	if filter.CustomerID != 0 {
+		db = db.Where("\"Customer_ID\" = ?", filter.CustomerID)
+	}
+	if filter.UFID != "" {
+		db = db.Where("\"Customer_ID\" IN (?)",
+			r.db.Model(&DataBase.Customer{}).Select("\"Customer_ID\"").Where("\"UFID\" = ?", filter.UFID))
+	}
+	if len(filter.Statuses) > 0 {
+		db = db.Where("\"Booking_Status\" IN ?", filter.Statuses)
+	}
+	if filter.Active {
+		db = db.Where("\"Booking_Status\" NOT IN ?", DataBase.CancelledStatuses)
+	}
+	return db
+}
+
+func (r gormBookings) Page(filter BookingFilter, q ListQuery) ([]DataBase.Bookings, *Cursor, error) {
+	db, err := bookingSorts.apply(r.filtered(filter), q)
+	if err != nil {
+		return nil, nil, err
+	}
+	var bookings []DataBase.Bookings
+	if err := db.Preload("Customer").Preload("Court").Preload("Sport").Find(&bookings).Error; err != nil {
+		return nil, nil, translateError(err)
+	}
+	bookings, next := bookingSorts.next(bookings, q)
+	return bookings, next, nil
+}
+
+func (r gormBookings) Count(filter BookingFilter) (int64, error) {
+	var count int64
+	err := r.filtered(filter).Count(&count).Error
+	return count, translateError(err)
+}
+
+func (r gormBookings) ListActiveOn(courtIDs []uint, date string) ([]DataBase.Bookings, error) {
+	var bookings []DataBase.Bookings
+	if len(courtIDs) == 0 {
+		return bookings, nil
+	}
+	err := r.db.
+		Where("\"Court_ID\" IN ? AND \"Booking_Date\" = ?", courtIDs, date).
+		Where("\"Booking_Status\" NOT IN ?", DataBase.CancelledStatuses).
+		Order("\"Booking_ID\"").
+		Find(&bookings).Error
+	return bookings, translateError(err)
+}
+
+func (r gormBookings) Create(booking *DataBase.Bookings) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Customer", "Sport", "Court").Create(booking).Error; err != nil {
+			return translateError(err)
+		}
+		if err := touchCourts(tx, []uint{booking.Court_ID}); err != nil {
+			return err
+		}
+		return translateError(tx.Create(&DataBase.Booking_StatusHistory{
+			Booking_ID: booking.Booking_ID,
+			To_Status:  booking.Booking_Status,
+			Changed_At: time.Now(),
+		}).Error)
+	})
+}
+
+func (r gormBookings) Transition(id uint, to DataBase.BookingStatus, note string) error {
+	return r.transitionBy(id, to, note, nil)
+}
+
+func (r gormBookings) AdminTransition(id uint, to DataBase.BookingStatus, note string, adminID uint) error {
+	return r.transitionBy(id, to, note, &adminID)
+}
+
+func (r gormBookings) transitionBy(id uint, to DataBase.BookingStatus, note string, changedBy *uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var booking DataBase.Bookings
+		if err := tx.First(&booking, id).Error; err != nil {
+			return translateError(err)
+		}
+		return transition(tx, booking, to, note, changedBy)
+	})
+}
+
+// transition moves a loaded booking to status to. The update only matches while the booking
+// still has the status it was read with, so of two concurrent transitions only one succeeds.
+func transition(tx *gorm.DB, booking DataBase.Bookings, to DataBase.BookingStatus, note string, changedBy *uint) error {
+	if !DataBase.CanTransition(booking.Booking_Status, to) {
+		return ErrInvalidTransition
+	}
+	result := tx.Model(&DataBase.Bookings{}).
+		Where("\"Booking_ID\" = ? AND \"Booking_Status\" = ?", booking.Booking_ID, booking.Booking_Status).
+		UpdateColumn("Booking_Status", to)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTransition
+	}
+	if err := touchCourts(tx, []uint{booking.Court_ID}); err != nil {
+		return err
+	}
+	return translateError(tx.Create(&DataBase.Booking_StatusHistory{
+		Booking_ID:  booking.Booking_ID,
+		From_Status: booking.Booking_Status,
+		To_Status:   to,
+		Changed_At:  time.Now(),
+		Note:        note,
+		Changed_By:  changedBy,
+	}).Error)
+}
+
+func (r gormBookings) CancelActiveForCourts(courtIDs []uint, status DataBase.BookingStatus, note string) error {
+	if len(courtIDs) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var bookings []DataBase.Bookings
+		err := tx.Where("\"Court_ID\" IN ? AND \"Booking_Status\" NOT IN ?", courtIDs, DataBase.CancelledStatuses).
+			Order("\"Booking_ID\"").
+			Find(&bookings).Error
+		if err != nil {
+			return translateError(err)
+		}
+		for _, booking := range bookings {
+			if !DataBase.CanTransition(booking.Booking_Status, status) {
+				continue
+			}
+			if err := transition(tx, booking, status, note, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r gormBookings) History(bookingID uint) ([]DataBase.Booking_StatusHistory, error) {
+	var history []DataBase.Booking_StatusHistory
+	err := r.db.Where("\"Booking_ID\" = ?", bookingID).Order("\"History_ID\"").Find(&history).Error
+	return history, translateError(err)
+}
+
+func (r gormBookings) Archive(before, note string) (int64, error) {
+	var archived int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var bookings []DataBase.Bookings
+		err := tx.Where("\"Booking_Date\" < ?", before).
+			Preload("Court").
+			Preload("Sport").
+			Order("\"Booking_ID\"").
+			Find(&bookings).Error
+		if err != nil || len(bookings) == 0 {
+			return translateError(err)
+		}
+
+		now := time.Now()
+		ids := make([]uint, 0, len(bookings))
+		rows := make([]DataBase.Booking_Archive, 0, len(bookings))
+		courtIDs := make([]uint, 0, len(bookings))
+		var history []DataBase.Booking_StatusHistory
+		for _, b := range bookings {
+			status := DataBase.RolloverStatus(b.Booking_Status)
+			if status != b.Booking_Status {
+				history = append(history, DataBase.Booking_StatusHistory{
+					Booking_ID: b.Booking_ID, From_Status: b.Booking_Status, To_Status: status, Changed_At: now, Note: note,
+				})
+			}
+			ids = append(ids, b.Booking_ID)
+			courtIDs = append(courtIDs, b.Court_ID)
+			rows = append(rows, archiveRow(b, status, now))
+		}
+		if len(history) > 0 {
+			if err := tx.CreateInBatches(history, 100).Error; err != nil {
+				return translateError(err)
+			}
+		}
+		if err := tx.CreateInBatches(rows, 100).Error; err != nil {
+			return translateError(err)
+		}
+		result := tx.Unscoped().Where("\"Booking_ID\" IN ?", ids).Delete(&DataBase.Bookings{})
+		if result.Error != nil {
+			return translateError(result.Error)
+		}
+		archived = result.RowsAffected
+		return touchCourts(tx, courtIDs)
+	})
+	return archived, err
+}
+
+func (r gormBookings) ListArchived(customerID uint, from, to string, offset, limit int) ([]DataBase.Booking_Archive, int64, error) {
+	db := r.db.Model(&DataBase.Booking_Archive{}).Where("\"Customer_ID\" = ?", customerID)
+	if from != "" {
+		db = db.Where("\"Booking_Date\" >= ?", from)
+	}
+	if to != "" {
+		db = db.Where("\"Booking_Date\" <= ?", to)
+	}
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, translateError(err)
+	}
+	var archived []DataBase.Booking_Archive
+	err := db.Order("\"Booking_Date\" DESC, \"Booking_Time\" DESC, \"Booking_ID\" DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&archived).Error
+	return archived, total, translateError(err)
+}
+
+// archiveRow is the archived copy of b, which ends on status. b's Court and Sport must be loaded.
+func archiveRow(b DataBase.Bookings, status DataBase.BookingStatus, at time.Time) DataBase.Booking_Archive {
+	return DataBase.Booking_Archive{
+		Booking_ID:     b.Booking_ID,
+		Customer_ID:    b.Customer_ID,
+		Sport_ID:       b.Sport_ID,
+		Sport_Name:     b.Sport.Sport_name,
+		Court_ID:       b.Court_ID,
+		Court_Name:     b.Court.Court_Name,
+		Booking_Status: status,
+		Booking_Time:   b.Booking_Time,
+		Booking_Date:   *b.Booking_Date,
+		Archived_At:    at,
+	}
+}
+
+func (r gormBookings) ListDeleted() ([]DataBase.Bookings, error) {
+	var bookings []DataBase.Bookings
+	err := deletedRows(r.db).Order("\"Deleted_At\" DESC, \"Booking_ID\"").Find(&bookings).Error
+	return bookings, translateError(err)
+}
+
+func (r gormBookings) Restore(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var booking DataBase.Bookings
+		if err := deletedRows(tx).First(&booking, id).Error; err != nil {
+			return translateError(err)
+		}
+		var deletedCourts int64
+		if err := deletedRows(tx).Model(&DataBase.Court{}).Where("\"Court_ID\" = ?", booking.Court_ID).Count(&deletedCourts).Error; err != nil {
+			return translateError(err)
+		}
+		if deletedCourts > 0 {
+			return ErrParentDeleted
+		}
+		if err := restore(tx.Where("\"Booking_ID\" = ?", id), &DataBase.Bookings{}); err != nil {
+			return err
+		}
+		return touchCourts(tx, []uint{booking.Court_ID})
+	})
+}
+
+func (r gormBookings) Purge(cutoff time.Time) (int64, error) {
+	var purged int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		bookingIDs := deletedRows(tx).Model(&DataBase.Bookings{}).Select("Booking_ID").Where("\"Deleted_At\" < ?", cutoff)
+		if err := tx.Where("\"Booking_ID\" IN (?)", bookingIDs).Delete(&DataBase.Booking_StatusHistory{}).Error; err != nil {
+			return translateError(err)
+		}
+		result := deletedRows(tx).Where("\"Deleted_At\" < ?", cutoff).Delete(&DataBase.Bookings{})
+		purged = result.RowsAffected
+		return translateError(result.Error)
+	})
+	return purged, err
+}
+
+func (r gormBookings) DeleteAll() error {
+	return r.db.Transaction(deleteAllBookings)
+}
+
+// deleteAllBookings empties Bookings, the archive and the history and restarts the ID sequences.
+func deleteAllBookings(tx *gorm.DB) error {
+	if err := deleteAll(tx, &DataBase.Booking_StatusHistory{}, "Booking_StatusHistory", "History_ID"); err != nil {
+		return err
+	}
+	if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&DataBase.Booking_Archive{}).Error; err != nil {
+		return translateError(err)
+	}
+	if err := deleteAll(tx, &DataBase.Bookings{}, "Bookings", "Booking_ID"); err != nil {
+		return err
+	}
+	return touchAllSports(tx)
+}
+
+// ---- Customers ----
+
+type gormCustomers struct{ db *gorm.DB }
+
+func (r gormCustomers) List() ([]DataBase.Customer, error) {
+	var customers []DataBase.Customer
+	err := r.db.Order("\"Customer_ID\"").Find(&customers).Error
+	return customers, translateError(err)
+}
+
+func (r gormCustomers) FindByID(id uint) (DataBase.Customer, error) {
+	var customer DataBase.Customer
+	err := r.db.First(&customer, id).Error
+	return customer, translateError(err)
+}
+
+func (r gormCustomers) FindByEmail(email string) (DataBase.Customer, error) {
+	var customer DataBase.Customer
+	normalizedEmail := strings.ToLower(strings.TrimSpace(email))
+	err := r.db.Where("LOWER(\"Email\") = ?", normalizedEmail).First(&customer).Error
+	return customer, translateError(err)
+}
+
+func (r gormCustomers) Create(customer *DataBase.Customer) error {
+	return translateError(r.db.Create(customer).Error)
+}
+
+func (r gormCustomers) UpdateProfile(id uint, name, ufid string) error {
+	updates := map[string]interface{}{}
+	if ufid != "" {
+		updates["UFID"] = ufid
+	}
+	if name != "" {
+		updates["Name"] = name
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	return translateError(r.db.Model(&DataBase.Customer{}).Where("\"Customer_ID\" = ?", id).Updates(updates).Error)
+}
+
+func (r gormCustomers) DeleteAll() error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteAllBookings(tx); err != nil {
+			return err
+		}
+		return deleteAll(tx, &DataBase.Customer{}, "Customer", "Customer_ID")
+	})
+}
+
+// ---- Blackouts ----
+
+type gormBlackouts struct{ db *gorm.DB }
+
+func (r gormBlackouts) List(courtIDs []uint, date string) ([]DataBase.Court_Blackout, error) {
+	var blackouts []DataBase.Court_Blackout
+	db := r.db
+	if courtIDs != nil {
+		if len(courtIDs) == 0 {
+			return blackouts, nil
+		}
+		db = db.Where("\"Court_ID\" IN ?", courtIDs)
+	}
+	if date != "" {
+		db = db.Where("\"Blackout_Date\" = ?", date)
+	}
+	err := db.Order("\"Blackout_Date\", \"Blackout_ID\"").Find(&blackouts).Error
+	return blackouts, translateError(err)
+}
+
+func (r gormBlackouts) FindByID(id uint) (DataBase.Court_Blackout, error) {
+	var blackout DataBase.Court_Blackout
+	err := r.db.First(&blackout, id).Error
+	return blackout, translateError(err)
+}
+
+func (r gormBlackouts) Create(blackout *DataBase.Court_Blackout) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(blackout).Error; err != nil {
+			return translateError(err)
+		}
+		return touchCourts(tx, []uint{blackout.Court_ID})
+	})
+}
+
+func (r gormBlackouts) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		courtIDs := tx.Model(&DataBase.Court_Blackout{}).Select("Court_ID").Where("\"Blackout_ID\" = ?", id)
+		if err := touchCourts(tx, courtIDs); err != nil {
+			return err
+		}
+		return translateError(tx.Delete(&DataBase.Court_Blackout{}, id).Error)
+	})
+}
+
+func (r gormBlackouts) DeleteByCourt(courtID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("\"Court_ID\" = ?", courtID).Delete(&DataBase.Court_Blackout{}).Error; err != nil {
+			return translateError(err)
+		}
+		return touchCourts(tx, []uint{courtID})
+	})
+}
+
+// ---- Admins ----
+
+type gormAdmins struct{ db *gorm.DB }
+
+func (r gormAdmins) List() ([]DataBase.Admin, error) {
+	var admins []DataBase.Admin
+	err := r.db.Order("\"Admin_ID\"").Find(&admins).Error
+	return admins, translateError(err)
+}
+
+func (r gormAdmins) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&DataBase.Admin{}).Count(&count).Error
+	return count, translateError(err)
+}
+
+func (r gormAdmins) CountActiveSuperAdmins(excludeID uint) (int64, error) {
+	// Postgres cannot lock rows for an aggregate, so the rows are locked and counted here
+	var ids []uint
+	err := r.db.Model(&DataBase.Admin{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("\"Role\" = ? AND \"Status\" = ?", DataBase.AdminRoleSuperAdmin, DataBase.AdminStatusActive).
+		Order("\"Admin_ID\"").
+		Pluck("Admin_ID", &ids).Error
+	if err != nil {
+		return 0, translateError(err)
+	}
+	var count int64
+	for _, id := range ids {
+		if id != excludeID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r gormAdmins) FindByID(id uint) (DataBase.Admin, error) {
+	var admin DataBase.Admin
+	err := r.db.First(&admin, id).Error
+	return admin, translateError(err)
+}
+
+func (r gormAdmins) FindByUsername(username string) (DataBase.Admin, error) {
+	var admin DataBase.Admin
+	err := r.db.Where("\"Username\" = ?", username).First(&admin).Error
+	return admin, translateError(err)
+}
+
+func (r gormAdmins) FindByInviteToken(tokenHash string) (DataBase.Admin, error) {
+	var admin DataBase.Admin
+	if tokenHash == "" {
+		return admin, ErrNotFound
+	}
+	err := r.db.Where("\"Invite_Token\" = ?", tokenHash).First(&admin).Error
+	return admin, translateError(err)
+}
+
+func (r gormAdmins) Create(admin *DataBase.Admin) error {
+	return translateError(r.db.Create(admin).Error)
+}
+
+func (r gormAdmins) Save(admin *DataBase.Admin) error {
+	return translateError(r.db.Omit("TOTP_Last_Step").Save(admin).Error)
+}
+
+func (r gormAdmins) UseTOTPStep(adminID uint, step int64) (bool, error) {
+	result := r.db.Model(&DataBase.Admin{}).
+		Where("\"Admin_ID\" = ? AND \"TOTP_Last_Step\" < ?", adminID, step).
+		Update("TOTP_Last_Step", step)
+	if result.Error != nil {
+		return false, translateError(result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r gormAdmins) LoginAttempts(keys []string) ([]DataBase.Admin_LoginAttempt, error) {
+	var attempts []DataBase.Admin_LoginAttempt
+	err := r.db.Where("\"Attempt_Key\" IN ?", keys).Find(&attempts).Error
+	return attempts, translateError(err)
+}
+
+// errLoginAttemptLocked rolls back the increment of an attempt that found its key locked.
+var errLoginAttemptLocked = errors.New("login attempt key is locked")
+
+func (r gormAdmins) ReserveLoginAttempt(key string, now time.Time, lockout func(failures int) time.Duration) (DataBase.Admin_LoginAttempt, bool, error) {
+	var attempt DataBase.Admin_LoginAttempt
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&DataBase.Admin_LoginAttempt{Attempt_Key: key}).Error; err != nil {
+			return err
+		}
+		// The increment takes the row lock first, so concurrent attempts on the key queue up here
+		// and each reads the count and lock left by the one before it.
+		if err := tx.Model(&DataBase.Admin_LoginAttempt{}).
+			Where("\"Attempt_Key\" = ?", key).
+			Update("Failures", gorm.Expr("\"Failures\" + 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("\"Attempt_Key\" = ?", key).First(&attempt).Error; err != nil {
+			return err
+		}
+		if attempt.Locked_Until != nil && attempt.Locked_Until.After(now) {
+			return errLoginAttemptLocked
+		}
+		if d := lockout(attempt.Failures); d > 0 {
+			until := now.Add(d)
+			attempt.Locked_Until = &until
+			return tx.Model(&attempt).Update("Locked_Until", until).Error
+		}
+		return nil
+	})
+	if errors.Is(err, errLoginAttemptLocked) {
+		attempt.Failures--
+		return attempt, false, nil
+	}
+	return attempt, err == nil, translateError(err)
+}
+
+func (r gormAdmins) ClearLoginAttempts(keys []string) error {
+	return translateError(r.db.Where("\"Attempt_Key\" IN ?", keys).Delete(&DataBase.Admin_LoginAttempt{}).Error)
+}
+
+func (r gormAdmins) ReplaceRecoveryCodes(adminID uint, hashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("\"Admin_ID\" = ?", adminID).Delete(&DataBase.Admin_RecoveryCode{}).Error; err != nil {
+			return translateError(err)
+		}
+		for _, hash := range hashes {
+			if err := tx.Create(&DataBase.Admin_RecoveryCode{Admin_ID: adminID, Code_Hash: hash}).Error; err != nil {
+				return translateError(err)
+			}
+		}
+		return nil
+	})
+}
+
+func (r gormAdmins) UseRecoveryCode(adminID uint, hash string, at time.Time) (bool, error) {
+	result := r.db.Model(&DataBase.Admin_RecoveryCode{}).
+		Where("\"Admin_ID\" = ? AND \"Code_Hash\" = ? AND \"Used_At\" IS NULL", adminID, hash).
+		Update("Used_At", at)
+	if result.Error != nil {
+		return false, translateError(result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// deleteAll empties table and restarts its ID sequence, which TRUNCATE ... RESTART IDENTITY
+// did on Postgres alone.
+func deleteAll(db *gorm.DB, model interface{}, table, idColumn string) error {
+	if err := db.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(model).Error; err != nil {
+		return translateError(err)
+	}
+
+	switch db.Dialector.Name() {
+	case "postgres":
+		return translateError(db.Exec("SELECT setval(pg_get_serial_sequence(?, ?), 1, false)",
+			"\""+table+"\"", idColumn).Error)
+	case "sqlite":
+		// sqlite_sequence only exists once a table declared with AUTOINCREMENT has had a row
+		if !db.Migrator().HasTable("sqlite_sequence") {
+			return nil
+		}
+		return translateError(db.Exec("DELETE FROM sqlite_sequence WHERE name = ?", table).Error)
+	}
+	return nil
+}
+
+// deletedNow is the Deleted_At stamp for one soft delete. Every row deleted together gets the same
+// stamp, which is how Restore finds them again; it is in UTC and truncated to the microseconds
+// Postgres keeps so that the value read back compares equal.
+func deletedNow() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// softDelete stamps the live rows of model matched by db as deleted.
+func softDelete(db *gorm.DB, model interface{}, stamp time.Time) error {
+	return translateError(db.Model(model).UpdateColumn("Deleted_At", stamp).Error)
+}
+
+// deletedRows scopes db to soft deleted rows only.
+func deletedRows(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Where("\"Deleted_At\" IS NOT NULL")
+}
+
+// restore clears Deleted_At on the soft deleted rows of model matched by db.
+func restore(db *gorm.DB, model interface{}) error {
+	return translateError(deletedRows(db).Model(model).UpdateColumn("Deleted_At", nil).Error)
+}
+
+// ---- Idempotency keys ----
+
+type gormIdempotencyKeys struct{ db *gorm.DB }
+
+func (r gormIdempotencyKeys) Reserve(key *DataBase.Idempotency_Key) error {
+	return translateError(r.db.Create(key).Error)
+}
+
+func (r gormIdempotencyKeys) Find(key string) (DataBase.Idempotency_Key, error) {
+	var record DataBase.Idempotency_Key
+	err := r.db.Where("\"Key\" = ?", key).First(&record).Error
+	return record, translateError(err)
+}
+
+func (r gormIdempotencyKeys) Complete(key string, status int, headers string, body []byte) error {
+	result := r.db.Model(&DataBase.Idempotency_Key{}).Where("\"Key\" = ?", key).
+		Updates(map[string]interface{}{"Status": status, "Headers": headers, "Body": body})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r gormIdempotencyKeys) Release(key string) error {
+	return translateError(r.db.Where("\"Key\" = ?", key).Delete(&DataBase.Idempotency_Key{}).Error)
+}
+
+func (r gormIdempotencyKeys) Purge(cutoff time.Time) (int64, error) {
+	result := r.db.Where("\"Expires_At\" < ?", cutoff).Delete(&DataBase.Idempotency_Key{})
+	return result.RowsAffected, translateError(result.Error)
+}
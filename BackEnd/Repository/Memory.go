@@ -0,0 +1,1435 @@
+package Repository
+
+import (
+	"BackEnd/DataBase"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryStore is an in-memory Store for handler tests. It enforces the same unique
+// constraints as the database schema and fills in associations the way the GORM
+// implementation preloads them, but has no SQL dialect of its own.
+type MemoryStore struct {
+	mu   sync.Mutex
+	txMu sync.Mutex
+	data memoryData
+}
+
+type memoryData struct {
+	sports        map[uint]DataBase.Sport
+	courts        map[uint]DataBase.Court
+	blackouts     map[uint]DataBase.Court_Blackout
+	bookings      map[uint]DataBase.Bookings
+	archive       map[uint]DataBase.Booking_Archive
+	history       map[uint]DataBase.Booking_StatusHistory
+	customers     map[uint]DataBase.Customer
+	admins        map[uint]DataBase.Admin
+	loginAttempts map[uint]DataBase.Admin_LoginAttempt
+	recoveryCodes map[uint]DataBase.Admin_RecoveryCode
+	idempotency   map[string]DataBase.Idempotency_Key
+	nextID        map[string]uint
+}
+
+// NewMemoryStore returns an empty in-memory Store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: memoryData{
+		sports:        map[uint]DataBase.Sport{},
+		courts:        map[uint]DataBase.Court{},
+		blackouts:     map[uint]DataBase.Court_Blackout{},
+		bookings:      map[uint]DataBase.Bookings{},
+		archive:       map[uint]DataBase.Booking_Archive{},
+		history:       map[uint]DataBase.Booking_StatusHistory{},
+		customers:     map[uint]DataBase.Customer{},
+		admins:        map[uint]DataBase.Admin{},
+		loginAttempts: map[uint]DataBase.Admin_LoginAttempt{},
+		recoveryCodes: map[uint]DataBase.Admin_RecoveryCode{},
+		idempotency:   map[string]DataBase.Idempotency_Key{},
+		nextID:        map[string]uint{},
+	}}
+}
+
+func (m *MemoryStore) Sports() SportRepository       { return memorySports{m} }
+func (m *MemoryStore) Courts() CourtRepository       { return memoryCourts{m} }
+func (m *MemoryStore) Bookings() BookingRepository   { return memoryBookings{m} }
+func (m *MemoryStore) Customers() CustomerRepository { return memoryCustomers{m} }
+func (m *MemoryStore) Admins() AdminRepository       { return memoryAdmins{m} }
+func (m *MemoryStore) Blackouts() BlackoutRepository { return memoryBlackouts{m} }
+func (m *MemoryStore) IdempotencyKeys() IdempotencyRepository {
+	return memoryIdempotencyKeys{m}
+}
+
+// Transaction runs fn with transactions serialised, restoring the previous state if fn fails.
+func (m *MemoryStore) Transaction(fn func(tx Store) error) error {
+	m.txMu.Lock()
+	defer m.txMu.Unlock()
+
+	m.mu.Lock()
+	snapshot := m.data.clone()
+	m.mu.Unlock()
+
+	if err := fn(memoryTx{m}); err != nil {
+		m.mu.Lock()
+		m.data = snapshot
+		m.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// memoryTx is the Store handed to a transaction body. Nested transactions join the outer one.
+type memoryTx struct {
+	*MemoryStore
+}
+
+func (t memoryTx) Transaction(fn func(tx Store) error) error {
+	return fn(t)
+}
+
+func (d memoryData) clone() memoryData {
+	c := memoryData{
+		sports:        make(map[uint]DataBase.Sport, len(d.sports)),
+		courts:        make(map[uint]DataBase.Court, len(d.courts)),
+		blackouts:     make(map[uint]DataBase.Court_Blackout, len(d.blackouts)),
+		bookings:      make(map[uint]DataBase.Bookings, len(d.bookings)),
+		archive:       make(map[uint]DataBase.Booking_Archive, len(d.archive)),
+		history:       make(map[uint]DataBase.Booking_StatusHistory, len(d.history)),
+		customers:     make(map[uint]DataBase.Customer, len(d.customers)),
+		admins:        make(map[uint]DataBase.Admin, len(d.admins)),
+		loginAttempts: make(map[uint]DataBase.Admin_LoginAttempt, len(d.loginAttempts)),
+		recoveryCodes: make(map[uint]DataBase.Admin_RecoveryCode, len(d.recoveryCodes)),
+		idempotency:   make(map[string]DataBase.Idempotency_Key, len(d.idempotency)),
+		nextID:        make(map[string]uint, len(d.nextID)),
+	}
+	for k, v := range d.sports {
+		c.sports[k] = v
+	}
+	for k, v := range d.courts {
+		c.courts[k] = v
+	}
+	for k, v := range d.blackouts {
+		c.blackouts[k] = v
+	}
+	for k, v := range d.bookings {
+		c.bookings[k] = v
+	}
+	for k, v := range d.archive {
+		c.archive[k] = v
+	}
+	for k, v := range d.history {
+		c.history[k] = v
+	}
+	for k, v := range d.customers {
+		c.customers[k] = v
+	}
+	for k, v := range d.admins {
+		c.admins[k] = v
+	}
+	for k, v := range d.loginAttempts {
+		c.loginAttempts[k] = v
+	}
+	for k, v := range d.recoveryCodes {
+		c.recoveryCodes[k] = v
+	}
+	for k, v := range d.idempotency {
+		c.idempotency[k] = v
+	}
+	for k, v := range d.nextID {
+		c.nextID[k] = v
+	}
+	return c
+}
+
+// assignID mimics an auto-increment column: an explicit ID is kept and moves the sequence forward.
+func (d *memoryData) assignID(table string, id uint) uint {
+	if id == 0 {
+		d.nextID[table]++
+		return d.nextID[table]
+	}
+	if id > d.nextID[table] {
+		d.nextID[table] = id
+	}
+	return id
+}
+
+func sortedKeys[V any](m map[uint]V) []uint {
+	keys := make([]uint, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// deletedAt is the Deleted_At for rows soft deleted together at stamp.
+func deletedAt(stamp time.Time) gorm.DeletedAt {
+	return gorm.DeletedAt{Time: stamp, Valid: true}
+}
+
+// deletedBefore reports whether a row was soft deleted before cutoff.
+func deletedBefore(at gorm.DeletedAt, cutoff time.Time) bool {
+	return at.Valid && at.Time.Before(cutoff)
+}
+
+// sortDeleted orders soft deleted rows most recently deleted first, then by ID.
+func sortDeleted[T any](rows []T, deleted func(T) gorm.DeletedAt) {
+	sort.SliceStable(rows, func(i, j int) bool { return deleted(rows[i]).Time.After(deleted(rows[j]).Time) })
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// touchSports moves the availability version of the given sports on. Callers hold the lock.
+func (d *memoryData) touchSports(sportIDs ...uint) {
+	for _, id := range sportIDs {
+		if sport, ok := d.sports[id]; ok {
+			sport.Availability_Version++
+			d.sports[id] = sport
+		}
+	}
+}
+
+// touchCourts moves the availability version of the sports of the given courts on. Callers hold the lock.
+func (d *memoryData) touchCourts(courtIDs ...uint) {
+	var sportIDs []uint
+	for _, id := range courtIDs {
+		if court, ok := d.courts[id]; ok && !containsID(sportIDs, court.Sport_id) {
+			sportIDs = append(sportIDs, court.Sport_id)
+		}
+	}
+	d.touchSports(sportIDs...)
+}
+
+// touchAllSports moves the availability version of every sport on. Callers hold the lock.
+func (d *memoryData) touchAllSports() {
+	d.touchSports(sortedKeys(d.sports)...)
+}
+
+// sportNameTaken reports whether a sport other than exceptID that is not deleted has name, as the
+// partial unique index does on the databases. Callers hold the lock.
+func (d *memoryData) sportNameTaken(name string, exceptID uint) bool {
+	for id, s := range d.sports {
+		if s.Sport_name == name && id != exceptID && !s.Deleted_At.Valid {
+			return true
+		}
+	}
+	return false
+}
+
+// courtNameTaken is sportNameTaken for courts. Callers hold the lock.
+func (d *memoryData) courtNameTaken(name string, exceptID uint) bool {
+	for id, c := range d.courts {
+		if c.Court_Name == name && id != exceptID && !c.Deleted_At.Valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ---- Sports ----
+
+type memorySports struct{ m *MemoryStore }
+
+func (r memorySports) List() ([]DataBase.Sport, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	sports := []DataBase.Sport{}
+	for _, id := range sortedKeys(r.m.data.sports) {
+		if sport := r.m.data.sports[id]; !sport.Deleted_At.Valid {
+			sports = append(sports, sport)
+		}
+	}
+	return sports, nil
+}
+
+func (r memorySports) Page(q ListQuery) ([]DataBase.Sport, *Cursor, error) {
+	sports, _ := r.List()
+	return sportSorts.page(sports, q)
+}
+
+func (r memorySports) Count() (int64, error) {
+	sports, _ := r.List()
+	return int64(len(sports)), nil
+}
+
+func (r memorySports) FindByID(id uint) (DataBase.Sport, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	sport, ok := r.m.data.sports[id]
+	if !ok || sport.Deleted_At.Valid {
+		return DataBase.Sport{}, ErrNotFound
+	}
+	return sport, nil
+}
+
+func (r memorySports) FindByName(name string) (DataBase.Sport, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	for _, id := range sortedKeys(r.m.data.sports) {
+		if sport := r.m.data.sports[id]; sport.Sport_name == name && !sport.Deleted_At.Valid {
+			return r.m.data.sports[id], nil
+		}
+	}
+	return DataBase.Sport{}, ErrNotFound
+}
+
+func (r memorySports) Create(sport *DataBase.Sport) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	for id, s := range r.m.data.sports {
+		if (s.Sport_name == sport.Sport_name && !s.Deleted_At.Valid) || id == sport.Sport_ID {
+			return ErrDuplicate
+		}
+	}
+	sport.Sport_ID = r.m.data.assignID("sports", sport.Sport_ID)
+	r.m.data.sports[sport.Sport_ID] = *sport
+	return nil
+}
+
+func (r memorySports) Update(sport *DataBase.Sport) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	stored, ok := r.m.data.sports[sport.Sport_ID]
+	if !ok || stored.Deleted_At.Valid {
+		return ErrNotFound
+	}
+	if r.m.data.sportNameTaken(sport.Sport_name, sport.Sport_ID) {
+		return ErrDuplicate
+	}
+	stored.Sport_name, stored.Sport_Description = sport.Sport_name, sport.Sport_Description
+	stored.Availability_Version++
+	r.m.data.sports[sport.Sport_ID] = stored
+	return nil
+}
+
+func (r memorySports) Delete(id uint) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	at := deletedAt(time.Now())
+	for courtID, court := range r.m.data.courts {
+		if court.Sport_id == id && !court.Deleted_At.Valid {
+			r.m.data.softDeleteBookings(func(b DataBase.Bookings) bool { return b.Court_ID == courtID }, at)
+			court.Deleted_At = at
+			r.m.data.courts[courtID] = court
+		}
+	}
+	if sport, ok := r.m.data.sports[id]; ok && !sport.Deleted_At.Valid {
+		sport.Deleted_At = at
+		r.m.data.sports[id] = sport
+	}
+	r.m.data.touchSports(id)
+	return nil
+}
+
+func (r memorySports) ListDeleted() ([]DataBase.Sport, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	sports := []DataBase.Sport{}
+	for _, id := range sortedKeys(r.m.data.sports) {
+		if sport := r.m.data.sports[id]; sport.Deleted_At.Valid {
+			sports = append(sports, sport)
+		}
+	}
+	sortDeleted(sports, func(s DataBase.Sport) gorm.DeletedAt { return s.Deleted_At })
+	return sports, nil
+}
+
+func (r memorySports) Restore(id uint) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	sport, ok := r.m.data.sports[id]
+	if !ok || !sport.Deleted_At.Valid {
+		return ErrNotFound
+	}
+	at := sport.Deleted_At
+	if r.m.data.sportNameTaken(sport.Sport_name, id) {
+		return ErrDuplicate
+	}
+	for _, court := range r.m.data.courts {
+		if court.Sport_id == id && court.Deleted_At == at && r.m.data.courtNameTaken(court.Court_Name, court.Court_ID) {
+			return ErrDuplicate
+		}
+	}
+	for courtID, court := range r.m.data.courts {
+		if court.Sport_id == id && court.Deleted_At == at {
+			r.m.data.restoreBookings(func(b DataBase.Bookings) bool { return b.Court_ID == courtID }, at)
+			court.Deleted_At = gorm.DeletedAt{}
+			r.m.data.courts[courtID] = court
+		}
+	}
+	sport.Deleted_At = gorm.DeletedAt{}
+	sport.Availability_Version++
+	r.m.data.sports[id] = sport
+	return nil
+}
+
+func (r memorySports) Purge(cutoff time.Time) (int64, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	var purged int64
+	for id, sport := range r.m.data.sports {
+		if deletedBefore(sport.Deleted_At, cutoff) {
+			delete(r.m.data.sports, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// ---- Courts ----
+
+type memoryCourts struct{ m *MemoryStore }
+
+func (r memoryCourts) List() ([]DataBase.Court, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	courts := []DataBase.Court{}
+	for _, id := range sortedKeys(r.m.data.courts) {
+		court := r.m.data.courts[id]
+		if court.Deleted_At.Valid {
+			continue
+		}
+		if sport, ok := r.m.data.sports[court.Sport_id]; ok && !sport.Deleted_At.Valid {
+			court.Sport = &sport
+		}
+		courts = append(courts, court)
+	}
+	return courts, nil
+}
+
+func (r memoryCourts) filtered(filter CourtFilter) []DataBase.Court {
+	courts, _ := r.List()
+	matched := []DataBase.Court{}
+	for _, court := range courts {
+		if (filter.SportID == 0 || court.Sport_id == filter.SportID) &&
+			(len(filter.SportIDs) == 0 || containsID(filter.SportIDs, court.Sport_id)) &&
+			(filter.Status == nil || court.Court_Status == *filter.Status) &&
+			(filter.Indoor == nil || court.Court_Indoor == *filter.Indoor) {
+			matched = append(matched, court)
+		}
+	}
+	return matched
+}
+
+func (r memoryCourts) Page(filter CourtFilter, q ListQuery) ([]DataBase.Court, *Cursor, error) {
+	return courtSorts.page(r.filtered(filter), q)
+}
+
+func (r memoryCourts) Count(filter CourtFilter) (int64, error) {
+	return int64(len(r.filtered(filter))), nil
+}
+
+func (r memoryCourts) Occupancy(courtIDs []uint, from, to string) ([]Occupied, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	occupied := []Occupied{}
+	for _, id := range sortedKeys(r.m.data.bookings) {
+		b := r.m.data.bookings[id]
+		if containsID(courtIDs, b.Court_ID) && isActive(b) && !b.Deleted_At.Valid && b.Booking_Date != nil &&
+			*b.Booking_Date >= from && *b.Booking_Date <= to {
+			slot := b.Booking_Time
+			occupied = append(occupied, Occupied{Court_ID: b.Court_ID, Date: *b.Booking_Date, Slot: &slot})
+		}
+	}
+	for _, id := range sortedKeys(r.m.data.blackouts) {
+		b := r.m.data.blackouts[id]
+		if containsID(courtIDs, b.Court_ID) && b.Blackout_Date >= from && b.Blackout_Date <= to {
+			occupied = append(occupied, Occupied{Court_ID: b.Court_ID, Date: b.Blackout_Date, Slot: b.Slot_Index})
+		}
+	}
+	return occupied, nil
+}
+
+func (r memoryCourts) ListBySport(sportID uint) ([]DataBase.Court, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	courts := []DataBase.Court{}
+	for _, id := range sortedKeys(r.m.data.courts) {
+		if court := r.m.data.courts[id]; court.Sport_id == sportID && !court.Deleted_At.Valid {
+			courts = append(courts, r.m.data.courts[id])
+		}
+	}
+	return courts, nil
+}
+
+func (r memoryCourts) FindByID(id uint) (DataBase.Court, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	court, ok := r.m.data.courts[id]
+	if !ok || court.Deleted_At.Valid {
+		return DataBase.Court{}, ErrNotFound
+	}
+	return court, nil
+}
+
+func (r memoryCourts) FindByName(name string) (DataBase.Court, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	for _, id := range sortedKeys(r.m.data.courts) {
+		if court := r.m.data.courts[id]; court.Court_Name == name && !court.Deleted_At.Valid {
+			return r.m.data.courts[id], nil
+		}
+	}
+	return DataBase.Court{}, ErrNotFound
+}
+
+func (r memoryCourts) Create(court *DataBase.Court) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	for id, c := range r.m.data.courts {
+		if (c.Court_Name == court.Court_Name && !c.Deleted_At.Valid) || id == court.Court_ID {
+			return ErrDuplicate
+		}
+	}
+	court.Court_ID = r.m.data.assignID("courts", court.Court_ID)
+	stored := *court
+	stored.Sport = nil
+	r.m.data.courts[court.Court_ID] = stored
+	r.m.data.touchSports(court.Sport_id)
+	return nil
+}
+
+func (r memoryCourts) Update(court *DataBase.Court) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	stored, ok := r.m.data.courts[court.Court_ID]
+	if !ok || stored.Deleted_At.Valid {
+		return ErrNotFound
+	}
+	if r.m.data.courtNameTaken(court.Court_Name, court.Court_ID) {
+		return ErrDuplicate
+	}
+	r.m.data.touchSports(stored.Sport_id)
+	updated := *court
+	updated.Sport, updated.Deleted_At = nil, stored.Deleted_At
+	r.m.data.courts[court.Court_ID] = updated
+	today := *DataBase.BookingDate(time.Now())
+	for id, b := range r.m.data.bookings {
+		if b.Court_ID == court.Court_ID && !b.Deleted_At.Valid && isActive(b) && b.Booking_Date != nil && *b.Booking_Date >= today {
+			b.Sport_ID = court.Sport_id
+			r.m.data.bookings[id] = b
+		}
+	}
+	r.m.data.touchSports(court.Sport_id)
+	return nil
+}
+
+func (r memoryCourts) Delete(id uint) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	court, ok := r.m.data.courts[id]
+	if !ok || court.Deleted_At.Valid {
+		return nil
+	}
+	at := deletedAt(time.Now())
+	r.m.data.softDeleteBookings(func(b DataBase.Bookings) bool { return b.Court_ID == id }, at)
+	court.Deleted_At = at
+	r.m.data.courts[id] = court
+	r.m.data.touchCourts(id)
+	return nil
+}
+
+func (r memoryCourts) ListDeleted() ([]DataBase.Court, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	courts := []DataBase.Court{}
+	for _, id := range sortedKeys(r.m.data.courts) {
+		if court := r.m.data.courts[id]; court.Deleted_At.Valid {
+			courts = append(courts, court)
+		}
+	}
+	sortDeleted(courts, func(c DataBase.Court) gorm.DeletedAt { return c.Deleted_At })
+	return courts, nil
+}
+
+func (r memoryCourts) Restore(id uint) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	court, ok := r.m.data.courts[id]
+	if !ok || !court.Deleted_At.Valid {
+		return ErrNotFound
+	}
+	if r.m.data.sports[court.Sport_id].Deleted_At.Valid {
+		return ErrParentDeleted
+	}
+	if r.m.data.courtNameTaken(court.Court_Name, id) {
+		return ErrDuplicate
+	}
+	r.m.data.restoreBookings(func(b DataBase.Bookings) bool { return b.Court_ID == id }, court.Deleted_At)
+	court.Deleted_At = gorm.DeletedAt{}
+	r.m.data.courts[id] = court
+	r.m.data.touchCourts(id)
+	return nil
+}
+
+func (r memoryCourts) Purge(cutoff time.Time) (int64, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	var purged int64
+	for id, court := range r.m.data.courts {
+		if deletedBefore(court.Deleted_At, cutoff) {
+			for blackoutID, b := range r.m.data.blackouts {
+				if b.Court_ID == id {
+					delete(r.m.data.blackouts, blackoutID)
+				}
+			}
+			delete(r.m.data.courts, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (r memoryCourts) Hours(courtIDs []uint) (map[uint]string, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	hours := map[uint]string{}
+	for _, id := range courtIDs {
+		if court, ok := r.m.data.courts[id]; ok && !court.Deleted_At.Valid && court.Court_Hours != "" {
+			hours[id] = court.Court_Hours
+		}
+	}
+	return hours, nil
+}
+
+func (r memoryCourts) AvailableIDs(courtName string) ([]uint, error) {
+	const AvailableStatus = 1
+
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	var ids []uint
+	for _, id := range sortedKeys(r.m.data.courts) {
+		court := r.m.data.courts[id]
+		if court.Court_Status != AvailableStatus || court.Deleted_At.Valid {
+			continue
+		}
+		if courtName != "" && !strings.EqualFold(court.Court_Name, courtName) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ---- Bookings ----
+
+type memoryBookings struct{ m *MemoryStore }
+
+// withAssociations fills in Customer, Sport and Court like the GORM preloads do. Callers hold the lock.
+func (r memoryBookings) withAssociations(b DataBase.Bookings) DataBase.Bookings {
+	b.Customer = r.m.data.customers[b.Customer_ID]
+	b.Sport = r.m.data.sports[b.Sport_ID]
+	b.Court = r.m.data.courts[b.Court_ID]
+	return b
+}
+
+func (r memoryBookings) List() ([]DataBase.Bookings, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	bookings := []DataBase.Bookings{}
+	for _, id := range sortedKeys(r.m.data.bookings) {
+		if b := r.m.data.bookings[id]; !b.Deleted_At.Valid {
+			bookings = append(bookings, b)
+		}
+	}
+	return bookings, nil
+}
+
+func (r memoryBookings) FindByID(id uint) (DataBase.Bookings, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	booking, ok := r.m.data.bookings[id]
+	if !ok || booking.Deleted_At.Valid {
+		return DataBase.Bookings{}, ErrNotFound
+	}
+	return booking, nil
+}
+
+func (r memoryBookings) ListByCustomer(customerID uint) ([]DataBase.Bookings, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	bookings := []DataBase.Bookings{}
+	for _, id := range sortedKeys(r.m.data.bookings) {
+		if b := r.m.data.bookings[id]; b.Customer_ID == customerID && !b.Deleted_At.Valid {
+			bookings = append(bookings, r.withAssociations(b))
+		}
+	}
+	return bookings, nil
+}
+
+func (r memoryBookings) ListActive() ([]DataBase.Bookings, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	bookings := []DataBase.Bookings{}
+	for _, id := range sortedKeys(r.m.data.bookings) {
+		if b := r.m.data.bookings[id]; isActive(b) && !b.Deleted_At.Valid {
+			bookings = append(bookings, r.withAssociations(b))
+		}
+	}
+	return bookings, nil
+}
+
+func (r memoryBookings) filtered(filter BookingFilter) []DataBase.Bookings {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	bookings := []DataBase.Bookings{}
+	for _, b := range r.m.data.bookings {
+		if !b.Deleted_At.Valid && filter.matches(b, r.m.data.customers[b.Customer_ID]) {
+			bookings = append(bookings, r.withAssociations(b))
+		}
+	}
+	return bookings
+}
+
+// matches reports whether booking b of customer c passes the filter.
+func (f BookingFilter) matches(b DataBase.Bookings, c DataBase.Customer) bool {
+	if (f.From != "" || f.To != "") && b.Booking_Date == nil {
+		return false
+	}
+	if f.From != "" && *b.Booking_Date < f.From || f.To != "" && *b.Booking_Date > f.To {
+		return false
+	}
+	if f.SportID != 0 && b.Sport_ID != f.SportID || f.CourtID != 0 && b.Court_ID != f.CourtID ||
+		f.CustomerID != 0 && b.Customer_ID != f.CustomerID || f.UFID != "" && c.UFID != f.UFID {
+		return false
+	}
+	if f.Active && !isActive(b) {
+		return false
+	}
+	if len(f.Statuses) == 0 {
+		return true
+	}
+	for _, status := range f.Statuses {
+		if b.Booking_Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (r memoryBookings) Page(filter BookingFilter, q ListQuery) ([]DataBase.Bookings, *Cursor, error) {
+	return bookingSorts.page(r.filtered(filter), q)
+}
+
+func (r memoryBookings) Count(filter BookingFilter) (int64, error) {
+	return int64(len(r.filtered(filter))), nil
+}
+
+func (r memoryBookings) ListActiveOn(courtIDs []uint, date string) ([]DataBase.Bookings, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	bookings := []DataBase.Bookings{}
+	for _, id := range sortedKeys(r.m.data.bookings) {
+		b := r.m.data.bookings[id]
+		if containsID(courtIDs, b.Court_ID) && isActive(b) && !b.Deleted_At.Valid && b.Booking_Date != nil && *b.Booking_Date == date {
+			bookings = append(bookings, b)
+		}
+	}
+	return bookings, nil
+}
+
+// slotTaken mirrors the unique index on active bookings: it reports whether another active
+// booking holds b's court, date and slot. Bookings without a date never clash.
+func (d memoryData) slotTaken(b DataBase.Bookings) bool {
+	if b.Booking_Date == nil || !isActive(b) {
+		return false
+	}
+	for id, other := range d.bookings {
+		if id != b.Booking_ID && isActive(other) && other.Booking_Date != nil &&
+			other.Court_ID == b.Court_ID && other.Booking_Time == b.Booking_Time && *other.Booking_Date == *b.Booking_Date {
+			return true
+		}
+	}
+	return false
+}
+
+func isActive(b DataBase.Bookings) bool {
+	return !b.Booking_Status.Cancelled()
+}
+
+// recordStatus appends a history entry for booking id. Callers hold the lock.
+func (d *memoryData) recordStatus(id uint, from, to DataBase.BookingStatus, note string, changedBy *uint) {
+	entry := DataBase.Booking_StatusHistory{Booking_ID: id, From_Status: from, To_Status: to, Changed_At: time.Now(), Note: note, Changed_By: changedBy}
+	entry.History_ID = d.assignID("history", 0)
+	d.history[entry.History_ID] = entry
+}
+
+// deleteHistory removes the history of every booking for which deleted returns true. Callers hold the lock.
+func (d *memoryData) deleteHistory(deleted func(bookingID uint) bool) {
+	for id, entry := range d.history {
+		if deleted(entry.Booking_ID) {
+			delete(d.history, id)
+		}
+	}
+}
+
+func (r memoryBookings) Create(booking *DataBase.Bookings) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	if _, exists := r.m.data.bookings[booking.Booking_ID]; exists {
+		return ErrDuplicate
+	}
+	if r.m.data.slotTaken(*booking) {
+		return ErrDuplicate
+	}
+	booking.Booking_ID = r.m.data.assignID("bookings", booking.Booking_ID)
+	stored := *booking
+	stored.Customer, stored.Sport, stored.Court = DataBase.Customer{}, DataBase.Sport{}, DataBase.Court{}
+	r.m.data.bookings[booking.Booking_ID] = stored
+	r.m.data.recordStatus(booking.Booking_ID, "", booking.Booking_Status, "", nil)
+	r.m.data.touchCourts(booking.Court_ID)
+	return nil
+}
+
+func (r memoryBookings) Transition(id uint, to DataBase.BookingStatus, note string) error {
+	return r.transitionBy(id, to, note, nil)
+}
+
+func (r memoryBookings) AdminTransition(id uint, to DataBase.BookingStatus, note string, adminID uint) error {
+	return r.transitionBy(id, to, note, &adminID)
+}
+
+func (r memoryBookings) transitionBy(id uint, to DataBase.BookingStatus, note string, changedBy *uint) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	booking, ok := r.m.data.bookings[id]
+	if !ok || booking.Deleted_At.Valid {
+		return ErrNotFound
+	}
+	if !DataBase.CanTransition(booking.Booking_Status, to) {
+		return ErrInvalidTransition
+	}
+	r.m.data.recordStatus(id, booking.Booking_Status, to, note, changedBy)
+	booking.Booking_Status = to
+	r.m.data.bookings[id] = booking
+	r.m.data.touchCourts(booking.Court_ID)
+	return nil
+}
+
+func (r memoryBookings) CancelActiveForCourts(courtIDs []uint, status DataBase.BookingStatus, note string) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	for _, id := range sortedKeys(r.m.data.bookings) {
+		b := r.m.data.bookings[id]
+		if containsID(courtIDs, b.Court_ID) && !b.Deleted_At.Valid && DataBase.CanTransition(b.Booking_Status, status) {
+			r.m.data.recordStatus(id, b.Booking_Status, status, note, nil)
+			b.Booking_Status = status
+			r.m.data.bookings[id] = b
+			r.m.data.touchCourts(b.Court_ID)
+		}
+	}
+	return nil
+}
+
+func (r memoryBookings) History(bookingID uint) ([]DataBase.Booking_StatusHistory, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	history := []DataBase.Booking_StatusHistory{}
+	for _, id := range sortedKeys(r.m.data.history) {
+		if entry := r.m.data.history[id]; entry.Booking_ID == bookingID {
+			history = append(history, entry)
+		}
+	}
+	return history, nil
+}
+
+func (r memoryBookings) Archive(before, note string) (int64, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	now := time.Now()
+	var archived int64
+	for _, id := range sortedKeys(r.m.data.bookings) {
+		b := r.m.data.bookings[id]
+		if b.Deleted_At.Valid || b.Booking_Date == nil || *b.Booking_Date >= before {
+			continue
+		}
+		status := DataBase.RolloverStatus(b.Booking_Status)
+		if status != b.Booking_Status {
+			r.m.data.recordStatus(id, b.Booking_Status, status, note, nil)
+		}
+		r.m.data.archive[id] = archiveRow(r.withAssociations(b), status, now)
+		delete(r.m.data.bookings, id)
+		r.m.data.touchCourts(b.Court_ID)
+		archived++
+	}
+	return archived, nil
+}
+
+func (r memoryBookings) ListArchived(customerID uint, from, to string, offset, limit int) ([]DataBase.Booking_Archive, int64, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	matched := []DataBase.Booking_Archive{}
+	for _, a := range r.m.data.archive {
+		if a.Customer_ID == customerID && (from == "" || a.Booking_Date >= from) && (to == "" || a.Booking_Date <= to) {
+			matched = append(matched, a)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if a.Booking_Date != b.Booking_Date {
+			return a.Booking_Date > b.Booking_Date
+		}
+		if a.Booking_Time != b.Booking_Time {
+			return a.Booking_Time > b.Booking_Time
+		}
+		return a.Booking_ID > b.Booking_ID
+	})
+
+	total := int64(len(matched))
+	if offset > len(matched) {
+		offset = len(matched)
+	}
+	matched = matched[offset:]
+	if limit >= 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, total, nil
+}
+
+func (r memoryBookings) ListDeleted() ([]DataBase.Bookings, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	bookings := []DataBase.Bookings{}
+	for _, id := range sortedKeys(r.m.data.bookings) {
+		if b := r.m.data.bookings[id]; b.Deleted_At.Valid {
+			bookings = append(bookings, b)
+		}
+	}
+	sortDeleted(bookings, func(b DataBase.Bookings) gorm.DeletedAt { return b.Deleted_At })
+	return bookings, nil
+}
+
+func (r memoryBookings) Restore(id uint) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	booking, ok := r.m.data.bookings[id]
+	if !ok || !booking.Deleted_At.Valid {
+		return ErrNotFound
+	}
+	if r.m.data.courts[booking.Court_ID].Deleted_At.Valid {
+		return ErrParentDeleted
+	}
+	booking.Deleted_At = gorm.DeletedAt{}
+	r.m.data.bookings[id] = booking
+	r.m.data.touchCourts(booking.Court_ID)
+	return nil
+}
+
+func (r memoryBookings) Purge(cutoff time.Time) (int64, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	purge := func(bookingID uint) bool { return deletedBefore(r.m.data.bookings[bookingID].Deleted_At, cutoff) }
+	r.m.data.deleteHistory(purge)
+	var purged int64
+	for id := range r.m.data.bookings {
+		if purge(id) {
+			delete(r.m.data.bookings, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// softDeleteBookings stamps the live bookings matched by match as deleted. Callers hold the lock.
+func (d *memoryData) softDeleteBookings(match func(DataBase.Bookings) bool, at gorm.DeletedAt) {
+	for id, b := range d.bookings {
+		if match(b) && !b.Deleted_At.Valid {
+			b.Deleted_At = at
+			d.bookings[id] = b
+		}
+	}
+}
+
+// restoreBookings undeletes the bookings matched by match that were deleted at at. Callers hold the lock.
+func (d *memoryData) restoreBookings(match func(DataBase.Bookings) bool, at gorm.DeletedAt) {
+	for id, b := range d.bookings {
+		if match(b) && b.Deleted_At == at {
+			b.Deleted_At = gorm.DeletedAt{}
+			d.bookings[id] = b
+		}
+	}
+}
+
+func (r memoryBookings) DeleteAll() error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	r.m.data.deleteAllBookings()
+	return nil
+}
+
+// deleteAllBookings empties bookings, the archive and the history. Callers hold the lock.
+func (d *memoryData) deleteAllBookings() {
+	d.bookings = map[uint]DataBase.Bookings{}
+	d.archive = map[uint]DataBase.Booking_Archive{}
+	d.nextID["bookings"] = 0
+	d.history = map[uint]DataBase.Booking_StatusHistory{}
+	d.nextID["history"] = 0
+	d.touchAllSports()
+}
+
+// ---- Customers ----
+
+type memoryCustomers struct{ m *MemoryStore }
+
+func (r memoryCustomers) List() ([]DataBase.Customer, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	customers := []DataBase.Customer{}
+	for _, id := range sortedKeys(r.m.data.customers) {
+		customers = append(customers, r.m.data.customers[id])
+	}
+	return customers, nil
+}
+
+func (r memoryCustomers) FindByID(id uint) (DataBase.Customer, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	customer, ok := r.m.data.customers[id]
+	if !ok {
+		return customer, ErrNotFound
+	}
+	return customer, nil
+}
+
+func (r memoryCustomers) FindByEmail(email string) (DataBase.Customer, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	normalizedEmail := strings.ToLower(strings.TrimSpace(email))
+	for _, id := range sortedKeys(r.m.data.customers) {
+		if strings.ToLower(r.m.data.customers[id].Email) == normalizedEmail {
+			return r.m.data.customers[id], nil
+		}
+	}
+	return DataBase.Customer{}, ErrNotFound
+}
+
+func (r memoryCustomers) Create(customer *DataBase.Customer) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	if _, exists := r.m.data.customers[customer.Customer_ID]; exists {
+		return ErrDuplicate
+	}
+	customer.Customer_ID = r.m.data.assignID("customers", customer.Customer_ID)
+	r.m.data.customers[customer.Customer_ID] = *customer
+	return nil
+}
+
+func (r memoryCustomers) UpdateProfile(id uint, name, ufid string) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	customer, ok := r.m.data.customers[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if ufid != "" {
+		customer.UFID = ufid
+	}
+	if name != "" {
+		customer.Name = name
+	}
+	r.m.data.customers[id] = customer
+	return nil
+}
+
+func (r memoryCustomers) DeleteAll() error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	r.m.data.deleteAllBookings()
+	r.m.data.customers = map[uint]DataBase.Customer{}
+	r.m.data.nextID["customers"] = 0
+	return nil
+}
+
+// ---- Blackouts ----
+
+type memoryBlackouts struct{ m *MemoryStore }
+
+func (r memoryBlackouts) List(courtIDs []uint, date string) ([]DataBase.Court_Blackout, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	blackouts := []DataBase.Court_Blackout{}
+	for _, id := range sortedKeys(r.m.data.blackouts) {
+		b := r.m.data.blackouts[id]
+		if courtIDs != nil && !containsID(courtIDs, b.Court_ID) {
+			continue
+		}
+		if date != "" && b.Blackout_Date != date {
+			continue
+		}
+		blackouts = append(blackouts, b)
+	}
+	sort.SliceStable(blackouts, func(i, j int) bool { return blackouts[i].Blackout_Date < blackouts[j].Blackout_Date })
+	return blackouts, nil
+}
+
+func (r memoryBlackouts) FindByID(id uint) (DataBase.Court_Blackout, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	blackout, ok := r.m.data.blackouts[id]
+	if !ok {
+		return blackout, ErrNotFound
+	}
+	return blackout, nil
+}
+
+func (r memoryBlackouts) Create(blackout *DataBase.Court_Blackout) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	if _, exists := r.m.data.blackouts[blackout.Blackout_ID]; exists {
+		return ErrDuplicate
+	}
+	blackout.Blackout_ID = r.m.data.assignID("blackouts", blackout.Blackout_ID)
+	r.m.data.blackouts[blackout.Blackout_ID] = *blackout
+	r.m.data.touchCourts(blackout.Court_ID)
+	return nil
+}
+
+func (r memoryBlackouts) Delete(id uint) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	if blackout, ok := r.m.data.blackouts[id]; ok {
+		r.m.data.touchCourts(blackout.Court_ID)
+	}
+	delete(r.m.data.blackouts, id)
+	return nil
+}
+
+func (r memoryBlackouts) DeleteByCourt(courtID uint) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	for id, b := range r.m.data.blackouts {
+		if b.Court_ID == courtID {
+			delete(r.m.data.blackouts, id)
+		}
+	}
+	r.m.data.touchCourts(courtID)
+	return nil
+}
+
+// ---- Admins ----
+
+type memoryAdmins struct{ m *MemoryStore }
+
+func (r memoryAdmins) List() ([]DataBase.Admin, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	admins := []DataBase.Admin{}
+	for _, id := range sortedKeys(r.m.data.admins) {
+		admins = append(admins, r.m.data.admins[id])
+	}
+	return admins, nil
+}
+
+func (r memoryAdmins) Count() (int64, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	return int64(len(r.m.data.admins)), nil
+}
+
+func (r memoryAdmins) CountActiveSuperAdmins(excludeID uint) (int64, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	var count int64
+	for id, a := range r.m.data.admins {
+		if id != excludeID && a.Role == DataBase.AdminRoleSuperAdmin && a.Status == DataBase.AdminStatusActive {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r memoryAdmins) FindByID(id uint) (DataBase.Admin, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	admin, ok := r.m.data.admins[id]
+	if !ok {
+		return admin, ErrNotFound
+	}
+	return admin, nil
+}
+
+func (r memoryAdmins) FindByUsername(username string) (DataBase.Admin, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	for _, a := range r.m.data.admins {
+		if a.Username == username {
+			return a, nil
+		}
+	}
+	return DataBase.Admin{}, ErrNotFound
+}
+
+func (r memoryAdmins) FindByInviteToken(tokenHash string) (DataBase.Admin, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	if tokenHash == "" {
+		return DataBase.Admin{}, ErrNotFound
+	}
+	for _, a := range r.m.data.admins {
+		if a.Invite_Token == tokenHash {
+			return a, nil
+		}
+	}
+	return DataBase.Admin{}, ErrNotFound
+}
+
+func (r memoryAdmins) Create(admin *DataBase.Admin) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	for id, a := range r.m.data.admins {
+		if a.Username == admin.Username || id == admin.Admin_ID {
+			return ErrDuplicate
+		}
+	}
+	// Column defaults from the schema
+	if admin.Role == "" {
+		admin.Role = DataBase.AdminRoleAdmin
+	}
+	if admin.Status == "" {
+		admin.Status = DataBase.AdminStatusActive
+	}
+	admin.Admin_ID = r.m.data.assignID("admins", admin.Admin_ID)
+	r.m.data.admins[admin.Admin_ID] = *admin
+	return nil
+}
+
+func (r memoryAdmins) Save(admin *DataBase.Admin) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	stored, ok := r.m.data.admins[admin.Admin_ID]
+	if !ok {
+		return ErrNotFound
+	}
+	saved := *admin
+	saved.TOTP_Last_Step = stored.TOTP_Last_Step
+	r.m.data.admins[admin.Admin_ID] = saved
+	return nil
+}
+
+func (r memoryAdmins) UseTOTPStep(adminID uint, step int64) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	admin, ok := r.m.data.admins[adminID]
+	if !ok || admin.TOTP_Last_Step >= step {
+		return false, nil
+	}
+	admin.TOTP_Last_Step = step
+	r.m.data.admins[adminID] = admin
+	return true, nil
+}
+
+func (r memoryAdmins) LoginAttempts(keys []string) ([]DataBase.Admin_LoginAttempt, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	attempts := []DataBase.Admin_LoginAttempt{}
+	for _, id := range sortedKeys(r.m.data.loginAttempts) {
+		a := r.m.data.loginAttempts[id]
+		for _, key := range keys {
+			if a.Attempt_Key == key {
+				attempts = append(attempts, a)
+			}
+		}
+	}
+	return attempts, nil
+}
+
+func (r memoryAdmins) ReserveLoginAttempt(key string, now time.Time, lockout func(failures int) time.Duration) (DataBase.Admin_LoginAttempt, bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	attempt := DataBase.Admin_LoginAttempt{Attempt_Key: key}
+	for _, a := range r.m.data.loginAttempts {
+		if a.Attempt_Key == key {
+			attempt = a
+		}
+	}
+	if attempt.Locked_Until != nil && attempt.Locked_Until.After(now) {
+		return attempt, false, nil
+	}
+
+	attempt.Failures++
+	if d := lockout(attempt.Failures); d > 0 {
+		until := now.Add(d)
+		attempt.Locked_Until = &until
+	}
+	attempt.ID = r.m.data.assignID("loginAttempts", attempt.ID)
+	r.m.data.loginAttempts[attempt.ID] = attempt
+	return attempt, true, nil
+}
+
+func (r memoryAdmins) ClearLoginAttempts(keys []string) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	for id, a := range r.m.data.loginAttempts {
+		for _, key := range keys {
+			if a.Attempt_Key == key {
+				delete(r.m.data.loginAttempts, id)
+			}
+		}
+	}
+	return nil
+}
+
+func (r memoryAdmins) ReplaceRecoveryCodes(adminID uint, hashes []string) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	for id, c := range r.m.data.recoveryCodes {
+		if c.Admin_ID == adminID {
+			delete(r.m.data.recoveryCodes, id)
+		}
+	}
+	for _, hash := range hashes {
+		id := r.m.data.assignID("recoveryCodes", 0)
+		r.m.data.recoveryCodes[id] = DataBase.Admin_RecoveryCode{ID: id, Admin_ID: adminID, Code_Hash: hash}
+	}
+	return nil
+}
+
+func (r memoryAdmins) UseRecoveryCode(adminID uint, hash string, at time.Time) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	for id, c := range r.m.data.recoveryCodes {
+		if c.Admin_ID == adminID && c.Code_Hash == hash && c.Used_At == nil {
+			used := at
+			c.Used_At = &used
+			r.m.data.recoveryCodes[id] = c
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ---- Idempotency keys ----
+
+type memoryIdempotencyKeys struct{ m *MemoryStore }
+
+func (r memoryIdempotencyKeys) Reserve(key *DataBase.Idempotency_Key) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	if _, exists := r.m.data.idempotency[key.Key]; exists {
+		return ErrDuplicate
+	}
+	if key.Created_At.IsZero() {
+		key.Created_At = time.Now()
+	}
+	r.m.data.idempotency[key.Key] = *key
+	return nil
+}
+
+func (r memoryIdempotencyKeys) Find(key string) (DataBase.Idempotency_Key, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	record, ok := r.m.data.idempotency[key]
+	if !ok {
+		return record, ErrNotFound
+	}
+	return record, nil
+}
+
+func (r memoryIdempotencyKeys) Complete(key string, status int, headers string, body []byte) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	record, ok := r.m.data.idempotency[key]
+	if !ok {
+		return ErrNotFound
+	}
+	record.Status, record.Headers, record.Body = status, headers, append([]byte(nil), body...)
+	r.m.data.idempotency[key] = record
+	return nil
+}
+
+func (r memoryIdempotencyKeys) Release(key string) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	delete(r.m.data.idempotency, key)
+	return nil
+}
+
+func (r memoryIdempotencyKeys) Purge(cutoff time.Time) (int64, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	var purged int64
+	for key, record := range r.m.data.idempotency {
+		if record.Expires_At.Before(cutoff) {
+			delete(r.m.data.idempotency, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,269 @@
+package Repository
+
+import (
+	"BackEnd/DataBase"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidQuery is returned for a sort field a list does not support, or a cursor whose value
+// does not fit the sort field.
+var ErrInvalidQuery = errors.New("invalid list query")
+
+// ListQuery asks for one page of a list. Rows are ordered by one field with the ID breaking
+// ties, so every row has a fixed place and a page can continue after any row without gaps or
+// repeats, however the rows before it change.
+type ListQuery struct {
+	// Sort is the field to order by, one of the list's sort fields; empty orders by ID.
+	Sort string
+	Desc bool
+	// After continues the list after the row it marks; nil starts at the first row.
+	After *Cursor
+	// Limit is the page size. Zero or less returns every row.
+	Limit int
+}
+
+// Cursor marks a row of a list by its value in the sort field and its ID.
+type Cursor struct {
+	Value string
+	ID    uint
+}
+
+// CourtFilter narrows a court list. Zero fields match every court.
+type CourtFilter struct {
+	SportID uint
+	// SportIDs keeps the courts of any of the sports; empty keeps every sport.
+	SportIDs []uint
+	Status   *int
+	Indoor   *bool
+}
+
+// Occupied is a slot of a court that cannot be booked on a date, because an active booking
+// holds it or a blackout blocks it. A nil Slot blocks the whole day.
+type Occupied struct {
+	Court_ID uint   `gorm:"column:Court_ID"`
+	Date     string `gorm:"column:Date"`
+	Slot     *int   `gorm:"column:Slot"`
+}
+
+// BookingFilter narrows a booking list. Zero fields match every booking that is neither
+// deleted nor archived.
+type BookingFilter struct {
+	// From and To bound Booking_Date, inclusive. Bookings without a date only match without bounds.
+	From, To   string
+	SportID    uint
+	CourtID    uint
+	CustomerID uint
+	UFID       string
+	// Statuses keeps the bookings in one of the statuses; empty keeps every status.
+	Statuses []DataBase.BookingStatus
+	// Active leaves out cancelled bookings.
+	Active bool
+}
+
+// Sort fields of each list, in the form the handlers accept.
+var (
+	SportSorts   = sportSorts.names()
+	CourtSorts   = courtSorts.names()
+	BookingSorts = bookingSorts.names()
+)
+
+var sportSorts = sortFields[DataBase.Sport]{
+	idColumn: "\"Sport_ID\"",
+	id:       func(s DataBase.Sport) uint { return s.Sport_ID },
+	fields: map[string]sortField[DataBase.Sport]{
+		"name": {column: "\"Sport_name\"", value: func(s DataBase.Sport) string { return s.Sport_name }},
+	},
+}
+
+var courtSorts = sortFields[DataBase.Court]{
+	idColumn: "\"Court_ID\"",
+	id:       func(c DataBase.Court) uint { return c.Court_ID },
+	fields: map[string]sortField[DataBase.Court]{
+		"name":     {column: "\"Court_Name\"", value: func(c DataBase.Court) string { return c.Court_Name }},
+		"location": {column: "\"Court_Location\"", value: func(c DataBase.Court) string { return c.Court_Location }},
+		"status":   {column: "\"Court_Status\"", numeric: true, value: func(c DataBase.Court) string { return strconv.Itoa(c.Court_Status) }},
+		"sport_id": {column: "\"Sport_id\"", numeric: true, value: func(c DataBase.Court) string { return formatID(c.Sport_id) }},
+	},
+}
+
+var bookingSorts = sortFields[DataBase.Bookings]{
+	idColumn: "\"Booking_ID\"",
+	id:       func(b DataBase.Bookings) uint { return b.Booking_ID },
+	fields: map[string]sortField[DataBase.Bookings]{
+		// Bookings made before dates were recorded sort as the empty date
+		"date": {column: "COALESCE(\"Booking_Date\", '')", value: func(b DataBase.Bookings) string {
+			if b.Booking_Date == nil {
+				return ""
+			}
+			return *b.Booking_Date
+		}},
+		"slot":        {column: "\"Booking_Time\"", numeric: true, value: func(b DataBase.Bookings) string { return strconv.Itoa(b.Booking_Time) }},
+		"status":      {column: "\"Booking_Status\"", value: func(b DataBase.Bookings) string { return string(b.Booking_Status) }},
+		"sport_id":    {column: "\"Sport_ID\"", numeric: true, value: func(b DataBase.Bookings) string { return formatID(b.Sport_ID) }},
+		"court_id":    {column: "\"Court_ID\"", numeric: true, value: func(b DataBase.Bookings) string { return formatID(b.Court_ID) }},
+		"customer_id": {column: "\"Customer_ID\"", numeric: true, value: func(b DataBase.Bookings) string { return formatID(b.Customer_ID) }},
+	},
+}
+
+// sortFields lists the fields a list of T can be ordered by besides its ID.
+type sortFields[T any] struct {
+	idColumn string
+	id       func(T) uint
+	fields   map[string]sortField[T]
+}
+
+type sortField[T any] struct {
+	// column is the quoted column, or an expression over columns, holding the field.
+	column  string
+	numeric bool
+	value   func(T) string
+}
+
+func formatID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func (s sortFields[T]) names() []string {
+	names := []string{"id"}
+	for name := range s.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// field returns the sort field named by q, the ID when it names none.
+func (s sortFields[T]) field(q ListQuery) (sortField[T], error) {
+	if q.Sort == "" || q.Sort == "id" {
+		return sortField[T]{column: s.idColumn, numeric: true, value: func(row T) string { return formatID(s.id(row)) }}, nil
+	}
+	field, ok := s.fields[q.Sort]
+	if !ok {
+		return field, fmt.Errorf("%w: cannot sort by %q", ErrInvalidQuery, q.Sort)
+	}
+	return field, nil
+}
+
+// arg converts a cursor value to the type of the field's column.
+func (f sortField[T]) arg(value string) (interface{}, error) {
+	if !f.numeric {
+		return value, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cursor does not fit the sort field", ErrInvalidQuery)
+	}
+	return n, nil
+}
+
+// compare orders two rows by the field, then by ID.
+func (s sortFields[T]) compare(f sortField[T], a, b T) int {
+	if c := compareValues(f, f.value(a), f.value(b)); c != 0 {
+		return c
+	}
+	return compareIDs(s.id(a), s.id(b))
+}
+
+func compareValues[T any](f sortField[T], a, b string) int {
+	if f.numeric {
+		x, _ := strconv.ParseInt(a, 10, 64)
+		y, _ := strconv.ParseInt(b, 10, 64)
+		return compareInts(x, y)
+	}
+	return strings.Compare(a, b)
+}
+
+func compareIDs(a, b uint) int {
+	return compareInts(int64(a), int64(b))
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// apply orders db by q, starts it after q's cursor and fetches one row more than the limit, which
+// tells next whether another page follows.
+func (s sortFields[T]) apply(db *gorm.DB, q ListQuery) (*gorm.DB, error) {
+	field, err := s.field(q)
+	if err != nil {
+		return nil, err
+	}
+	direction, after := "ASC", ">"
+	if q.Desc {
+		direction, after = "DESC", "<"
+	}
+	if q.After != nil {
+		value, err := field.arg(q.After.Value)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where(fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", field.column, after, field.column, s.idColumn, after),
+			value, value, q.After.ID)
+	}
+	db = db.Order(field.column + " " + direction + ", " + s.idColumn + " " + direction)
+	if q.Limit > 0 {
+		db = db.Limit(q.Limit + 1)
+	}
+	return db, nil
+}
+
+// page does in memory what apply does in SQL, for rows already filtered.
+func (s sortFields[T]) page(rows []T, q ListQuery) ([]T, *Cursor, error) {
+	field, err := s.field(q)
+	if err != nil {
+		return nil, nil, err
+	}
+	order := func(a, b T) int {
+		if q.Desc {
+			return s.compare(field, b, a)
+		}
+		return s.compare(field, a, b)
+	}
+	sort.Slice(rows, func(i, j int) bool { return order(rows[i], rows[j]) < 0 })
+
+	if q.After != nil {
+		if _, err := field.arg(q.After.Value); err != nil {
+			return nil, nil, err
+		}
+		start := sort.Search(len(rows), func(i int) bool {
+			c := compareValues(field, field.value(rows[i]), q.After.Value)
+			if c == 0 {
+				c = compareIDs(s.id(rows[i]), q.After.ID)
+			}
+			if q.Desc {
+				c = -c
+			}
+			return c > 0
+		})
+		rows = rows[start:]
+	}
+	if q.Limit > 0 && len(rows) > q.Limit+1 {
+		rows = rows[:q.Limit+1]
+	}
+	rows, next := s.next(rows, q)
+	return rows, next, nil
+}
+
+// next drops the extra row fetched by apply and returns the cursor of the page after rows, or nil
+// when rows is the last page.
+func (s sortFields[T]) next(rows []T, q ListQuery) ([]T, *Cursor) {
+	if q.Limit <= 0 || len(rows) <= q.Limit {
+		return rows, nil
+	}
+	rows = rows[:q.Limit]
+	field, _ := s.field(q)
+	last := rows[len(rows)-1]
+	return rows, &Cursor{Value: field.value(last), ID: s.id(last)}
+}
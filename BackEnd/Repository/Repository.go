@@ -0,0 +1,203 @@
+// Package Repository defines the data access interfaces used by the HTTP handlers,
+// together with a GORM implementation for production and an in-memory fake for tests.
+package Repository
+
+import (
+	"BackEnd/DataBase"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist.
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicate is returned when a unique field (sport name, court name, username) is already taken.
+	ErrDuplicate = errors.New("record already exists")
+	// ErrParentDeleted is returned when restoring a record whose sport or court is still deleted.
+	ErrParentDeleted = errors.New("parent record is deleted")
+	// ErrInvalidTransition is returned when a booking's current status does not allow the requested one.
+	ErrInvalidTransition = errors.New("booking status transition not allowed")
+)
+
+// Store gives access to every repository and lets callers group several operations in one transaction.
+type Store interface {
+	Sports() SportRepository
+	Courts() CourtRepository
+	Bookings() BookingRepository
+	Customers() CustomerRepository
+	Admins() AdminRepository
+	Blackouts() BlackoutRepository
+	IdempotencyKeys() IdempotencyRepository
+
+	// Transaction runs fn against a Store bound to a single transaction.
+	// The transaction is committed if fn returns nil and rolled back otherwise.
+	Transaction(fn func(tx Store) error) error
+}
+
+type SportRepository interface {
+	List() ([]DataBase.Sport, error)
+	// Page returns a page of the sports in q's order, with the cursor of the next page or nil.
+	Page(q ListQuery) ([]DataBase.Sport, *Cursor, error)
+	Count() (int64, error)
+	FindByID(id uint) (DataBase.Sport, error)
+	FindByName(name string) (DataBase.Sport, error)
+	Create(sport *DataBase.Sport) error
+	// Update writes the name and description of an existing sport. It returns ErrNotFound if the
+	// sport does not exist and ErrDuplicate if another sport has the name.
+	Update(sport *DataBase.Sport) error
+	// Delete soft deletes the sport together with its courts and their bookings. They all get the
+	// same Deleted_At, which is how Restore tells them from rows deleted separately.
+	Delete(id uint) error
+	SoftDeletes[DataBase.Sport]
+}
+
+// SoftDeletes is implemented by the repositories of soft deleted records. Lookups and lists
+// elsewhere in a repository leave soft deleted rows out.
+type SoftDeletes[T any] interface {
+	// ListDeleted returns the soft deleted records, most recently deleted first.
+	ListDeleted() ([]T, error)
+	// Restore undeletes a record along with the children deleted with it. It returns ErrNotFound
+	// if no soft deleted record has the ID, and ErrParentDeleted if its parent is deleted too.
+	Restore(id uint) error
+	// Purge permanently removes the records soft deleted before cutoff and reports how many.
+	Purge(cutoff time.Time) (int64, error)
+}
+
+type CourtRepository interface {
+	// List returns every court with its Sport loaded.
+	List() ([]DataBase.Court, error)
+	ListBySport(sportID uint) ([]DataBase.Court, error)
+	// Page returns a page of the matching courts in q's order with their Sport loaded, and the
+	// cursor of the next page or nil.
+	Page(filter CourtFilter, q ListQuery) ([]DataBase.Court, *Cursor, error)
+	Count(filter CourtFilter) (int64, error)
+	FindByID(id uint) (DataBase.Court, error)
+	FindByName(name string) (DataBase.Court, error)
+	Create(court *DataBase.Court) error
+	// Update writes every field of an existing court. Its active bookings from today on move with
+	// it to a new sport; past, cancelled, undated and deleted ones keep the sport they were made
+	// for. It returns ErrNotFound if the court does not exist and ErrDuplicate if another court
+	// has the name.
+	Update(court *DataBase.Court) error
+	// Delete soft deletes the court together with its bookings.
+	Delete(id uint) error
+	SoftDeletes[DataBase.Court]
+
+	// Occupancy returns the occupied slots of the given courts on the dates from..to inclusive,
+	// read in one query over the active bookings and the blackouts.
+	Occupancy(courtIDs []uint, from, to string) ([]Occupied, error)
+	// Hours returns the Court_Hours of those of the given courts that have hours of their own,
+	// by court ID.
+	Hours(courtIDs []uint) (map[uint]string, error)
+
+	// AvailableIDs returns the IDs of open courts (Court_Status 1), optionally
+	// restricted to one court name matched case-insensitively.
+	AvailableIDs(courtName string) ([]uint, error)
+}
+
+type BookingRepository interface {
+	// List returns every booking that is neither deleted nor archived, whatever its status.
+	List() ([]DataBase.Bookings, error)
+	FindByID(id uint) (DataBase.Bookings, error)
+	// ListByCustomer returns a customer's bookings with Court and Sport loaded.
+	ListByCustomer(customerID uint) ([]DataBase.Bookings, error)
+	// ListActive returns the bookings that are not cancelled, with Customer, Court and Sport loaded.
+	ListActive() ([]DataBase.Bookings, error)
+	// Page returns a page of the matching bookings in q's order with Customer, Court and Sport
+	// loaded, and the cursor of the next page or nil.
+	Page(filter BookingFilter, q ListQuery) ([]DataBase.Bookings, *Cursor, error)
+	Count(filter BookingFilter) (int64, error)
+	// ListActiveOn returns the active bookings on the given courts for one Booking_Date.
+	ListActiveOn(courtIDs []uint, date string) ([]DataBase.Bookings, error)
+	// Create records the booking's initial status in its history. It returns ErrDuplicate if
+	// another active booking holds the same court, date and slot.
+	Create(booking *DataBase.Bookings) error
+	// Transition moves a booking to status to and records the change in its history. It returns
+	// ErrInvalidTransition if the booking's current status does not allow the change.
+	Transition(id uint, to DataBase.BookingStatus, note string) error
+	// AdminTransition is Transition on behalf of an admin, whose ID the history records.
+	AdminTransition(id uint, to DataBase.BookingStatus, note string, adminID uint) error
+	// CancelActiveForCourts moves every booking on the given courts that can still be cancelled
+	// to the cancelled status given, recording each change in the booking's history.
+	CancelActiveForCourts(courtIDs []uint, status DataBase.BookingStatus, note string) error
+	// History returns a booking's status changes, oldest first. Archived bookings keep theirs.
+	History(bookingID uint) ([]DataBase.Booking_StatusHistory, error)
+	// Archive moves the bookings dated before the given date to the archive, reports how many,
+	// and records a change to DataBase.RolloverStatus under note for each one that needs it.
+	// Soft deleted bookings stay where they are, and so do bookings without a date, since
+	// there is no telling whether their day has passed.
+	Archive(before, note string) (int64, error)
+	// ListArchived returns a page of a customer's archived bookings, newest first, dated from..to
+	// inclusive where either bound may be empty, together with how many match in total.
+	ListArchived(customerID uint, from, to string, offset, limit int) ([]DataBase.Booking_Archive, int64, error)
+	SoftDeletes[DataBase.Bookings]
+	// DeleteAll permanently removes every booking, deleted, archived or not, with its history.
+	DeleteAll() error
+}
+
+type CustomerRepository interface {
+	List() ([]DataBase.Customer, error)
+	FindByID(id uint) (DataBase.Customer, error)
+	// FindByEmail matches the email case-insensitively.
+	FindByEmail(email string) (DataBase.Customer, error)
+	Create(customer *DataBase.Customer) error
+	// UpdateProfile overwrites the name and UFID; empty values leave the current value untouched.
+	UpdateProfile(id uint, name, ufid string) error
+	// DeleteAll removes every customer along with their bookings and restarts the ID sequence.
+	DeleteAll() error
+}
+
+type BlackoutRepository interface {
+	// List returns the blackouts on the given courts for one date, or on every court when courtIDs
+	// is nil and on every date when date is empty.
+	List(courtIDs []uint, date string) ([]DataBase.Court_Blackout, error)
+	FindByID(id uint) (DataBase.Court_Blackout, error)
+	Create(blackout *DataBase.Court_Blackout) error
+	Delete(id uint) error
+	DeleteByCourt(courtID uint) error
+}
+
+type AdminRepository interface {
+	List() ([]DataBase.Admin, error)
+	Count() (int64, error)
+	// CountActiveSuperAdmins counts active super admins other than excludeID. Inside a transaction
+	// it locks every active super admin until the transaction ends, so two transactions that each
+	// demote or deactivate one cannot both count the other and leave none.
+	CountActiveSuperAdmins(excludeID uint) (int64, error)
+	FindByID(id uint) (DataBase.Admin, error)
+	FindByUsername(username string) (DataBase.Admin, error)
+	FindByInviteToken(tokenHash string) (DataBase.Admin, error)
+	Create(admin *DataBase.Admin) error
+	// Save writes every field of an existing admin except TOTP_Last_Step, which only UseTOTPStep moves.
+	Save(admin *DataBase.Admin) error
+
+	// UseTOTPStep records step as the admin's last accepted TOTP step and reports whether it was
+	// later than the one stored, so each code is accepted at most once.
+	UseTOTPStep(adminID uint, step int64) (bool, error)
+
+	LoginAttempts(keys []string) ([]DataBase.Admin_LoginAttempt, error)
+	// ReserveLoginAttempt counts one attempt against key unless key is locked at now, and locks it
+	// for lockout(failures) when that is positive. It returns the updated attempt, or ok == false
+	// and counts nothing while key is locked. Concurrent attempts are counted one at a time, so
+	// each of them sees its own count and none gets past a lock set by another.
+	ReserveLoginAttempt(key string, now time.Time, lockout func(failures int) time.Duration) (attempt DataBase.Admin_LoginAttempt, ok bool, err error)
+	ClearLoginAttempts(keys []string) error
+
+	// ReplaceRecoveryCodes deletes an admin's recovery codes and stores the given hashes instead.
+	ReplaceRecoveryCodes(adminID uint, hashes []string) error
+	// UseRecoveryCode marks an unused code as used and reports whether one matched.
+	UseRecoveryCode(adminID uint, hash string, at time.Time) (bool, error)
+}
+
+type IdempotencyRepository interface {
+	// Reserve stores a key whose request is still in flight. It returns ErrDuplicate if the key is
+	// already stored, expired or not.
+	Reserve(key *DataBase.Idempotency_Key) error
+	Find(key string) (DataBase.Idempotency_Key, error)
+	// Complete stores the response to the request reserved under key.
+	Complete(key string, status int, headers string, body []byte) error
+	// Release deletes key, so that its request can be retried.
+	Release(key string) error
+	// Purge deletes the keys that expired before cutoff and reports how many.
+	Purge(cutoff time.Time) (int64, error)
+}
@@ -0,0 +1,834 @@
+package Repository_test
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"BackEnd/Repository/TestStore"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// forEachStore runs the test against the GORM store on the TEST_DB_DRIVER backend and the
+// in-memory fake, so the fake keeps behaving like the real thing.
+func forEachStore(t *testing.T, test func(t *testing.T, store Repository.Store)) {
+	t.Run("gorm", func(t *testing.T) { test(t, Repository.NewGormStore(TestStore.OpenDB(t))) })
+	t.Run("memory", func(t *testing.T) { test(t, Repository.NewMemoryStore()) })
+}
+
+// seedCourt creates a sport with one open court.
+func seedCourt(t *testing.T, store Repository.Store) (DataBase.Sport, DataBase.Court) {
+	sport := DataBase.Sport{Sport_name: "Tennis"}
+	if err := store.Sports().Create(&sport); err != nil {
+		t.Fatalf("failed to create sport: %v", err)
+	}
+	court := DataBase.Court{Court_Name: "Court A", Court_Location: "Downtown", Court_Status: 1, Sport_id: sport.Sport_ID}
+	if err := store.Courts().Create(&court); err != nil {
+		t.Fatalf("failed to create court: %v", err)
+	}
+	return sport, court
+}
+
+func TestSportsAndCourts(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Repository.Store) {
+		sport, court := seedCourt(t, store)
+
+		if err := store.Sports().Create(&DataBase.Sport{Sport_name: "Tennis"}); !errors.Is(err, Repository.ErrDuplicate) {
+			t.Errorf("expected ErrDuplicate for a repeated sport name, got %v", err)
+		}
+		if _, err := store.Sports().FindByName("Squash"); !errors.Is(err, Repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for an unknown sport, got %v", err)
+		}
+
+		courts, err := store.Courts().List()
+		if err != nil || len(courts) != 1 || courts[0].Sport.Sport_name != "Tennis" {
+			t.Fatalf("expected one court with its sport loaded, got %+v (error: %v)", courts, err)
+		}
+
+		ids, err := store.Courts().AvailableIDs("court a")
+		if err != nil || len(ids) != 1 || ids[0] != court.Court_ID {
+			t.Errorf("expected court name match to be case insensitive, got %v (error: %v)", ids, err)
+		}
+
+		bySport, _ := store.Courts().ListBySport(sport.Sport_ID)
+		if len(bySport) != 1 {
+			t.Errorf("expected one court for the sport, got %d", len(bySport))
+		}
+	})
+}
+
+func TestUpdateSportsAndCourts(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Repository.Store) {
+		tennis, court := seedCourt(t, store)
+		padel := DataBase.Sport{Sport_name: "Padel"}
+		store.Sports().Create(&padel)
+		other := DataBase.Court{Court_Name: "Court B", Court_Location: "Uptown", Court_Status: 1, Sport_id: tennis.Sport_ID}
+		store.Courts().Create(&other)
+		booking := DataBase.Bookings{Customer_ID: 1, Sport_ID: tennis.Sport_ID, Court_ID: court.Court_ID, Booking_Time: 1,
+			Booking_Date: DataBase.BookingDate(time.Now()), Booking_Status: DataBase.BookingConfirmed}
+		store.Bookings().Create(&booking)
+		// Bookings that stay under the sport they were made for when the court moves
+		kept := []DataBase.Bookings{
+			{Booking_Time: 2, Booking_Date: DataBase.BookingDate(time.Now().AddDate(0, 0, -1)), Booking_Status: DataBase.BookingCompleted},
+			{Booking_Time: 3, Booking_Date: DataBase.BookingDate(time.Now()), Booking_Status: DataBase.BookingCancelledByUser},
+			{Booking_Time: 4, Booking_Status: DataBase.BookingConfirmed},
+		}
+		for i := range kept {
+			kept[i].Customer_ID, kept[i].Sport_ID, kept[i].Court_ID = 1, tennis.Sport_ID, court.Court_ID
+			store.Bookings().Create(&kept[i])
+		}
+
+		tennis.Sport_name, tennis.Sport_Description = "Lawn Tennis", "On grass"
+		if err := store.Sports().Update(&tennis); err != nil {
+			t.Fatalf("failed to update sport: %v", err)
+		}
+		if saved, _ := store.Sports().FindByID(tennis.Sport_ID); saved.Sport_name != "Lawn Tennis" || saved.Sport_Description != "On grass" {
+			t.Errorf("expected the sport to be renamed, got %+v", saved)
+		}
+		padel.Sport_name = "Lawn Tennis"
+		if err := store.Sports().Update(&padel); !errors.Is(err, Repository.ErrDuplicate) {
+			t.Errorf("expected ErrDuplicate renaming a sport to a taken name, got %v", err)
+		}
+		if err := store.Sports().Update(&DataBase.Sport{Sport_ID: 999, Sport_name: "Golf"}); !errors.Is(err, Repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for an unknown sport, got %v", err)
+		}
+
+		capacity := 4
+		court.Court_Name, court.Court_Location, court.Court_Capacity = "Centre Court", "Riverside", &capacity
+		court.Court_Status, court.Court_Indoor, court.Sport_id = 0, true, padel.Sport_ID
+		if err := store.Courts().Update(&court); err != nil {
+			t.Fatalf("failed to update court: %v", err)
+		}
+		saved, _ := store.Courts().FindByID(court.Court_ID)
+		if saved.Court_Name != "Centre Court" || saved.Court_Location != "Riverside" || saved.Court_Capacity == nil ||
+			*saved.Court_Capacity != 4 || saved.Court_Status != 0 || !saved.Court_Indoor || saved.Sport_id != padel.Sport_ID {
+			t.Errorf("expected every field updated, got %+v", saved)
+		}
+		if moved, _ := store.Bookings().FindByID(booking.Booking_ID); moved.Sport_ID != padel.Sport_ID ||
+			moved.Booking_Status != DataBase.BookingConfirmed {
+			t.Errorf("expected the booking to follow its court to the new sport, got %+v", moved)
+		}
+		for _, b := range kept {
+			if stayed, _ := store.Bookings().FindByID(b.Booking_ID); stayed.Sport_ID != tennis.Sport_ID {
+				t.Errorf("expected booking %d to keep the sport it was made for, got %+v", b.Booking_Time, stayed)
+			}
+		}
+
+		other.Court_Name = "Centre Court"
+		if err := store.Courts().Update(&other); !errors.Is(err, Repository.ErrDuplicate) {
+			t.Errorf("expected ErrDuplicate renaming a court to a taken name, got %v", err)
+		}
+		store.Courts().Delete(other.Court_ID)
+		other.Court_Name = "Court C"
+		if err := store.Courts().Update(&other); !errors.Is(err, Repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a deleted court, got %v", err)
+		}
+	})
+}
+
+func TestPages(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Repository.Store) {
+		sport, court := seedCourt(t, store)
+		other := DataBase.Court{Court_Name: "Court B", Court_Location: "Campus", Court_Status: 0, Sport_id: sport.Sport_ID}
+		store.Courts().Create(&other)
+		ana := DataBase.Customer{Email: "ana@ufl.edu", UFID: "11111111"}
+		bo := DataBase.Customer{Email: "bo@ufl.edu", UFID: "22222222"}
+		store.Customers().Create(&ana)
+		store.Customers().Create(&bo)
+
+		// Two bookings share each date, so the ID has to break the ties
+		for i, date := range []string{"2026-03-02", "2026-03-01", "2026-03-02", "2026-03-01", "2026-03-03"} {
+			customer := ana
+			if i%2 == 1 {
+				customer = bo
+			}
+			booking := DataBase.Bookings{Customer_ID: customer.Customer_ID, Sport_ID: sport.Sport_ID, Court_ID: court.Court_ID,
+				Booking_Status: DataBase.BookingConfirmed, Booking_Time: i, Booking_Date: &date}
+			if err := store.Bookings().Create(&booking); err != nil {
+				t.Fatalf("failed to create booking: %v", err)
+			}
+		}
+		store.Bookings().Transition(5, DataBase.BookingCancelledByUser, "")
+
+		var ids []uint
+		q := Repository.ListQuery{Sort: "date", Desc: true, Limit: 2}
+		for page := 0; page < 5; page++ {
+			bookings, next, err := store.Bookings().Page(Repository.BookingFilter{}, q)
+			if err != nil {
+				t.Fatalf("failed to list bookings: %v", err)
+			}
+			for _, b := range bookings {
+				ids = append(ids, b.Booking_ID)
+			}
+			if next == nil {
+				break
+			}
+			q.After = next
+		}
+		if want := []uint{5, 3, 1, 4, 2}; fmt.Sprint(ids) != fmt.Sprint(want) {
+			t.Errorf("expected bookings %v newest first across pages, got %v", want, ids)
+		}
+
+		filter := Repository.BookingFilter{From: "2026-03-02", UFID: "11111111", Active: true}
+		bookings, next, _ := store.Bookings().Page(filter, Repository.ListQuery{})
+		if len(bookings) != 2 || next != nil || bookings[0].Booking_ID != 1 || bookings[0].Customer.Email != "ana@ufl.edu" {
+			t.Errorf("expected Ana's two active bookings from March 2, got %+v", bookings)
+		}
+		if count, _ := store.Bookings().Count(Repository.BookingFilter{Statuses: []DataBase.BookingStatus{DataBase.BookingCancelledByUser}}); count != 1 {
+			t.Errorf("expected one cancelled booking, got %d", count)
+		}
+
+		open := 1
+		courts, _, _ := store.Courts().Page(Repository.CourtFilter{Status: &open}, Repository.ListQuery{Sort: "name"})
+		if len(courts) != 1 || courts[0].Court_ID != court.Court_ID || courts[0].Sport == nil {
+			t.Errorf("expected the open court with its sport, got %+v", courts)
+		}
+		courts, next, _ = store.Courts().Page(Repository.CourtFilter{}, Repository.ListQuery{Sort: "location", Limit: 1})
+		if len(courts) != 1 || courts[0].Court_ID != other.Court_ID || next == nil || next.Value != "Campus" {
+			t.Errorf("expected the court on campus first, got %+v with cursor %+v", courts, next)
+		}
+
+		if _, _, err := store.Sports().Page(Repository.ListQuery{Sort: "colour"}); !errors.Is(err, Repository.ErrInvalidQuery) {
+			t.Errorf("expected ErrInvalidQuery for an unknown sort field, got %v", err)
+		}
+		after := &Repository.Cursor{Value: "Campus", ID: 1}
+		if _, _, err := store.Courts().Page(Repository.CourtFilter{}, Repository.ListQuery{Sort: "status", After: after}); !errors.Is(err, Repository.ErrInvalidQuery) {
+			t.Errorf("expected ErrInvalidQuery for a cursor of another field, got %v", err)
+		}
+	})
+}
+
+func TestBlackouts(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Repository.Store) {
+		_, court := seedCourt(t, store)
+		slot := 3
+
+		for _, blackout := range []DataBase.Court_Blackout{
+			{Court_ID: court.Court_ID, Blackout_Date: "2026-05-02", Slot_Index: &slot},
+			{Court_ID: court.Court_ID, Blackout_Date: "2026-05-01", Reason: "Resurfacing"},
+			{Court_ID: court.Court_ID + 1, Blackout_Date: "2026-05-01"},
+		} {
+			if err := store.Blackouts().Create(&blackout); err != nil {
+				t.Fatalf("failed to create blackout: %v", err)
+			}
+		}
+
+		all, err := store.Blackouts().List(nil, "")
+		if err != nil || len(all) != 3 || all[0].Blackout_Date != "2026-05-01" {
+			t.Errorf("expected every blackout ordered by date, got %+v (error: %v)", all, err)
+		}
+		onDay, _ := store.Blackouts().List([]uint{court.Court_ID}, "2026-05-01")
+		if len(onDay) != 1 || onDay[0].Reason != "Resurfacing" || onDay[0].Slot_Index != nil {
+			t.Errorf("expected the whole day blackout, got %+v", onDay)
+		}
+		if none, _ := store.Blackouts().List([]uint{}, ""); len(none) != 0 {
+			t.Errorf("expected no blackouts for an empty court list, got %d", len(none))
+		}
+
+		if err := store.Blackouts().DeleteByCourt(court.Court_ID); err != nil {
+			t.Fatalf("DeleteByCourt failed: %v", err)
+		}
+		if left, _ := store.Blackouts().List(nil, ""); len(left) != 1 {
+			t.Errorf("expected only the other court's blackout to remain, got %d", len(left))
+		}
+		if _, err := store.Blackouts().FindByID(all[0].Blackout_ID); !errors.Is(err, Repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a deleted blackout, got %v", err)
+		}
+	})
+}
+
+func TestBookings(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Repository.Store) {
+		sport, court := seedCourt(t, store)
+
+		customer := DataBase.Customer{Name: "Jane", Email: "Jane@Example.com"}
+		if err := store.Customers().Create(&customer); err != nil {
+			t.Fatalf("failed to create customer: %v", err)
+		}
+		if found, err := store.Customers().FindByEmail("jane@example.com"); err != nil || found.Customer_ID != customer.Customer_ID {
+			t.Errorf("expected email lookup to be case insensitive, got %+v (error: %v)", found, err)
+		}
+
+		today := DataBase.BookingDate(time.Now())
+		for i, status := range []DataBase.BookingStatus{DataBase.BookingConfirmed, DataBase.BookingCancelledByUser, DataBase.BookingHeld} {
+			booking := DataBase.Bookings{Customer_ID: customer.Customer_ID, Sport_ID: sport.Sport_ID, Court_ID: court.Court_ID,
+				Booking_Status: status, Booking_Time: i, Booking_Date: today}
+			if err := store.Bookings().Create(&booking); err != nil {
+				t.Fatalf("failed to create booking: %v", err)
+			}
+		}
+
+		active, err := store.Bookings().ListActive()
+		if err != nil || len(active) != 2 {
+			t.Fatalf("expected 2 active bookings, got %d (error: %v)", len(active), err)
+		}
+		if active[0].Customer.Email != customer.Email || active[0].Court.Court_Name != "Court A" || active[0].Sport.Sport_name != "Tennis" {
+			t.Errorf("expected associations to be loaded, got %+v", active[0])
+		}
+
+		onDay, err := store.Bookings().ListActiveOn([]uint{court.Court_ID}, *today)
+		if err != nil || len(onDay) != 2 || onDay[1].Booking_Time != 2 {
+			t.Errorf("expected today's 2 active bookings, got %+v (error: %v)", onDay, err)
+		}
+		if other, _ := store.Bookings().ListActiveOn([]uint{court.Court_ID}, "2000-01-01"); len(other) != 0 {
+			t.Errorf("expected no bookings on another day, got %d", len(other))
+		}
+
+		if err := store.Bookings().CancelActiveForCourts([]uint{court.Court_ID}, DataBase.BookingCancelledByAdmin, "Court reset"); err != nil {
+			t.Fatalf("CancelActiveForCourts failed: %v", err)
+		}
+		if active, _ := store.Bookings().ListActive(); len(active) != 0 {
+			t.Errorf("expected no active bookings after cancelling the court, got %d", len(active))
+		}
+
+		mine, _ := store.Bookings().ListByCustomer(customer.Customer_ID)
+		if len(mine) != 3 {
+			t.Errorf("expected the customer's full history, got %d bookings", len(mine))
+		}
+	})
+}
+
+func TestBookingTransitions(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Repository.Store) {
+		sport, court := seedCourt(t, store)
+		booking := DataBase.Bookings{Customer_ID: 1, Sport_ID: sport.Sport_ID, Court_ID: court.Court_ID,
+			Booking_Status: DataBase.BookingConfirmed, Booking_Date: DataBase.BookingDate(time.Now())}
+		if err := store.Bookings().Create(&booking); err != nil {
+			t.Fatalf("failed to create booking: %v", err)
+		}
+
+		if err := store.Bookings().Transition(booking.Booking_ID, DataBase.BookingCompleted, ""); !errors.Is(err, Repository.ErrInvalidTransition) {
+			t.Errorf("expected ErrInvalidTransition completing an unchecked booking, got %v", err)
+		}
+		if err := store.Bookings().Transition(booking.Booking_ID, DataBase.BookingCheckedIn, "At the desk"); err != nil {
+			t.Fatalf("check in failed: %v", err)
+		}
+		// Checked in bookings are past cancelling, so resetting the court leaves them alone
+		if err := store.Bookings().CancelActiveForCourts([]uint{court.Court_ID}, DataBase.BookingCancelledBySystem, ""); err != nil {
+			t.Fatalf("CancelActiveForCourts failed: %v", err)
+		}
+		if err := store.Bookings().Transition(booking.Booking_ID, DataBase.BookingCompleted, ""); err != nil {
+			t.Fatalf("complete failed: %v", err)
+		}
+		if err := store.Bookings().Transition(999, DataBase.BookingCheckedIn, ""); !errors.Is(err, Repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+
+		history, err := store.Bookings().History(booking.Booking_ID)
+		if err != nil || len(history) != 3 {
+			t.Fatalf("expected 3 history entries, got %+v (error: %v)", history, err)
+		}
+		expected := []DataBase.BookingStatus{DataBase.BookingConfirmed, DataBase.BookingCheckedIn, DataBase.BookingCompleted}
+		for i, entry := range history {
+			if entry.To_Status != expected[i] || entry.Changed_At.IsZero() {
+				t.Errorf("unexpected history entry %d: %+v", i, entry)
+			}
+			if i > 0 && entry.From_Status != expected[i-1] {
+				t.Errorf("expected entry %d to come from %s, got %s", i, expected[i-1], entry.From_Status)
+			}
+		}
+		if history[0].From_Status != "" || history[1].Note != "At the desk" {
+			t.Errorf("unexpected history: %+v", history)
+		}
+
+		if err := store.Courts().Delete(court.Court_ID); err != nil {
+			t.Fatalf("deleting the court failed: %v", err)
+		}
+		if history, _ := store.Bookings().History(booking.Booking_ID); len(history) != 3 {
+			t.Errorf("expected history to be kept while the booking is soft deleted, got %d entries", len(history))
+		}
+		if _, err := store.Bookings().Purge(time.Now().Add(time.Minute)); err != nil {
+			t.Fatalf("Purge failed: %v", err)
+		}
+		if history, _ := store.Bookings().History(booking.Booking_ID); len(history) != 0 {
+			t.Errorf("expected history to be purged with the booking, got %d entries", len(history))
+		}
+	})
+}
+
+func TestSoftDelete(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Repository.Store) {
+		sport, court := seedCourt(t, store)
+		other := DataBase.Court{Court_Name: "Court B", Court_Location: "Uptown", Court_Status: 1, Sport_id: sport.Sport_ID}
+		if err := store.Courts().Create(&other); err != nil {
+			t.Fatalf("failed to create court: %v", err)
+		}
+		var bookings []DataBase.Bookings
+		for _, courtID := range []uint{court.Court_ID, other.Court_ID} {
+			booking := DataBase.Bookings{Customer_ID: 1, Sport_ID: sport.Sport_ID, Court_ID: courtID,
+				Booking_Status: DataBase.BookingConfirmed, Booking_Date: DataBase.BookingDate(time.Now())}
+			if err := store.Bookings().Create(&booking); err != nil {
+				t.Fatalf("failed to create booking: %v", err)
+			}
+			bookings = append(bookings, booking)
+		}
+
+		// Court B goes first on its own, then the sport takes Court A with it
+		if err := store.Courts().Delete(other.Court_ID); err != nil {
+			t.Fatalf("deleting the court failed: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+		if err := store.Sports().Delete(sport.Sport_ID); err != nil {
+			t.Fatalf("deleting the sport failed: %v", err)
+		}
+
+		if _, err := store.Sports().FindByName("Tennis"); !errors.Is(err, Repository.ErrNotFound) {
+			t.Errorf("expected the deleted sport to be hidden, got %v", err)
+		}
+		if courts, _ := store.Courts().List(); len(courts) != 0 {
+			t.Errorf("expected deleted courts to be hidden, got %d", len(courts))
+		}
+		if active, _ := store.Bookings().ListActive(); len(active) != 0 {
+			t.Errorf("expected deleted bookings to be hidden, got %d", len(active))
+		}
+		// A deleted sport gives up its name, and cannot come back while another sport has it
+		replacement := DataBase.Sport{Sport_name: "Tennis"}
+		if err := store.Sports().Create(&replacement); err != nil {
+			t.Fatalf("expected a deleted sport's name to be free, got %v", err)
+		}
+		if err := store.Sports().Restore(sport.Sport_ID); !errors.Is(err, Repository.ErrDuplicate) {
+			t.Errorf("expected ErrDuplicate restoring a sport whose name is taken, got %v", err)
+		}
+		if _, err := store.Sports().FindByID(sport.Sport_ID); !errors.Is(err, Repository.ErrNotFound) {
+			t.Errorf("expected the failed restore to leave the sport deleted, got %v", err)
+		}
+		replacement.Sport_name = "Padel"
+		if err := store.Sports().Update(&replacement); err != nil {
+			t.Fatalf("failed to rename the replacement sport: %v", err)
+		}
+		clash := DataBase.Court{Court_Name: "Court A", Court_Location: "Elsewhere", Court_Status: 1, Sport_id: replacement.Sport_ID}
+		if err := store.Courts().Create(&clash); err != nil {
+			t.Fatalf("expected a deleted court's name to be free, got %v", err)
+		}
+		if err := store.Sports().Restore(sport.Sport_ID); !errors.Is(err, Repository.ErrDuplicate) {
+			t.Errorf("expected ErrDuplicate restoring a sport with a court whose name is taken, got %v", err)
+		}
+		clash.Court_Name = "Court C"
+		if err := store.Courts().Update(&clash); err != nil {
+			t.Fatalf("failed to rename the clashing court: %v", err)
+		}
+
+		deleted, err := store.Courts().ListDeleted()
+		if err != nil || len(deleted) != 2 || deleted[0].Court_ID != court.Court_ID || !deleted[0].Deleted_At.Valid {
+			t.Fatalf("expected both courts, most recent first, got %+v (error: %v)", deleted, err)
+		}
+
+		if err := store.Courts().Restore(other.Court_ID); !errors.Is(err, Repository.ErrParentDeleted) {
+			t.Errorf("expected ErrParentDeleted restoring a court of a deleted sport, got %v", err)
+		}
+		if err := store.Sports().Restore(999); !errors.Is(err, Repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound restoring an unknown sport, got %v", err)
+		}
+
+		// Restoring the sport brings back what was deleted with it, but not Court B
+		if err := store.Sports().Restore(sport.Sport_ID); err != nil {
+			t.Fatalf("restoring the sport failed: %v", err)
+		}
+		if courts, _ := store.Courts().ListBySport(sport.Sport_ID); len(courts) != 1 || courts[0].Court_ID != court.Court_ID {
+			t.Errorf("expected only Court A back, got %+v", courts)
+		}
+		if _, err := store.Bookings().FindByID(bookings[0].Booking_ID); err != nil {
+			t.Errorf("expected Court A's booking back, got %v", err)
+		}
+		if _, err := store.Bookings().FindByID(bookings[1].Booking_ID); !errors.Is(err, Repository.ErrNotFound) {
+			t.Errorf("expected Court B's booking to stay deleted, got %v", err)
+		}
+
+		if err := store.Courts().Restore(other.Court_ID); err != nil {
+			t.Fatalf("restoring the court failed: %v", err)
+		}
+		if _, err := store.Bookings().FindByID(bookings[1].Booking_ID); err != nil {
+			t.Errorf("expected Court B's booking back, got %v", err)
+		}
+
+		// Purging only removes rows deleted before the cutoff
+		if err := store.Courts().Delete(other.Court_ID); err != nil {
+			t.Fatalf("deleting the court failed: %v", err)
+		}
+		if purged, err := store.Courts().Purge(time.Now().Add(-time.Hour)); err != nil || purged != 0 {
+			t.Errorf("expected nothing to purge yet, got %d (error: %v)", purged, err)
+		}
+		if purged, err := store.Bookings().Purge(time.Now().Add(time.Minute)); err != nil || purged != 1 {
+			t.Errorf("expected one booking purged, got %d (error: %v)", purged, err)
+		}
+		if purged, err := store.Courts().Purge(time.Now().Add(time.Minute)); err != nil || purged != 1 {
+			t.Errorf("expected one court purged, got %d (error: %v)", purged, err)
+		}
+		if err := store.Courts().Restore(other.Court_ID); !errors.Is(err, Repository.ErrNotFound) {
+			t.Errorf("expected a purged court to be gone, got %v", err)
+		}
+	})
+}
+
+func TestArchive(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Repository.Store) {
+		sport, court := seedCourt(t, store)
+		yesterday := DataBase.BookingDate(time.Now().AddDate(0, 0, -1))
+		bookings := []DataBase.Bookings{
+			{Booking_Status: DataBase.BookingConfirmed, Booking_Time: 4, Booking_Date: DataBase.BookingDate(time.Now())},
+			{Booking_Status: DataBase.BookingConfirmed, Booking_Time: 5},
+			{Booking_Status: DataBase.BookingHeld, Booking_Time: 1, Booking_Date: yesterday},
+			{Booking_Status: DataBase.BookingCheckedIn, Booking_Time: 2, Booking_Date: yesterday},
+			{Booking_Status: DataBase.BookingNoShow, Booking_Time: 3, Booking_Date: yesterday},
+		}
+		for i := range bookings {
+			bookings[i].Customer_ID, bookings[i].Sport_ID, bookings[i].Court_ID = 1, sport.Sport_ID, court.Court_ID
+			if err := store.Bookings().Create(&bookings[i]); err != nil {
+				t.Fatalf("failed to create booking: %v", err)
+			}
+		}
+
+		archived, err := store.Bookings().Archive(*DataBase.BookingDate(time.Now()), "Rolled over")
+		if err != nil || archived != 3 {
+			t.Fatalf("expected yesterday's 3 bookings to be archived, got %d (error: %v)", archived, err)
+		}
+		if _, err := store.Bookings().FindByID(bookings[2].Booking_ID); !errors.Is(err, Repository.ErrNotFound) {
+			t.Errorf("expected an archived booking to leave Bookings, got %v", err)
+		}
+		for _, b := range bookings[:2] {
+			if _, err := store.Bookings().FindByID(b.Booking_ID); err != nil {
+				t.Errorf("expected booking %d to stay, got %v", b.Booking_ID, err)
+			}
+		}
+
+		rows, total, err := store.Bookings().ListArchived(1, "", "", 0, 10)
+		if err != nil || total != 3 || len(rows) != 3 {
+			t.Fatalf("expected 3 archived bookings, got %d of %d (error: %v)", len(rows), total, err)
+		}
+		expected := []DataBase.BookingStatus{DataBase.BookingNoShow, DataBase.BookingCompleted, DataBase.BookingCancelledBySystem}
+		for i, row := range rows {
+			if row.Booking_Status != expected[i] || row.Court_Name != "Court A" || row.Sport_Name != "Tennis" {
+				t.Errorf("unexpected archived booking %d: %+v", i, row)
+			}
+		}
+
+		history, _ := store.Bookings().History(bookings[2].Booking_ID)
+		if len(history) != 2 || history[1].To_Status != DataBase.BookingCancelledBySystem || history[1].Note != "Rolled over" {
+			t.Errorf("expected the lapsed hold to be recorded, got %+v", history)
+		}
+		if history, _ := store.Bookings().History(bookings[4].Booking_ID); len(history) != 1 {
+			t.Errorf("expected no change recorded for a final status, got %+v", history)
+		}
+
+		// IDs of archived bookings are not handed out again
+		next := DataBase.Bookings{Customer_ID: 1, Sport_ID: sport.Sport_ID, Court_ID: court.Court_ID,
+			Booking_Status: DataBase.BookingConfirmed, Booking_Time: 6, Booking_Date: DataBase.BookingDate(time.Now())}
+		if err := store.Bookings().Create(&next); err != nil {
+			t.Fatalf("failed to create booking: %v", err)
+		}
+		if next.Booking_ID <= bookings[4].Booking_ID {
+			t.Errorf("expected a fresh booking ID, got %d", next.Booking_ID)
+		}
+	})
+}
+
+func TestTransactionRollsBack(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Repository.Store) {
+		_, court := seedCourt(t, store)
+
+		errAbort := errors.New("abort")
+		err := store.Transaction(func(tx Repository.Store) error {
+			if err := tx.Blackouts().Create(&DataBase.Court_Blackout{Court_ID: court.Court_ID, Blackout_Date: "2026-05-01"}); err != nil {
+				return err
+			}
+			if err := tx.Sports().Create(&DataBase.Sport{Sport_name: "Squash"}); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("expected the callback error, got %v", err)
+		}
+
+		if blackouts, _ := store.Blackouts().List(nil, ""); len(blackouts) != 0 {
+			t.Errorf("expected blackout insert to be rolled back, got %d", len(blackouts))
+		}
+		if _, err := store.Sports().FindByName("Squash"); !errors.Is(err, Repository.ErrNotFound) {
+			t.Errorf("expected sport insert to be rolled back, got %v", err)
+		}
+	})
+}
+
+func TestAdmins(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Repository.Store) {
+		root := DataBase.Admin{Username: "root", Password: "x", Role: DataBase.AdminRoleSuperAdmin}
+		if err := store.Admins().Create(&root); err != nil {
+			t.Fatalf("failed to create admin: %v", err)
+		}
+		plain := DataBase.Admin{Username: "plain", Password: "x"}
+		store.Admins().Create(&plain)
+
+		if err := store.Admins().Create(&DataBase.Admin{Username: "root"}); !errors.Is(err, Repository.ErrDuplicate) {
+			t.Errorf("expected ErrDuplicate for a repeated username, got %v", err)
+		}
+
+		saved, _ := store.Admins().FindByID(plain.Admin_ID)
+		if saved.Role != DataBase.AdminRoleAdmin || saved.Status != DataBase.AdminStatusActive {
+			t.Errorf("expected default role and status, got %q/%q", saved.Role, saved.Status)
+		}
+
+		if n, _ := store.Admins().CountActiveSuperAdmins(root.Admin_ID); n != 0 {
+			t.Errorf("expected no other super admin, got %d", n)
+		}
+
+		if err := store.Admins().ReplaceRecoveryCodes(root.Admin_ID, []string{"a", "b"}); err != nil {
+			t.Fatalf("ReplaceRecoveryCodes failed: %v", err)
+		}
+		now := time.Now()
+		if ok, _ := store.Admins().UseRecoveryCode(root.Admin_ID, "a", now); !ok {
+			t.Errorf("expected recovery code to match")
+		}
+		if ok, _ := store.Admins().UseRecoveryCode(root.Admin_ID, "a", now); ok {
+			t.Errorf("expected recovery code to be single use")
+		}
+
+		if ok, _ := store.Admins().UseTOTPStep(root.Admin_ID, 100); !ok {
+			t.Errorf("expected a first TOTP step to be accepted")
+		}
+		if ok, _ := store.Admins().UseTOTPStep(root.Admin_ID, 100); ok {
+			t.Errorf("expected a replayed TOTP step to be refused")
+		}
+		root.Role = DataBase.AdminRoleSuperAdmin
+		store.Admins().Save(&root)
+		if ok, _ := store.Admins().UseTOTPStep(root.Admin_ID, 99); ok {
+			t.Errorf("expected Save to leave the last TOTP step alone")
+		}
+
+		lockAtThree := func(failures int) time.Duration {
+			if failures >= 3 {
+				return time.Minute
+			}
+			return 0
+		}
+		for i := 1; i <= 3; i++ {
+			attempt, ok, err := store.Admins().ReserveLoginAttempt("user:root", now, lockAtThree)
+			if err != nil || !ok || attempt.Failures != i {
+				t.Fatalf("reservation %d: got %+v, %v, %v", i, attempt, ok, err)
+			}
+		}
+		attempt, ok, _ := store.Admins().ReserveLoginAttempt("user:root", now, lockAtThree)
+		if ok || attempt.Locked_Until == nil || !attempt.Locked_Until.After(now) {
+			t.Errorf("expected the locked key to refuse the attempt, got %+v, %v", attempt, ok)
+		}
+		if attempts, _ := store.Admins().LoginAttempts([]string{"user:root"}); len(attempts) != 1 || attempts[0].Failures != 3 {
+			t.Errorf("expected a refused attempt not to count, got %+v", attempts)
+		}
+		if _, ok, _ := store.Admins().ReserveLoginAttempt("user:root", now.Add(2*time.Minute), lockAtThree); !ok {
+			t.Errorf("expected the key to take attempts again once the lock expired")
+		}
+		store.Admins().ClearLoginAttempts([]string{"user:root"})
+		if attempts, _ := store.Admins().LoginAttempts([]string{"user:root"}); len(attempts) != 0 {
+			t.Errorf("expected attempts to be cleared, got %+v", attempts)
+		}
+	})
+}
+
+func TestDeleteAllRestartsIDs(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Repository.Store) {
+		sport, court := seedCourt(t, store)
+
+		customer := DataBase.Customer{Name: "Jane", Email: "jane@example.com"}
+		store.Customers().Create(&customer)
+		booking := DataBase.Bookings{Customer_ID: customer.Customer_ID, Sport_ID: sport.Sport_ID, Court_ID: court.Court_ID, Booking_Status: DataBase.BookingConfirmed}
+		store.Bookings().Create(&booking)
+
+		// Customers still referenced by bookings must not block the reset
+		if err := store.Customers().DeleteAll(); err != nil {
+			t.Fatalf("Customers DeleteAll failed: %v", err)
+		}
+		if err := store.Bookings().DeleteAll(); err != nil {
+			t.Fatalf("Bookings DeleteAll failed: %v", err)
+		}
+
+		again := DataBase.Customer{Name: "John", Email: "john@example.com"}
+		if err := store.Customers().Create(&again); err != nil {
+			t.Fatalf("failed to create customer: %v", err)
+		}
+		if again.Customer_ID != 1 {
+			t.Errorf("expected customer IDs to restart at 1, got %d", again.Customer_ID)
+		}
+		if mine, _ := store.Bookings().ListByCustomer(again.Customer_ID); len(mine) != 0 {
+			t.Errorf("expected no bookings to survive, got %d", len(mine))
+		}
+	})
+}
+
+func TestActiveBookingsAreUniquePerSlot(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Repository.Store) {
+		sport, court := seedCourt(t, store)
+		customer := DataBase.Customer{Name: "Jane", Email: "jane@example.com"}
+		store.Customers().Create(&customer)
+
+		today := DataBase.BookingDate(time.Now())
+		booking := func(status DataBase.BookingStatus, date *string) DataBase.Bookings {
+			return DataBase.Bookings{Customer_ID: customer.Customer_ID, Sport_ID: sport.Sport_ID, Court_ID: court.Court_ID,
+				Booking_Status: status, Booking_Time: 3, Booking_Date: date}
+		}
+
+		first := booking(DataBase.BookingConfirmed, today)
+		if err := store.Bookings().Create(&first); err != nil {
+			t.Fatalf("failed to create booking: %v", err)
+		}
+		second := booking(DataBase.BookingHeld, today)
+		if err := store.Bookings().Create(&second); !errors.Is(err, Repository.ErrDuplicate) {
+			t.Errorf("expected ErrDuplicate for a second active booking, got %v", err)
+		}
+
+		// Cancelled bookings, other days and undated legacy bookings do not clash
+		for _, b := range []DataBase.Bookings{
+			booking(DataBase.BookingCancelledByUser, today),
+			booking(DataBase.BookingConfirmed, DataBase.BookingDate(time.Now().AddDate(0, 0, 1))),
+			booking(DataBase.BookingConfirmed, nil),
+			booking(DataBase.BookingConfirmed, nil),
+		} {
+			if err := store.Bookings().Create(&b); err != nil {
+				t.Errorf("expected %s booking on %v to be accepted, got %v", b.Booking_Status, b.Booking_Date, err)
+			}
+		}
+
+		// Once the first booking is cancelled, the slot can be booked again
+		store.Bookings().Transition(first.Booking_ID, DataBase.BookingCancelledByUser, "")
+		again := booking(DataBase.BookingConfirmed, today)
+		if err := store.Bookings().Create(&again); err != nil {
+			t.Errorf("expected the slot to be free after cancelling, got %v", err)
+		}
+	})
+}
+
+func TestOccupancy(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Repository.Store) {
+		_, court := seedCourt(t, store)
+		date := func(d string) *string { return &d }
+		slot := func(i int) *int { return &i }
+
+		bookings := []DataBase.Bookings{
+			{Customer_ID: 1, Sport_ID: 1, Court_ID: court.Court_ID, Booking_Time: 2, Booking_Date: date("2026-05-01"), Booking_Status: DataBase.BookingConfirmed},
+			{Customer_ID: 1, Sport_ID: 1, Court_ID: court.Court_ID, Booking_Time: 3, Booking_Date: date("2026-05-01"), Booking_Status: DataBase.BookingCancelledByUser},
+			{Customer_ID: 1, Sport_ID: 1, Court_ID: court.Court_ID, Booking_Time: 4, Booking_Date: date("2026-05-09"), Booking_Status: DataBase.BookingConfirmed},
+		}
+		for i := range bookings {
+			if err := store.Bookings().Create(&bookings[i]); err != nil {
+				t.Fatalf("failed to create booking: %v", err)
+			}
+		}
+		store.Blackouts().Create(&DataBase.Court_Blackout{Court_ID: court.Court_ID, Blackout_Date: "2026-05-02"})
+		store.Blackouts().Create(&DataBase.Court_Blackout{Court_ID: court.Court_ID, Blackout_Date: "2026-05-03", Slot_Index: slot(5)})
+
+		occupied, err := store.Courts().Occupancy([]uint{court.Court_ID}, "2026-05-01", "2026-05-03")
+		if err != nil {
+			t.Fatalf("failed to read occupancy: %v", err)
+		}
+		var got []string
+		for _, o := range occupied {
+			entry := fmt.Sprintf("%d %s", o.Court_ID, o.Date)
+			if o.Slot != nil {
+				entry += fmt.Sprintf(" %d", *o.Slot)
+			}
+			got = append(got, entry)
+		}
+		sort.Strings(got)
+		want := []string{"1 2026-05-01 2", "1 2026-05-02", "1 2026-05-03 5"}
+		if strings.Join(got, ", ") != strings.Join(want, ", ") {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+
+		if occupied, err := store.Courts().Occupancy(nil, "2026-05-01", "2026-05-31"); err != nil || len(occupied) != 0 {
+			t.Errorf("expected nothing occupied without courts, got %v (error: %v)", occupied, err)
+		}
+	})
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Repository.Store) {
+		keys := store.IdempotencyKeys()
+		now := time.Now()
+		fresh := DataBase.Idempotency_Key{Key: "fresh", Request_Hash: "a", Created_At: now, Expires_At: now.Add(time.Hour)}
+		stale := DataBase.Idempotency_Key{Key: "stale", Request_Hash: "b", Created_At: now, Expires_At: now.Add(-time.Minute)}
+		for _, key := range []*DataBase.Idempotency_Key{&fresh, &stale} {
+			if err := keys.Reserve(key); err != nil {
+				t.Fatalf("failed to reserve %s: %v", key.Key, err)
+			}
+		}
+		if err := keys.Reserve(&DataBase.Idempotency_Key{Key: "fresh", Request_Hash: "c", Created_At: now, Expires_At: now}); !errors.Is(err, Repository.ErrDuplicate) {
+			t.Errorf("expected ErrDuplicate for a reserved key, got %v", err)
+		}
+
+		if err := keys.Complete("fresh", 201, `{"Location":["/x"]}`, []byte("created")); err != nil {
+			t.Fatalf("Complete failed: %v", err)
+		}
+		if err := keys.Complete("missing", 201, "", nil); !errors.Is(err, Repository.ErrNotFound) {
+			t.Errorf("expected ErrNotFound completing an unknown key, got %v", err)
+		}
+		saved, err := keys.Find("fresh")
+		if err != nil || saved.Status != 201 || string(saved.Body) != "created" || saved.Request_Hash != "a" {
+			t.Errorf("expected the completed response, got %+v (%v)", saved, err)
+		}
+
+		if n, err := keys.Purge(now); err != nil || n != 1 {
+			t.Errorf("expected one expired key purged, got %d (%v)", n, err)
+		}
+		if _, err := keys.Find("stale"); !errors.Is(err, Repository.ErrNotFound) {
+			t.Errorf("expected the expired key to be gone, got %v", err)
+		}
+		keys.Release("fresh")
+		if _, err := keys.Find("fresh"); !errors.Is(err, Repository.ErrNotFound) {
+			t.Errorf("expected the released key to be gone, got %v", err)
+		}
+	})
+}
+
+func TestAvailabilityVersion(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Repository.Store) {
+		tennis, court := seedCourt(t, store)
+		squash := DataBase.Sport{Sport_name: "Squash"}
+		store.Sports().Create(&squash)
+
+		versions := func() (int64, int64) {
+			t.Helper()
+			a, errA := store.Sports().FindByID(tennis.Sport_ID)
+			b, errB := store.Sports().FindByID(squash.Sport_ID)
+			if errA != nil || errB != nil {
+				t.Fatalf("failed to load sports: %v %v", errA, errB)
+			}
+			return a.Availability_Version, b.Availability_Version
+		}
+		// moved runs write and checks which sports' versions moved on
+		moved := func(name string, wantTennis, wantSquash bool, write func() error) {
+			t.Helper()
+			beforeTennis, beforeSquash := versions()
+			if err := write(); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			afterTennis, afterSquash := versions()
+			if (afterTennis > beforeTennis) != wantTennis || (afterSquash > beforeSquash) != wantSquash {
+				t.Errorf("%s: tennis %d -> %d, squash %d -> %d", name, beforeTennis, afterTennis, beforeSquash, afterSquash)
+			}
+		}
+
+		date := "2026-01-31"
+		booking := DataBase.Bookings{Customer_ID: 1, Sport_ID: tennis.Sport_ID, Court_ID: court.Court_ID,
+			Booking_Status: DataBase.BookingConfirmed, Booking_Time: 1, Booking_Date: &date}
+		moved("booking", true, false, func() error { return store.Bookings().Create(&booking) })
+		moved("cancellation", true, false, func() error {
+			return store.Bookings().Transition(booking.Booking_ID, DataBase.BookingCancelledByUser, "")
+		})
+
+		blackout := DataBase.Court_Blackout{Court_ID: court.Court_ID, Blackout_Date: date}
+		moved("blackout", true, false, func() error { return store.Blackouts().Create(&blackout) })
+		moved("lifted blackout", true, false, func() error { return store.Blackouts().Delete(blackout.Blackout_ID) })
+
+		moved("unrelated sport renamed", false, true, func() error {
+			squash.Sport_name = "Padel"
+			return store.Sports().Update(&squash)
+		})
+		moved("court moved", true, true, func() error {
+			court.Sport_id = squash.Sport_ID
+			return store.Courts().Update(&court)
+		})
+		moved("court deleted", false, true, func() error { return store.Courts().Delete(court.Court_ID) })
+		moved("bookings deleted", true, true, func() error { return store.Bookings().DeleteAll() })
+	})
+}
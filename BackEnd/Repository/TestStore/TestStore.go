@@ -0,0 +1,117 @@
+// Package TestStore opens the stores used by tests. TEST_DB_DRIVER selects the backend:
+//
+//   - "sqlite" (default) runs against a private in-memory SQLite database
+//   - "postgres" runs against TEST_DATABASE_URL, each test in a schema of its own
+//   - "memory" runs against Repository.NewMemoryStore
+//
+// Every database is brought up to date with Migrations.Up, so tests see the real schema.
+package TestStore
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Migrations"
+	"BackEnd/Repository"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const driverMemory = "memory"
+
+// Driver returns the backend selected by TEST_DB_DRIVER.
+func Driver() string {
+	if driver := os.Getenv("TEST_DB_DRIVER"); driver != "" {
+		return driver
+	}
+	return DataBase.DriverSQLite
+}
+
+// Open returns an empty, migrated store on the selected backend.
+func Open(t testing.TB) Repository.Store {
+	t.Helper()
+	if Driver() == driverMemory {
+		return Repository.NewMemoryStore()
+	}
+	return Repository.NewGormStore(OpenDB(t))
+}
+
+// OpenDB returns an empty, migrated database on the selected SQL backend. With TEST_DB_DRIVER
+// set to "memory" it falls back to SQLite.
+func OpenDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	config := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+
+	var db *gorm.DB
+	switch driver := Driver(); driver {
+	case DataBase.DriverSQLite, driverMemory:
+		var err error
+		db, err = DataBase.OpenDriver(DataBase.DriverSQLite, "file::memory:", config)
+		if err != nil {
+			t.Fatalf("failed to open sqlite: %v", err)
+		}
+	case DataBase.DriverPostgres:
+		db = openPostgresSchema(t, config)
+	default:
+		t.Fatalf("unsupported TEST_DB_DRIVER %q", driver)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if _, err := Migrations.Up(db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+// openPostgresSchema creates a throwaway schema and connects with it as the search path,
+// so tests can run in parallel against one database without seeing each other's rows.
+func openPostgresSchema(t testing.TB, config *gorm.Config) *gorm.DB {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Fatal("TEST_DB_DRIVER=postgres needs TEST_DATABASE_URL")
+	}
+
+	admin, err := DataBase.OpenDriver(DataBase.DriverPostgres, dsn, config)
+	if err != nil {
+		t.Fatalf("failed to open postgres: %v", err)
+	}
+
+	suffix := make([]byte, 6)
+	rand.Read(suffix)
+	schema := "test_" + hex.EncodeToString(suffix)
+	if err := admin.Exec(fmt.Sprintf("CREATE SCHEMA %s", schema)).Error; err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.Exec(fmt.Sprintf("DROP SCHEMA %s CASCADE", schema))
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	db, err := DataBase.OpenDriver(DataBase.DriverPostgres, withSearchPath(dsn, schema), config)
+	if err != nil {
+		t.Fatalf("failed to open postgres schema: %v", err)
+	}
+	return db
+}
+
+// withSearchPath adds search_path to a key=value or URL connection string.
+func withSearchPath(dsn, schema string) string {
+	if strings.Contains(dsn, "://") {
+		if strings.Contains(dsn, "?") {
+			return dsn + "&search_path=" + schema
+		}
+		return dsn + "?search_path=" + schema
+	}
+	return dsn + " search_path=" + schema
+}
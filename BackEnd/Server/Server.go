@@ -0,0 +1,82 @@
+// Package Server runs the HTTP server and shuts it down gracefully: on a signal it stops
+// accepting connections, lets in-flight requests finish, then releases everything else the
+// process holds, such as the scheduler and the database pool.
+package Server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Config holds the server's address, timeouts and limits.
+type Config struct {
+	Port              int
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	// ShutdownTimeout bounds the whole shutdown: draining requests and every cleanup step.
+	ShutdownTimeout time.Duration
+}
+
+// New returns a server for handler configured by config.
+func New(config Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ":" + strconv.Itoa(config.Port),
+		Handler:           handler,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+	}
+}
+
+// Run serves srv until ctx is done, then shuts it down within shutdownTimeout: the listener
+// closes, in-flight requests drain, and the cleanup steps run in order with whatever time is
+// left. Every cleanup step runs even if draining or an earlier step failed; all failures are
+// returned together.
+func Run(ctx context.Context, srv *http.Server, shutdownTimeout time.Duration, cleanup ...func(ctx context.Context) error) error {
+	listener, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	return serve(ctx, srv, listener, shutdownTimeout, cleanup)
+}
+
+func serve(ctx context.Context, srv *http.Server, listener net.Listener, shutdownTimeout time.Duration, cleanup []func(ctx context.Context) error) error {
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(listener)
+	}()
+	log.Printf("Server is running on %s", listener.Addr())
+
+	select {
+	case err := <-served:
+		// Serve only returns early if the listener failed
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down, waiting up to %s for requests and jobs to finish", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("draining requests: %w", err))
+	}
+	for _, step := range cleanup {
+		if err := step(shutdownCtx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,112 @@
+package Server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// startServing runs serve on a free port with handler and returns its address and result.
+func startServing(t *testing.T, ctx context.Context, handler http.HandlerFunc, timeout time.Duration, cleanup ...func(ctx context.Context) error) (string, <-chan error) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(ctx, New(Config{}, handler), listener, timeout, cleanup)
+	}()
+	return "http://" + listener.Addr().String(), done
+}
+
+func TestRun_DrainsRequestsBeforeCleanup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started, release := make(chan struct{}), make(chan struct{})
+	var steps []string
+
+	addr, done := startServing(t, ctx, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "finished")
+	}, 5*time.Second,
+		func(ctx context.Context) error { steps = append(steps, "scheduler"); return nil },
+		func(ctx context.Context) error { steps = append(steps, "database"); return errors.New("close failed") },
+	)
+
+	response := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(addr)
+		if err != nil {
+			response <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		response <- string(body)
+	}()
+
+	<-started
+	cancel()
+	select {
+	case err := <-done:
+		t.Fatalf("expected shutdown to wait for the request, returned %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if _, err := http.Get(addr); err == nil {
+		t.Error("expected new connections to be refused while draining")
+	}
+
+	close(release)
+	if body := <-response; body != "finished" {
+		t.Errorf("expected the in-flight request to finish, got %q", body)
+	}
+	err := <-done
+	if err == nil || err.Error() != "close failed" {
+		t.Errorf("expected the cleanup error, got %v", err)
+	}
+	if len(steps) != 2 || steps[0] != "scheduler" || steps[1] != "database" {
+		t.Errorf("expected every cleanup step in order, got %v", steps)
+	}
+}
+
+func TestRun_ShutdownTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	cleaned := false
+
+	addr, done := startServing(t, ctx, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}, 50*time.Millisecond, func(ctx context.Context) error { cleaned = true; return nil })
+
+	go http.Get(addr)
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected the drain to time out, got %v", err)
+		}
+		if !cleaned {
+			t.Error("expected cleanup to run after a timed out drain")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected shutdown to give up on a stuck request")
+	}
+}
+
+func TestNew(t *testing.T) {
+	config := Config{Port: 9090, ReadTimeout: time.Second, ReadHeaderTimeout: time.Second, WriteTimeout: 2 * time.Minute,
+		IdleTimeout: time.Minute, MaxHeaderBytes: 4096, ShutdownTimeout: time.Second}
+	srv := New(config, http.NotFoundHandler())
+	if srv.Addr != ":9090" || srv.WriteTimeout != 2*time.Minute || srv.MaxHeaderBytes != 4096 {
+		t.Errorf("server not configured from %+v: %+v", config, srv)
+	}
+}
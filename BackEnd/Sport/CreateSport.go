@@ -1,6 +1,7 @@
 package Sport
 
 import (
+	"BackEnd/APIError"
 	"BackEnd/DataBase"
 	"encoding/json"
 	"net/http"
@@ -14,40 +15,34 @@ import (
 // @Produce      json
 // @Param        sport  body      DataBase.Sport  true  "Sport object"
 // @Success      201    {object}  map[string]interface{}  "Sport record added successfully"  example({"message": "Sport record added successfully!!", "sport": {"Sport_ID": 1, "Sport_name": "Tennis"}})
-// @Failure      400    {string}  string  "Sport_name is required or the sport already exists or invalid request body"
-// @Failure      500    {string}  string  "Internal Server Error"
+// @Failure      400    {object}  DataBase.ErrorResponse  "Sport_name is required or the sport already exists or invalid request body"
+// @Failure      500    {object}  DataBase.ErrorResponse  "Internal Server Error"
+// @Deprecated
 // @Router       /CreateSport [post]
-func CreateSport(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateSport(w http.ResponseWriter, r *http.Request) {
 	var s DataBase.Sport
 	err := json.NewDecoder(r.Body).Decode(&s)
 
 	w.Header().Set("Content-Type", "application/json")
 
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid request body"})
+		APIError.Malformed(w, r, "Invalid request body")
 		return
 	}
 
 	if s.Sport_name == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Sport_name is required"})
+		APIError.Validation(w, r, "Sport_name is required", APIError.Field("Sport_name", "is required"))
 		return
 	}
 
-	var existingSport DataBase.Sport
 	// Case sensitive lookup
-	result := DataBase.DB.Where("\"Sport_name\" = ?", s.Sport_name).First(&existingSport)
-
-	if result.RowsAffected > 0 {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"message": "The sport record already exists"})
+	if _, err := h.Store.Sports().FindByName(s.Sport_name); err == nil {
+		APIError.Write(w, r, http.StatusBadRequest, APIError.SportAlreadyExists, "The sport record already exists")
 		return
 	}
 
-	if err := DataBase.DB.Create(&s).Error; err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+	if err := h.Store.Sports().Create(&s); err != nil {
+		APIError.Internal(w, r, "Failed to create sport", err)
 		return
 	}
 
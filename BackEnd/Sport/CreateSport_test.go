@@ -1,33 +1,18 @@
 package Sport
 
 import (
-	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
 	"bytes"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
-
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 )
 
-// Setup an in-memory SQLite database for testing
-func setupTestDB() *gorm.DB {
-	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
-	if err != nil {
-		panic("failed to connect to the database")
-	}
-
-	// Auto-migrate schema
-	db.AutoMigrate(&DataBase.Sport{})
-
-	return db
-}
-
 // Test case for successfully creating a sport
 func TestCreateSport(t *testing.T) {
-	DataBase.DB = setupTestDB()
+	store := TestStore.Open(t)
+	h := NewHandler(store)
 
 	sportRequest := map[string]interface{}{
 		"Sport_name":        "Football",
@@ -42,7 +27,7 @@ func TestCreateSport(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	recorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(CreateSport)
+	handler := http.HandlerFunc(h.CreateSport)
 	handler.ServeHTTP(recorder, req)
 
 	t.Logf("Response Body: %s", recorder.Body.String())
@@ -58,17 +43,14 @@ func TestCreateSport(t *testing.T) {
 		t.Errorf("unexpected response message: %v", response["message"])
 	}
 
-	var savedSport DataBase.Sport
-	result := DataBase.DB.First(&savedSport, "sport_name = ?", "Football")
-
-	if result.Error != nil {
-		t.Errorf("Sport not found in database: %v", result.Error)
+	if _, err := store.Sports().FindByName("Football"); err != nil {
+		t.Errorf("Sport not found in store: %v", err)
 	}
 }
 
 // Test case for creating a duplicate sport
 func TestCreateDuplicateSport(t *testing.T) {
-	DataBase.DB = setupTestDB()
+	h := NewHandler(TestStore.Open(t))
 
 	// First request: should pass
 	sportRequest := map[string]interface{}{
@@ -81,7 +63,7 @@ func TestCreateDuplicateSport(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	recorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(CreateSport)
+	handler := http.HandlerFunc(h.CreateSport)
 	handler.ServeHTTP(recorder, req)
 
 	// Second request: should fail due to duplicate sport
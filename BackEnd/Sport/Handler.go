@@ -0,0 +1,18 @@
+package Sport
+
+import (
+	"BackEnd/Events"
+	"BackEnd/Repository"
+)
+
+// Handler serves the sport endpoints using the injected repositories.
+type Handler struct {
+	Store Repository.Store
+	// Events receives every change to availability; nil ignores them.
+	Events *Events.Broker
+}
+
+// NewHandler returns a Handler backed by the given store.
+func NewHandler(store Repository.Store) *Handler {
+	return &Handler{Store: store}
+}
@@ -1,7 +1,8 @@
 package Sport
 
 import (
-	"BackEnd/DataBase"
+	"BackEnd/Pagination"
+	"BackEnd/Repository"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,21 +10,37 @@ import (
 
 // ListSports godoc
 // @Summary Get a list of sports
-// @Description Fetches all sports names from the database
+// @Description Fetches the sports a page at a time. X-Total-Count gives the number of sports, and X-Next-Cursor and the Link header the next page.
 // @Tags sports
 // @Produce json
+// @Param limit query int false "Sports per page, at most 500" default(100)
+// @Param cursor query string false "Cursor of the page, from X-Next-Cursor"
+// @Param sort query string false "id or name, prefixed with - for descending order" default(id)
 // @Success 200 {array} DataBase.Sport "List of sports"
-// @Failure 500 {object} map[string]string "Failed to fetch sports"
+// @Failure 400 {object} DataBase.ErrorResponse "Invalid list parameters"
+// @Failure 500 {object} DataBase.ErrorResponse "Failed to fetch sports"
+// @Deprecated
 // @Router /ListSports [get]
-func ListSports(w http.ResponseWriter, r *http.Request) {
-	var sports []DataBase.Sport
+func (h *Handler) ListSports(w http.ResponseWriter, r *http.Request) {
+	params := Pagination.New(r)
+	q := params.List(Repository.SportSorts)
+	if !params.Check(w, r) {
+		return
+	}
 
-	if err := DataBase.DB.Find(&sports).Error; err != nil {
+	sports, next, err := h.Store.Sports().Page(q)
+	var total int64
+	if err == nil {
+		total, err = h.Store.Sports().Count()
+	}
+	if err != nil {
 		fmt.Println("Failed to fetch sports:", err)
-		http.Error(w, "Failed to fetch sports", http.StatusInternalServerError)
+		Pagination.Failed(w, r, err, "Failed to fetch sports")
 		return
 	}
 
+	Pagination.SetTotal(w, total)
+	Pagination.SetNext(w, r, q, next)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(sports)
@@ -2,6 +2,7 @@ package Sport
 
 import (
 	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -9,10 +10,11 @@ import (
 )
 
 func TestListSports(t *testing.T) {
-	DataBase.DB = setupTestDB()
+	store := TestStore.Open(t)
+	h := NewHandler(store)
 
 	sport := DataBase.Sport{Sport_name: "Football"}
-	DataBase.DB.Create(&sport)
+	store.Sports().Create(&sport)
 
 	req, err := http.NewRequest("GET", "/ListSports", nil)
 	if err != nil {
@@ -20,26 +22,27 @@ func TestListSports(t *testing.T) {
 	}
 
 	recorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(ListSports)
+	handler := http.HandlerFunc(h.ListSports)
 	handler.ServeHTTP(recorder, req)
 
 	if status := recorder.Code; status != http.StatusOK {
 		t.Errorf("expected status %d, got %d", http.StatusOK, status)
 	}
 
-	var response []string
+	var response []DataBase.Sport
 	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
 		t.Errorf("failed to decode response body: %v", err)
 	}
 
 	expectedSport := "Football"
-	if len(response) == 0 || response[0] != expectedSport {
+	if len(response) == 0 || response[0].Sport_name != expectedSport {
 		t.Errorf("expected %v, got %v", expectedSport, response)
 	}
 }
 
 func TestListSportsMultipleEntries(t *testing.T) {
-	DataBase.DB = setupTestDB()
+	store := TestStore.Open(t)
+	h := NewHandler(store)
 
 	sports := []DataBase.Sport{
 		{Sport_name: "Basketball"},
@@ -47,23 +50,26 @@ func TestListSportsMultipleEntries(t *testing.T) {
 		{Sport_name: "Volleyball"},
 	}
 	for _, s := range sports {
-		DataBase.DB.Create(&s)
+		store.Sports().Create(&s)
 	}
 
 	req, _ := http.NewRequest("GET", "/ListSports", nil)
 	recorder := httptest.NewRecorder()
-	handler := http.HandlerFunc(ListSports)
+	handler := http.HandlerFunc(h.ListSports)
 	handler.ServeHTTP(recorder, req)
 
-	var response []string
+	var response []DataBase.Sport
 	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
 		t.Fatalf("failed to decode response: %v", err)
 	}
 
 	expected := []string{"Basketball", "Tennis", "Volleyball"}
+	if len(response) != len(expected) {
+		t.Fatalf("expected %d sports, got %d", len(expected), len(response))
+	}
 	for i, sport := range expected {
-		if response[i] != sport {
-			t.Errorf("expected %s, got %s", sport, response[i])
+		if response[i].Sport_name != sport {
+			t.Errorf("expected %s, got %s", sport, response[i].Sport_name)
 		}
 	}
 }
@@ -0,0 +1,30 @@
+package Utils
+
+import (
+	"BackEnd/Repository"
+	"log"
+	"time"
+)
+
+// PurgeDeleted permanently removes the bookings, courts and sports soft deleted more than
+// retention ago. Children go first so nothing is left pointing at a purged row.
+func PurgeDeleted(store Repository.Store, retention time.Duration) error {
+	cutoff := time.Now().Add(-retention)
+	return store.Transaction(func(tx Repository.Store) error {
+		bookings, err := tx.Bookings().Purge(cutoff)
+		if err != nil {
+			return err
+		}
+		courts, err := tx.Courts().Purge(cutoff)
+		if err != nil {
+			return err
+		}
+		sports, err := tx.Sports().Purge(cutoff)
+		if err != nil {
+			return err
+		}
+		log.Printf("Purged %d booking(s), %d court(s) and %d sport(s) deleted before %s",
+			bookings, courts, sports, cutoff.Format(time.RFC3339))
+		return nil
+	})
+}
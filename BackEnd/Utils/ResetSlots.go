@@ -1,7 +1,10 @@
 package Utils
 
 import (
+	"BackEnd/APIError"
 	"BackEnd/DataBase"
+	"BackEnd/Events"
+	"BackEnd/Repository"
 	"encoding/json"
 	"log"
 	"net/http"
@@ -10,7 +13,7 @@ import (
 // ResetCourtSlots godoc
 //
 // @Summary      Reset all time‑slots for available courts
-// @Description  Sets every slot (08‑18h) back to **available** (value `1`) for every court whose `court_status == 1`.<br>
+// @Description  Cancels every booking that can still be cancelled on every court whose `court_status == 1`, so each slot (08‑18h) is back to **available** (value `1`) unless blacked out.<br>
 //   - If **court_name** is supplied, only that court is reset.<br>
 //   - If the court (or any available courts) are not found, the call is a no‑op and returns **200** with an
 //     informational message.
@@ -21,23 +24,12 @@ import (
 // @Param        court_name  query     string  false  "Reset a single court by name"  example("Court A")
 // @Success      200         {object}  map[string]string  "Slots reset successfully"
 // @Failure      500         {object}  DataBase.ErrorResponse  "Database error while updating slots"
+// @Deprecated
 // @Router       /resetCourtSlots [put]
-func ResetTimeSlotsForAvailableCourts(courtName string) error {
-	const (
-		AvailableStatus = 1
-		SlotAvailable   = 1
-	)
-
-	var courtIDs []uint
-	db := DataBase.DB.Model(&DataBase.Court{}).Where("\"Court_Status\" = ?", AvailableStatus)
-
-	// If a court name is provided, filter by that name (Case Insensitive)
-	if courtName != "" {
-		db = db.Where("LOWER(\"Court_Name\") = LOWER(?)", courtName)
-	}
-
-	// Get the court IDs that match the condition(s)
-	if err := db.Pluck("\"Court_ID\"", &courtIDs).Error; err != nil {
+func ResetTimeSlotsForAvailableCourts(store Repository.Store, courtName string, status DataBase.BookingStatus) error {
+	// Get the IDs of available courts, filtered by name (Case Insensitive) if one is provided
+	courtIDs, err := store.Courts().AvailableIDs(courtName)
+	if err != nil {
 		return err
 	}
 
@@ -50,29 +42,8 @@ func ResetTimeSlotsForAvailableCourts(courtName string) error {
 		return nil
 	}
 
-	slotReset := map[string]interface{}{
-		"slot_08_09": SlotAvailable, "slot_09_10": SlotAvailable,
-		"slot_10_11": SlotAvailable, "slot_11_12": SlotAvailable,
-		"slot_12_13": SlotAvailable, "slot_13_14": SlotAvailable,
-		"slot_14_15": SlotAvailable, "slot_15_16": SlotAvailable,
-		"slot_16_17": SlotAvailable, "slot_17_18": SlotAvailable,
-	}
-
-	// Update slots to available
-	if err := DataBase.DB.
-		Model(&DataBase.Court_TimeSlots{}).
-		Where("\"Court_ID\" IN ?", courtIDs).
-		Updates(slotReset).Error; err != nil {
-		return err
-	}
-
-	// Cancel all associated active bookings for these courts
-	if err := DataBase.DB.
-		Model(&DataBase.Bookings{}).
-		Where("\"Court_ID\" IN ? AND \"Booking_Status\" = ?", courtIDs, "Confirmed").
-		Update("Booking_Status", "Cancelled by UF CourtLink").Error; err != nil {
-		// Log error but don't fail the whole reset? Or fail?
-		// For admin reset, we probably want to know.
+	// Cancel all associated active bookings for these courts, which frees every slot
+	if err := store.Bookings().CancelActiveForCourts(courtIDs, status, "Court slots reset"); err != nil {
 		log.Printf("Failed to cancel bookings for reset courts: %v\n", err)
 		return err
 	}
@@ -85,14 +56,15 @@ func ResetTimeSlotsForAvailableCourts(courtName string) error {
 
 	return nil
 }
+
 // DeleteAllBookings deletes all bookings from the database
-func DeleteAllBookings(w http.ResponseWriter, r *http.Request) {
-	if err := DataBase.DB.Exec("TRUNCATE TABLE \"Bookings\" RESTART IDENTITY CASCADE").Error; err != nil {
-		http.Error(w, "Failed to delete all bookings", http.StatusInternalServerError)
+func (h *Handler) DeleteAllBookings(w http.ResponseWriter, r *http.Request) {
+	// With no bookings left every slot is available again
+	if err := h.Store.Bookings().DeleteAll(); err != nil {
+		APIError.Internal(w, r, "Failed to delete all bookings", err)
 		return
 	}
-	// Also reset all slots to 1
-	DataBase.DB.Exec("UPDATE \"Court_TimeSlots\" SET slot_08_09=1, slot_09_10=1, slot_10_11=1, slot_11_12=1, slot_12_13=1, slot_13_14=1, slot_14_15=1, slot_15_16=1, slot_16_17=1, slot_17_18=1")
+	h.Events.Publish(Events.Change{Kind: Events.Reset})
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -100,18 +72,16 @@ func DeleteAllBookings(w http.ResponseWriter, r *http.Request) {
 }
 
 // ResetSystem wipes Customers and Bookings
-func ResetSystem(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) ResetSystem(w http.ResponseWriter, r *http.Request) {
 	// Truncate Bookings
-	if err := DataBase.DB.Exec("TRUNCATE TABLE \"Bookings\" RESTART IDENTITY CASCADE").Error; err != nil {
+	if err := h.Store.Bookings().DeleteAll(); err != nil {
 		log.Printf("Failed to truncate bookings: %v\n", err)
 	}
 	// Truncate Customers
-	if err := DataBase.DB.Exec("TRUNCATE TABLE \"Customer\" RESTART IDENTITY CASCADE").Error; err != nil {
+	if err := h.Store.Customers().DeleteAll(); err != nil {
 		log.Printf("Failed to truncate customers: %v\n", err)
 	}
-
-	// Reset slots
-	DataBase.DB.Exec("UPDATE \"Court_TimeSlots\" SET slot_08_09=1, slot_09_10=1, slot_10_11=1, slot_11_12=1, slot_12_13=1, slot_13_14=1, slot_14_15=1, slot_15_16=1, slot_16_17=1, slot_17_18=1")
+	h.Events.Publish(Events.Change{Kind: Events.Reset})
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -0,0 +1,21 @@
+package Utils
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository"
+	"log"
+	"time"
+)
+
+// RolloverBookings archives every booking dated before the day containing now. Bookings that
+// were going ahead are marked completed on the way, and cancellations keep their status.
+// Availability needs no reset: it is derived from dated bookings, so a new day starts free.
+func RolloverBookings(store Repository.Store, now time.Time) error {
+	today := *DataBase.BookingDate(now)
+	archived, err := store.Bookings().Archive(today, "Archived by nightly rollover")
+	if err != nil {
+		return err
+	}
+	log.Printf("Archived %d booking(s) dated before %s", archived, today)
+	return nil
+}
@@ -0,0 +1,221 @@
+package V1
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/Bookings"
+	"BackEnd/DataBase"
+	"BackEnd/Events"
+	"BackEnd/Repository"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// BookingResource is a booking as the API returns it. Court and sport names are included when
+// a listing has them at hand.
+type BookingResource struct {
+	ID         uint                   `json:"id"`
+	CustomerID uint                   `json:"customer_id"`
+	SportID    uint                   `json:"sport_id"`
+	SportName  string                 `json:"sport_name,omitempty"`
+	CourtID    uint                   `json:"court_id"`
+	CourtName  string                 `json:"court_name,omitempty"`
+	Date       string                 `json:"date,omitempty"`
+	SlotIndex  int                    `json:"slot_index"`
+	Slot       string                 `json:"slot"`
+	Status     DataBase.BookingStatus `json:"status"`
+}
+
+// BookingRequest books a slot of a court for a customer. Date defaults to today.
+type BookingRequest struct {
+	CourtID   uint   `json:"court_id"`
+	Email     string `json:"email"`
+	SlotIndex *int   `json:"slot_index"`
+	Date      string `json:"date"`
+}
+
+// CancelBookingRequest identifies the customer cancelling a booking.
+type CancelBookingRequest struct {
+	Email string `json:"email"`
+}
+
+func bookingResource(b DataBase.Bookings) BookingResource {
+	resource := BookingResource{
+		ID:         b.Booking_ID,
+		CustomerID: b.Customer_ID,
+		SportID:    b.Sport_ID,
+		SportName:  b.Sport.Sport_name,
+		CourtID:    b.Court_ID,
+		CourtName:  b.Court.Court_Name,
+		SlotIndex:  b.Booking_Time,
+		Slot:       DataBase.SlotLabel(b.Booking_Time),
+		Status:     b.Booking_Status,
+	}
+	if b.Booking_Date != nil {
+		resource.Date = *b.Booking_Date
+	}
+	return resource
+}
+
+func archivedBookingResource(b DataBase.Booking_Archive) BookingResource {
+	return BookingResource{
+		ID:         b.Booking_ID,
+		CustomerID: b.Customer_ID,
+		SportID:    b.Sport_ID,
+		SportName:  b.Sport_Name,
+		CourtID:    b.Court_ID,
+		CourtName:  b.Court_Name,
+		Date:       b.Booking_Date,
+		SlotIndex:  b.Booking_Time,
+		Slot:       DataBase.SlotLabel(b.Booking_Time),
+		Status:     b.Booking_Status,
+	}
+}
+
+// CreateBooking godoc
+// @Summary      Book a slot
+// @Description  Books a court's slot for a customer on a date, today by default, if the slot is neither booked nor blacked out. Otherwise the 409 answer lists the nearest free alternatives: other courts of the sport at the same time, and the court's own slots shortly before and after.
+// @Tags         v1
+// @Accept       json
+// @Produce      json
+// @Param        booking  body      BookingRequest  true  "Booking"
+// @Success      201      {object}  BookingResource
+// @Header       201      {string}  Location  "Address of the new booking"
+// @Failure      400      {object}  DataBase.ErrorResponse  "Invalid request body, slot index or date"
+// @Failure      401      {object}  DataBase.ErrorResponse  "Missing or invalid token"
+// @Failure      409      {object}  DataBase.ErrorResponse  "Slot is already booked or unavailable, with the nearest free alternatives, or the court is closed"
+// @Failure      422      {object}  DataBase.ErrorResponse  "Court or customer not found"
+// @Failure      500      {object}  DataBase.ErrorResponse  "Failed to create booking"
+// @Router       /api/v1/bookings [post]
+func (h *Handler) CreateBooking(w http.ResponseWriter, r *http.Request) {
+	var req BookingRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.SlotIndex == nil {
+		APIError.Validation(w, r, "slot_index is required", APIError.Field("slot_index", "is required"))
+		return
+	}
+	date, ok := dateParam(w, r, req.Date)
+	if !ok {
+		return
+	}
+	// Booking_Dates sort like the days they name
+	if date < *DataBase.BookingDate(time.Now()) {
+		APIError.Validation(w, r, "date must not be in the past", APIError.Field("date", "must not be in the past"))
+		return
+	}
+
+	court, err := h.Store.Courts().FindByID(req.CourtID)
+	if err != nil {
+		APIError.Write(w, r, http.StatusUnprocessableEntity, APIError.CourtNotFound, "Court not found")
+		return
+	}
+	customer, err := h.Store.Customers().FindByEmail(strings.ToLower(strings.TrimSpace(req.Email)))
+	if err != nil {
+		APIError.Write(w, r, http.StatusUnprocessableEntity, APIError.CustomerNotFound, "Customer not found")
+		return
+	}
+
+	booking := DataBase.Bookings{
+		Customer_ID:  customer.Customer_ID,
+		Sport_ID:     court.Sport_id,
+		Court_ID:     court.Court_ID,
+		Booking_Time: *req.SlotIndex,
+		Booking_Date: &date,
+	}
+	err = Bookings.Book(h.Store, &booking)
+	switch {
+	case errors.Is(err, Bookings.ErrInvalidSlot):
+		APIError.Validation(w, r, "Invalid slot index", APIError.Field("slot_index", "must be one of the day's slots"))
+	case errors.Is(err, Bookings.ErrSlotTaken):
+		Bookings.WriteSlotTaken(w, r, h.Store, h.Alternatives, booking)
+	case errors.Is(err, Bookings.ErrCourtClosed):
+		APIError.Write(w, r, http.StatusConflict, APIError.CourtUnavailable, "Court is closed")
+	case err != nil:
+		APIError.Internal(w, r, "Failed to create booking", err)
+	default:
+		h.Events.Publish(Events.ForBooking(Events.Booked, booking))
+		booking.Court = court
+		writeCreated(w, fmt.Sprintf("/bookings/%d", booking.Booking_ID), bookingResource(booking))
+	}
+}
+
+// GetBooking godoc
+// @Summary      Get a booking
+// @Tags         v1
+// @Produce      json
+// @Param        id   path      int  true  "Booking ID"
+// @Success      200  {object}  BookingResource
+// @Failure      400  {object}  DataBase.ErrorResponse  "Invalid booking ID"
+// @Failure      401  {object}  DataBase.ErrorResponse  "Missing or invalid token"
+// @Failure      404  {object}  DataBase.ErrorResponse  "Booking not found"
+// @Router       /api/v1/bookings/{id} [get]
+func (h *Handler) GetBooking(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "booking")
+	if !ok {
+		return
+	}
+	booking, err := h.Store.Bookings().FindByID(id)
+	if errors.Is(err, Repository.ErrNotFound) {
+		APIError.Write(w, r, http.StatusNotFound, APIError.BookingNotFound, "Booking not found")
+		return
+	} else if err != nil {
+		APIError.Internal(w, r, "Failed to fetch booking", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, bookingResource(booking))
+}
+
+// CancelBooking godoc
+// @Summary      Cancel a booking
+// @Description  Cancels the booking on behalf of the customer who made it, freeing its slot. The booking is kept with its new status.
+// @Tags         v1
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                   true  "Booking ID"
+// @Param        request  body      CancelBookingRequest  true  "Customer cancelling"
+// @Success      200      {object}  BookingResource
+// @Failure      400      {object}  DataBase.ErrorResponse  "Invalid booking ID or request body"
+// @Failure      401      {object}  DataBase.ErrorResponse  "Missing or invalid token"
+// @Failure      403      {object}  DataBase.ErrorResponse  "The booking belongs to another customer"
+// @Failure      404      {object}  DataBase.ErrorResponse  "Booking not found"
+// @Failure      409      {object}  DataBase.ErrorResponse  "Booking can no longer be cancelled"
+// @Failure      500      {object}  DataBase.ErrorResponse  "Failed to cancel booking"
+// @Router       /api/v1/bookings/{id}/cancel [post]
+func (h *Handler) CancelBooking(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "booking")
+	if !ok {
+		return
+	}
+	var req CancelBookingRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	cancelled, err := Bookings.Cancel(h.Store, id, req.Email)
+	switch {
+	case errors.Is(err, Repository.ErrNotFound):
+		APIError.Write(w, r, http.StatusNotFound, APIError.BookingNotFound, "Booking not found")
+		return
+	case errors.Is(err, Bookings.ErrUnknownCustomer), errors.Is(err, Bookings.ErrNotOwner):
+		APIError.Write(w, r, http.StatusForbidden, APIError.NotBookingOwner, "Unauthorized to cancel this booking")
+		return
+	case errors.Is(err, Repository.ErrInvalidTransition):
+		APIError.Write(w, r, http.StatusConflict, APIError.InvalidStatusTransition, "Booking can no longer be cancelled")
+		return
+	case err != nil:
+		APIError.Internal(w, r, "Failed to cancel booking", err)
+		return
+	}
+	h.Events.Publish(Events.ForBooking(Events.Cancelled, cancelled))
+
+	booking, err := h.Store.Bookings().FindByID(id)
+	if err != nil {
+		APIError.Internal(w, r, "Failed to fetch booking", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, bookingResource(booking))
+}
@@ -0,0 +1,115 @@
+package V1
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBookAndCancel(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	sport := DataBase.Sport{Sport_name: "Volleyball"}
+	store.Sports().Create(&sport)
+	court := DataBase.Court{Court_Name: "Sand 1", Court_Location: "Lake", Court_Status: 1, Sport_id: sport.Sport_ID}
+	store.Courts().Create(&court)
+
+	if rr := call(h, "PUT", "/customers/Ana@UFL.edu", CustomerRequest{Name: "Ana"}); rr.Code != http.StatusCreated {
+		t.Fatalf("expected the customer to be created, got %d: %s", rr.Code, rr.Body.String())
+	}
+	rr := call(h, "PUT", "/customers/ana@ufl.edu", CustomerRequest{UFID: "12345678"})
+	var customer CustomerResource
+	json.Unmarshal(rr.Body.Bytes(), &customer)
+	if rr.Code != http.StatusOK || customer.Name != "Ana" || customer.UFID != "12345678" {
+		t.Errorf("expected the profile to be updated, got %d %+v", rr.Code, customer)
+	}
+
+	slot, tomorrow := 3, *DataBase.BookingDate(time.Now().AddDate(0, 0, 1))
+	rr = call(h, "POST", "/bookings", BookingRequest{CourtID: court.Court_ID, Email: "ana@ufl.edu", SlotIndex: &slot, Date: tomorrow})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var booking BookingResource
+	json.Unmarshal(rr.Body.Bytes(), &booking)
+	if rr.Header().Get("Location") != "/api/v1/bookings/1" || booking.SportID != sport.Sport_ID ||
+		booking.Date != tomorrow || booking.Status != DataBase.BookingConfirmed || booking.CourtName != "Sand 1" {
+		t.Errorf("unexpected booking %+v at %q", booking, rr.Header().Get("Location"))
+	}
+
+	yesterday := *DataBase.BookingDate(time.Now().AddDate(0, 0, -1))
+	outOfDay := DataBase.SlotCount
+	for _, tc := range []struct {
+		name    string
+		request BookingRequest
+		status  int
+	}{
+		{"Taken slot", BookingRequest{CourtID: court.Court_ID, Email: "ana@ufl.edu", SlotIndex: &slot, Date: tomorrow}, http.StatusConflict},
+		{"Missing slot", BookingRequest{CourtID: court.Court_ID, Email: "ana@ufl.edu"}, http.StatusBadRequest},
+		{"Slot outside the day", BookingRequest{CourtID: court.Court_ID, Email: "ana@ufl.edu", SlotIndex: &outOfDay}, http.StatusBadRequest},
+		{"Past date", BookingRequest{CourtID: court.Court_ID, Email: "ana@ufl.edu", SlotIndex: &slot, Date: yesterday}, http.StatusBadRequest},
+		{"Unknown court", BookingRequest{CourtID: 9, Email: "ana@ufl.edu", SlotIndex: &slot}, http.StatusUnprocessableEntity},
+		{"Unknown customer", BookingRequest{CourtID: court.Court_ID, Email: "bo@ufl.edu", SlotIndex: &slot}, http.StatusUnprocessableEntity},
+	} {
+		if rr := call(h, "POST", "/bookings", tc.request); rr.Code != tc.status {
+			t.Errorf("%s: expected status %d, got %d", tc.name, tc.status, rr.Code)
+		}
+	}
+
+	rr = call(h, "GET", "/customers/ana@ufl.edu/bookings", nil)
+	var bookings []BookingResource
+	json.Unmarshal(rr.Body.Bytes(), &bookings)
+	if len(bookings) != 1 || bookings[0].ID != booking.ID {
+		t.Errorf("expected the customer's booking, got %s", rr.Body.String())
+	}
+
+	if rr := call(h, "POST", "/bookings/1/cancel", CancelBookingRequest{Email: "bo@ufl.edu"}); rr.Code != http.StatusForbidden {
+		t.Errorf("expected another customer to be refused with 403, got %d", rr.Code)
+	}
+	rr = call(h, "POST", "/bookings/1/cancel", CancelBookingRequest{Email: "ANA@ufl.edu"})
+	json.Unmarshal(rr.Body.Bytes(), &booking)
+	if rr.Code != http.StatusOK || booking.Status != DataBase.BookingCancelledByUser {
+		t.Errorf("expected the booking to be cancelled, got %d %+v", rr.Code, booking)
+	}
+	if rr := call(h, "POST", "/bookings/1/cancel", CancelBookingRequest{Email: "ana@ufl.edu"}); rr.Code != http.StatusConflict {
+		t.Errorf("expected a second cancellation to fail with 409, got %d", rr.Code)
+	}
+	if rr := call(h, "GET", "/bookings/2", nil); rr.Code != http.StatusNotFound {
+		t.Errorf("expected an unknown booking to answer 404, got %d", rr.Code)
+	}
+}
+
+func TestListCustomerBookings_Archived(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	sport := DataBase.Sport{Sport_name: "Tennis"}
+	store.Sports().Create(&sport)
+	court := DataBase.Court{Court_Name: "Court 1", Court_Location: "Rec", Court_Status: 1, Sport_id: sport.Sport_ID}
+	store.Courts().Create(&court)
+	customer := DataBase.Customer{Email: "a@ufl.edu"}
+	store.Customers().Create(&customer)
+	for _, date := range []string{"2026-01-05", "2026-01-06", "2026-01-07"} {
+		store.Bookings().Create(&DataBase.Bookings{Customer_ID: customer.Customer_ID, Sport_ID: sport.Sport_ID, Court_ID: court.Court_ID,
+			Booking_Status: DataBase.BookingConfirmed, Booking_Date: &date})
+	}
+	store.Bookings().Archive("2026-02-01", "Rollover")
+
+	rr := call(h, "GET", "/customers/a@ufl.edu/bookings?archived=true&page_size=2", nil)
+	var bookings []BookingResource
+	json.Unmarshal(rr.Body.Bytes(), &bookings)
+	if rr.Header().Get("X-Total-Count") != "3" || len(bookings) != 2 || bookings[0].Date != "2026-01-07" ||
+		bookings[0].CourtName != "Court 1" || bookings[0].Status != DataBase.BookingCompleted {
+		t.Errorf("expected the newest page of the archive, got %s", rr.Body.String())
+	}
+
+	if rr := call(h, "GET", "/customers/a@ufl.edu/bookings?archived=true&page=0", nil); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected an invalid page to fail with 400, got %d", rr.Code)
+	}
+	if rr := call(h, "GET", "/customers/b@ufl.edu/bookings", nil); rr.Code != http.StatusNotFound {
+		t.Errorf("expected an unknown customer to answer 404, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,417 @@
+package V1
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/Availability"
+	"BackEnd/Caching"
+	"BackEnd/Court"
+	"BackEnd/DataBase"
+	"BackEnd/Events"
+	"BackEnd/Pagination"
+	"BackEnd/Repository"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CourtResource is a court as the API returns it. Status 1 means the court is open.
+type CourtResource struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	Capacity *int   `json:"capacity"`
+	Status   int    `json:"status"`
+	Indoor   bool   `json:"indoor"`
+	// Hours are when the court can be booked, such as 09:00-17:00, or empty for the whole day.
+	Hours   string `json:"hours"`
+	SportID uint   `json:"sport_id"`
+}
+
+// CourtRequest creates a court. Status defaults to 1, open.
+type CourtRequest struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	Capacity *int   `json:"capacity"`
+	Status   int    `json:"status"`
+	Indoor   bool   `json:"indoor"`
+	SportID  uint   `json:"sport_id"`
+}
+
+// CourtPatch changes the fields of a court that are sent. A null capacity clears it, and empty
+// hours open the court for the whole day.
+type CourtPatch struct {
+	Name     *string       `json:"name"`
+	Location *string       `json:"location"`
+	Capacity Nullable[int] `json:"capacity" swaggertype:"integer"`
+	Status   *int          `json:"status"`
+	Indoor   *bool         `json:"indoor"`
+	Hours    *string       `json:"hours"`
+	SportID  *uint         `json:"sport_id"`
+}
+
+// CourtAvailability lists the slots of a court on one date.
+type CourtAvailability struct {
+	CourtID uint   `json:"court_id"`
+	Date    string `json:"date"`
+	Slots   []Slot `json:"slots"`
+}
+
+// Slot is one slot of a court's day: available, booked, or unavailable when blacked out or
+// outside the court's hours.
+type Slot struct {
+	Index int    `json:"index"`
+	Label string `json:"label"`
+	State string `json:"state"`
+}
+
+var slotStates = map[int]string{
+	DataBase.SlotUnavailable: "unavailable",
+	DataBase.SlotAvailable:   "available",
+	DataBase.SlotBooked:      "booked",
+}
+
+func courtResource(c DataBase.Court) CourtResource {
+	return CourtResource{
+		ID:       c.Court_ID,
+		Name:     c.Court_Name,
+		Location: c.Court_Location,
+		Capacity: c.Court_Capacity,
+		Status:   c.Court_Status,
+		Indoor:   c.Court_Indoor,
+		Hours:    c.Court_Hours,
+		SportID:  c.Sport_id,
+	}
+}
+
+func courtResources(courts []DataBase.Court) []CourtResource {
+	resources := make([]CourtResource, len(courts))
+	for i, court := range courts {
+		resources[i] = courtResource(court)
+	}
+	return resources
+}
+
+// dateParam returns the date parameter formatted as a Booking_Date, or today's when it is
+// empty, writing the error response itself.
+func dateParam(w http.ResponseWriter, r *http.Request, date string) (string, bool) {
+	if date == "" {
+		return *DataBase.BookingDate(time.Now()), true
+	}
+	if _, err := time.Parse(DataBase.BookingDateLayout, date); err != nil {
+		APIError.Validation(w, r, "date must be formatted as YYYY-MM-DD", APIError.Field("date", "must be formatted as YYYY-MM-DD"))
+		return "", false
+	}
+	return date, true
+}
+
+// findCourt looks up the {id} court, writing the error response itself.
+func (h *Handler) findCourt(w http.ResponseWriter, r *http.Request) (DataBase.Court, bool) {
+	id, ok := pathID(w, r, "court")
+	if !ok {
+		return DataBase.Court{}, false
+	}
+	court, err := h.Store.Courts().FindByID(id)
+	if errors.Is(err, Repository.ErrNotFound) {
+		APIError.Write(w, r, http.StatusNotFound, APIError.CourtNotFound, "Court not found")
+		return DataBase.Court{}, false
+	} else if err != nil {
+		APIError.Internal(w, r, "Failed to fetch court", err)
+		return DataBase.Court{}, false
+	}
+	return court, true
+}
+
+// listCourts writes the page of courts matching filter that params ask for.
+func (h *Handler) listCourts(w http.ResponseWriter, r *http.Request, params *Pagination.Params, filter Repository.CourtFilter) {
+	q := params.List(Repository.CourtSorts)
+	if !params.Check(w, r) {
+		return
+	}
+	courts, next, err := h.Store.Courts().Page(filter, q)
+	var total int64
+	if err == nil {
+		total, err = h.Store.Courts().Count(filter)
+	}
+	if err != nil {
+		Pagination.Failed(w, r, err, "Failed to fetch courts")
+		return
+	}
+	Pagination.SetTotal(w, total)
+	Pagination.SetNext(w, r, q, next)
+	writeJSON(w, http.StatusOK, courtResources(courts))
+}
+
+// ListCourts godoc
+// @Summary      List courts
+// @Description  Lists the courts a page at a time. X-Total-Count gives the number of matching courts, and X-Next-Cursor and the Link header the next page.
+// @Tags         v1
+// @Produce      json
+// @Param        sport_id  query     int     false  "Only courts of this sport"
+// @Param        status    query     int     false  "Only courts with this status, 1 for open"
+// @Param        indoor    query     bool    false  "Only indoor courts when true, only outdoor ones when false"
+// @Param        limit     query     int     false  "Courts per page, at most 500"  default(100)
+// @Param        cursor    query     string  false  "Cursor of the page, from X-Next-Cursor"
+// @Param        sort      query     string  false  "id, name, location, status or sport_id, prefixed with - for descending order"  default(id)
+// @Success      200       {array}   CourtResource
+// @Failure      400       {object}  DataBase.ErrorResponse  "Invalid list parameters"
+// @Failure      401       {object}  DataBase.ErrorResponse  "Missing or invalid token"
+// @Failure      500       {object}  DataBase.ErrorResponse  "Failed to fetch courts"
+// @Router       /api/v1/courts [get]
+func (h *Handler) ListCourts(w http.ResponseWriter, r *http.Request) {
+	params := Pagination.New(r)
+	h.listCourts(w, r, params, Repository.CourtFilter{SportID: params.ID("sport_id"), Status: params.Int("status"), Indoor: params.Bool("indoor")})
+}
+
+// CreateCourt godoc
+// @Summary      Create a court
+// @Description  Adds a court to a sport. Every court has the same daily slots, open for booking unless blacked out.
+// @Tags         v1
+// @Accept       json
+// @Produce      json
+// @Param        court  body      CourtRequest  true  "Court"
+// @Success      201    {object}  CourtResource
+// @Header       201    {string}  Location  "Address of the new court"
+// @Failure      400    {object}  DataBase.ErrorResponse  "Invalid request body or missing name"
+// @Failure      401    {object}  DataBase.ErrorResponse  "Missing or invalid admin session"
+// @Failure      409    {object}  DataBase.ErrorResponse  "A court with that name already exists"
+// @Failure      422    {object}  DataBase.ErrorResponse  "Sport not found"
+// @Failure      500    {object}  DataBase.ErrorResponse  "Failed to create court"
+// @Router       /api/v1/courts [post]
+func (h *Handler) CreateCourt(w http.ResponseWriter, r *http.Request) {
+	var req CourtRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		APIError.Validation(w, r, "name is required", APIError.Field("name", "is required"))
+		return
+	}
+	if _, err := h.Store.Sports().FindByID(req.SportID); err != nil {
+		APIError.Write(w, r, http.StatusUnprocessableEntity, APIError.SportNotFound, "Sport not found")
+		return
+	}
+
+	court := DataBase.Court{
+		Court_Name:     req.Name,
+		Court_Location: req.Location,
+		Court_Capacity: req.Capacity,
+		Court_Status:   req.Status,
+		Court_Indoor:   req.Indoor,
+		Sport_id:       req.SportID,
+	}
+	err := Court.Create(h.Store, &court)
+	if errors.Is(err, Repository.ErrDuplicate) {
+		APIError.Write(w, r, http.StatusConflict, APIError.CourtAlreadyExists, "A court with that name already exists")
+		return
+	} else if err != nil {
+		APIError.Internal(w, r, "Failed to create court", err)
+		return
+	}
+	writeCreated(w, fmt.Sprintf("/courts/%d", court.Court_ID), courtResource(court))
+}
+
+// GetCourt godoc
+// @Summary      Get a court
+// @Tags         v1
+// @Produce      json
+// @Param        id   path      int  true  "Court ID"
+// @Success      200  {object}  CourtResource
+// @Failure      400  {object}  DataBase.ErrorResponse  "Invalid court ID"
+// @Failure      401  {object}  DataBase.ErrorResponse  "Missing or invalid token"
+// @Failure      404  {object}  DataBase.ErrorResponse  "Court not found"
+// @Router       /api/v1/courts/{id} [get]
+func (h *Handler) GetCourt(w http.ResponseWriter, r *http.Request) {
+	if court, ok := h.findCourt(w, r); ok {
+		writeJSON(w, http.StatusOK, courtResource(court))
+	}
+}
+
+// UpdateCourt godoc
+// @Summary      Update a court
+// @Description  Changes the fields of a court that are sent and leaves the others as they are; a null capacity clears it and empty hours open the court all day. Hours such as 09:00-17:00 must open when a slot starts and close when one ends, and bookings already made outside them are kept. The court keeps its bookings, which move with it to a new sport.
+// @Tags         v1
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int         true  "Court ID"
+// @Param        court  body      CourtPatch  true  "Fields to change"
+// @Success      200    {object}  CourtResource
+// @Failure      400    {object}  DataBase.ErrorResponse  "Invalid court ID, request body or fields"
+// @Failure      401    {object}  DataBase.ErrorResponse  "Missing or invalid admin session"
+// @Failure      404    {object}  DataBase.ErrorResponse  "Court not found"
+// @Failure      409    {object}  DataBase.ErrorResponse  "A court with that name already exists"
+// @Failure      422    {object}  DataBase.ErrorResponse  "Sport not found"
+// @Failure      500    {object}  DataBase.ErrorResponse  "Failed to update court"
+// @Router       /api/v1/courts/{id} [patch]
+func (h *Handler) UpdateCourt(w http.ResponseWriter, r *http.Request) {
+	court, ok := h.findCourt(w, r)
+	if !ok {
+		return
+	}
+	var req CourtPatch
+	if !decode(w, r, &req) {
+		return
+	}
+
+	var details []DataBase.FieldError
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			details = append(details, APIError.Field("name", "must not be blank"))
+		}
+		court.Court_Name = *req.Name
+	}
+	if req.Location != nil {
+		if strings.TrimSpace(*req.Location) == "" {
+			details = append(details, APIError.Field("location", "must not be blank"))
+		}
+		court.Court_Location = *req.Location
+	}
+	if req.Capacity.Set {
+		if req.Capacity.Value != nil && *req.Capacity.Value < 1 {
+			details = append(details, APIError.Field("capacity", "must be at least 1"))
+		}
+		court.Court_Capacity = req.Capacity.Value
+	}
+	if req.Status != nil {
+		if *req.Status != 0 && *req.Status != 1 {
+			details = append(details, APIError.Field("status", "must be 0 for closed or 1 for open"))
+		}
+		court.Court_Status = *req.Status
+	}
+	if req.Indoor != nil {
+		court.Court_Indoor = *req.Indoor
+	}
+	if req.Hours != nil {
+		court.Court_Hours = ""
+		if *req.Hours != "" {
+			hours, err := Availability.ParseHours(*req.Hours)
+			if err != nil {
+				details = append(details, APIError.Field("hours", err.Error()))
+			}
+			court.Court_Hours = hours
+		}
+	}
+	if len(details) > 0 {
+		APIError.Validation(w, r, "Invalid court fields", details...)
+		return
+	}
+	if req.SportID != nil && *req.SportID != court.Sport_id {
+		if _, err := h.Store.Sports().FindByID(*req.SportID); errors.Is(err, Repository.ErrNotFound) {
+			APIError.Write(w, r, http.StatusUnprocessableEntity, APIError.SportNotFound, "Sport not found")
+			return
+		} else if err != nil {
+			APIError.Internal(w, r, "Failed to fetch sport", err)
+			return
+		}
+		court.Sport_id = *req.SportID
+	}
+
+	court.Sport = nil
+	err := h.Store.Courts().Update(&court)
+	switch {
+	case errors.Is(err, Repository.ErrDuplicate):
+		APIError.Write(w, r, http.StatusConflict, APIError.CourtAlreadyExists, "A court with that name already exists")
+	case errors.Is(err, Repository.ErrNotFound):
+		APIError.Write(w, r, http.StatusNotFound, APIError.CourtNotFound, "Court not found")
+	case err != nil:
+		APIError.Internal(w, r, "Failed to update court", err)
+	default:
+		writeJSON(w, http.StatusOK, courtResource(court))
+	}
+}
+
+// DeleteCourt godoc
+// @Summary      Delete a court
+// @Description  Removes the court's blackouts and soft deletes the court together with its bookings; an admin can restore them until they are purged.
+// @Tags         v1
+// @Param        id   path  int  true  "Court ID"
+// @Success      204
+// @Failure      400  {object}  DataBase.ErrorResponse  "Invalid court ID"
+// @Failure      401  {object}  DataBase.ErrorResponse  "Missing or invalid admin session"
+// @Failure      404  {object}  DataBase.ErrorResponse  "Court not found"
+// @Failure      500  {object}  DataBase.ErrorResponse  "Failed to delete court"
+// @Router       /api/v1/courts/{id} [delete]
+func (h *Handler) DeleteCourt(w http.ResponseWriter, r *http.Request) {
+	court, ok := h.findCourt(w, r)
+	if !ok {
+		return
+	}
+	if err := Court.Delete(h.Store, court.Court_ID); err != nil {
+		APIError.Internal(w, r, "Failed to delete court", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetCourtAvailability godoc
+// @Summary      Get a court's availability
+// @Description  Lists the court's slots on a date, today by default, as available, booked, or unavailable when blacked out.
+// @Description  The response carries an ETag; send it back in If-None-Match to get 304 while nothing has changed.
+// @Tags         v1
+// @Produce      json
+// @Param        id             path      int     true   "Court ID"
+// @Param        date           query     string  false  "Date, YYYY-MM-DD"  example(2026-01-31)
+// @Param        If-None-Match  header    string  false  "ETag of the availability already held"
+// @Success      200   {object}  CourtAvailability
+// @Success      304   "Availability has not changed since the ETag in If-None-Match"
+// @Failure      400   {object}  DataBase.ErrorResponse  "Invalid court ID or date"
+// @Failure      401   {object}  DataBase.ErrorResponse  "Missing or invalid token"
+// @Failure      404   {object}  DataBase.ErrorResponse  "Court not found"
+// @Failure      500   {object}  DataBase.ErrorResponse  "Failed to load court availability"
+// @Router       /api/v1/courts/{id}/availability [get]
+func (h *Handler) GetCourtAvailability(w http.ResponseWriter, r *http.Request) {
+	court, ok := h.findCourt(w, r)
+	if !ok {
+		return
+	}
+	date, ok := dateParam(w, r, r.URL.Query().Get("date"))
+	if !ok {
+		return
+	}
+	sport, err := h.Store.Sports().FindByID(court.Sport_id)
+	if err != nil {
+		APIError.Internal(w, r, "Failed to load court availability", err)
+		return
+	}
+	if Caching.NotModified(w, r, Availability.ETag(sport, date)) {
+		return
+	}
+
+	courts, err := Court.WithAvailability(h.Store, []DataBase.Court{court}, date)
+	if err != nil {
+		APIError.Internal(w, r, "Failed to load court availability", err)
+		return
+	}
+
+	availability := CourtAvailability{CourtID: court.Court_ID, Date: date, Slots: []Slot{}}
+	for index, state := range courts[0].Slots {
+		availability.Slots = append(availability.Slots, Slot{Index: index, Label: DataBase.SlotLabel(index), State: slotStates[state]})
+	}
+	writeJSON(w, http.StatusOK, availability)
+}
+
+// ResetCourt godoc
+// @Summary      Reset a court
+// @Description  Cancels every active booking on the court on behalf of an admin, freeing all of its slots.
+// @Tags         v1
+// @Param        id   path  int  true  "Court ID"
+// @Success      204
+// @Failure      400  {object}  DataBase.ErrorResponse  "Invalid court ID"
+// @Failure      401  {object}  DataBase.ErrorResponse  "Missing or invalid admin session"
+// @Failure      404  {object}  DataBase.ErrorResponse  "Court not found"
+// @Failure      500  {object}  DataBase.ErrorResponse  "Failed to reset court"
+// @Router       /api/v1/courts/{id}/reset [post]
+func (h *Handler) ResetCourt(w http.ResponseWriter, r *http.Request) {
+	court, ok := h.findCourt(w, r)
+	if !ok {
+		return
+	}
+	err := h.Store.Bookings().CancelActiveForCourts([]uint{court.Court_ID}, DataBase.BookingCancelledByAdmin, "Court slots reset")
+	if err != nil {
+		APIError.Internal(w, r, "Failed to reset court", err)
+		return
+	}
+	h.Events.Publish(Events.ForCourt(Events.Reset, court))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,187 @@
+package V1
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestCourts(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	sport := DataBase.Sport{Sport_name: "Basketball"}
+	store.Sports().Create(&sport)
+
+	if rr := call(h, "POST", "/courts", CourtRequest{Name: "Court A", SportID: 9}); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected an unknown sport to fail with 422, got %d", rr.Code)
+	}
+
+	rr := call(h, "POST", "/courts", CourtRequest{Name: "Court A", Location: "Southwest", SportID: sport.Sport_ID})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created CourtResource
+	json.Unmarshal(rr.Body.Bytes(), &created)
+	if created.Status != 1 || rr.Header().Get("Location") != "/api/v1/courts/1" {
+		t.Errorf("expected an open court at /api/v1/courts/1, got %+v at %q", created, rr.Header().Get("Location"))
+	}
+	if rr := call(h, "POST", "/courts", CourtRequest{Name: "Court A", SportID: sport.Sport_ID}); rr.Code != http.StatusConflict {
+		t.Errorf("expected a duplicate name to fail with 409, got %d", rr.Code)
+	}
+
+	rr = call(h, "GET", "/courts", nil)
+	var courts []CourtResource
+	json.Unmarshal(rr.Body.Bytes(), &courts)
+	if len(courts) != 1 || courts[0] != created {
+		t.Errorf("expected the created court, got %s", rr.Body.String())
+	}
+
+	if rr := call(h, "DELETE", "/courts/1", nil); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected delete to answer 204, got %d", rr.Code)
+	}
+	if rr := call(h, "GET", "/courts/1", nil); rr.Code != http.StatusNotFound {
+		t.Errorf("expected the deleted court to be gone, got %d", rr.Code)
+	}
+}
+
+func TestUpdateCourt(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	tennis := DataBase.Sport{Sport_name: "Tennis"}
+	padel := DataBase.Sport{Sport_name: "Padel"}
+	store.Sports().Create(&tennis)
+	store.Sports().Create(&padel)
+	capacity := 4
+	court := DataBase.Court{Court_Name: "Court 1", Court_Location: "Rec", Court_Capacity: &capacity, Court_Status: 1, Sport_id: tennis.Sport_ID}
+	store.Courts().Create(&court)
+	store.Courts().Create(&DataBase.Court{Court_Name: "Court 2", Court_Location: "Rec", Court_Status: 1, Sport_id: tennis.Sport_ID})
+	customer := DataBase.Customer{Email: "a@ufl.edu"}
+	store.Customers().Create(&customer)
+	booking := DataBase.Bookings{Customer_ID: customer.Customer_ID, Sport_ID: tennis.Sport_ID, Court_ID: court.Court_ID, Booking_Time: 3,
+		Booking_Date: DataBase.BookingDate(time.Now()), Booking_Status: DataBase.BookingConfirmed}
+	store.Bookings().Create(&booking)
+
+	rr := call(h, "PATCH", "/courts/1", json.RawMessage(`{"name":"Centre Court","indoor":true,"sport_id":2}`))
+	var updated CourtResource
+	json.Unmarshal(rr.Body.Bytes(), &updated)
+	want := CourtResource{ID: 1, Name: "Centre Court", Location: "Rec", Capacity: updated.Capacity, Status: 1, Indoor: true, SportID: padel.Sport_ID}
+	if rr.Code != http.StatusOK || updated != want || updated.Capacity == nil || *updated.Capacity != 4 {
+		t.Errorf("expected only the fields sent to change, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	// The booking stays, on the renamed court and under its new sport
+	moved, _ := store.Bookings().FindByID(booking.Booking_ID)
+	if moved.Court_ID != court.Court_ID || moved.Sport_ID != padel.Sport_ID || moved.Booking_Status != DataBase.BookingConfirmed {
+		t.Errorf("expected the booking to be kept under the new sport, got %+v", moved)
+	}
+	rr = call(h, "GET", "/courts/1/availability", nil)
+	var availability CourtAvailability
+	json.Unmarshal(rr.Body.Bytes(), &availability)
+	if len(availability.Slots) < 4 || availability.Slots[3].State != "booked" {
+		t.Errorf("expected the booked slot to stay booked, got %s", rr.Body.String())
+	}
+
+	if rr := call(h, "PATCH", "/courts/1", json.RawMessage(`{"capacity":null,"status":0}`)); rr.Code != http.StatusOK {
+		t.Errorf("expected capacity to be cleared, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if saved, _ := store.Courts().FindByID(1); saved.Court_Capacity != nil || saved.Court_Status != 0 || saved.Court_Name != "Centre Court" {
+		t.Errorf("expected a closed court without capacity, got %+v", saved)
+	}
+
+	// Hours are kept through other changes and cleared by empty hours
+	if rr := call(h, "PATCH", "/courts/1", json.RawMessage(`{"hours":"10:00-14:00"}`)); rr.Code != http.StatusOK ||
+		!strings.Contains(rr.Body.String(), `"hours":"10:00-14:00"`) {
+		t.Errorf("expected the court's hours set, got %d: %s", rr.Code, rr.Body.String())
+	}
+	call(h, "PATCH", "/courts/1", json.RawMessage(`{"location":"Rec"}`))
+	if saved, _ := store.Courts().FindByID(1); saved.Court_Hours != "10:00-14:00" {
+		t.Errorf("expected the hours kept, got %q", saved.Court_Hours)
+	}
+	call(h, "PATCH", "/courts/1", json.RawMessage(`{"hours":""}`))
+	if saved, _ := store.Courts().FindByID(1); saved.Court_Hours != "" {
+		t.Errorf("expected the hours cleared, got %q", saved.Court_Hours)
+	}
+
+	for _, tc := range []struct {
+		path, body string
+		status     int
+	}{
+		{"/courts/1", `{"name":"Court 2"}`, http.StatusConflict},
+		{"/courts/1", `{"hours":"10:30-14:00"}`, http.StatusBadRequest},
+		{"/courts/1", `{"name":" ","location":"","capacity":0,"status":5}`, http.StatusBadRequest},
+		{"/courts/1", `{"capacity":"four"}`, http.StatusBadRequest},
+		{"/courts/1", `{"sport_id":9}`, http.StatusUnprocessableEntity},
+		{"/courts/9", `{"name":"Court 9"}`, http.StatusNotFound},
+	} {
+		if rr := call(h, "PATCH", tc.path, json.RawMessage(tc.body)); rr.Code != tc.status {
+			t.Errorf("PATCH %s %s: expected status %d, got %d", tc.path, tc.body, tc.status, rr.Code)
+		}
+	}
+	rr = call(h, "PATCH", "/courts/1", json.RawMessage(`{"name":" ","location":"","capacity":0,"status":5}`))
+	var response DataBase.ErrorResponse
+	json.Unmarshal(rr.Body.Bytes(), &response)
+	if len(response.Details) != 4 {
+		t.Errorf("expected every invalid field reported, got %+v", response.Details)
+	}
+}
+
+func TestGetCourtAvailability(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	sport := DataBase.Sport{Sport_name: "Tennis"}
+	store.Sports().Create(&sport)
+	court := DataBase.Court{Court_Name: "Court 1", Court_Location: "Rec", Court_Status: 1, Sport_id: sport.Sport_ID}
+	store.Courts().Create(&court)
+	customer := DataBase.Customer{Email: "a@ufl.edu"}
+	store.Customers().Create(&customer)
+
+	tomorrow := *DataBase.BookingDate(time.Now().AddDate(0, 0, 1))
+	store.Bookings().Create(&DataBase.Bookings{Customer_ID: customer.Customer_ID, Sport_ID: sport.Sport_ID, Court_ID: court.Court_ID,
+		Booking_Status: DataBase.BookingConfirmed, Booking_Time: 2, Booking_Date: &tomorrow})
+	slot := 5
+	store.Blackouts().Create(&DataBase.Court_Blackout{Court_ID: court.Court_ID, Blackout_Date: tomorrow, Slot_Index: &slot})
+
+	rr := call(h, "GET", "/courts/1/availability?date="+tomorrow, nil)
+	var availability CourtAvailability
+	json.Unmarshal(rr.Body.Bytes(), &availability)
+	if rr.Code != http.StatusOK || availability.Date != tomorrow || len(availability.Slots) != DataBase.SlotCount {
+		t.Fatalf("expected every slot of %s, got %d %s", tomorrow, rr.Code, rr.Body.String())
+	}
+	want := Slot{Index: 2, Label: DataBase.SlotLabels[2], State: "booked"}
+	if availability.Slots[2] != want || availability.Slots[5].State != "unavailable" || availability.Slots[0].State != "available" {
+		t.Errorf("unexpected slots %+v", availability.Slots)
+	}
+
+	// Today is free
+	rr = call(h, "GET", "/courts/1/availability", nil)
+	json.Unmarshal(rr.Body.Bytes(), &availability)
+	if availability.Date != *DataBase.BookingDate(time.Now()) || availability.Slots[2].State != "available" {
+		t.Errorf("expected today's slots to be free, got %+v", availability)
+	}
+
+	// Revalidating with the ETag of unchanged availability answers 304
+	etag := rr.Header().Get("ETag")
+	r := mux.NewRouter()
+	h.Routes(r, open, open)
+	req := httptest.NewRequest("GET", Prefix+"/courts/1/availability", nil)
+	req.Header.Set("If-None-Match", etag)
+	revalidated := httptest.NewRecorder()
+	r.ServeHTTP(revalidated, req)
+	if etag == "" || revalidated.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for ETag %q, got %d", etag, revalidated.Code)
+	}
+
+	if rr := call(h, "GET", "/courts/1/availability?date=31/01/2026", nil); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected a malformed date to fail with 400, got %d", rr.Code)
+	}
+}
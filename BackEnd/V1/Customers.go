@@ -0,0 +1,191 @@
+package V1
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/Bookings"
+	"BackEnd/DataBase"
+	"BackEnd/Pagination"
+	"BackEnd/Repository"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// CustomerResource is a customer as the API returns it. Customers are addressed by email.
+type CustomerResource struct {
+	ID      uint   `json:"id"`
+	Email   string `json:"email"`
+	Name    string `json:"name"`
+	UFID    string `json:"ufid"`
+	Contact string `json:"contact"`
+}
+
+// CustomerRequest creates or updates a customer's profile. Empty fields keep the stored values.
+type CustomerRequest struct {
+	Name    string `json:"name"`
+	UFID    string `json:"ufid"`
+	Contact string `json:"contact"`
+}
+
+func customerResource(c DataBase.Customer) CustomerResource {
+	return CustomerResource{ID: c.Customer_ID, Email: c.Email, Name: c.Name, UFID: c.UFID, Contact: c.Contact}
+}
+
+// pathEmail returns the normalized {email} route variable.
+func pathEmail(r *http.Request) string {
+	return strings.ToLower(strings.TrimSpace(mux.Vars(r)["email"]))
+}
+
+// findCustomer looks up the {email} customer, writing the error response itself.
+func (h *Handler) findCustomer(w http.ResponseWriter, r *http.Request) (DataBase.Customer, bool) {
+	customer, err := h.Store.Customers().FindByEmail(pathEmail(r))
+	if errors.Is(err, Repository.ErrNotFound) {
+		APIError.Write(w, r, http.StatusNotFound, APIError.CustomerNotFound, "Customer not found")
+		return DataBase.Customer{}, false
+	} else if err != nil {
+		APIError.Internal(w, r, "Failed to fetch customer", err)
+		return DataBase.Customer{}, false
+	}
+	return customer, true
+}
+
+// GetCustomer godoc
+// @Summary      Get a customer
+// @Tags         v1
+// @Produce      json
+// @Param        email  path      string  true  "Customer email"
+// @Success      200    {object}  CustomerResource
+// @Failure      401    {object}  DataBase.ErrorResponse  "Missing or invalid token"
+// @Failure      404    {object}  DataBase.ErrorResponse  "Customer not found"
+// @Router       /api/v1/customers/{email} [get]
+func (h *Handler) GetCustomer(w http.ResponseWriter, r *http.Request) {
+	if customer, ok := h.findCustomer(w, r); ok {
+		writeJSON(w, http.StatusOK, customerResource(customer))
+	}
+}
+
+// PutCustomer godoc
+// @Summary      Create or update a customer
+// @Description  Creates the customer with this email, or updates the name and UFID of an existing one. Empty fields keep the stored values.
+// @Tags         v1
+// @Accept       json
+// @Produce      json
+// @Param        email     path      string           true  "Customer email"
+// @Param        customer  body      CustomerRequest  true  "Profile"
+// @Success      200       {object}  CustomerResource  "Customer updated"
+// @Success      201       {object}  CustomerResource  "Customer created"
+// @Failure      400       {object}  DataBase.ErrorResponse  "Invalid email or request body"
+// @Failure      401       {object}  DataBase.ErrorResponse  "Missing or invalid token"
+// @Failure      500       {object}  DataBase.ErrorResponse  "Failed to save customer"
+// @Router       /api/v1/customers/{email} [put]
+func (h *Handler) PutCustomer(w http.ResponseWriter, r *http.Request) {
+	email := pathEmail(r)
+	if !strings.Contains(email, "@") {
+		APIError.Validation(w, r, "Invalid email", APIError.Field("email", "must be an email address"))
+		return
+	}
+	var req CustomerRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	existing, err := h.Store.Customers().FindByEmail(email)
+	if err == nil {
+		if err := h.Store.Customers().UpdateProfile(existing.Customer_ID, req.Name, req.UFID); err != nil {
+			APIError.Internal(w, r, "Failed to save customer", err)
+			return
+		}
+		if existing, err = h.Store.Customers().FindByID(existing.Customer_ID); err != nil {
+			APIError.Internal(w, r, "Failed to fetch customer", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, customerResource(existing))
+		return
+	} else if !errors.Is(err, Repository.ErrNotFound) {
+		APIError.Internal(w, r, "Failed to fetch customer", err)
+		return
+	}
+
+	customer := DataBase.Customer{Email: email, Name: req.Name, UFID: req.UFID, Contact: req.Contact}
+	if err := h.Store.Customers().Create(&customer); err != nil {
+		APIError.Internal(w, r, "Failed to save customer", err)
+		return
+	}
+	writeCreated(w, "/customers/"+email, customerResource(customer))
+}
+
+// ListCustomerBookings godoc
+// @Summary      List a customer's bookings
+// @Description  Lists the customer's current bookings a page at a time; X-Next-Cursor and the Link header give the next page. With **archived=true** the archive of past bookings is listed instead, newest first and paged with **page** and **page_size**; only **from** and **to** filter it. The X-Total-Count header gives the number of matches.
+// @Tags         v1
+// @Produce      json
+// @Param        email      path   string  true   "Customer email"
+// @Param        archived   query  bool    false  "List archived past bookings instead"
+// @Param        from       query  string  false  "Earliest booking date, inclusive"  example(2026-01-01)
+// @Param        to         query  string  false  "Latest booking date, inclusive"  example(2026-01-31)
+// @Param        sport_id   query  int     false  "Only bookings for this sport"
+// @Param        court_id   query  int     false  "Only bookings on this court"
+// @Param        status     query  string  false  "Only bookings in these statuses, comma separated"  example(confirmed,checked_in)
+// @Param        limit      query  int     false  "Current bookings per page, at most 500"  default(100)
+// @Param        cursor     query  string  false  "Cursor of the page, from X-Next-Cursor"
+// @Param        sort       query  string  false  "id, date, slot, status, sport_id, court_id or customer_id, prefixed with - for descending order"  default(id)
+// @Param        page       query  int     false  "Archive page, starting at 1"  default(1)
+// @Param        page_size  query  int     false  "Archived bookings per page, at most 100"  default(20)
+// @Success      200        {array}   BookingResource
+// @Failure      400        {object}  DataBase.ErrorResponse  "Invalid list or archive parameter"
+// @Failure      401        {object}  DataBase.ErrorResponse  "Missing or invalid token"
+// @Failure      404        {object}  DataBase.ErrorResponse  "Customer not found"
+// @Failure      500        {object}  DataBase.ErrorResponse  "Failed to fetch bookings"
+// @Router       /api/v1/customers/{email}/bookings [get]
+func (h *Handler) ListCustomerBookings(w http.ResponseWriter, r *http.Request) {
+	customer, ok := h.findCustomer(w, r)
+	if !ok {
+		return
+	}
+
+	resources := []BookingResource{}
+	if archived, _ := strconv.ParseBool(r.URL.Query().Get("archived")); archived {
+		from, to, offset, limit, err := Bookings.ArchivePage(r.URL.Query())
+		if err != nil {
+			APIError.Validation(w, r, err.Error())
+			return
+		}
+		bookings, total, err := h.Store.Bookings().ListArchived(customer.Customer_ID, from, to, offset, limit)
+		if err != nil {
+			APIError.Internal(w, r, "Failed to fetch bookings", err)
+			return
+		}
+		for _, booking := range bookings {
+			resources = append(resources, archivedBookingResource(booking))
+		}
+		w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+		writeJSON(w, http.StatusOK, resources)
+		return
+	}
+
+	params := Pagination.New(r)
+	filter := Bookings.Filter(params)
+	filter.CustomerID = customer.Customer_ID
+	q := params.List(Repository.BookingSorts)
+	if !params.Check(w, r) {
+		return
+	}
+	bookings, next, err := h.Store.Bookings().Page(filter, q)
+	var total int64
+	if err == nil {
+		total, err = h.Store.Bookings().Count(filter)
+	}
+	if err != nil {
+		Pagination.Failed(w, r, err, "Failed to fetch bookings")
+		return
+	}
+	for _, booking := range bookings {
+		resources = append(resources, bookingResource(booking))
+	}
+	Pagination.SetTotal(w, total)
+	Pagination.SetNext(w, r, q, next)
+	writeJSON(w, http.StatusOK, resources)
+}
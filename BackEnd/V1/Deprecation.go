@@ -0,0 +1,23 @@
+package V1
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// LegacyDeprecatedAt is when the routes outside /api/v1 were deprecated.
+var LegacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// Deprecated wraps a legacy route so that every response says it is deprecated (RFC 9745) and,
+// when successor is not empty, links the /api/v1 resource that replaces it.
+func Deprecated(successor string, next http.HandlerFunc) http.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(LegacyDeprecatedAt.Unix(), 10)
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", deprecation)
+		if successor != "" {
+			w.Header().Set("Link", "<"+Prefix+successor+`>; rel="successor-version"`)
+		}
+		next(w, r)
+	}
+}
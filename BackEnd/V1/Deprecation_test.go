@@ -0,0 +1,32 @@
+package V1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDeprecated(t *testing.T) {
+	legacy := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}
+
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/ListSports", nil)
+	Deprecated("/sports", legacy)(rr, req)
+	if rr.Code != http.StatusTeapot {
+		t.Errorf("expected the legacy handler to answer, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Deprecation"); got != "@1792368000" {
+		t.Errorf("expected the deprecation date, got %q", got)
+	}
+	if got := rr.Header().Get("Link"); got != `</api/v1/sports>; rel="successor-version"` {
+		t.Errorf("expected a link to the successor, got %q", got)
+	}
+
+	rr = httptest.NewRecorder()
+	Deprecated("", legacy)(rr, req)
+	if rr.Header().Get("Deprecation") == "" || rr.Header().Get("Link") != "" {
+		t.Errorf("expected only the deprecation header, got %v", rr.Header())
+	}
+}
@@ -0,0 +1,128 @@
+// Package V1 serves the versioned API under /api/v1. Sports, courts, bookings and customers are
+// resources addressed by path, changed with the matching HTTP verbs and answered with the
+// matching status codes. The older routes share the same operations and are kept as deprecated
+// adapters until clients have moved over.
+package V1
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/Availability"
+	"BackEnd/Events"
+	"BackEnd/Repository"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Handler serves the /api/v1 endpoints using the injected repositories.
+type Handler struct {
+	Store Repository.Store
+	// Alternatives bounds the free slots offered when a slot cannot be booked.
+	Alternatives Availability.AlternativeOptions
+	// Events receives every change to availability and feeds the availability stream.
+	Events *Events.Broker
+	// Heartbeat is how often an idle availability stream sends a comment.
+	Heartbeat time.Duration
+}
+
+// NewHandler returns a Handler backed by the given store.
+func NewHandler(store Repository.Store) *Handler {
+	return &Handler{Store: store, Alternatives: Availability.DefaultAlternatives,
+		Events: Events.NewBroker(Events.DefaultBacklog), Heartbeat: DefaultHeartbeat}
+}
+
+// Prefix is where the API is mounted; Location headers and links point below it.
+const Prefix = "/api/v1"
+
+// Middleware wraps the handler of a route, for example to check who is calling.
+type Middleware func(http.Handler) http.Handler
+
+// Routes registers every endpoint on r below Prefix. Every endpoint goes through authenticate,
+// except the ones that change sports and courts, which go through admin instead. They are
+// registered on r itself rather than on a subrouter, which would answer 404 instead of 405 to
+// an unsupported method.
+func (h *Handler) Routes(r *mux.Router, authenticate, admin Middleware) {
+	route := func(path string, f http.HandlerFunc, method string) {
+		r.Handle(Prefix+path, authenticate(f)).Methods(method)
+	}
+	adminRoute := func(path string, f http.HandlerFunc, method string) {
+		r.Handle(Prefix+path, admin(f)).Methods(method)
+	}
+
+	route("/sports", h.ListSports, "GET")
+	adminRoute("/sports", h.CreateSport, "POST")
+	route("/sports/{id}", h.GetSport, "GET")
+	adminRoute("/sports/{id}", h.UpdateSport, "PATCH")
+	adminRoute("/sports/{id}", h.DeleteSport, "DELETE")
+	route("/sports/{id}/courts", h.ListSportCourts, "GET")
+	adminRoute("/sports/{id}/reset", h.ResetSport, "POST")
+
+	route("/courts", h.ListCourts, "GET")
+	adminRoute("/courts", h.CreateCourt, "POST")
+	route("/courts/{id}", h.GetCourt, "GET")
+	adminRoute("/courts/{id}", h.UpdateCourt, "PATCH")
+	adminRoute("/courts/{id}", h.DeleteCourt, "DELETE")
+	route("/courts/{id}/availability", h.GetCourtAvailability, "GET")
+	adminRoute("/courts/{id}/reset", h.ResetCourt, "POST")
+
+	route("/availability/search", h.SearchAvailability, "GET")
+	route("/availability/stream", h.StreamAvailability, "GET")
+
+	route("/bookings", h.CreateBooking, "POST")
+	route("/bookings/{id}", h.GetBooking, "GET")
+	route("/bookings/{id}/cancel", h.CancelBooking, "POST")
+
+	route("/customers/{email}", h.GetCustomer, "GET")
+	route("/customers/{email}", h.PutCustomer, "PUT")
+	route("/customers/{email}/bookings", h.ListCustomerBookings, "GET")
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeCreated answers 201 with the new resource and its address below Prefix.
+func writeCreated(w http.ResponseWriter, path string, body interface{}) {
+	w.Header().Set("Location", Prefix+path)
+	writeJSON(w, http.StatusCreated, body)
+}
+
+// decode reads the JSON request body into v, writing the error response itself.
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		APIError.Malformed(w, r, "Invalid request body")
+		return false
+	}
+	return true
+}
+
+// Nullable is a PATCH field that can be cleared: Set reports whether the field was sent at all,
+// and Value is nil when it was sent as null.
+type Nullable[T any] struct {
+	Set   bool
+	Value *T
+}
+
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Set, n.Value = true, nil
+	if string(data) == "null" {
+		return nil
+	}
+	n.Value = new(T)
+	return json.Unmarshal(data, n.Value)
+}
+
+// pathID parses the {id} route variable of a resource, writing the error response itself.
+func pathID(w http.ResponseWriter, r *http.Request, resource string) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id == 0 {
+		APIError.Malformed(w, r, "Invalid "+resource+" ID")
+		return 0, false
+	}
+	return uint(id), true
+}
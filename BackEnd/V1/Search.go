@@ -0,0 +1,222 @@
+package V1
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/Availability"
+	"BackEnd/DataBase"
+	"BackEnd/Pagination"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SearchMatch is a run of free slots on one court and date, long enough for the search.
+type SearchMatch struct {
+	Rank      int    `json:"rank"`
+	CourtID   uint   `json:"court_id"`
+	CourtName string `json:"court_name"`
+	Location  string `json:"location"`
+	Indoor    bool   `json:"indoor"`
+	SportID   uint   `json:"sport_id"`
+	SportName string `json:"sport_name"`
+	Date      string `json:"date"`
+	Start     string `json:"start"`
+	End       string `json:"end"`
+	Slots     []int  `json:"slots"`
+}
+
+const (
+	// defaultSearchDays is how many days a search covers when it gives no end date.
+	defaultSearchDays = 7
+	// maxSearchDays is the longest range of dates one search may cover.
+	maxSearchDays = 31
+	// defaultSearchLimit and maxSearchLimit bound the number of matches returned.
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// SearchAvailability godoc
+// @Summary      Search free courts
+// @Description  Finds free time across courts and dates, such as any basketball or volleyball court free for 2 hours between 17:00 and 19:00 on a Friday, indoors only. A match is a run of back to back free slots on an open court that starts at **start** or later, ends by **end** and lasts at least **duration**. Matches are ranked by date, then start time, then the order of **sports**, and X-Total-Count gives how many there are before **limit**.
+// @Tags         v1
+// @Produce      json
+// @Param        sports    query     string  false  "Sport names, comma separated, the preferred first; every sport by default"  example(Basketball,Volleyball)
+// @Param        from      query     string  false  "First date searched, today by default"  example(2026-01-30)
+// @Param        to        query     string  false  "Last date searched, six days after from by default and at most 30 days after it"  example(2026-02-05)
+// @Param        days      query     string  false  "Only these days of the week, comma separated"  example(fri,sat)
+// @Param        start     query     string  false  "Earliest start time"  default(00:00)
+// @Param        end       query     string  false  "Latest end time"  default(24:00)
+// @Param        duration  query     string  false  "How long the court is needed, such as 2h or 90m; one slot by default"
+// @Param        indoor    query     bool    false  "Only indoor courts when true, only outdoor ones when false"
+// @Param        limit     query     int     false  "Matches returned, at most 100"  default(20)
+// @Success      200       {array}   SearchMatch
+// @Failure      400       {object}  DataBase.ErrorResponse  "Invalid search parameters"
+// @Failure      401       {object}  DataBase.ErrorResponse  "Missing or invalid token"
+// @Failure      500       {object}  DataBase.ErrorResponse  "Failed to search availability"
+// @Router       /api/v1/availability/search [get]
+func (h *Handler) SearchAvailability(w http.ResponseWriter, r *http.Request) {
+	params := Pagination.New(r)
+	q := Availability.SearchQuery{
+		From:   params.Date("from"),
+		To:     params.Date("to"),
+		Indoor: params.Bool("indoor"),
+		Start:  searchTime(params, "start", 0),
+		End:    searchTime(params, "end", 24*60),
+	}
+	if err := h.searchSports(params, &q); err != nil {
+		APIError.Internal(w, r, "Failed to fetch sports", err)
+		return
+	}
+	searchDates(params, &q)
+
+	for _, day := range splitList(params.String("days")) {
+		weekday, ok := parseWeekday(day)
+		if !ok {
+			params.Invalid("days", "has an unknown day "+strconv.Quote(day))
+			continue
+		}
+		q.Days = append(q.Days, weekday)
+	}
+
+	if raw := params.String("duration"); raw != "" {
+		duration, err := time.ParseDuration(raw)
+		if err != nil || duration < time.Minute || duration%time.Minute != 0 {
+			params.Invalid("duration", "must be a whole number of minutes such as 2h or 90m")
+		}
+		q.Duration = int(duration / time.Minute)
+	}
+	if q.End <= q.Start {
+		params.Invalid("end", "must be after start")
+	} else if q.Duration > q.End-q.Start {
+		params.Invalid("duration", "must fit between start and end")
+	}
+
+	limit := defaultSearchLimit
+	if n := params.Int("limit"); n != nil {
+		if *n < 1 || *n > maxSearchLimit {
+			params.Invalid("limit", "must be a number from 1 to "+strconv.Itoa(maxSearchLimit))
+		}
+		limit = *n
+	}
+	if !params.Check(w, r) {
+		return
+	}
+
+	matches, err := Availability.Search(h.Store, q)
+	if err != nil {
+		APIError.Internal(w, r, "Failed to search availability", err)
+		return
+	}
+	Pagination.SetTotal(w, int64(len(matches)))
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	results := make([]SearchMatch, len(matches))
+	for i, m := range matches {
+		results[i] = SearchMatch{
+			Rank:      i + 1,
+			CourtID:   m.Court.Court_ID,
+			CourtName: m.Court.Court_Name,
+			Location:  m.Court.Court_Location,
+			Indoor:    m.Court.Court_Indoor,
+			SportID:   m.Court.Sport_id,
+			Date:      m.Date,
+			Start:     Availability.FormatMinutes(m.Start),
+			End:       Availability.FormatMinutes(m.End),
+			Slots:     m.Slots,
+		}
+		if m.Court.Sport != nil {
+			results[i].SportName = m.Court.Sport.Sport_name
+		}
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// searchSports looks up the sports named in the sports parameter, case-insensitively.
+func (h *Handler) searchSports(params *Pagination.Params, q *Availability.SearchQuery) error {
+	names := splitList(params.String("sports"))
+	if len(names) == 0 {
+		return nil
+	}
+	sports, err := h.Store.Sports().List()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		found := false
+		for _, sport := range sports {
+			if strings.EqualFold(sport.Sport_name, name) {
+				q.SportIDs = append(q.SportIDs, sport.Sport_ID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			params.Invalid("sports", "has an unknown sport "+strconv.Quote(name))
+		}
+	}
+	return nil
+}
+
+// parseWeekday reads a day of the week such as Friday or fri.
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(name, day.String()) || strings.EqualFold(name, day.String()[:3]) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// searchDates fills in the default dates and checks the range is neither in the past nor too long.
+func searchDates(params *Pagination.Params, q *Availability.SearchQuery) {
+	today := *DataBase.BookingDate(time.Now())
+	if q.From == "" {
+		q.From = today
+	}
+	from, err := time.Parse(DataBase.BookingDateLayout, q.From)
+	if err != nil {
+		return
+	}
+	if q.From < today {
+		params.Invalid("from", "must not be in the past")
+	}
+	if q.To == "" {
+		q.To = from.AddDate(0, 0, defaultSearchDays-1).Format(DataBase.BookingDateLayout)
+	}
+	if q.To < q.From {
+		params.Invalid("to", "must not be before from")
+	} else if q.To >= from.AddDate(0, 0, maxSearchDays).Format(DataBase.BookingDateLayout) {
+		params.Invalid("to", "must be at most "+strconv.Itoa(maxSearchDays-1)+" days after from")
+	}
+}
+
+// searchTime reads a time of day such as 17:00 as minutes after midnight; 24:00 is the end of the day.
+func searchTime(params *Pagination.Params, name string, fallback int) int {
+	raw := params.String(name)
+	if raw == "" {
+		return fallback
+	}
+	if raw == "24:00" {
+		return 24 * 60
+	}
+	t, err := time.Parse("15:04", raw)
+	if err != nil {
+		params.Invalid(name, "must be a time such as 17:00")
+		return fallback
+	}
+	return t.Hour()*60 + t.Minute()
+}
+
+// splitList splits a comma separated parameter, dropping empty entries.
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,112 @@
+package V1
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSearchAvailability(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	basketball := DataBase.Sport{Sport_name: "Basketball"}
+	volleyball := DataBase.Sport{Sport_name: "Volleyball"}
+	store.Sports().Create(&basketball)
+	store.Sports().Create(&volleyball)
+	courts := []DataBase.Court{
+		{Court_Name: "Hoops Hall", Court_Location: "Rec", Court_Status: 1, Court_Indoor: true, Sport_id: basketball.Sport_ID},
+		{Court_Name: "Net Hall", Court_Location: "Rec", Court_Status: 1, Court_Indoor: true, Sport_id: volleyball.Sport_ID},
+		{Court_Name: "Hoops Yard", Court_Location: "Field", Court_Status: 1, Sport_id: basketball.Sport_ID},
+		{Court_Name: "Closed Hall", Court_Location: "Rec", Court_Status: 0, Court_Indoor: true, Sport_id: basketball.Sport_ID},
+	}
+	for i := range courts {
+		store.Courts().Create(&courts[i])
+	}
+
+	// The next two Fridays, both in the future
+	friday := time.Now().AddDate(0, 0, 1)
+	for friday.Weekday() != time.Friday {
+		friday = friday.AddDate(0, 0, 1)
+	}
+	first := *DataBase.BookingDate(friday)
+	second := *DataBase.BookingDate(friday.AddDate(0, 0, 7))
+
+	// Hoops Hall has no two free hours in a row between 15:00 and 18:00 on the first Friday, and
+	// Net Hall is blacked out on the second
+	booking := DataBase.Bookings{Customer_ID: 1, Sport_ID: basketball.Sport_ID, Court_ID: courts[0].Court_ID, Booking_Time: 8,
+		Booking_Date: &first, Booking_Status: DataBase.BookingConfirmed}
+	store.Bookings().Create(&booking)
+	store.Blackouts().Create(&DataBase.Court_Blackout{Court_ID: courts[1].Court_ID, Blackout_Date: second})
+
+	search := func(query string) ([]SearchMatch, string) {
+		rr := call(h, "GET", "/availability/search?"+query, nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", query, rr.Code, rr.Body.String())
+		}
+		var matches []SearchMatch
+		json.Unmarshal(rr.Body.Bytes(), &matches)
+		return matches, rr.Header().Get("X-Total-Count")
+	}
+	describe := func(matches []SearchMatch) []string {
+		described := []string{}
+		for _, m := range matches {
+			described = append(described, fmt.Sprintf("%d %s %s %s-%s", m.Rank, m.CourtName, m.Date, m.Start, m.End))
+		}
+		return described
+	}
+
+	window := "from=" + first + "&to=" + second + "&days=Friday&start=15:00&end=18:00&duration=2h"
+	matches, total := search("sports=basketball,VOLLEYBALL&indoor=true&" + window)
+	want := []string{
+		"1 Net Hall " + first + " 15:00-17:00",
+		"2 Net Hall " + first + " 16:00-18:00",
+		"3 Hoops Hall " + second + " 15:00-17:00",
+		"4 Hoops Hall " + second + " 16:00-18:00",
+	}
+	if got := describe(matches); fmt.Sprint(got) != fmt.Sprint(want) || total != "4" {
+		t.Errorf("expected %v, got %v with total %s", want, got, total)
+	}
+	if m := matches[0]; m.SportName != "Volleyball" || !m.Indoor || fmt.Sprint(m.Slots) != "[7 8]" {
+		t.Errorf("unexpected match details: %+v", m)
+	}
+
+	// Outdoor courts join in, and basketball ranks ahead of volleyball at the same time
+	matches, total = search("sports=Basketball,Volleyball&limit=2&" + window)
+	want = []string{
+		"1 Hoops Yard " + first + " 15:00-17:00",
+		"2 Net Hall " + first + " 15:00-17:00",
+	}
+	if got := describe(matches); fmt.Sprint(got) != fmt.Sprint(want) || total != "8" {
+		t.Errorf("expected %v, got %v with total %s", want, got, total)
+	}
+
+	// A court that closes at 16:00 has no two hours free after 15:00
+	store.Courts().Update(&DataBase.Court{Court_ID: courts[2].Court_ID, Court_Name: "Hoops Yard", Court_Location: "Field",
+		Court_Status: 1, Court_Hours: "08:00-16:00", Sport_id: basketball.Sport_ID})
+	matches, _ = search("sports=basketball&indoor=false&" + window)
+	if got := describe(matches); len(got) != 0 {
+		t.Errorf("expected no match after Hoops Yard closes, got %v", got)
+	}
+
+	for _, query := range []string{
+		"sports=Curling",
+		"days=Funday",
+		"start=25:00",
+		"start=18:00&end=17:00",
+		"start=15:00&end=18:00&duration=4h",
+		"duration=soon",
+		"from=2000-01-01",
+		"to=" + *DataBase.BookingDate(time.Now().AddDate(0, 2, 0)),
+		"indoor=maybe",
+		"limit=1000",
+	} {
+		if rr := call(h, "GET", "/availability/search?"+query, nil); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, rr.Code)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package V1
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/DataBase"
+	"BackEnd/Events"
+	"BackEnd/Pagination"
+	"BackEnd/Repository"
+	"BackEnd/Sport"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SportResource is a sport as the API returns it.
+type SportResource struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// SportRequest creates a sport.
+type SportRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// SportPatch changes the fields of a sport that are sent.
+type SportPatch struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+func sportResource(s DataBase.Sport) SportResource {
+	return SportResource{ID: s.Sport_ID, Name: s.Sport_name, Description: s.Sport_Description}
+}
+
+// findSport looks up the {id} sport, writing the error response itself.
+func (h *Handler) findSport(w http.ResponseWriter, r *http.Request) (DataBase.Sport, bool) {
+	id, ok := pathID(w, r, "sport")
+	if !ok {
+		return DataBase.Sport{}, false
+	}
+	sport, err := h.Store.Sports().FindByID(id)
+	if errors.Is(err, Repository.ErrNotFound) {
+		APIError.Write(w, r, http.StatusNotFound, APIError.SportNotFound, "Sport not found")
+		return DataBase.Sport{}, false
+	} else if err != nil {
+		APIError.Internal(w, r, "Failed to fetch sport", err)
+		return DataBase.Sport{}, false
+	}
+	return sport, true
+}
+
+// ListSports godoc
+// @Summary      List sports
+// @Description  Lists the sports a page at a time. X-Total-Count gives the number of sports, and X-Next-Cursor and the Link header the next page.
+// @Tags         v1
+// @Produce      json
+// @Param        limit   query     int     false  "Sports per page, at most 500"  default(100)
+// @Param        cursor  query     string  false  "Cursor of the page, from X-Next-Cursor"
+// @Param        sort    query     string  false  "id or name, prefixed with - for descending order"  default(id)
+// @Success      200     {array}   SportResource
+// @Failure      400     {object}  DataBase.ErrorResponse  "Invalid list parameters"
+// @Failure      401     {object}  DataBase.ErrorResponse  "Missing or invalid token"
+// @Failure      500     {object}  DataBase.ErrorResponse  "Failed to fetch sports"
+// @Router       /api/v1/sports [get]
+func (h *Handler) ListSports(w http.ResponseWriter, r *http.Request) {
+	params := Pagination.New(r)
+	q := params.List(Repository.SportSorts)
+	if !params.Check(w, r) {
+		return
+	}
+	sports, next, err := h.Store.Sports().Page(q)
+	var total int64
+	if err == nil {
+		total, err = h.Store.Sports().Count()
+	}
+	if err != nil {
+		Pagination.Failed(w, r, err, "Failed to fetch sports")
+		return
+	}
+
+	resources := make([]SportResource, len(sports))
+	for i, sport := range sports {
+		resources[i] = sportResource(sport)
+	}
+	Pagination.SetTotal(w, total)
+	Pagination.SetNext(w, r, q, next)
+	writeJSON(w, http.StatusOK, resources)
+}
+
+// CreateSport godoc
+// @Summary      Create a sport
+// @Tags         v1
+// @Accept       json
+// @Produce      json
+// @Param        sport  body      SportRequest  true  "Sport"
+// @Success      201    {object}  SportResource
+// @Header       201    {string}  Location  "Address of the new sport"
+// @Failure      400    {object}  DataBase.ErrorResponse  "Invalid request body or missing name"
+// @Failure      401    {object}  DataBase.ErrorResponse  "Missing or invalid admin session"
+// @Failure      409    {object}  DataBase.ErrorResponse  "A sport with that name already exists"
+// @Failure      500    {object}  DataBase.ErrorResponse  "Failed to create sport"
+// @Router       /api/v1/sports [post]
+func (h *Handler) CreateSport(w http.ResponseWriter, r *http.Request) {
+	var req SportRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		APIError.Validation(w, r, "name is required", APIError.Field("name", "is required"))
+		return
+	}
+
+	sport := DataBase.Sport{Sport_name: req.Name, Sport_Description: req.Description}
+	err := h.Store.Sports().Create(&sport)
+	if errors.Is(err, Repository.ErrDuplicate) {
+		APIError.Write(w, r, http.StatusConflict, APIError.SportAlreadyExists, "A sport with that name already exists")
+		return
+	} else if err != nil {
+		APIError.Internal(w, r, "Failed to create sport", err)
+		return
+	}
+	writeCreated(w, fmt.Sprintf("/sports/%d", sport.Sport_ID), sportResource(sport))
+}
+
+// GetSport godoc
+// @Summary      Get a sport
+// @Tags         v1
+// @Produce      json
+// @Param        id   path      int  true  "Sport ID"
+// @Success      200  {object}  SportResource
+// @Failure      400  {object}  DataBase.ErrorResponse  "Invalid sport ID"
+// @Failure      401  {object}  DataBase.ErrorResponse  "Missing or invalid token"
+// @Failure      404  {object}  DataBase.ErrorResponse  "Sport not found"
+// @Router       /api/v1/sports/{id} [get]
+func (h *Handler) GetSport(w http.ResponseWriter, r *http.Request) {
+	if sport, ok := h.findSport(w, r); ok {
+		writeJSON(w, http.StatusOK, sportResource(sport))
+	}
+}
+
+// UpdateSport godoc
+// @Summary      Update a sport
+// @Description  Changes the name or description of a sport, leaving the fields not sent as they are. Its courts and bookings are unaffected.
+// @Tags         v1
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int         true  "Sport ID"
+// @Param        sport  body      SportPatch  true  "Fields to change"
+// @Success      200    {object}  SportResource
+// @Failure      400    {object}  DataBase.ErrorResponse  "Invalid sport ID, request body or blank name"
+// @Failure      401    {object}  DataBase.ErrorResponse  "Missing or invalid admin session"
+// @Failure      404    {object}  DataBase.ErrorResponse  "Sport not found"
+// @Failure      409    {object}  DataBase.ErrorResponse  "A sport with that name already exists"
+// @Failure      500    {object}  DataBase.ErrorResponse  "Failed to update sport"
+// @Router       /api/v1/sports/{id} [patch]
+func (h *Handler) UpdateSport(w http.ResponseWriter, r *http.Request) {
+	sport, ok := h.findSport(w, r)
+	if !ok {
+		return
+	}
+	var req SportPatch
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			APIError.Validation(w, r, "name must not be blank", APIError.Field("name", "must not be blank"))
+			return
+		}
+		sport.Sport_name = *req.Name
+	}
+	if req.Description != nil {
+		sport.Sport_Description = *req.Description
+	}
+
+	err := h.Store.Sports().Update(&sport)
+	switch {
+	case errors.Is(err, Repository.ErrDuplicate):
+		APIError.Write(w, r, http.StatusConflict, APIError.SportAlreadyExists, "A sport with that name already exists")
+	case errors.Is(err, Repository.ErrNotFound):
+		APIError.Write(w, r, http.StatusNotFound, APIError.SportNotFound, "Sport not found")
+	case err != nil:
+		APIError.Internal(w, r, "Failed to update sport", err)
+	default:
+		writeJSON(w, http.StatusOK, sportResource(sport))
+	}
+}
+
+// DeleteSport godoc
+// @Summary      Delete a sport
+// @Description  Soft deletes the sport together with its courts and their bookings; an admin can restore them until they are purged.
+// @Tags         v1
+// @Param        id   path  int  true  "Sport ID"
+// @Success      204
+// @Failure      400  {object}  DataBase.ErrorResponse  "Invalid sport ID"
+// @Failure      401  {object}  DataBase.ErrorResponse  "Missing or invalid admin session"
+// @Failure      404  {object}  DataBase.ErrorResponse  "Sport not found"
+// @Failure      500  {object}  DataBase.ErrorResponse  "Failed to delete sport"
+// @Router       /api/v1/sports/{id} [delete]
+func (h *Handler) DeleteSport(w http.ResponseWriter, r *http.Request) {
+	sport, ok := h.findSport(w, r)
+	if !ok {
+		return
+	}
+	if err := Sport.Delete(h.Store, sport.Sport_ID); err != nil {
+		APIError.Internal(w, r, "Failed to delete sport", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSportCourts godoc
+// @Summary      List a sport's courts
+// @Description  Lists the sport's courts a page at a time, like /api/v1/courts?sport_id={id}.
+// @Tags         v1
+// @Produce      json
+// @Param        id      path      int     true   "Sport ID"
+// @Param        status  query     int     false  "Only courts with this status, 1 for open"
+// @Param        indoor  query     bool    false  "Only indoor courts when true, only outdoor ones when false"
+// @Param        limit   query     int     false  "Courts per page, at most 500"  default(100)
+// @Param        cursor  query     string  false  "Cursor of the page, from X-Next-Cursor"
+// @Param        sort    query     string  false  "id, name, location or status, prefixed with - for descending order"  default(id)
+// @Success      200     {array}   CourtResource
+// @Failure      400     {object}  DataBase.ErrorResponse  "Invalid sport ID or list parameters"
+// @Failure      401     {object}  DataBase.ErrorResponse  "Missing or invalid token"
+// @Failure      404  {object}  DataBase.ErrorResponse  "Sport not found"
+// @Failure      500  {object}  DataBase.ErrorResponse  "Failed to fetch courts"
+// @Router       /api/v1/sports/{id}/courts [get]
+func (h *Handler) ListSportCourts(w http.ResponseWriter, r *http.Request) {
+	sport, ok := h.findSport(w, r)
+	if !ok {
+		return
+	}
+	params := Pagination.New(r)
+	h.listCourts(w, r, params, Repository.CourtFilter{SportID: sport.Sport_ID, Status: params.Int("status"), Indoor: params.Bool("indoor")})
+}
+
+// ResetSport godoc
+// @Summary      Reset a sport's courts
+// @Description  Cancels every active booking on the sport's courts on behalf of an admin, freeing all of their slots.
+// @Tags         v1
+// @Param        id   path  int  true  "Sport ID"
+// @Success      204
+// @Failure      400  {object}  DataBase.ErrorResponse  "Invalid sport ID"
+// @Failure      401  {object}  DataBase.ErrorResponse  "Missing or invalid admin session"
+// @Failure      404  {object}  DataBase.ErrorResponse  "Sport not found"
+// @Failure      500  {object}  DataBase.ErrorResponse  "Failed to reset courts"
+// @Router       /api/v1/sports/{id}/reset [post]
+func (h *Handler) ResetSport(w http.ResponseWriter, r *http.Request) {
+	sport, ok := h.findSport(w, r)
+	if !ok {
+		return
+	}
+	if err := Sport.Reset(h.Store, sport.Sport_ID); err != nil {
+		APIError.Internal(w, r, "Failed to reset courts", err)
+		return
+	}
+	h.Events.Publish(Events.Change{Kind: Events.Reset, SportID: sport.Sport_ID})
+	w.WriteHeader(http.StatusNoContent)
+}
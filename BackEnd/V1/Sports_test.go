@@ -0,0 +1,149 @@
+package V1
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// open lets every request through in place of the checks main puts in front of the API.
+func open(next http.Handler) http.Handler { return next }
+
+// call sends a request through the API's routes.
+func call(h *Handler, method, path string, payload interface{}) *httptest.ResponseRecorder {
+	r := mux.NewRouter()
+	h.Routes(r, open, open)
+
+	var body bytes.Buffer
+	if payload != nil {
+		json.NewEncoder(&body).Encode(payload)
+	}
+	req, _ := http.NewRequest(method, Prefix+path, &body)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestSports(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	rr := call(h, "POST", "/sports", SportRequest{Name: "Tennis", Description: "Singles or doubles"})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var created SportResource
+	json.Unmarshal(rr.Body.Bytes(), &created)
+	if location := rr.Header().Get("Location"); location != "/api/v1/sports/1" || created.Name != "Tennis" {
+		t.Errorf("unexpected sport %+v at %q", created, location)
+	}
+
+	if rr := call(h, "POST", "/sports", SportRequest{Name: "Tennis"}); rr.Code != http.StatusConflict {
+		t.Errorf("expected a duplicate name to fail with 409, got %d", rr.Code)
+	}
+	if rr := call(h, "POST", "/sports", SportRequest{Name: " "}); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected a missing name to fail with 400, got %d", rr.Code)
+	}
+
+	rr = call(h, "GET", "/sports/1", nil)
+	var fetched SportResource
+	json.Unmarshal(rr.Body.Bytes(), &fetched)
+	if rr.Code != http.StatusOK || fetched != created {
+		t.Errorf("expected to fetch %+v, got %d %+v", created, rr.Code, fetched)
+	}
+
+	for _, tc := range []struct {
+		method, path string
+		status       int
+	}{
+		{"GET", "/sports/9", http.StatusNotFound},
+		{"GET", "/sports/tennis", http.StatusBadRequest},
+		{"PUT", "/sports/1", http.StatusMethodNotAllowed},
+		{"DELETE", "/sports/9", http.StatusNotFound},
+	} {
+		if rr := call(h, tc.method, tc.path, nil); rr.Code != tc.status {
+			t.Errorf("%s %s: expected status %d, got %d", tc.method, tc.path, tc.status, rr.Code)
+		}
+	}
+}
+
+func TestUpdateSport(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	store.Sports().Create(&DataBase.Sport{Sport_name: "Tennis", Sport_Description: "Singles or doubles"})
+	store.Sports().Create(&DataBase.Sport{Sport_name: "Squash"})
+
+	rr := call(h, "PATCH", "/sports/1", json.RawMessage(`{"description":"Doubles only"}`))
+	var updated SportResource
+	json.Unmarshal(rr.Body.Bytes(), &updated)
+	if rr.Code != http.StatusOK || updated != (SportResource{ID: 1, Name: "Tennis", Description: "Doubles only"}) {
+		t.Errorf("expected only the description to change, got %d %+v", rr.Code, updated)
+	}
+	if rr := call(h, "PATCH", "/sports/1", json.RawMessage(`{"name":"Lawn Tennis"}`)); rr.Code != http.StatusOK {
+		t.Errorf("expected the rename to succeed, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if saved, _ := store.Sports().FindByID(1); saved.Sport_name != "Lawn Tennis" || saved.Sport_Description != "Doubles only" {
+		t.Errorf("expected the renamed sport to be stored, got %+v", saved)
+	}
+
+	for _, tc := range []struct {
+		path, body string
+		status     int
+	}{
+		{"/sports/1", `{"name":"Squash"}`, http.StatusConflict},
+		{"/sports/1", `{"name":"  "}`, http.StatusBadRequest},
+		{"/sports/1", `{"name":`, http.StatusBadRequest},
+		{"/sports/9", `{"name":"Golf"}`, http.StatusNotFound},
+	} {
+		if rr := call(h, "PATCH", tc.path, json.RawMessage(tc.body)); rr.Code != tc.status {
+			t.Errorf("PATCH %s %s: expected status %d, got %d", tc.path, tc.body, tc.status, rr.Code)
+		}
+	}
+}
+
+func TestDeleteAndResetSport(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+
+	sport := DataBase.Sport{Sport_name: "Squash"}
+	store.Sports().Create(&sport)
+	court := DataBase.Court{Court_Name: "Squash 1", Court_Location: "Rec", Court_Status: 1, Sport_id: sport.Sport_ID}
+	store.Courts().Create(&court)
+	customer := DataBase.Customer{Email: "a@ufl.edu"}
+	store.Customers().Create(&customer)
+	booking := DataBase.Bookings{Customer_ID: customer.Customer_ID, Sport_ID: sport.Sport_ID, Court_ID: court.Court_ID,
+		Booking_Status: DataBase.BookingConfirmed, Booking_Date: DataBase.BookingDate(time.Now())}
+	store.Bookings().Create(&booking)
+
+	rr := call(h, "GET", "/sports/1/courts", nil)
+	var courts []CourtResource
+	json.Unmarshal(rr.Body.Bytes(), &courts)
+	if len(courts) != 1 || courts[0].Name != "Squash 1" {
+		t.Errorf("expected the sport's court, got %s", rr.Body.String())
+	}
+
+	if rr := call(h, "POST", "/sports/1/reset", nil); rr.Code != http.StatusNoContent {
+		t.Fatalf("expected reset to answer 204, got %d", rr.Code)
+	}
+	if b, _ := store.Bookings().FindByID(booking.Booking_ID); b.Booking_Status != DataBase.BookingCancelledByAdmin {
+		t.Errorf("expected the booking to be cancelled by the reset, got %s", b.Booking_Status)
+	}
+
+	if rr := call(h, "DELETE", "/sports/1", nil); rr.Code != http.StatusNoContent || rr.Body.Len() != 0 {
+		t.Fatalf("expected delete to answer 204 without a body, got %d", rr.Code)
+	}
+	if _, err := store.Courts().FindByID(court.Court_ID); err == nil {
+		t.Error("expected the sport's court to be deleted with it")
+	}
+	if rr := call(h, "GET", "/sports/1", nil); rr.Code != http.StatusNotFound {
+		t.Errorf("expected the deleted sport to be gone, got %d", rr.Code)
+	}
+}
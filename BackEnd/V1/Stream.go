@@ -0,0 +1,118 @@
+package V1
+
+import (
+	"BackEnd/APIError"
+	"BackEnd/Events"
+	"BackEnd/Pagination"
+	"BackEnd/Repository"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultHeartbeat is how often an idle stream sends a comment to keep proxies from
+	// closing it.
+	DefaultHeartbeat = 15 * time.Second
+	// streamRetry is how long, in milliseconds, a client waits before reconnecting.
+	streamRetry = 3000
+)
+
+// StreamAvailability godoc
+// @Summary      Stream availability changes
+// @Description  Sends a Server-Sent Event whenever slots are booked, cancelled, reset or blacked out, for one sport, one court or every court. Each event's data is a JSON change naming its kind (booked, cancelled, reset, blackout or blackout_lifted) and the sport, court, date and slot it concerns; a field left out means all of them. Clients should load the affected availability again. A comment is sent on idle streams every heartbeat.
+// @Description  A reconnecting client sends the ID of the last event it saw in Last-Event-ID, which browsers do by themselves, and first receives the changes it missed. If those are no longer known, it gets a resync event instead and must reload all availability.
+// @Tags         v1
+// @Produce      text/event-stream
+// @Param        sport_id       query   int     false  "Only changes to this sport's courts"
+// @Param        court_id       query   int     false  "Only changes to this court"
+// @Param        Last-Event-ID  header  string  false  "ID of the last event received"
+// @Param        last_event_id  query   string  false  "Same as Last-Event-ID, for clients that cannot set headers"
+// @Success      200            {object}  Events.Change  "Stream of changes"
+// @Failure      400            {object}  DataBase.ErrorResponse  "Invalid sport or court ID"
+// @Failure      401            {object}  DataBase.ErrorResponse  "Missing or invalid token"
+// @Failure      404            {object}  DataBase.ErrorResponse  "Sport or court not found"
+// @Failure      500            {object}  DataBase.ErrorResponse  "Failed to open the stream"
+// @Router       /api/v1/availability/stream [get]
+func (h *Handler) StreamAvailability(w http.ResponseWriter, r *http.Request) {
+	params := Pagination.New(r)
+	filter := Events.Filter{SportID: params.ID("sport_id"), CourtID: params.ID("court_id")}
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = params.String("last_event_id")
+	}
+	if !params.Check(w, r) || !h.streamFilter(w, r, &filter) {
+		return
+	}
+
+	sub := h.Events.Subscribe(filter, lastEventID)
+	defer sub.Close()
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry)
+	for _, change := range sub.Missed {
+		writeEvent(w, change)
+	}
+	rc.Flush()
+
+	heartbeat := time.NewTicker(h.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case change, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			writeEvent(w, change)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// streamFilter checks that the sport and court of filter exist, writing the error response
+// itself. A court's sport joins the filter, so changes to the whole sport reach the court.
+func (h *Handler) streamFilter(w http.ResponseWriter, r *http.Request, filter *Events.Filter) bool {
+	if filter.SportID != 0 {
+		_, err := h.Store.Sports().FindByID(filter.SportID)
+		if errors.Is(err, Repository.ErrNotFound) {
+			APIError.Write(w, r, http.StatusNotFound, APIError.SportNotFound, "Sport not found")
+			return false
+		} else if err != nil {
+			APIError.Internal(w, r, "Failed to open the stream", err)
+			return false
+		}
+	}
+	if filter.CourtID != 0 {
+		court, err := h.Store.Courts().FindByID(filter.CourtID)
+		if errors.Is(err, Repository.ErrNotFound) || err == nil && filter.SportID != 0 && court.Sport_id != filter.SportID {
+			APIError.Write(w, r, http.StatusNotFound, APIError.CourtNotFound, "Court not found")
+			return false
+		} else if err != nil {
+			APIError.Internal(w, r, "Failed to open the stream", err)
+			return false
+		}
+		filter.SportID = court.Sport_id
+	}
+	return true
+}
+
+// writeEvent writes change as one event. Events have no name, so clients read them in onmessage.
+func writeEvent(w http.ResponseWriter, change Events.Change) {
+	data, _ := json.Marshal(change)
+	fmt.Fprintf(w, "id: %s\ndata: %s\n\n", change.ID, data)
+}
@@ -0,0 +1,146 @@
+package V1
+
+import (
+	"BackEnd/DataBase"
+	"BackEnd/Events"
+	"BackEnd/Repository/TestStore"
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// stream reads the events of an availability stream.
+type stream struct {
+	t      *testing.T
+	resp   *http.Response
+	lines  *bufio.Scanner
+	lastID string
+}
+
+func openStream(t *testing.T, url, lastEventID string) *stream {
+	t.Helper()
+	req, _ := http.NewRequest("GET", url, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	return &stream{t: t, resp: resp, lines: bufio.NewScanner(resp.Body)}
+}
+
+// next returns the next change, or a zero change with Kind "heartbeat" for a heartbeat comment.
+func (s *stream) next() Events.Change {
+	s.t.Helper()
+	for s.lines.Scan() {
+		line := s.lines.Text()
+		switch {
+		case line == ": heartbeat":
+			return Events.Change{Kind: "heartbeat"}
+		case strings.HasPrefix(line, "id: "):
+			s.lastID = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			var change Events.Change
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &change); err != nil || change.ID != s.lastID {
+				s.t.Fatalf("unexpected event %q after id %q", line, s.lastID)
+			}
+			return change
+		}
+	}
+	s.t.Fatalf("stream ended: %v", s.lines.Err())
+	return Events.Change{}
+}
+
+func TestStreamAvailability(t *testing.T) {
+	store := TestStore.Open(t)
+	h := NewHandler(store)
+	h.Heartbeat = 50 * time.Millisecond
+
+	tennis := DataBase.Sport{Sport_name: "Tennis"}
+	store.Sports().Create(&tennis)
+	squash := DataBase.Sport{Sport_name: "Squash"}
+	store.Sports().Create(&squash)
+	court := DataBase.Court{Court_Name: "Court 1", Court_Location: "Rec", Court_Status: 1, Sport_id: tennis.Sport_ID}
+	store.Courts().Create(&court)
+	other := DataBase.Court{Court_Name: "Court 2", Court_Location: "Rec", Court_Status: 1, Sport_id: squash.Sport_ID}
+	store.Courts().Create(&other)
+	customer := DataBase.Customer{Email: "a@ufl.edu"}
+	store.Customers().Create(&customer)
+
+	r := mux.NewRouter()
+	h.Routes(r, open, open)
+	server := httptest.NewServer(r)
+	defer server.Close()
+	defer h.Events.Close()
+
+	for query, code := range map[string]int{"?sport_id=9": http.StatusNotFound, "?court_id=9": http.StatusNotFound,
+		"?sport_id=2&court_id=1": http.StatusNotFound, "?court_id=x": http.StatusBadRequest} {
+		if rr := call(h, "GET", "/availability/stream"+query, nil); rr.Code != code {
+			t.Errorf("expected %d for %s, got %d", code, query, rr.Code)
+		}
+	}
+
+	events := openStream(t, server.URL+Prefix+"/availability/stream?court_id=1", "")
+	if change := events.next(); change.Kind != "heartbeat" {
+		t.Fatalf("expected a heartbeat on an idle stream, got %+v", change)
+	}
+
+	// Changes to the other sport's court are not sent
+	call(h, "POST", "/courts/2/reset", nil)
+	rr := call(h, "POST", "/bookings", map[string]interface{}{"court_id": 1, "email": "a@ufl.edu", "slot_index": 3})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("failed to book: %d %s", rr.Code, rr.Body.String())
+	}
+	change := events.next()
+	for change.Kind == "heartbeat" {
+		change = events.next()
+	}
+	if change.Kind != Events.Booked || change.CourtID != 1 || change.SportID != tennis.Sport_ID || change.Slot == nil || *change.Slot != 3 {
+		t.Fatalf("expected the booking, got %+v", change)
+	}
+	booked := events.lastID
+
+	// A client that drops out gets what it missed when it reconnects
+	call(h, "POST", "/bookings/1/cancel", map[string]string{"email": "a@ufl.edu"})
+	call(h, "POST", "/sports/1/reset", nil)
+	resumed := openStream(t, server.URL+Prefix+"/availability/stream?court_id=1", booked)
+	if change := resumed.next(); change.Kind != Events.Cancelled || change.Date != *DataBase.BookingDate(time.Now()) {
+		t.Errorf("expected the cancellation first, got %+v", change)
+	}
+	if change := resumed.next(); change.Kind != Events.Reset || change.SportID != tennis.Sport_ID || change.CourtID != 0 {
+		t.Errorf("expected the sport reset next, got %+v", change)
+	}
+
+	// An ID from before a restart asks the client to reload everything
+	if change := openStream(t, server.URL+Prefix+"/availability/stream", "stale-7").next(); change.Kind != Events.Resync {
+		t.Errorf("expected a resync, got %+v", change)
+	}
+}
+
+func TestStreamAvailabilityWithoutBroker(t *testing.T) {
+	h := NewHandler(TestStore.Open(t))
+	h.Events = nil
+	h.Heartbeat = 50 * time.Millisecond
+
+	r := mux.NewRouter()
+	h.Routes(r, open, open)
+	server := httptest.NewServer(r)
+	// Registered before the stream, so the stream is closed first and the server can shut down
+	t.Cleanup(server.Close)
+
+	// The stream stays up and only sends heartbeats
+	if change := openStream(t, server.URL+Prefix+"/availability/stream", "").next(); change.Kind != "heartbeat" {
+		t.Errorf("expected a heartbeat, got %+v", change)
+	}
+}
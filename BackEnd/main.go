@@ -7,18 +7,35 @@ package main
 // @BasePath /
 
 import (
+	"BackEnd/APIError"
 	"BackEnd/Admin"
+	"BackEnd/Availability"
+	"BackEnd/Backup"
 	"BackEnd/Bookings"
+	"BackEnd/Config"
 	"BackEnd/Court"
 	"BackEnd/Customer"
+	"BackEnd/DataBase"
+	"BackEnd/Events"
+	"BackEnd/Health"
+	"BackEnd/Idempotency"
+	"BackEnd/Migrations"
+	"BackEnd/Repository"
+	"BackEnd/Server"
 	"BackEnd/Sport"
 	"BackEnd/Utils"
+	"BackEnd/V1"
 	_ "BackEnd/docs"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/MicahParks/keyfunc/v2"
@@ -31,24 +48,33 @@ import (
 
 var jwks *keyfunc.JWKS
 
-func init() {
-	// Initialize JWKS from the Cognito URL provided via env var
-	jwksURL := os.Getenv("COGNITO_JWKS_URL")
-	if jwksURL == "" {
-		fmt.Println("WARNING: COGNITO_JWKS_URL environment variable is not set. Auth will fail.")
+// jwksHeartbeat beats on every successful JWKS fetch.
+var jwksHeartbeat Health.Heartbeat
+
+func initJWKS(auth Config.Auth) {
+	// Initialize JWKS from the configured Cognito URL
+	if auth.JWKSURL == "" {
+		fmt.Println("WARNING: COGNITO_JWKS_URL is not configured. Auth will fail.")
 		return
 	}
 
 	var err error
 	// Create the JWKS from the URL.
 	options := keyfunc.Options{
-		RefreshInterval: time.Hour,
+		RefreshInterval:  time.Duration(auth.JWKSRefreshInterval),
 		RefreshRateLimit: time.Minute * 5,
 		RefreshErrorHandler: func(err error) {
 			log.Printf("There was an error with the JWKS refresh: %v", err)
 		},
+		ResponseExtractor: func(ctx context.Context, resp *http.Response) (json.RawMessage, error) {
+			keys, err := keyfunc.ResponseExtractorStatusOK(ctx, resp)
+			if err == nil {
+				jwksHeartbeat.Beat()
+			}
+			return keys, err
+		},
 	}
-	jwks, err = keyfunc.Get(jwksURL, options)
+	jwks, err = keyfunc.Get(auth.JWKSURL, options)
 	if err != nil {
 		log.Fatalf("Failed to create JWKS from resource at the given URL.\nError: %v", err)
 	}
@@ -59,7 +85,7 @@ func validateToken(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			http.Error(w, "Missing token", http.StatusUnauthorized)
+			APIError.Write(w, r, http.StatusUnauthorized, APIError.Unauthorized, "Missing token")
 			return
 		}
 
@@ -69,12 +95,12 @@ func validateToken(next http.Handler) http.Handler {
 		token, err := jwt.Parse(tokenString, jwks.Keyfunc)
 
 		if err != nil {
-			http.Error(w, "Invalid token: "+err.Error(), http.StatusUnauthorized)
+			APIError.Write(w, r, http.StatusUnauthorized, APIError.InvalidToken, "Invalid token: "+err.Error())
 			return
 		}
 
 		if !token.Valid {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			APIError.Write(w, r, http.StatusUnauthorized, APIError.InvalidToken, "Invalid token")
 			return
 		}
 
@@ -84,68 +110,292 @@ func validateToken(next http.Handler) http.Handler {
 
 func main() {
 
-	startScheduler()
+	config, err := Config.FromEnvironment()
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+
+	// "main config print" shows the effective configuration instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := Config.Run(config, os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("config: %v", err)
+		}
+		return
+	}
+	DataBase.SetSlots(config.Slots.Labels())
+
+	db, err := DataBase.Open(config.Database.Driver, config.Database.URL, config.Database.Pool(), config.Database.Retry())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// "main migrate up|down|status" manages the schema instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := Migrations.Run(db, os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+		return
+	}
+
+	if err := Migrations.EnsureCurrent(db); errors.Is(err, Migrations.ErrSchemaAhead) {
+		log.Fatalf("Refusing to start: %v. Deploy the newer build, or run its \"migrate down\" first.", err)
+	} else if err != nil {
+		log.Fatalf("Refusing to start: %v. Run \"%s migrate up\" first.", err, os.Args[0])
+	}
+
+	store := Repository.NewGormStore(db)
+
+	// "main export|import" moves data between databases instead of serving
+	if len(os.Args) > 1 && (os.Args[1] == "export" || os.Args[1] == "import") {
+		if err := Backup.Run(store, os.Args[1:], os.Stdout); err != nil {
+			log.Fatalf("%s: %v", os.Args[1], err)
+		}
+		return
+	}
+
+	if configJSON, err := json.Marshal(config.Redacted()); err == nil {
+		log.Printf("Configuration: %s", configJSON)
+	}
+	initJWKS(config.Auth)
+
+	adminHandler := Admin.NewHandler(store)
+	adminHandler.BootstrapToken = config.Auth.AdminBootstrapToken
+	if config.Auth.AdminBootstrapToken == "" {
+		if count, err := store.Admins().Count(); err == nil && count == 0 {
+			fmt.Println("WARNING: ADMIN_BOOTSTRAP_TOKEN is not configured and there is no admin yet. Whoever calls /admin/bootstrap first becomes super admin.")
+		}
+	}
+	adminHandler.SessionTTL = time.Duration(config.Auth.AdminSessionTTL)
+	if config.Auth.AdminSessionSecret != "" {
+		adminHandler.SessionSecret = []byte(config.Auth.AdminSessionSecret)
+	} else {
+		fmt.Println("WARNING: ADMIN_SESSION_SECRET is not configured. Admin sessions end when the server restarts.")
+	}
+	bookingHandler := Bookings.NewHandler(store)
+	courtHandler := Court.NewHandler(store)
+	customerHandler := Customer.NewHandler(store)
+	sportHandler := Sport.NewHandler(store)
+	utilsHandler := Utils.NewHandler(store)
+	v1Handler := V1.NewHandler(store)
+	bookingHandler.Alternatives = config.Alternatives.Options()
+	courtHandler.Alternatives = config.Alternatives.Options()
+	v1Handler.Alternatives = config.Alternatives.Options()
+
+	// Every handler that books, cancels, resets or blacks out publishes to the one broker the
+	// availability stream reads
+	broker := Events.NewBroker(config.Events.Backlog)
+	adminHandler.Events = broker
+	bookingHandler.Events = broker
+	courtHandler.Events = broker
+	sportHandler.Events = broker
+	utilsHandler.Events = broker
+	v1Handler.Events = broker
+	v1Handler.Heartbeat = time.Duration(config.Events.Heartbeat)
+
+	var schedulerHeartbeat Health.Heartbeat
+	scheduler := startScheduler(store, config.Schedule, &schedulerHeartbeat)
+
+	live := Health.NewChecker(2*time.Second).
+		Add("scheduler", schedulerHeartbeat.Fresh(schedulerHeartbeatMaxAge))
+	ready := Health.NewChecker(2*time.Second).
+		Add("database", Health.Database(db)).
+		Add("migrations", Health.Schema(db)).
+		Add("scheduler", schedulerHeartbeat.Fresh(schedulerHeartbeatMaxAge))
+	if jwks != nil {
+		// Fails once two refreshes in a row have been missed
+		ready.Add("jwks", jwksHeartbeat.Fresh(2*time.Duration(config.Auth.JWKSRefreshInterval)))
+	}
+	healthHandler := Health.NewHandler(live, ready)
+
 	r := mux.NewRouter()
+	r.NotFoundHandler = APIError.NotFoundHandler()
+	r.MethodNotAllowedHandler = APIError.MethodNotAllowedHandler()
 
 	corsHandler := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"}, // Allow all domains temporarily
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedOrigins:   config.CORS.AllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-Bootstrap-Token", APIError.RequestIDHeader, Idempotency.Header, "If-None-Match", "Last-Event-ID"},
+		ExposedHeaders:   []string{"Location", "Deprecation", "Link", "X-Total-Count", "X-Next-Cursor", APIError.RequestIDHeader, Idempotency.ReplayedHeader, "ETag"},
 		AllowCredentials: true,
 	})
 
 	r.Use(mux.CORSMethodMiddleware(r))
 
-	r.HandleFunc("/getCourts", Court.GetCourt).Methods("GET", "OPTIONS")
-	r.HandleFunc("/getCourts", Court.GetCourt).Methods("GET", "OPTIONS")
-	r.HandleFunc("/Customer", Customer.CreateCustomer).Methods("POST", "OPTIONS")
-	r.HandleFunc("/GetCustomer", Customer.GetCustomer).Methods("GET", "OPTIONS")
-	r.HandleFunc("/UpdateCourtSlotandBooking", Court.UpdateCourtSlotandBooking).Methods("PUT", "OPTIONS")
-	r.HandleFunc("/CreateBooking", Bookings.CreateBooking).Methods("POST", "OPTIONS")
-	r.HandleFunc("/CreateSport", Sport.CreateSport).Methods("POST", "OPTIONS")
-	r.HandleFunc("/DeleteSport", Sport.DeleteSport).Methods("DELETE", "OPTIONS")
-	r.HandleFunc("/ResetSportCourts", Sport.ResetSportCourts).Methods("POST", "OPTIONS")
-	r.HandleFunc("/ResetSportCourts", Sport.ResetSportCourts).Methods("POST", "OPTIONS")
-	r.HandleFunc("/admin/deleteAllBookings", Utils.DeleteAllBookings).Methods("DELETE", "OPTIONS")
-	r.HandleFunc("/admin/resetSystem", Utils.ResetSystem).Methods("DELETE", "OPTIONS")
-	r.HandleFunc("/DeleteCourt", Court.DeleteCourt).Methods("DELETE", "OPTIONS")
-	r.HandleFunc("/CreateCourt", Court.CreateCourtWithTimeSlots).Methods("POST", "OPTIONS")
-	r.HandleFunc("/ListSports", Sport.ListSports).Methods("GET", "OPTIONS")
-	r.HandleFunc("/ListCourts", Court.ListCourts).Methods("GET", "OPTIONS")
-	r.HandleFunc("/CancelBookingandUpdateSlot", Court.CancelBookingandUpdateSlot).Methods("PUT", "OPTIONS")
-	r.HandleFunc("/listBookings", Bookings.ListBookings).Methods("GET", "OPTIONS")
-	r.HandleFunc("/cancelBooking", Bookings.CancelBooking).Methods("POST", "OPTIONS")
-
-	r.HandleFunc("/AdminLogin", Admin.AdminLogin).Methods("POST", "OPTIONS")
-
-	r.HandleFunc("/resetCourtSlots", Court.ResetCourtSlotsHandler).Methods("PUT", "OPTIONS")
-
-	r.HandleFunc("/admin/allBookings", Admin.GetAllBookings).Methods("GET", "OPTIONS")
-	r.HandleFunc("/admin/cancelBooking", Admin.AdminCancelBooking).Methods("POST", "OPTIONS")
-	r.HandleFunc("/create-admin", Admin.SeedAdminCreate).Methods("GET", "OPTIONS")
+	r.HandleFunc("/healthz", healthHandler.Healthz).Methods("GET")
+	r.HandleFunc("/readyz", healthHandler.Readyz).Methods("GET")
 
+	// Registered ahead of the /api/v1 routes: a prefix route matched after them would turn
+	// their 405 for an unsupported method into a 404
 	newroute := r.PathPrefix("/api").Subrouter()
 	newroute.Use(validateToken)
-	newroute.HandleFunc("/CreateCustomer", Customer.CreateCustomer).Methods("POST", "OPTIONS")
+	newroute.HandleFunc("/CreateCustomer", customerHandler.CreateCustomer).Methods("POST", "OPTIONS")
+
+	// Resource routes take a Cognito token, except that changing sports and courts takes an admin
+	// session instead. The ones below predate them and only remain as deprecated adapters; those
+	// that change sports, courts or slots or wipe data take an admin session as well
+	v1Handler.Routes(r, validateToken, adminHandler.RequireAdmin)
+
+	r.HandleFunc("/getCourts", V1.Deprecated("/sports", courtHandler.GetCourt)).Methods("GET", "OPTIONS")
+	r.HandleFunc("/Customer", V1.Deprecated("/customers", customerHandler.CreateCustomer)).Methods("POST", "OPTIONS")
+	r.HandleFunc("/GetCustomer", V1.Deprecated("/customers", customerHandler.GetCustomer)).Methods("GET", "OPTIONS")
+	r.Handle("/UpdateCourtSlotandBooking", adminHandler.RequireAdmin(V1.Deprecated("/bookings", courtHandler.UpdateCourtSlotandBooking))).Methods("PUT", "OPTIONS")
+	r.HandleFunc("/CreateBooking", V1.Deprecated("/bookings", bookingHandler.CreateBooking)).Methods("POST", "OPTIONS")
+	r.Handle("/CreateSport", adminHandler.RequireAdmin(V1.Deprecated("/sports", sportHandler.CreateSport))).Methods("POST", "OPTIONS")
+	r.Handle("/DeleteSport", adminHandler.RequireAdmin(V1.Deprecated("/sports", sportHandler.DeleteSport))).Methods("DELETE", "OPTIONS")
+	r.Handle("/ResetSportCourts", adminHandler.RequireAdmin(V1.Deprecated("/sports", sportHandler.ResetSportCourts))).Methods("POST", "OPTIONS")
+	r.Handle("/admin/deleteAllBookings", adminHandler.RequireAdmin(http.HandlerFunc(utilsHandler.DeleteAllBookings))).Methods("DELETE", "OPTIONS")
+	r.Handle("/admin/resetSystem", adminHandler.RequireAdmin(http.HandlerFunc(utilsHandler.ResetSystem))).Methods("DELETE", "OPTIONS")
+	r.Handle("/DeleteCourt", adminHandler.RequireAdmin(V1.Deprecated("/courts", courtHandler.DeleteCourt))).Methods("DELETE", "OPTIONS")
+	r.Handle("/CreateCourt", adminHandler.RequireAdmin(V1.Deprecated("/courts", courtHandler.CreateCourtWithTimeSlots))).Methods("POST", "OPTIONS")
+	r.HandleFunc("/ListSports", V1.Deprecated("/sports", sportHandler.ListSports)).Methods("GET", "OPTIONS")
+	r.HandleFunc("/ListCourts", V1.Deprecated("/courts", courtHandler.ListCourts)).Methods("GET", "OPTIONS")
+	r.HandleFunc("/CancelBookingandUpdateSlot", V1.Deprecated("/bookings", courtHandler.CancelBookingandUpdateSlot)).Methods("PUT", "OPTIONS")
+	r.HandleFunc("/listBookings", V1.Deprecated("/customers", bookingHandler.ListBookings)).Methods("GET", "OPTIONS")
+	r.HandleFunc("/cancelBooking", V1.Deprecated("/bookings", bookingHandler.CancelBooking)).Methods("POST", "OPTIONS")
+	r.Handle("/resetCourtSlots", adminHandler.RequireAdmin(V1.Deprecated("/courts", courtHandler.ResetCourtSlotsHandler))).Methods("PUT", "OPTIONS")
+
+	adminRoutes(r, adminHandler, courtHandler)
 
 	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
-	handler := corsHandler.Handler(r)
+	idempotent := Idempotency.Middleware(store, time.Duration(config.Idempotency.TTL))
+	handler := corsHandler.Handler(APIError.RequestID(idempotent(r)))
+
+	// SIGINT or SIGTERM drains requests, waits for running jobs and closes the pool
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverConfig := config.Server.HTTP()
+	srv := Server.New(serverConfig, handler)
+	// Open availability streams never finish by themselves, so end them before draining
+	srv.RegisterOnShutdown(broker.Close)
+	err = Server.Run(ctx, srv, serverConfig.ShutdownTimeout,
+		func(ctx context.Context) error {
+			select {
+			case <-scheduler.Stop().Done():
+				return nil
+			case <-ctx.Done():
+				return fmt.Errorf("scheduler jobs still running: %w", ctx.Err())
+			}
+		},
+		func(ctx context.Context) error {
+			if jwks != nil {
+				jwks.EndBackground()
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.Close()
+		},
+	)
+	if err != nil {
+		log.Fatalf("Server stopped with errors: %v", err)
+	}
+	log.Println("Server stopped")
+}
+
+// adminRoutes registers /AdminLogin and the routes under /admin.
+func adminRoutes(r *mux.Router, admins *Admin.Handler, courts *Court.Handler) {
+	admin := func(f http.HandlerFunc) http.Handler { return admins.RequireAdmin(f) }
+	superAdmin := func(f http.HandlerFunc) http.Handler { return admins.RequireSuperAdmin(f) }
+
+	r.HandleFunc("/AdminLogin", admins.AdminLogin).Methods("POST", "OPTIONS")
+	r.HandleFunc("/admin/totp/enroll", admins.AdminEnrollTOTP).Methods("POST", "OPTIONS")
+	r.HandleFunc("/admin/totp/verify", admins.AdminVerifyTOTP).Methods("POST", "OPTIONS")
+	r.HandleFunc("/admin/bootstrap", admins.BootstrapAdmin).Methods("POST", "OPTIONS")
+	r.HandleFunc("/admin/admins/password", admins.SetAdminPassword).Methods("POST", "OPTIONS")
+
+	// Managing bookings, courts and data takes the session of an active admin, issued by /AdminLogin
+	r.Handle("/admin/allBookings", admin(admins.GetAllBookings)).Methods("GET", "OPTIONS")
+	// Superseded by POST /admin/bookings/{id}/status with cancelled_by_admin
+	r.Handle("/admin/cancelBooking", admin(V1.Deprecated("", admins.AdminCancelBooking))).Methods("POST", "OPTIONS")
+	r.Handle("/admin/bookings/{id}/status", admin(admins.SetBookingStatus)).Methods("POST", "OPTIONS")
+	r.Handle("/admin/bookings/{id}/history", admin(admins.GetBookingHistory)).Methods("GET", "OPTIONS")
+	r.Handle("/admin/blackouts", admin(courts.ListBlackouts)).Methods("GET", "OPTIONS")
+	r.Handle("/admin/blackouts", admin(courts.CreateBlackout)).Methods("POST", "OPTIONS")
+	r.Handle("/admin/blackouts/{id}", admin(courts.DeleteBlackout)).Methods("DELETE", "OPTIONS")
+	r.Handle("/admin/courts/import", admin(courts.ImportCourts)).Methods("POST", "OPTIONS")
+	r.Handle("/admin/reconcile", admin(admins.ReconcileAvailability)).Methods("POST", "OPTIONS")
+	r.Handle("/admin/export", admin(admins.ExportData)).Methods("GET", "OPTIONS")
+	// Importing writes records in bulk, so it takes a super admin
+	r.Handle("/admin/import", superAdmin(admins.ImportData)).Methods("POST", "OPTIONS")
 
-	fmt.Println("Server is running on port 8080")
-	log.Fatal(http.ListenAndServe(":8080", handler))
+	// Deleted records are only listed and restored with the session of an active admin
+	r.Handle("/admin/deleted", admin(admins.ListDeleted)).Methods("GET", "OPTIONS")
+	r.Handle("/admin/sports/{id}/restore", admin(admins.RestoreSport)).Methods("POST", "OPTIONS")
+	r.Handle("/admin/courts/{id}/restore", admin(admins.RestoreCourt)).Methods("POST", "OPTIONS")
+	r.Handle("/admin/bookings/{id}/restore", admin(admins.RestoreBooking)).Methods("POST", "OPTIONS")
+
+	// Managing admins takes the session of an active super admin
+	r.Handle("/admin/admins", superAdmin(admins.ListAdmins)).Methods("GET", "OPTIONS")
+	r.Handle("/admin/admins", superAdmin(admins.InviteAdmin)).Methods("POST", "OPTIONS")
+	r.Handle("/admin/admins/{id}/role", superAdmin(admins.SetAdminRole)).Methods("PUT", "OPTIONS")
+	r.Handle("/admin/admins/{id}/deactivate", superAdmin(admins.DeactivateAdmin)).Methods("POST", "OPTIONS")
+	r.Handle("/admin/admins/{id}/reset-password", superAdmin(admins.ResetAdminPassword)).Methods("POST", "OPTIONS")
 }
 
-func startScheduler() {
+// schedulerHeartbeatMaxAge is how long the scheduler may go without running its heartbeat
+// job before the liveness probe fails.
+const schedulerHeartbeatMaxAge = 3 * time.Minute
+
+func startScheduler(store Repository.Store, schedule Config.Schedule, heartbeat *Health.Heartbeat) *cron.Cron {
 	c := cron.New()
-	_, err := c.AddFunc("0 0 * * *", func() {
-		log.Println("Resetting court time slots at midnight...")
-		if err := Utils.ResetTimeSlotsForAvailableCourts(""); err != nil {
-			log.Printf("Error resetting slots: %v", err)
+	_, err := c.AddFunc("@every 1m", heartbeat.Beat)
+	if err != nil {
+		log.Fatalf("Failed to schedule heartbeat job: %v", err)
+	}
+
+	_, err = c.AddFunc(schedule.Rollover, func() {
+		log.Println("Archiving past bookings...")
+		if err := Utils.RolloverBookings(store, time.Now()); err != nil {
+			log.Printf("Error archiving bookings: %v", err)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Failed to schedule rollover job: %v", err)
+	}
+
+	// Only report inconsistencies unless the apply mode asks for them to be repaired
+	apply := schedule.ReconcileMode == Config.ReconcileApply
+	_, err = c.AddFunc(schedule.Reconcile, func() {
+		report, err := Availability.Reconcile(store, apply)
+		if err != nil {
+			log.Printf("Error reconciling availability: %v", err)
+			return
+		}
+		for _, issue := range report.Issues {
+			log.Printf("Availability issue (%s, repaired=%t): %s", issue.Kind, issue.Repaired, issue.Detail)
+		}
+		log.Printf("Availability check found %d issue(s), apply=%t", len(report.Issues), apply)
+	})
+	if err != nil {
+		log.Fatalf("Failed to schedule reconcile job: %v", err)
+	}
+
+	// Soft deleted records can be restored until the retention period is over
+	retention := time.Duration(schedule.SoftDeleteRetentionDays) * 24 * time.Hour
+	_, err = c.AddFunc(schedule.Purge, func() {
+		if err := Utils.PurgeDeleted(store, retention); err != nil {
+			log.Printf("Error purging deleted records: %v", err)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Failed to schedule purge job: %v", err)
+	}
+
+	_, err = c.AddFunc(schedule.IdempotencyPurge, func() {
+		if err := Idempotency.Purge(store, time.Now()); err != nil {
+			log.Printf("Error purging idempotency keys: %v", err)
 		}
 	})
 	if err != nil {
-		log.Fatalf("Failed to schedule reset job: %v", err)
+		log.Fatalf("Failed to schedule idempotency purge job: %v", err)
 	}
 	c.Start()
+	heartbeat.Beat()
+	return c
 }
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"BackEnd/Admin"
+	"BackEnd/Court"
+	"BackEnd/DataBase"
+	"BackEnd/Repository/TestStore"
+	"BackEnd/V1"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestV1Authentication(t *testing.T) {
+	store := TestStore.Open(t)
+	adminHandler := Admin.NewHandler(store)
+	r := mux.NewRouter()
+	V1.NewHandler(store).Routes(r, validateToken, adminHandler.RequireAdmin)
+
+	store.Admins().Create(&DataBase.Admin{Username: "root", Password: "rootpass"})
+	login := httptest.NewRecorder()
+	body, _ := json.Marshal(Admin.LoginRequest{Username: "root", Password: "rootpass"})
+	adminHandler.AdminLogin(login, httptest.NewRequest("POST", "/AdminLogin", bytes.NewReader(body)))
+	var session Admin.LoginResponse
+	json.Unmarshal(login.Body.Bytes(), &session)
+	if session.Token == "" {
+		t.Fatalf("expected an admin session, got %d: %s", login.Code, login.Body.String())
+	}
+
+	call := func(method, path, authorization, payload string) int {
+		req := httptest.NewRequest(method, V1.Prefix+path, bytes.NewBufferString(payload))
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	cases := []struct {
+		name          string
+		method, path  string
+		authorization string
+		payload       string
+		status        int
+	}{
+		{"customer without a token", "PUT", "/customers/a@ufl.edu", "", `{"name":"A"}`, http.StatusUnauthorized},
+		{"customer with a malformed token", "PUT", "/customers/a@ufl.edu", "Bearer not-a-token", `{"name":"A"}`, http.StatusUnauthorized},
+		{"reading without a token", "GET", "/sports", "", "", http.StatusUnauthorized},
+		{"booking without a token", "POST", "/bookings", "", `{}`, http.StatusUnauthorized},
+		{"catalogue without a session", "POST", "/sports", "", `{"name":"Tennis"}`, http.StatusUnauthorized},
+		{"catalogue with another token", "DELETE", "/courts/1", "Bearer not-a-token", "", http.StatusUnauthorized},
+		{"catalogue as an admin", "POST", "/sports", "Bearer " + session.Token, `{"name":"Tennis"}`, http.StatusCreated},
+	}
+	for _, tc := range cases {
+		if code := call(tc.method, tc.path, tc.authorization, tc.payload); code != tc.status {
+			t.Errorf("%s: expected status %d, got %d", tc.name, tc.status, code)
+		}
+	}
+
+	if _, err := store.Customers().FindByEmail("a@ufl.edu"); err == nil {
+		t.Error("expected the unauthenticated PUT not to create the customer")
+	}
+}
+
+func TestAdminRoutesTakeASession(t *testing.T) {
+	store := TestStore.Open(t)
+	adminHandler := Admin.NewHandler(store)
+	r := mux.NewRouter()
+	adminRoutes(r, adminHandler, Court.NewHandler(store))
+
+	store.Admins().Create(&DataBase.Admin{Username: "desk", Password: "deskpass"})
+	login := httptest.NewRecorder()
+	body, _ := json.Marshal(Admin.LoginRequest{Username: "desk", Password: "deskpass"})
+	adminHandler.AdminLogin(login, httptest.NewRequest("POST", "/AdminLogin", bytes.NewReader(body)))
+	var session Admin.LoginResponse
+	json.Unmarshal(login.Body.Bytes(), &session)
+	if session.Token == "" {
+		t.Fatalf("expected an admin session, got %d: %s", login.Code, login.Body.String())
+	}
+
+	routes := []struct{ method, path string }{
+		{"GET", "/admin/allBookings"},
+		{"POST", "/admin/cancelBooking"},
+		{"POST", "/admin/bookings/1/status"},
+		{"GET", "/admin/bookings/1/history"},
+		{"GET", "/admin/blackouts"},
+		{"POST", "/admin/blackouts"},
+		{"DELETE", "/admin/blackouts/1"},
+		{"POST", "/admin/courts/import"},
+		{"POST", "/admin/reconcile"},
+		{"GET", "/admin/export"},
+		{"POST", "/admin/import"},
+		{"GET", "/admin/deleted"},
+		{"GET", "/admin/admins"},
+	}
+	for _, route := range routes {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(route.method, route.path, bytes.NewBufferString("{}")))
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s without a session: expected status 401, got %d", route.method, route.path, rr.Code)
+		}
+	}
+
+	// Any admin may export, but only a super admin may import
+	cases := []struct {
+		method, path string
+		status       int
+	}{
+		{"GET", "/admin/export", http.StatusOK},
+		{"POST", "/admin/import", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, bytes.NewBufferString("{}"))
+		req.Header.Set("Authorization", "Bearer "+session.Token)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != tc.status {
+			t.Errorf("%s %s as an admin: expected status %d, got %d", tc.method, tc.path, tc.status, rr.Code)
+		}
+	}
+}
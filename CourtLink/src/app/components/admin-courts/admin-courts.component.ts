@@ -58,7 +58,7 @@ export class AdminCourtsComponent implements OnInit {
       if (result) {
         this.http.post('http://localhost:8080/CreateCourt', result).subscribe({
           next: () => this.fetchCourts(),
-          error: (err) => alert('Error creating court: ' + err.error),
+          error: (err) => alert('Error creating court: ' + (err.error?.message ?? err.message)),
         });
       }
     });
@@ -71,7 +71,7 @@ export class AdminCourtsComponent implements OnInit {
       })
       .subscribe({
         next: () => this.fetchCourts(),
-        error: (err) => alert('Error deleting court: ' + err.error),
+        error: (err) => alert('Error deleting court: ' + (err.error?.message ?? err.message)),
       });
   }
 
@@ -45,7 +45,7 @@ describe('AdminLoginComponent', () => {
   });
 
   it('should display error on failed login', () => {
-    spyOn(component['http'], 'post').and.returnValue(throwError(() => ({ error: 'Invalid login' })));
+    spyOn(component['http'], 'post').and.returnValue(throwError(() => ({ error: { code: 'INVALID_CREDENTIALS', message: 'Invalid login' } })));
 
     component.username = 'wrong';
     component.password = 'wrong';
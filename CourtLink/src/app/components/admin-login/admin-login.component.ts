@@ -29,7 +29,7 @@ export class AdminLoginComponent {
         this.router.navigate(['/admin-portal']);
       },
       error: (err) => {
-        this.errorMessage = err.error || 'Login failed. Check credentials.';
+        this.errorMessage = err.error?.message || 'Login failed. Check credentials.';
       }
     });
   }
@@ -54,7 +54,7 @@ export class AdminSportsComponent implements OnInit {
             this.sports = [...this.sports]; // trigger update
           },
           error: (err) => {
-            alert('Error creating sport: ' + err.error);
+            alert('Error creating sport: ' + (err.error?.message ?? err.message));
           },
         });
       }
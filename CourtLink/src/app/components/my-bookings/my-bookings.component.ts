@@ -37,8 +37,8 @@ export class MyBookingsComponent {
     this.http.get<any[]>(url).subscribe({
       next: (data) => {
         const sorted = data.sort((a, b) => {
-          if (a.booking_status === 'booked' && b.booking_status !== 'booked') return -1;
-          if (a.booking_status !== 'booked' && b.booking_status === 'booked') return 1;
+          if (a.booking_status === 'confirmed' && b.booking_status !== 'confirmed') return -1;
+          if (a.booking_status !== 'confirmed' && b.booking_status === 'confirmed') return 1;
           return 0;
         });
         this.bookings.set(sorted);
@@ -72,7 +72,7 @@ export class MyBookingsComponent {
         // 🕒 Delay updating list until animation finishes
         setTimeout(() => {
           const updated = this.bookings().map(b =>
-            b.booking_id === bookingId ? { ...b, booking_status: 'cancelled' } : b
+            b.booking_id === bookingId ? { ...b, booking_status: 'cancelled_by_user' } : b
           );
           this.bookings.set(updated);
           this.cancellingId.set(null);
@@ -100,10 +100,10 @@ export class MyBookingsComponent {
     const all = this.bookings();
     return this.showCancelled()
       ? all.sort((a, b) => {
-          if (a.booking_status === 'booked' && b.booking_status !== 'booked') return -1;
-          if (a.booking_status !== 'booked' && b.booking_status === 'booked') return 1;
+          if (a.booking_status === 'confirmed' && b.booking_status !== 'confirmed') return -1;
+          if (a.booking_status !== 'confirmed' && b.booking_status === 'confirmed') return 1;
           return 0;
         })
-      : all.filter(b => b.booking_status === 'booked');
+      : all.filter(b => b.booking_status === 'confirmed');
   }
 }